@@ -0,0 +1,114 @@
+package errorpb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoding selects the wire format problem responses are written in.
+type Encoding int
+
+const (
+	EncodingProtobuf Encoding = iota
+	EncodingJSON
+)
+
+const (
+	ProtobufContentType = "application/problem+protobuf"
+	JSONContentType     = "application/problem+json"
+
+	// ProtoParameter is the parameter of ProtobufContentType naming the
+	// full name of the problem message, e.g. errorpb.ValidationProblem.
+	ProtoParameter = "proto"
+)
+
+type encodingKey struct{}
+
+// WithEncoding returns a context that makes WriteHttpResponse use enc.
+func WithEncoding(ctx context.Context, enc Encoding) context.Context {
+	return context.WithValue(ctx, encodingKey{}, enc)
+}
+
+// EncodingFromContext returns the encoding set by WithEncoding, defaulting to
+// EncodingProtobuf.
+func EncodingFromContext(ctx context.Context) Encoding {
+	enc, _ := ctx.Value(encodingKey{}).(Encoding)
+	return enc
+}
+
+// problemFieldName is the field every problem extension message embeds its
+// base Problem in.
+const problemFieldName = "problem"
+
+var jsonMarshalOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// MarshalJSON encodes msg as an RFC 7807 problem document. Extension messages
+// like ValidationProblem have their embedded Problem members hoisted to the
+// top level next to the extension members.
+func MarshalJSON(msg proto.Message) ([]byte, error) {
+	b, err := jsonMarshalOptions.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ProtoReflect().Descriptor().Fields().ByName(problemFieldName) == nil {
+		return b, nil
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	problem, ok := members[problemFieldName]
+	if !ok {
+		return b, nil
+	}
+	delete(members, problemFieldName)
+
+	var problemMembers map[string]json.RawMessage
+	if err := json.Unmarshal(problem, &problemMembers); err != nil {
+		return nil, err
+	}
+	for k, v := range problemMembers {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON.
+func UnmarshalJSON(b []byte, msg proto.Message) error {
+	problemField := msg.ProtoReflect().Descriptor().Fields().ByName(problemFieldName)
+	if problemField == nil {
+		return protojson.Unmarshal(b, msg)
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return fmt.Errorf("invalid problem document: %w", err)
+	}
+
+	problemMembers := make(map[string]json.RawMessage)
+	baseFields := problemField.Message().Fields()
+	for i := 0; i < baseFields.Len(); i++ {
+		name := string(baseFields.Get(i).Name())
+		if v, ok := members[name]; ok {
+			problemMembers[name] = v
+			delete(members, name)
+		}
+	}
+
+	problem, err := json.Marshal(problemMembers)
+	if err != nil {
+		return err
+	}
+	members[problemFieldName] = problem
+
+	b, err = json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(b, msg)
+}
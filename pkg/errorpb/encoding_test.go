@@ -0,0 +1,61 @@
+package errorpb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	vp := NewValidationError("/api/v1/machines", []*InvalidField{
+		{Field: proto.String("nics"), Reason: proto.String("at least one NIC is required")},
+	})
+
+	b, err := MarshalJSON(vp)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(b, &members); err != nil {
+		t.Fatalf("failed to decode problem document: %v", err)
+	}
+	if _, ok := members["problem"]; ok {
+		t.Error("expected problem members to be hoisted to the top level")
+	}
+	if members["type"] != "https://api.example.com/errors/validation-error" {
+		t.Errorf("unexpected type %v", members["type"])
+	}
+	if members["status"] != float64(400) {
+		t.Errorf("unexpected status %v", members["status"])
+	}
+	if _, ok := members["invalid_fields"]; !ok {
+		t.Error("expected invalid_fields extension member")
+	}
+
+	var got ValidationProblem
+	if err := UnmarshalJSON(b, &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !proto.Equal(vp, &got) {
+		t.Errorf("round trip mismatch: want %v, got %v", vp, &got)
+	}
+}
+
+func TestMarshalJSON_Problem(t *testing.T) {
+	p := NewInternalError("/api/v1/machines", "boom")
+
+	b, err := MarshalJSON(p)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	var got Problem
+	if err := UnmarshalJSON(b, &got); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !proto.Equal(p, &got) {
+		t.Errorf("round trip mismatch: want %v, got %v", p, &got)
+	}
+}
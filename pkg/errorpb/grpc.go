@@ -0,0 +1,66 @@
+package errorpb
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// grpcCodes maps the HTTP status of a problem to the closest gRPC code.
+var grpcCodes = map[int32]codes.Code{
+	http.StatusBadRequest:           codes.InvalidArgument,
+	http.StatusNotFound:             codes.NotFound,
+	http.StatusConflict:             codes.Aborted,
+	http.StatusGone:                 codes.OutOfRange,
+	http.StatusPreconditionFailed:   codes.FailedPrecondition,
+	http.StatusUnsupportedMediaType: codes.InvalidArgument,
+	http.StatusUnprocessableEntity:  codes.FailedPrecondition,
+	http.StatusFailedDependency:     codes.Aborted,
+	http.StatusInternalServerError:  codes.Internal,
+}
+
+// GRPCStatus implements the interface status.FromError looks for, so a
+// problem returned from a gRPC handler reaches the client as a status
+// carrying the problem as its detail.
+func (p *Problem) GRPCStatus() *status.Status {
+	return grpcStatus(grpcCode(p), p, p)
+}
+
+func (vp *ValidationProblem) GRPCStatus() *status.Status {
+	return grpcStatus(codes.InvalidArgument, vp.GetProblem(), vp)
+}
+
+func (cp *ConflictProblem) GRPCStatus() *status.Status {
+	return grpcStatus(codes.AlreadyExists, cp.GetProblem(), cp)
+}
+
+func (mp *MachineNotFoundProblem) GRPCStatus() *status.Status {
+	return grpcStatus(codes.NotFound, mp.GetProblem(), mp)
+}
+
+func (ip *IdempotencyKeyMismatchProblem) GRPCStatus() *status.Status {
+	return grpcStatus(codes.FailedPrecondition, ip.GetProblem(), ip)
+}
+
+func (tp *IllegalTransitionProblem) GRPCStatus() *status.Status {
+	return grpcStatus(codes.FailedPrecondition, tp.GetProblem(), tp)
+}
+
+func grpcCode(p *Problem) codes.Code {
+	if code, ok := grpcCodes[p.GetStatus()]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+func grpcStatus(code codes.Code, p *Problem, detail proto.Message) *status.Status {
+	st := status.New(code, p.GetDetail())
+	withDetails, err := st.WithDetails(protoadapt.MessageV1Of(detail))
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
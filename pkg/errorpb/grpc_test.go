@@ -0,0 +1,48 @@
+package errorpb
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGRPCStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{"validation", NewValidationError("/m", nil), codes.InvalidArgument},
+		{"conflict", NewConflictError("/m", "1", nil), codes.AlreadyExists},
+		{"machine not found", NewMachineNotFoundError("/m", "1"), codes.NotFound},
+		{"precondition failed", NewPreconditionFailedError("/m", "1"), codes.FailedPrecondition},
+		{"resume token expired", NewResumeTokenExpiredError("/m", 1), codes.OutOfRange},
+		{"internal", NewInternalError("/m", "boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Wrapped, as the gRPC server sees errors that pass through
+			// interceptors.
+			st, ok := status.FromError(fmt.Errorf("rpc: %w", tt.err))
+			if !ok {
+				t.Fatal("expected the problem to convert to a status")
+			}
+			if st.Code() != tt.wantCode {
+				t.Errorf("want code %s, got %s", tt.wantCode, st.Code())
+			}
+
+			details := st.Details()
+			if len(details) != 1 {
+				t.Fatalf("want the problem as the only detail, got %v", details)
+			}
+			detail, ok := details[0].(proto.Message)
+			if !ok || !proto.Equal(detail, tt.err.(proto.Message)) {
+				t.Errorf("want detail %v, got %v", tt.err, details[0])
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package errorpb
 
 import (
 	"context"
+	"fmt"
+	"mime"
 	"net/http"
 
 	"google.golang.org/protobuf/proto"
@@ -11,33 +13,66 @@ func (p *Problem) Error() string {
 	return p.GetDetail()
 }
 
-func (p *Problem) WriteHttpResponse(_ context.Context, w http.ResponseWriter) {
-	writeProtoError(w, int(p.GetStatus()), p)
+func (p *Problem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(p.GetStatus()), p)
 }
 
 func (vp *ValidationProblem) Error() string {
 	return vp.GetProblem().GetDetail()
 }
 
-func (vp *ValidationProblem) WriteHttpResponse(_ context.Context, w http.ResponseWriter) {
-	writeProtoError(w, int(vp.GetProblem().GetStatus()), vp)
+func (vp *ValidationProblem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(vp.GetProblem().GetStatus()), vp)
 }
 
 func (cp *ConflictProblem) Error() string {
 	return cp.GetProblem().GetDetail()
 }
 
-func (cp *ConflictProblem) WriteHttpResponse(_ context.Context, w http.ResponseWriter) {
-	writeProtoError(w, int(cp.GetProblem().GetStatus()), cp)
+func (cp *ConflictProblem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(cp.GetProblem().GetStatus()), cp)
 }
 
-func writeProtoError(w http.ResponseWriter, status int, msg proto.Message) {
-	b, err := proto.Marshal(msg)
+func (mp *MachineNotFoundProblem) Error() string {
+	return mp.GetProblem().GetDetail()
+}
+
+func (mp *MachineNotFoundProblem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(mp.GetProblem().GetStatus()), mp)
+}
+
+func (ip *IdempotencyKeyMismatchProblem) Error() string {
+	return ip.GetProblem().GetDetail()
+}
+
+func (ip *IdempotencyKeyMismatchProblem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(ip.GetProblem().GetStatus()), ip)
+}
+
+func (tp *IllegalTransitionProblem) Error() string {
+	return tp.GetProblem().GetDetail()
+}
+
+func (tp *IllegalTransitionProblem) WriteHttpResponse(ctx context.Context, w http.ResponseWriter) {
+	writeProtoError(ctx, w, int(tp.GetProblem().GetStatus()), tp)
+}
+
+func writeProtoError(ctx context.Context, w http.ResponseWriter, status int, msg proto.Message) {
+	// Unlike a problem document, the protobuf encoding does not identify
+	// its message, so the media type names it.
+	marshal, contentType := proto.Marshal, mime.FormatMediaType(ProtobufContentType, map[string]string{
+		ProtoParameter: string(msg.ProtoReflect().Descriptor().FullName()),
+	})
+	if EncodingFromContext(ctx) == EncodingJSON {
+		marshal, contentType = MarshalJSON, JSONContentType
+	}
+
+	b, err := marshal(msg)
 	if err != nil {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/problem+protobuf")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	w.Write(b)
 }
@@ -69,6 +104,118 @@ func NewConflictError(instance, existingResourceID string, conflictingFields map
 	}
 }
 
+func NewMachineNotFoundError(instance, machineID string) *MachineNotFoundProblem {
+	return &MachineNotFoundProblem{
+		Problem: &Problem{
+			Type:     proto.String("https://api.example.com/errors/machine-not-found"),
+			Title:    proto.String("Machine Not Found"),
+			Status:   proto.Int32(http.StatusNotFound),
+			Detail:   proto.String(fmt.Sprintf("Machine with ID %s not found", machineID)),
+			Instance: proto.String(instance),
+		},
+		MachineId: proto.String(machineID),
+	}
+}
+
+func NewIdempotencyKeyMismatchError(instance, idempotencyKey string) *IdempotencyKeyMismatchProblem {
+	return &IdempotencyKeyMismatchProblem{
+		Problem: &Problem{
+			Type:     proto.String("https://api.example.com/errors/idempotency-key-mismatch"),
+			Title:    proto.String("Idempotency Key Mismatch"),
+			Status:   proto.Int32(http.StatusUnprocessableEntity),
+			Detail:   proto.String("The idempotency key was already used with a different request body"),
+			Instance: proto.String(instance),
+		},
+		IdempotencyKey: proto.String(idempotencyKey),
+	}
+}
+
+func NewIllegalTransitionError(instance, resourceID, from, to string, allowed []string) *IllegalTransitionProblem {
+	return &IllegalTransitionProblem{
+		Problem: &Problem{
+			Type:     proto.String("https://api.example.com/errors/illegal-transition"),
+			Title:    proto.String("Illegal Transition"),
+			Status:   proto.Int32(http.StatusConflict),
+			Detail:   proto.String(fmt.Sprintf("Resource with ID %s cannot transition from %s to %s", resourceID, from, to)),
+			Instance: proto.String(instance),
+		},
+		ResourceId:    proto.String(resourceID),
+		FromState:     proto.String(from),
+		ToState:       proto.String(to),
+		AllowedStates: allowed,
+	}
+}
+
+func NewRevisionNotFoundError(instance, machineID string, revision int64) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/revision-not-found"),
+		Title:    proto.String("Revision Not Found"),
+		Status:   proto.Int32(http.StatusNotFound),
+		Detail:   proto.String(fmt.Sprintf("Machine with ID %s has no revision %d", machineID, revision)),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewWebhookNotFoundError(instance, webhookID string) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/webhook-not-found"),
+		Title:    proto.String("Webhook Not Found"),
+		Status:   proto.Int32(http.StatusNotFound),
+		Detail:   proto.String(fmt.Sprintf("Webhook with ID %s does not exist", webhookID)),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewDeliveryNotFoundError(instance, webhookID string, sequence int64) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/delivery-not-found"),
+		Title:    proto.String("Delivery Not Found"),
+		Status:   proto.Int32(http.StatusNotFound),
+		Detail:   proto.String(fmt.Sprintf("Webhook with ID %s has no delivery of event %d", webhookID, sequence)),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewResumeTokenExpiredError(instance string, sequence int64) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/resume-token-expired"),
+		Title:    proto.String("Resume Token Expired"),
+		Status:   proto.Int32(http.StatusGone),
+		Detail:   proto.String(fmt.Sprintf("Changes after event %d are no longer retained; reconnect without Last-Event-ID and resynchronize", sequence)),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewPreconditionFailedError(instance, machineID string) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/precondition-failed"),
+		Title:    proto.String("Precondition Failed"),
+		Status:   proto.Int32(http.StatusPreconditionFailed),
+		Detail:   proto.String(fmt.Sprintf("Machine with ID %s has been modified since it was last read", machineID)),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewBatchAbortedError(instance string) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/batch-aborted"),
+		Title:    proto.String("Batch Aborted"),
+		Status:   proto.Int32(http.StatusFailedDependency),
+		Detail:   proto.String("The machine was not created because another machine in the atomic batch failed"),
+		Instance: proto.String(instance),
+	}
+}
+
+func NewUnsupportedMediaTypeError(instance, contentType string) *Problem {
+	return &Problem{
+		Type:     proto.String("https://api.example.com/errors/unsupported-media-type"),
+		Title:    proto.String("Unsupported Media Type"),
+		Status:   proto.Int32(http.StatusUnsupportedMediaType),
+		Detail:   proto.String(fmt.Sprintf("Content-Type %s is not supported", contentType)),
+		Instance: proto.String(instance),
+	}
+}
+
 func NewInternalError(instance, detail string) *Problem {
 	return &Problem{
 		Type:     proto.String("https://api.example.com/errors/internal-error"),
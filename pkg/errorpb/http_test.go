@@ -0,0 +1,63 @@
+package errorpb
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWriteHttpResponse_ContentType(t *testing.T) {
+	vp := NewValidationError("/api/v1/machines", []*InvalidField{
+		{Field: proto.String("nics"), Reason: proto.String("is required")},
+	})
+
+	tests := []struct {
+		name      string
+		enc       Encoding
+		mediaType string
+		params    map[string]string
+	}{
+		{
+			name:      "protobuf names the message",
+			enc:       EncodingProtobuf,
+			mediaType: ProtobufContentType,
+			params:    map[string]string{ProtoParameter: "errorpb.ValidationProblem"},
+		},
+		{
+			name:      "json",
+			enc:       EncodingJSON,
+			mediaType: JSONContentType,
+			params:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			vp.WriteHttpResponse(WithEncoding(context.Background(), tt.enc), w)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want status 400, got %d", w.Code)
+			}
+			mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("invalid Content-Type: %v", err)
+			}
+			if mediaType != tt.mediaType {
+				t.Errorf("want media type %s, got %s", tt.mediaType, mediaType)
+			}
+			for k, v := range tt.params {
+				if params[k] != v {
+					t.Errorf("want parameter %s=%s, got %q", k, v, params[k])
+				}
+			}
+			if len(params) != len(tt.params) {
+				t.Errorf("unexpected parameters %v", params)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: idempotency_key_mismatch_problem.proto
+
+package errorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IdempotencyKeyMismatchProblem struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Problem        *Problem               `protobuf:"bytes,1,opt,name=problem" json:"problem,omitempty"`
+	IdempotencyKey *string                `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IdempotencyKeyMismatchProblem) Reset() {
+	*x = IdempotencyKeyMismatchProblem{}
+	mi := &file_idempotency_key_mismatch_problem_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdempotencyKeyMismatchProblem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdempotencyKeyMismatchProblem) ProtoMessage() {}
+
+func (x *IdempotencyKeyMismatchProblem) ProtoReflect() protoreflect.Message {
+	mi := &file_idempotency_key_mismatch_problem_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdempotencyKeyMismatchProblem.ProtoReflect.Descriptor instead.
+func (*IdempotencyKeyMismatchProblem) Descriptor() ([]byte, []int) {
+	return file_idempotency_key_mismatch_problem_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IdempotencyKeyMismatchProblem) GetProblem() *Problem {
+	if x != nil {
+		return x.Problem
+	}
+	return nil
+}
+
+func (x *IdempotencyKeyMismatchProblem) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+var File_idempotency_key_mismatch_problem_proto protoreflect.FileDescriptor
+
+const file_idempotency_key_mismatch_problem_proto_rawDesc = "" +
+	"\n" +
+	"&idempotency_key_mismatch_problem.proto\x12\aerrorpb\x1a\rproblem.proto\"t\n" +
+	"\x1dIdempotencyKeyMismatchProblem\x12*\n" +
+	"\aproblem\x18\x01 \x01(\v2\x10.errorpb.ProblemR\aproblem\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKeyB.Z,github.com/Zaba505/infra/pkg/errorpb;errorpbb\beditionsp\xe8\a"
+
+var (
+	file_idempotency_key_mismatch_problem_proto_rawDescOnce sync.Once
+	file_idempotency_key_mismatch_problem_proto_rawDescData []byte
+)
+
+func file_idempotency_key_mismatch_problem_proto_rawDescGZIP() []byte {
+	file_idempotency_key_mismatch_problem_proto_rawDescOnce.Do(func() {
+		file_idempotency_key_mismatch_problem_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idempotency_key_mismatch_problem_proto_rawDesc), len(file_idempotency_key_mismatch_problem_proto_rawDesc)))
+	})
+	return file_idempotency_key_mismatch_problem_proto_rawDescData
+}
+
+var file_idempotency_key_mismatch_problem_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_idempotency_key_mismatch_problem_proto_goTypes = []any{
+	(*IdempotencyKeyMismatchProblem)(nil), // 0: errorpb.IdempotencyKeyMismatchProblem
+	(*Problem)(nil),                       // 1: errorpb.Problem
+}
+var file_idempotency_key_mismatch_problem_proto_depIdxs = []int32{
+	1, // 0: errorpb.IdempotencyKeyMismatchProblem.problem:type_name -> errorpb.Problem
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_idempotency_key_mismatch_problem_proto_init() }
+func file_idempotency_key_mismatch_problem_proto_init() {
+	if File_idempotency_key_mismatch_problem_proto != nil {
+		return
+	}
+	file_problem_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idempotency_key_mismatch_problem_proto_rawDesc), len(file_idempotency_key_mismatch_problem_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_idempotency_key_mismatch_problem_proto_goTypes,
+		DependencyIndexes: file_idempotency_key_mismatch_problem_proto_depIdxs,
+		MessageInfos:      file_idempotency_key_mismatch_problem_proto_msgTypes,
+	}.Build()
+	File_idempotency_key_mismatch_problem_proto = out.File
+	file_idempotency_key_mismatch_problem_proto_goTypes = nil
+	file_idempotency_key_mismatch_problem_proto_depIdxs = nil
+}
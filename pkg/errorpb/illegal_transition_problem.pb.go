@@ -0,0 +1,163 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: illegal_transition_problem.proto
+
+package errorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IllegalTransitionProblem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Problem       *Problem               `protobuf:"bytes,1,opt,name=problem" json:"problem,omitempty"`
+	ResourceId    *string                `protobuf:"bytes,2,opt,name=resource_id,json=resourceId" json:"resource_id,omitempty"`
+	FromState     *string                `protobuf:"bytes,3,opt,name=from_state,json=fromState" json:"from_state,omitempty"`
+	ToState       *string                `protobuf:"bytes,4,opt,name=to_state,json=toState" json:"to_state,omitempty"`
+	AllowedStates []string               `protobuf:"bytes,5,rep,name=allowed_states,json=allowedStates" json:"allowed_states,omitempty"` // states from_state may move to
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IllegalTransitionProblem) Reset() {
+	*x = IllegalTransitionProblem{}
+	mi := &file_illegal_transition_problem_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IllegalTransitionProblem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IllegalTransitionProblem) ProtoMessage() {}
+
+func (x *IllegalTransitionProblem) ProtoReflect() protoreflect.Message {
+	mi := &file_illegal_transition_problem_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IllegalTransitionProblem.ProtoReflect.Descriptor instead.
+func (*IllegalTransitionProblem) Descriptor() ([]byte, []int) {
+	return file_illegal_transition_problem_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IllegalTransitionProblem) GetProblem() *Problem {
+	if x != nil {
+		return x.Problem
+	}
+	return nil
+}
+
+func (x *IllegalTransitionProblem) GetResourceId() string {
+	if x != nil && x.ResourceId != nil {
+		return *x.ResourceId
+	}
+	return ""
+}
+
+func (x *IllegalTransitionProblem) GetFromState() string {
+	if x != nil && x.FromState != nil {
+		return *x.FromState
+	}
+	return ""
+}
+
+func (x *IllegalTransitionProblem) GetToState() string {
+	if x != nil && x.ToState != nil {
+		return *x.ToState
+	}
+	return ""
+}
+
+func (x *IllegalTransitionProblem) GetAllowedStates() []string {
+	if x != nil {
+		return x.AllowedStates
+	}
+	return nil
+}
+
+var File_illegal_transition_problem_proto protoreflect.FileDescriptor
+
+const file_illegal_transition_problem_proto_rawDesc = "" +
+	"\n" +
+	" illegal_transition_problem.proto\x12\aerrorpb\x1a\rproblem.proto\"\xc8\x01\n" +
+	"\x18IllegalTransitionProblem\x12*\n" +
+	"\aproblem\x18\x01 \x01(\v2\x10.errorpb.ProblemR\aproblem\x12\x1f\n" +
+	"\vresource_id\x18\x02 \x01(\tR\n" +
+	"resourceId\x12\x1d\n" +
+	"\n" +
+	"from_state\x18\x03 \x01(\tR\tfromState\x12\x19\n" +
+	"\bto_state\x18\x04 \x01(\tR\atoState\x12%\n" +
+	"\x0eallowed_states\x18\x05 \x03(\tR\rallowedStatesB.Z,github.com/Zaba505/infra/pkg/errorpb;errorpbb\beditionsp\xe8\a"
+
+var (
+	file_illegal_transition_problem_proto_rawDescOnce sync.Once
+	file_illegal_transition_problem_proto_rawDescData []byte
+)
+
+func file_illegal_transition_problem_proto_rawDescGZIP() []byte {
+	file_illegal_transition_problem_proto_rawDescOnce.Do(func() {
+		file_illegal_transition_problem_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_illegal_transition_problem_proto_rawDesc), len(file_illegal_transition_problem_proto_rawDesc)))
+	})
+	return file_illegal_transition_problem_proto_rawDescData
+}
+
+var file_illegal_transition_problem_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_illegal_transition_problem_proto_goTypes = []any{
+	(*IllegalTransitionProblem)(nil), // 0: errorpb.IllegalTransitionProblem
+	(*Problem)(nil),                  // 1: errorpb.Problem
+}
+var file_illegal_transition_problem_proto_depIdxs = []int32{
+	1, // 0: errorpb.IllegalTransitionProblem.problem:type_name -> errorpb.Problem
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_illegal_transition_problem_proto_init() }
+func file_illegal_transition_problem_proto_init() {
+	if File_illegal_transition_problem_proto != nil {
+		return
+	}
+	file_problem_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_illegal_transition_problem_proto_rawDesc), len(file_illegal_transition_problem_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_illegal_transition_problem_proto_goTypes,
+		DependencyIndexes: file_illegal_transition_problem_proto_depIdxs,
+		MessageInfos:      file_illegal_transition_problem_proto_msgTypes,
+	}.Build()
+	File_illegal_transition_problem_proto = out.File
+	file_illegal_transition_problem_proto_goTypes = nil
+	file_illegal_transition_problem_proto_depIdxs = nil
+}
@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: machine_not_found_problem.proto
+
+package errorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MachineNotFoundProblem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Problem       *Problem               `protobuf:"bytes,1,opt,name=problem" json:"problem,omitempty"`
+	MachineId     *string                `protobuf:"bytes,2,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineNotFoundProblem) Reset() {
+	*x = MachineNotFoundProblem{}
+	mi := &file_machine_not_found_problem_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineNotFoundProblem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineNotFoundProblem) ProtoMessage() {}
+
+func (x *MachineNotFoundProblem) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_not_found_problem_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineNotFoundProblem.ProtoReflect.Descriptor instead.
+func (*MachineNotFoundProblem) Descriptor() ([]byte, []int) {
+	return file_machine_not_found_problem_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineNotFoundProblem) GetProblem() *Problem {
+	if x != nil {
+		return x.Problem
+	}
+	return nil
+}
+
+func (x *MachineNotFoundProblem) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+var File_machine_not_found_problem_proto protoreflect.FileDescriptor
+
+const file_machine_not_found_problem_proto_rawDesc = "" +
+	"\n" +
+	"\x1fmachine_not_found_problem.proto\x12\aerrorpb\x1a\rproblem.proto\"c\n" +
+	"\x16MachineNotFoundProblem\x12*\n" +
+	"\aproblem\x18\x01 \x01(\v2\x10.errorpb.ProblemR\aproblem\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x02 \x01(\tR\tmachineIdB.Z,github.com/Zaba505/infra/pkg/errorpb;errorpbb\beditionsp\xe8\a"
+
+var (
+	file_machine_not_found_problem_proto_rawDescOnce sync.Once
+	file_machine_not_found_problem_proto_rawDescData []byte
+)
+
+func file_machine_not_found_problem_proto_rawDescGZIP() []byte {
+	file_machine_not_found_problem_proto_rawDescOnce.Do(func() {
+		file_machine_not_found_problem_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_machine_not_found_problem_proto_rawDesc), len(file_machine_not_found_problem_proto_rawDesc)))
+	})
+	return file_machine_not_found_problem_proto_rawDescData
+}
+
+var file_machine_not_found_problem_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_machine_not_found_problem_proto_goTypes = []any{
+	(*MachineNotFoundProblem)(nil), // 0: errorpb.MachineNotFoundProblem
+	(*Problem)(nil),                // 1: errorpb.Problem
+}
+var file_machine_not_found_problem_proto_depIdxs = []int32{
+	1, // 0: errorpb.MachineNotFoundProblem.problem:type_name -> errorpb.Problem
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_machine_not_found_problem_proto_init() }
+func file_machine_not_found_problem_proto_init() {
+	if File_machine_not_found_problem_proto != nil {
+		return
+	}
+	file_problem_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_machine_not_found_problem_proto_rawDesc), len(file_machine_not_found_problem_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_machine_not_found_problem_proto_goTypes,
+		DependencyIndexes: file_machine_not_found_problem_proto_depIdxs,
+		MessageInfos:      file_machine_not_found_problem_proto_msgTypes,
+	}.Build()
+	File_machine_not_found_problem_proto = out.File
+	file_machine_not_found_problem_proto_goTypes = nil
+	file_machine_not_found_problem_proto_depIdxs = nil
+}
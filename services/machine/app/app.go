@@ -16,28 +16,76 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/Zaba505/infra/services/machine/endpoint"
+	"github.com/Zaba505/infra/services/machine/events"
 	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/Zaba505/infra/services/machine/webhooks"
 	"github.com/go-chi/chi/v5"
 	"github.com/sourcegraph/conc/pool"
 	"github.com/z5labs/bedrock/config"
+	"google.golang.org/grpc"
 )
 
 type Config struct {
-	HTTP      HTTPConfig
-	Firestore FirestoreConfig
+	HTTP        HTTPConfig
+	Storage     StorageConfig
+	Firestore   FirestoreConfig
+	Retention   RetentionConfig
+	Idempotency IdempotencyConfig
+	Watch       WatchConfig
+	Events      EventsConfig
+	Webhooks    WebhooksConfig
 }
 
+// HTTPConfig controls the server. Shutdown waits up to ShutdownTimeout for
+// in-flight requests before closing their connections.
 type HTTPConfig struct {
-	Port int
+	Port            int
+	ShutdownTimeout time.Duration
 }
 
 type FirestoreConfig struct {
 	ProjectID string
 }
 
+// RetentionConfig controls how long deleted machines can be restored before
+// they are purged for good.
+type RetentionConfig struct {
+	Period        time.Duration
+	PurgeInterval time.Duration
+}
+
+// IdempotencyConfig controls how long responses to requests carrying an
+// Idempotency-Key are kept for replay.
+type IdempotencyConfig struct {
+	TTL time.Duration
+}
+
+// WatchConfig controls the machine change stream. Changes older than
+// Retention are purged along with deleted machines, after which watchers
+// that were disconnected for longer have to resynchronize.
+type WatchConfig struct {
+	PollInterval time.Duration
+	Retention    time.Duration
+}
+
+// WebhooksConfig controls webhook deliveries. Failed deliveries are retried
+// with exponential backoff from MinBackoff to MaxBackoff, for up to Horizon
+// after they were enqueued. Settled deliveries are kept in the delivery log
+// for Retention.
+type WebhooksConfig struct {
+	PollInterval time.Duration
+	Horizon      time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	Concurrency  int
+	Retention    time.Duration
+}
+
 func ConfigFromEnv(ctx context.Context) Config {
 	return Config{
 		HTTP: HTTPConfig{
@@ -48,9 +96,150 @@ func ConfigFromEnv(ctx context.Context) Config {
 					config.IntFromString(config.Env("HTTP_PORT")),
 				),
 			),
+			ShutdownTimeout: config.Must(
+				ctx,
+				config.Default(
+					10*time.Second,
+					config.DurationFromString(config.Env("HTTP_SHUTDOWN_TIMEOUT")),
+				),
+			),
+		},
+		Storage: StorageConfig{
+			Backend: config.Must(
+				ctx,
+				config.Default(
+					StorageBackendFirestore,
+					config.Env("STORAGE_BACKEND"),
+				),
+			),
+			SQLitePath: config.Must(
+				ctx,
+				config.Default(
+					"machines.db",
+					config.Env("SQLITE_PATH"),
+				),
+			),
 		},
 		Firestore: FirestoreConfig{
-			ProjectID: config.Must(ctx, config.Env("GCP_PROJECT_ID")),
+			// Only required by the firestore storage backend.
+			ProjectID: config.MustOr(ctx, "", config.Env("GCP_PROJECT_ID")),
+		},
+		Retention: RetentionConfig{
+			Period: config.Must(
+				ctx,
+				config.Default(
+					30*24*time.Hour,
+					config.DurationFromString(config.Env("RETENTION_PERIOD")),
+				),
+			),
+			PurgeInterval: config.Must(
+				ctx,
+				config.Default(
+					time.Hour,
+					config.DurationFromString(config.Env("RETENTION_PURGE_INTERVAL")),
+				),
+			),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: config.Must(
+				ctx,
+				config.Default(
+					24*time.Hour,
+					config.DurationFromString(config.Env("IDEMPOTENCY_KEY_TTL")),
+				),
+			),
+		},
+		Watch: WatchConfig{
+			PollInterval: config.Must(
+				ctx,
+				config.Default(
+					time.Second,
+					config.DurationFromString(config.Env("WATCH_POLL_INTERVAL")),
+				),
+			),
+			Retention: config.Must(
+				ctx,
+				config.Default(
+					7*24*time.Hour,
+					config.DurationFromString(config.Env("WATCH_RETENTION")),
+				),
+			),
+		},
+		Events: EventsConfig{
+			Publisher: config.Must(
+				ctx,
+				config.Default(
+					EventsPublisherNone,
+					config.Env("EVENTS_PUBLISHER"),
+				),
+			),
+			// Only required by the pubsub events publisher.
+			PubSubTopic: config.MustOr(ctx, "", config.Env("EVENTS_PUBSUB_TOPIC")),
+			FilePath: config.Must(
+				ctx,
+				config.Default(
+					"events.jsonl",
+					config.Env("EVENTS_FILE"),
+				),
+			),
+			PollInterval: config.Must(
+				ctx,
+				config.Default(
+					time.Second,
+					config.DurationFromString(config.Env("EVENTS_POLL_INTERVAL")),
+				),
+			),
+		},
+		Webhooks: WebhooksConfig{
+			PollInterval: config.Must(
+				ctx,
+				config.Default(
+					time.Second,
+					config.DurationFromString(config.Env("WEBHOOK_POLL_INTERVAL")),
+				),
+			),
+			Horizon: config.Must(
+				ctx,
+				config.Default(
+					24*time.Hour,
+					config.DurationFromString(config.Env("WEBHOOK_HORIZON")),
+				),
+			),
+			MinBackoff: config.Must(
+				ctx,
+				config.Default(
+					10*time.Second,
+					config.DurationFromString(config.Env("WEBHOOK_MIN_BACKOFF")),
+				),
+			),
+			MaxBackoff: config.Must(
+				ctx,
+				config.Default(
+					time.Hour,
+					config.DurationFromString(config.Env("WEBHOOK_MAX_BACKOFF")),
+				),
+			),
+			Timeout: config.Must(
+				ctx,
+				config.Default(
+					10*time.Second,
+					config.DurationFromString(config.Env("WEBHOOK_TIMEOUT")),
+				),
+			),
+			Concurrency: config.Must(
+				ctx,
+				config.Default(
+					8,
+					config.IntFromString(config.Env("WEBHOOK_CONCURRENCY")),
+				),
+			),
+			Retention: config.Must(
+				ctx,
+				config.Default(
+					7*24*time.Hour,
+					config.DurationFromString(config.Env("WEBHOOK_RETENTION")),
+				),
+			),
 		},
 	}
 }
@@ -65,18 +254,32 @@ func Main(ctx context.Context) int {
 
 	cfg := ConfigFromEnv(sigCtx)
 
-	fsClient, err := service.NewFirestoreClient(sigCtx, cfg.Firestore.ProjectID)
+	storage, err := newStorage(sigCtx, cfg)
 	if err != nil {
-		log.ErrorContext(sigCtx, "failed to initialize firestore client", slog.Any("error", err))
+		log.ErrorContext(sigCtx, "failed to initialize storage", slog.String("backend", cfg.Storage.Backend), slog.Any("error", err))
 		return 1
 	}
+	defer storage.Close()
 
-	mux := chi.NewRouter()
-	endpoint.RegisterMachines(mux, fsClient)
-
-	srv := &http.Server{
-		Handler: mux,
+	publisher, err := newPublisher(sigCtx, cfg)
+	if err != nil {
+		log.ErrorContext(sigCtx, "failed to initialize events publisher", slog.String("publisher", cfg.Events.Publisher), slog.Any("error", err))
+		return 1
 	}
+	// Changes are kept until every consumer of the change log has
+	// processed them.
+	consumers := []string{webhooks.Consumer}
+	if publisher != nil {
+		defer publisher.Close()
+		consumers = append(consumers, events.RelayConsumer)
+	}
+
+	mux := newRouter(cfg, storage)
+
+	grpcServer := grpc.NewServer()
+	endpoint.RegisterMachineService(grpcServer, storage, cfg.Idempotency.TTL, cfg.Watch.PollInterval)
+
+	srv := newServer(grpcServer, mux)
 
 	ls, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.HTTP.Port))
 	if err != nil {
@@ -90,7 +293,11 @@ func Main(ctx context.Context) int {
 		return 1
 	}
 
-	ls = tls.NewListener(ls, &tls.Config{Certificates: []tls.Certificate{cert}})
+	// gRPC needs HTTP/2, which clients only get by negotiating it.
+	ls = tls.NewListener(ls, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	})
 
 	pool := pool.New().WithErrors().WithContext(sigCtx)
 	pool.Go(func(ctx context.Context) error {
@@ -101,10 +308,38 @@ func Main(ctx context.Context) int {
 
 		return nil
 	})
+	pool.Go(func(ctx context.Context) error {
+		purgeExpired(ctx, log, storage, cfg, consumers)
+		return nil
+	})
+	if publisher != nil {
+		pool.Go(func(ctx context.Context) error {
+			events.NewRelay(storage, publisher, endpoint.MachineCloudEvent).Run(ctx, cfg.Events.PollInterval)
+			return nil
+		})
+	}
+	pool.Go(func(ctx context.Context) error {
+		dispatcher := webhooks.NewDispatcher(storage, endpoint.MachineCloudEvent, webhooks.Config{
+			Horizon:     cfg.Webhooks.Horizon,
+			MinBackoff:  cfg.Webhooks.MinBackoff,
+			MaxBackoff:  cfg.Webhooks.MaxBackoff,
+			Timeout:     cfg.Webhooks.Timeout,
+			Concurrency: cfg.Webhooks.Concurrency,
+		})
+		dispatcher.Run(ctx, cfg.Webhooks.PollInterval)
+		return nil
+	})
 	pool.Go(func(ctx context.Context) error {
 		<-ctx.Done()
 		log.InfoContext(ctx, "shutting down HTTP server")
-		return srv.Shutdown(context.Background())
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WarnContext(ctx, "failed to shut down HTTP server gracefully", slog.Any("error", err))
+			return srv.Close()
+		}
+		return nil
 	})
 
 	if err := pool.Wait(); err != nil {
@@ -115,6 +350,128 @@ func Main(ctx context.Context) int {
 	return 0
 }
 
+// newServer returns a server of grpcServer and mux whose request contexts
+// are canceled once it shuts down, so that streams such as :watch end
+// instead of holding up Shutdown until it times out. grpcServer is stopped
+// with it; GracefulStop is not supported for calls served through
+// ServeHTTP, whose draining the HTTP server does instead.
+func newServer(grpcServer *grpc.Server, mux http.Handler) *http.Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Handler:     withGRPC(grpcServer, mux),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	srv.RegisterOnShutdown(cancel)
+	srv.RegisterOnShutdown(grpcServer.Stop)
+	return srv
+}
+
+// newRouter registers every HTTP endpoint of the service.
+func newRouter(cfg Config, storage Storage) *chi.Mux {
+	mux := chi.NewRouter()
+	mux.Use(endpoint.ContentNegotiation)
+	endpoint.RegisterMachines(mux, storage, cfg.Idempotency.TTL)
+	endpoint.BatchCreateMachines(mux, storage)
+	endpoint.ListMachines(mux, storage)
+	endpoint.GetMachine(mux, storage)
+	endpoint.UpdateMachine(mux, storage)
+	endpoint.PatchMachine(mux, storage)
+	endpoint.DeleteMachine(mux, storage)
+	endpoint.RestoreMachine(mux, storage, cfg.Retention.Period)
+	endpoint.TransitionMachine(mux, storage)
+	endpoint.GetMachineLabels(mux, storage)
+	endpoint.UpdateMachineLabels(mux, storage)
+	endpoint.ListMachineRevisions(mux, storage)
+	endpoint.GetMachineRevision(mux, storage)
+	endpoint.RollbackMachine(mux, storage)
+	endpoint.SearchMachines(mux, storage)
+	endpoint.GetInventorySummary(mux, storage)
+	endpoint.WatchMachines(mux, storage, cfg.Watch.PollInterval)
+	endpoint.CreateWebhook(mux, storage)
+	endpoint.ListWebhooks(mux, storage)
+	endpoint.GetWebhook(mux, storage)
+	endpoint.UpdateWebhook(mux, storage)
+	endpoint.DeleteWebhook(mux, storage)
+	endpoint.ListWebhookDeliveries(mux, storage)
+	endpoint.RedeliverWebhookDelivery(mux, storage, cfg.Webhooks.Horizon)
+	endpoint.OpenAPI(mux)
+	return mux
+}
+
+// withGRPC serves gRPC calls with grpcServer and everything else with mux,
+// so that both share one listener.
+func withGRPC(grpcServer *grpc.Server, mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// purgeExpired purges deleted machines past their retention period, changes
+// past theirs which every one of consumers has processed, and settled webhook
+// deliveries past theirs.
+func purgeExpired(ctx context.Context, log *slog.Logger, storage Storage, cfg Config, consumers []string) {
+	ticker := time.NewTicker(cfg.Retention.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		resp, err := storage.PurgeDeletedMachines(ctx, &service.PurgeDeletedMachinesRequest{
+			DeletedBefore: time.Now().Add(-cfg.Retention.Period),
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to purge deleted machines", slog.Any("error", err))
+		} else if resp.Purged > 0 {
+			log.InfoContext(ctx, "purged deleted machines", slog.Int("count", resp.Purged))
+		}
+
+		if err := purgeMachineChanges(ctx, log, storage, cfg.Watch, consumers); err != nil {
+			log.ErrorContext(ctx, "failed to purge machine changes", slog.Any("error", err))
+		}
+
+		deliveries, err := storage.PurgeWebhookDeliveries(ctx, &service.PurgeWebhookDeliveriesRequest{
+			Before: time.Now().Add(-cfg.Webhooks.Retention),
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to purge webhook deliveries", slog.Any("error", err))
+		} else if deliveries.Purged > 0 {
+			log.InfoContext(ctx, "purged webhook deliveries", slog.Int("count", deliveries.Purged))
+		}
+	}
+}
+
+func purgeMachineChanges(ctx context.Context, log *slog.Logger, storage Storage, cfg WatchConfig, consumers []string) error {
+	req := &service.PurgeMachineChangesRequest{
+		Before: time.Now().Add(-cfg.Retention),
+	}
+	for _, consumer := range consumers {
+		cursor, err := storage.GetChangeCursor(ctx, &service.GetChangeCursorRequest{Consumer: consumer})
+		if err != nil {
+			return err
+		}
+		if req.Through == nil || cursor.Sequence < *req.Through {
+			req.Through = &cursor.Sequence
+		}
+	}
+
+	resp, err := storage.PurgeMachineChanges(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Purged > 0 {
+		log.InfoContext(ctx, "purged machine changes", slog.Int("count", resp.Purged))
+	}
+	return nil
+}
+
 func generateSelfSignedCert() (tls.Certificate, error) {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestNewRouter_OpenAPI asserts that the served OpenAPI document and the
+// registered routes describe the same API.
+func TestNewRouter_OpenAPI(t *testing.T) {
+	mux := newRouter(Config{}, service.NewMemoryStore())
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	err := chi.Walk(mux, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		operation := strings.ToLower(method) + " " + route
+		registered[operation] = true
+		if _, ok := doc.Paths[route][strings.ToLower(method)]; !ok {
+			t.Errorf("%s is registered but not documented", operation)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+	if len(registered) == 0 {
+		t.Fatal("expected registered routes")
+	}
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			if operation := method + " " + path; !registered[operation] {
+				t.Errorf("%s is documented but not registered", operation)
+			}
+		}
+	}
+}
+
+// TestNewServer_Shutdown asserts that open :watch and WatchMachines streams
+// do not hold up shutdown.
+func TestNewServer_Shutdown(t *testing.T) {
+	cfg := Config{Watch: WatchConfig{PollInterval: 10 * time.Millisecond}}
+	storage := service.NewMemoryStore()
+	grpcServer := grpc.NewServer()
+	endpoint.RegisterMachineService(grpcServer, storage, time.Hour, cfg.Watch.PollInterval)
+
+	srv := newServer(grpcServer, newRouter(cfg, storage))
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config = srv
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + "/api/v1/machines:watch")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+
+	certs := x509.NewCertPool()
+	certs.AddCert(ts.Certificate())
+	conn, err := grpc.NewClient(strings.TrimPrefix(ts.URL, "https://"),
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(certs, "")),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	c := endpointpb.NewRegisterMachineServiceClient(conn)
+	_, err = c.RegisterMachine(context.Background(), &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("aa:bb:cc:dd:ee:ff")}},
+	})
+	if err != nil {
+		t.Fatalf("RegisterMachine: %v", err)
+	}
+	stream, err := c.WatchMachines(context.Background(), &endpointpb.WatchMachinesRequest{After: proto.Int64(0)})
+	if err != nil {
+		t.Fatalf("WatchMachines: %v", err)
+	}
+	// The registration is replayed once the call is being served.
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Error("expected the WatchMachines stream to end")
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/events"
+)
+
+const (
+	EventsPublisherNone   = "none"
+	EventsPublisherPubSub = "pubsub"
+	EventsPublisherFile   = "file"
+)
+
+// EventsConfig selects where machine changes are published as CloudEvents.
+// The file publisher needs no GCP credentials and is meant for local
+// development.
+type EventsConfig struct {
+	Publisher    string
+	PubSubTopic  string
+	FilePath     string
+	PollInterval time.Duration
+}
+
+// newPublisher returns nil if publishing is disabled.
+func newPublisher(ctx context.Context, cfg Config) (events.Publisher, error) {
+	switch cfg.Events.Publisher {
+	case EventsPublisherNone:
+		return nil, nil
+	case EventsPublisherPubSub:
+		if cfg.Firestore.ProjectID == "" || cfg.Events.PubSubTopic == "" {
+			return nil, fmt.Errorf("GCP_PROJECT_ID and EVENTS_PUBSUB_TOPIC must be set for the %s events publisher", EventsPublisherPubSub)
+		}
+		return events.NewPubSubPublisher(ctx, cfg.Firestore.ProjectID, cfg.Events.PubSubTopic)
+	case EventsPublisherFile:
+		return events.NewFilePublisher(cfg.Events.FilePath)
+	default:
+		return nil, fmt.Errorf("unknown events publisher %q", cfg.Events.Publisher)
+	}
+}
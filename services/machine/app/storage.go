@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zaba505/infra/services/machine/endpoint"
+	"github.com/Zaba505/infra/services/machine/events"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/Zaba505/infra/services/machine/webhooks"
+)
+
+const (
+	StorageBackendFirestore = "firestore"
+	StorageBackendMemory    = "memory"
+	StorageBackendSQLite    = "sqlite"
+)
+
+// StorageConfig selects where machines are stored. The memory and sqlite
+// backends need no GCP credentials, which makes them suitable for local
+// development and self-hosted deployments.
+type StorageConfig struct {
+	Backend    string
+	SQLitePath string
+}
+
+// Storage is implemented by every storage backend.
+type Storage interface {
+	endpoint.FirestoreClient
+	PurgeDeletedMachines(ctx context.Context, req *service.PurgeDeletedMachinesRequest) (*service.PurgeDeletedMachinesResponse, error)
+	PurgeMachineChanges(ctx context.Context, req *service.PurgeMachineChangesRequest) (*service.PurgeMachineChangesResponse, error)
+	PurgeWebhookDeliveries(ctx context.Context, req *service.PurgeWebhookDeliveriesRequest) (*service.PurgeWebhookDeliveriesResponse, error)
+	events.ChangeLog
+	webhooks.Storage
+}
+
+func newStorage(ctx context.Context, cfg Config) (Storage, error) {
+	switch cfg.Storage.Backend {
+	case StorageBackendFirestore:
+		if cfg.Firestore.ProjectID == "" {
+			return nil, fmt.Errorf("GCP_PROJECT_ID must be set for the %s storage backend", StorageBackendFirestore)
+		}
+		return service.NewFirestoreClient(ctx, cfg.Firestore.ProjectID)
+	case StorageBackendMemory:
+		return service.NewMemoryStore(), nil
+	case StorageBackendSQLite:
+		return service.NewSQLiteStore(ctx, cfg.Storage.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
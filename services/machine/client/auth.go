@@ -0,0 +1,43 @@
+package client
+
+import (
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// Authenticator adds credentials to a request. It is called for every
+// attempt, so credentials which expire can be refreshed in between.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator.
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken authenticates with a static bearer token.
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// TokenSource authenticates with tokens from ts, e.g. the ID tokens of
+// google.golang.org/api/idtoken for a service behind Cloud Run IAM. Token
+// is called for every attempt, so ts should cache tokens as
+// oauth2.ReuseTokenSource does.
+func TokenSource(ts oauth2.TokenSource) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		token, err := ts.Token()
+		if err != nil {
+			return err
+		}
+		token.SetAuthHeader(req)
+		return nil
+	})
+}
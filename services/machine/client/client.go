@@ -0,0 +1,268 @@
+// Package client is a typed client of the machine service's REST API, for
+// the Boot Service and CLI tools.
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	protobufContentType = "application/x-protobuf"
+
+	defaultMaxAttempts = 3
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = 2 * time.Second
+
+	// maxDetailLength bounds the excerpt of a response body which is not a
+	// problem kept as the detail of the problem returned in its place.
+	maxDetailLength = 512
+)
+
+// Config controls how a Client reaches the service.
+//
+// Calls which are safe to repeat are retried after a transport error or a
+// 429, 502, 503 or 504 response, up to MaxAttempts attempts in total. The
+// first retry waits MinBackoff, or as long as the response's Retry-After
+// asks, and every further retry waits twice as long, up to MaxBackoff.
+// Zero values select 3 attempts, 100ms and 2s; set MaxAttempts to 1 to
+// disable retries.
+type Config struct {
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	// Auth adds credentials to every attempt, none when nil.
+	Auth Authenticator
+
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Client calls the machine service. Bodies are exchanged as protobuf, and
+// every call is traced with its trace context propagated to the service.
+//
+// Non-2xx responses are returned as the errorpb problem the service
+// responded with, e.g. *errorpb.ValidationProblem, so that callers can
+// inspect them with errors.As. Responses which are not a problem, say from
+// a proxy, are returned as a *errorpb.Problem with their status.
+type Client struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	baseURL    *url.URL
+	http       *http.Client
+	cfg        Config
+}
+
+// New returns a client of the service at baseURL, e.g.
+// https://machine.example.com.
+func New(baseURL string, cfg Config) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	return &Client{
+		tracer:     otel.Tracer("machine/client"),
+		propagator: otel.GetTextMapPropagator(),
+		baseURL:    u,
+		http:       httpClient,
+		cfg:        cfg,
+	}, nil
+}
+
+// call describes one request of a Client method.
+type call struct {
+	name   string
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   proto.Message
+
+	// idempotent calls are retried.
+	idempotent bool
+}
+
+// do sends cl, retrying it when it is idempotent, and decodes a successful
+// response body into out unless it is nil. It returns the headers of the
+// successful response.
+func (c *Client) do(ctx context.Context, cl call, out proto.Message) (_ http.Header, err error) {
+	ctx, span := c.tracer.Start(ctx, cl.name, trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	var body []byte
+	if cl.body != nil {
+		body, err = proto.Marshal(cl.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		status, header, respBody, err := c.send(ctx, cl, body)
+
+		retryable := cl.idempotent && attempt < c.cfg.MaxAttempts && ctx.Err() == nil
+		if retryable && (err != nil || retryableStatus(status)) {
+			if err := sleep(ctx, c.backoff(attempt, header)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case err != nil:
+			return nil, err
+		case status < 200 || status > 299:
+			return nil, decodeProblem(cl.path, status, header, respBody)
+		}
+		if out != nil {
+			if err := proto.Unmarshal(respBody, out); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+		}
+		return header, nil
+	}
+}
+
+// send makes one attempt at cl.
+func (c *Client) send(ctx context.Context, cl call, body []byte) (int, http.Header, []byte, error) {
+	u := c.baseURL.JoinPath(cl.path)
+	u.RawQuery = cl.query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, cl.method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	for k, v := range cl.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", protobufContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", protobufContentType)
+	}
+	if c.cfg.Auth != nil {
+		if err := c.cfg.Auth.Authenticate(req); err != nil {
+			return 0, nil, nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
+	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, resp.Header, respBody, nil
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff is the delay before the retry after the given number of attempts.
+// A Retry-After in seconds takes precedence, up to MaxBackoff.
+func (c *Client) backoff(attempts int, header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, c.cfg.MaxBackoff)
+	}
+
+	delay := c.cfg.MinBackoff
+	for i := 1; i < attempts && delay < c.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.cfg.MaxBackoff)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decodeProblem returns the problem a non-2xx response carries, as the
+// errorpb message its media type names.
+func decodeProblem(instance string, status int, header http.Header, body []byte) error {
+	mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == errorpb.ProtobufContentType {
+		name := protoreflect.FullName(params[errorpb.ProtoParameter])
+		if name == "" {
+			name = (&errorpb.Problem{}).ProtoReflect().Descriptor().FullName()
+		}
+		if mt, err := protoregistry.GlobalTypes.FindMessageByName(name); err == nil {
+			msg := mt.New().Interface()
+			if problem, ok := msg.(error); ok && proto.Unmarshal(body, msg) == nil {
+				return problem
+			}
+		}
+	}
+
+	detail := string(body)
+	if len(detail) > maxDetailLength {
+		detail = detail[:maxDetailLength]
+	}
+	if detail == "" {
+		detail = fmt.Sprintf("unexpected response status %d", status)
+	}
+	return &errorpb.Problem{
+		Title:    proto.String(http.StatusText(status)),
+		Status:   proto.Int32(int32(status)),
+		Detail:   proto.String(detail),
+		Instance: proto.String(instance),
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+// flakyHandler fails the first failures requests with status before handing
+// requests to next, recording the headers of every request.
+type flakyHandler struct {
+	next     http.Handler
+	status   int
+	failures int
+
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.headers = append(h.headers, r.Header.Clone())
+	attempt := len(h.headers)
+	h.mu.Unlock()
+
+	if attempt <= h.failures {
+		w.WriteHeader(h.status)
+		w.Write([]byte("upstream unavailable"))
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+func TestNew(t *testing.T) {
+	for _, baseURL := range []string{"", "machine.example.com", "://"} {
+		if _, err := New(baseURL, Config{}); err == nil {
+			t.Errorf("expected base URL %q to be rejected", baseURL)
+		}
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		failures     int
+		maxAttempts  int
+		wantAttempts int
+		wantStatus   int32
+	}{
+		{
+			name:         "retried until success",
+			status:       http.StatusServiceUnavailable,
+			failures:     2,
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted",
+			status:       http.StatusBadGateway,
+			failures:     3,
+			wantAttempts: 3,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "retries disabled",
+			status:       http.StatusTooManyRequests,
+			failures:     1,
+			maxAttempts:  1,
+			wantAttempts: 1,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+		{
+			name:         "not retryable",
+			status:       http.StatusInternalServerError,
+			failures:     1,
+			wantAttempts: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &flakyHandler{next: machineService(), status: tt.status, failures: tt.failures}
+			c := newTestClient(t, h, Config{MaxAttempts: tt.maxAttempts})
+
+			_, err := c.RegisterMachine(context.Background(), machineRequest("aa:bb:cc:dd:ee:ff"))
+			if len(h.headers) != tt.wantAttempts {
+				t.Errorf("want %d attempts, got %d", tt.wantAttempts, len(h.headers))
+			}
+			for _, header := range h.headers {
+				if key := header.Get("Idempotency-Key"); key == "" || key != h.headers[0].Get("Idempotency-Key") {
+					t.Errorf("want every attempt to send the same Idempotency-Key, got %q", key)
+				}
+			}
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Errorf("RegisterMachine: %v", err)
+				}
+				return
+			}
+			// The failures are not problems, so they are returned as one.
+			var p *errorpb.Problem
+			if !errors.As(err, &p) || p.GetStatus() != tt.wantStatus || p.GetDetail() != "upstream unavailable" {
+				t.Errorf("expected a problem with status %d, got %T %v", tt.wantStatus, err, err)
+			}
+		})
+	}
+}
+
+func TestClient_Canceled(t *testing.T) {
+	h := &flakyHandler{next: machineService(), status: http.StatusServiceUnavailable, failures: 1}
+	c := newTestClient(t, h, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.ListMachines(ctx, &endpointpb.ListMachinesRequest{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+	if len(h.headers) != 0 {
+		t.Errorf("want no attempts, got %d", len(h.headers))
+	}
+}
+
+func TestClient_Headers(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagator) })
+
+	h := &flakyHandler{next: machineService()}
+	c := newTestClient(t, h, Config{Auth: BearerToken("secret")})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	if _, err := c.ListMachines(ctx, &endpointpb.ListMachinesRequest{Mac: proto.String("aa:bb:cc:dd:ee:ff")}); err != nil {
+		t.Fatalf("ListMachines: %v", err)
+	}
+
+	header := h.headers[0]
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("want the bearer token, got %q", got)
+	}
+	if got := header.Get("Accept"); got != protobufContentType {
+		t.Errorf("want protobuf accepted, got %q", got)
+	}
+	carrier := propagation.HeaderCarrier(header)
+	remote := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+	if remote.TraceID() != traceID {
+		t.Errorf("want trace %s propagated, got traceparent %q", traceID, header.Get("Traceparent"))
+	}
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+const machinesPath = "/api/v1/machines"
+
+// Machine is a machine together with its revision, which the ETag of the
+// response reports. Passing the revision as the expected revision of an
+// update or delete makes the write fail with a precondition failed problem
+// when the machine has changed since. The revision is zero when the
+// response did not report one.
+type Machine struct {
+	*endpointpb.Machine
+	Revision int64
+}
+
+// RegisterMachine registers a new machine. Every call sends a new
+// Idempotency-Key, so that it can be retried without registering the
+// machine twice.
+func (c *Client) RegisterMachine(ctx context.Context, req *endpointpb.RegisterMachineRequest) (*endpointpb.RegisterMachineResponse, error) {
+	resp := new(endpointpb.RegisterMachineResponse)
+	_, err := c.do(ctx, call{
+		name:       "RegisterMachine",
+		method:     http.MethodPost,
+		path:       machinesPath,
+		header:     http.Header{"Idempotency-Key": {uuid.NewString()}},
+		body:       req,
+		idempotent: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetMachine retrieves a machine.
+func (c *Client) GetMachine(ctx context.Context, req *endpointpb.GetMachineRequest) (*Machine, error) {
+	machine := new(endpointpb.Machine)
+	header, err := c.do(ctx, call{
+		name:       "GetMachine",
+		method:     http.MethodGet,
+		path:       machinePath(req.GetMachineId()),
+		idempotent: true,
+	}, machine)
+	if err != nil {
+		return nil, err
+	}
+	return &Machine{Machine: machine, Revision: parseETag(header)}, nil
+}
+
+// ListMachines retrieves one page of machines.
+func (c *Client) ListMachines(ctx context.Context, req *endpointpb.ListMachinesRequest) (*endpointpb.ListMachinesResponse, error) {
+	query := make(url.Values)
+	if req.PerPage != nil {
+		query.Set("per_page", strconv.FormatInt(int64(req.GetPerPage()), 10))
+	}
+	for name, value := range map[string]string{
+		"page_token":     req.GetPageToken(),
+		"mac":            req.GetMac(),
+		"label_selector": req.GetLabelSelector(),
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	resp := new(endpointpb.ListMachinesResponse)
+	_, err := c.do(ctx, call{
+		name:       "ListMachines",
+		method:     http.MethodGet,
+		path:       machinesPath,
+		query:      query,
+		idempotent: true,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListMachinePages iterates over the pages of machines, starting at the
+// page of req. Iteration stops after the last page or the first error.
+func (c *Client) ListMachinePages(ctx context.Context, req *endpointpb.ListMachinesRequest) iter.Seq2[*endpointpb.ListMachinesResponse, error] {
+	return func(yield func(*endpointpb.ListMachinesResponse, error) bool) {
+		req := proto.CloneOf(req)
+		if req == nil {
+			req = new(endpointpb.ListMachinesRequest)
+		}
+		for {
+			resp, err := c.ListMachines(ctx, req)
+			if !yield(resp, err) || err != nil {
+				return
+			}
+
+			token := resp.GetPagination().GetNextPageToken()
+			if token == "" {
+				return
+			}
+			req.PageToken = proto.String(token)
+		}
+	}
+}
+
+// UpdateMachine replaces the hardware profile of a machine, failing when
+// the machine is not at the expected revision if one is set. When a retry
+// follows an attempt whose response was lost, the machine may already be
+// at the next revision and a call with an expected revision fails with a
+// precondition failed problem.
+func (c *Client) UpdateMachine(ctx context.Context, req *endpointpb.UpdateMachineRequest) (*Machine, error) {
+	machine := new(endpointpb.Machine)
+	header, err := c.do(ctx, call{
+		name:       "UpdateMachine",
+		method:     http.MethodPut,
+		path:       machinePath(req.GetMachineId()),
+		header:     ifMatch(req.ExpectedRevision),
+		body:       req.GetMachine(),
+		idempotent: true,
+	}, machine)
+	if err != nil {
+		return nil, err
+	}
+	return &Machine{Machine: machine, Revision: parseETag(header)}, nil
+}
+
+// DeleteMachine deletes a machine, failing when the machine is not at the
+// expected revision if one is set. When a retry follows an attempt whose
+// response was lost, the machine may already be deleted and the call fails
+// with a machine not found problem.
+func (c *Client) DeleteMachine(ctx context.Context, req *endpointpb.DeleteMachineRequest) error {
+	_, err := c.do(ctx, call{
+		name:       "DeleteMachine",
+		method:     http.MethodDelete,
+		path:       machinePath(req.GetMachineId()),
+		header:     ifMatch(req.ExpectedRevision),
+		idempotent: true,
+	}, nil)
+	return err
+}
+
+func machinePath(machineID string) string {
+	return machinesPath + "/" + url.PathEscape(machineID)
+}
+
+// ifMatch is the precondition header expecting revision, if any.
+func ifMatch(revision *int64) http.Header {
+	if revision == nil {
+		return nil
+	}
+	return http.Header{"If-Match": {strconv.Quote(strconv.FormatInt(*revision, 10))}}
+}
+
+func parseETag(header http.Header) int64 {
+	tag, err := strconv.Unquote(header.Get("ETag"))
+	if err != nil {
+		return 0
+	}
+	revision, _ := strconv.ParseInt(tag, 10, 64)
+	return revision
+}
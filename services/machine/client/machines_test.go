@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestClient returns a client of the machine endpoints served by handler,
+// with short backoffs.
+func newTestClient(t *testing.T, handler http.Handler, cfg Config) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg.HTTPClient = srv.Client()
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = time.Millisecond
+	}
+	c, err := New(srv.URL, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// machineService serves the machine endpoints backed by in-memory storage.
+func machineService() http.Handler {
+	storage := service.NewMemoryStore()
+	mux := chi.NewRouter()
+	mux.Use(endpoint.ContentNegotiation)
+	endpoint.RegisterMachines(mux, storage, time.Hour)
+	endpoint.ListMachines(mux, storage)
+	endpoint.GetMachine(mux, storage)
+	endpoint.UpdateMachine(mux, storage)
+	endpoint.DeleteMachine(mux, storage)
+	return mux
+}
+
+func machineRequest(mac string) *endpointpb.RegisterMachineRequest {
+	return &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String(mac)}},
+	}
+}
+
+func TestClient_Machines(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, machineService(), Config{})
+
+	var ids []string
+	for _, mac := range []string{"aa:bb:cc:dd:ee:01", "aa:bb:cc:dd:ee:02", "aa:bb:cc:dd:ee:03"} {
+		resp, err := c.RegisterMachine(ctx, machineRequest(mac))
+		if err != nil {
+			t.Fatalf("RegisterMachine: %v", err)
+		}
+		ids = append(ids, resp.GetMachineId())
+	}
+
+	machine, err := c.GetMachine(ctx, &endpointpb.GetMachineRequest{MachineId: proto.String(ids[0])})
+	if err != nil {
+		t.Fatalf("GetMachine: %v", err)
+	}
+	if machine.GetId() != ids[0] || machine.Revision == 0 {
+		t.Errorf("unexpected machine %v at revision %d", machine.Machine, machine.Revision)
+	}
+
+	var listed []string
+	var pages int
+	for page, err := range c.ListMachinePages(ctx, &endpointpb.ListMachinesRequest{PerPage: proto.Int32(2)}) {
+		if err != nil {
+			t.Fatalf("ListMachinePages: %v", err)
+		}
+		pages++
+		for _, m := range page.GetMachines() {
+			listed = append(listed, m.GetId())
+		}
+	}
+	if pages != 2 || len(listed) != len(ids) {
+		t.Errorf("want %d machines in 2 pages, got %v in %d", len(ids), listed, pages)
+	}
+
+	updated, err := c.UpdateMachine(ctx, &endpointpb.UpdateMachineRequest{
+		MachineId:        proto.String(ids[0]),
+		Machine:          machineRequest("aa:bb:cc:dd:ee:04"),
+		ExpectedRevision: proto.Int64(machine.Revision),
+	})
+	if err != nil {
+		t.Fatalf("UpdateMachine: %v", err)
+	}
+	if updated.Revision <= machine.Revision || updated.GetNics()[0].GetMac() != "aa:bb:cc:dd:ee:04" {
+		t.Errorf("unexpected update %v at revision %d", updated.Machine, updated.Revision)
+	}
+
+	_, err = c.UpdateMachine(ctx, &endpointpb.UpdateMachineRequest{
+		MachineId:        proto.String(ids[0]),
+		Machine:          machineRequest("aa:bb:cc:dd:ee:05"),
+		ExpectedRevision: proto.Int64(machine.Revision),
+	})
+	var p *errorpb.Problem
+	if !errors.As(err, &p) || p.GetStatus() != http.StatusPreconditionFailed {
+		t.Errorf("expected a precondition failed problem for a stale revision, got %v", err)
+	}
+
+	err = c.DeleteMachine(ctx, &endpointpb.DeleteMachineRequest{
+		MachineId:        proto.String(ids[0]),
+		ExpectedRevision: proto.Int64(updated.Revision),
+	})
+	if err != nil {
+		t.Fatalf("DeleteMachine: %v", err)
+	}
+	_, err = c.GetMachine(ctx, &endpointpb.GetMachineRequest{MachineId: proto.String(ids[0])})
+	var nf *errorpb.MachineNotFoundProblem
+	if !errors.As(err, &nf) || nf.GetMachineId() != ids[0] {
+		t.Errorf("expected a machine not found problem after deleting, got %v", err)
+	}
+}
+
+func TestClient_Problems(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, machineService(), Config{})
+
+	resp, err := c.RegisterMachine(ctx, machineRequest("aa:bb:cc:dd:ee:ff"))
+	if err != nil {
+		t.Fatalf("RegisterMachine: %v", err)
+	}
+
+	_, err = c.RegisterMachine(ctx, &endpointpb.RegisterMachineRequest{})
+	var vp *errorpb.ValidationProblem
+	if !errors.As(err, &vp) || len(vp.GetInvalidFields()) == 0 {
+		t.Errorf("expected a validation problem, got %T %v", err, err)
+	}
+
+	_, err = c.RegisterMachine(ctx, machineRequest("aa:bb:cc:dd:ee:ff"))
+	var cp *errorpb.ConflictProblem
+	if !errors.As(err, &cp) || cp.GetExistingResourceId() != resp.GetMachineId() {
+		t.Errorf("expected a conflict with %s, got %T %v", resp.GetMachineId(), err, err)
+	}
+
+	_, err = c.ListMachines(ctx, &endpointpb.ListMachinesRequest{PerPage: proto.Int32(0)})
+	if !errors.As(err, &vp) || vp.GetInvalidFields()[0].GetField() != "per_page" {
+		t.Errorf("expected a validation problem about per_page, got %T %v", err, err)
+	}
+}
@@ -0,0 +1,58 @@
+// Command migrate-macs rewrites every stored NIC MAC address into the
+// canonical form produced by service.ParseMAC and rebuilds the MAC index.
+// Until a run completes without failures, the service scans the machines for
+// MACs missing from the index. It is safe to run more than once.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/z5labs/bedrock/config"
+)
+
+func main() {
+	os.Exit(run(context.Background()))
+}
+
+func run(ctx context.Context) int {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		AddSource: true,
+	}))
+
+	projectID := config.Must(ctx, config.Env("GCP_PROJECT_ID"))
+
+	fsClient, err := service.NewFirestoreClient(ctx, projectID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to initialize firestore client", slog.Any("error", err))
+		return 1
+	}
+	defer fsClient.Close()
+
+	resp, err := fsClient.MigrateMACs(ctx, &service.MigrateMACsRequest{DryRun: *dryRun})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to migrate MAC addresses", slog.Any("error", err))
+		return 1
+	}
+
+	for _, failure := range resp.Failures {
+		log.ErrorContext(ctx, "failed to migrate machine", slog.String("machine_id", failure.MachineID), slog.Any("error", failure.Err))
+	}
+	log.InfoContext(ctx, "migrated MAC addresses",
+		slog.Bool("dry_run", *dryRun),
+		slog.Int("scanned", resp.Scanned),
+		slog.Int("rewritten", resp.Rewritten),
+		slog.Int("failed", len(resp.Failures)),
+	)
+
+	if len(resp.Failures) > 0 {
+		return 1
+	}
+	return 0
+}
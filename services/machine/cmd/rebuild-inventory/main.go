@@ -0,0 +1,46 @@
+// Command rebuild-inventory recomputes the inventory summary served by
+// GET /api/v1/inventory/summary from every stored machine. Run it once after
+// upgrading to a release that maintains the summary, or whenever it is
+// suspected to have drifted. It is safe to run more than once.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/z5labs/bedrock/config"
+)
+
+func main() {
+	os.Exit(run(context.Background()))
+}
+
+func run(ctx context.Context) int {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		AddSource: true,
+	}))
+
+	projectID := config.Must(ctx, config.Env("GCP_PROJECT_ID"))
+
+	fsClient, err := service.NewFirestoreClient(ctx, projectID)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to initialize firestore client", slog.Any("error", err))
+		return 1
+	}
+	defer fsClient.Close()
+
+	resp, err := fsClient.RebuildInventorySummary(ctx, &service.RebuildInventorySummaryRequest{})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to rebuild inventory summary", slog.Any("error", err))
+		return 1
+	}
+
+	log.InfoContext(ctx, "rebuilt inventory summary",
+		slog.Int64("machines", resp.Summary.Machines),
+		slog.Int64("cores", resp.Summary.Cores),
+		slog.Int64("memory", resp.Summary.Memory),
+	)
+	return 0
+}
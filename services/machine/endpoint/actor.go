@@ -0,0 +1,27 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// actorHeader carries the identity of the caller. Requests reach the service
+// through the authenticating load balancer, which sets this header.
+const actorHeader = "X-Actor"
+
+func requestActor(r *http.Request) string {
+	if actor := r.Header.Get(actorHeader); actor != "" {
+		return actor
+	}
+	return "unknown"
+}
+
+// callActor is requestActor for gRPC calls, whose headers arrive as metadata.
+func callActor(ctx context.Context) string {
+	if actors := metadata.ValueFromIncomingContext(ctx, actorHeader); len(actors) > 0 && actors[0] != "" {
+		return actors[0]
+	}
+	return "unknown"
+}
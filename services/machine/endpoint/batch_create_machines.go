@@ -0,0 +1,203 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxBatchSize = 100
+
+type batchCreateMachinesHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func BatchCreateMachines(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &batchCreateMachinesHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/machines:batchCreate", handler)
+}
+
+// ServeHTTP answers with 200 OK and one result per request whenever the batch
+// itself is well formed; the outcome of each machine is in its result.
+func (h *batchCreateMachinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	var req endpointpb.BatchCreateMachinesRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	reqs := req.GetRequests()
+	if len(reqs) == 0 || len(reqs) > maxBatchSize {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("requests"), Reason: proto.String(fmt.Sprintf("must contain between 1 and %d machines", maxBatchSize))},
+		}))
+		return
+	}
+
+	results := make([]*endpointpb.BatchCreateMachineResult, len(reqs))
+	machines := make([]*service.CreateMachineRequest, len(reqs))
+	for i, invalidFields := range validateBatch(reqs) {
+		if len(invalidFields) > 0 {
+			results[i] = batchProblemResult(errorpb.NewValidationError(instance, invalidFields))
+			continue
+		}
+
+		machineID, err := uuid.NewV7()
+		if err != nil {
+			errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to generate machine ID: %v", err)))
+			return
+		}
+		machines[i] = &service.CreateMachineRequest{
+			MachineID: machineID.String(),
+			Machine:   convertMachineRequest(reqs[i]),
+			Actor:     requestActor(r),
+		}
+	}
+
+	if req.GetAtomic() {
+		err := h.createAtomic(r, instance, machines, results)
+		if err != nil {
+			errorHandler(ctx, w, err)
+			return
+		}
+	} else {
+		h.createEach(r, instance, machines, results)
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.BatchCreateMachinesResponse{
+		Results: results,
+	})
+}
+
+// createAtomic creates every valid machine in one transaction, unless any
+// machine has already failed validation or conflicts with storage. Machines
+// that were not at fault are then reported as aborted.
+func (h *batchCreateMachinesHandler) createAtomic(r *http.Request, instance string, machines []*service.CreateMachineRequest, results []*endpointpb.BatchCreateMachineResult) error {
+	failed := false
+	for _, result := range results {
+		failed = failed || result != nil
+	}
+
+	if !failed {
+		_, err := h.firestoreClient.CreateMachines(r.Context(), &service.CreateMachinesRequest{
+			Machines: machines,
+		})
+		var batchErr *service.BatchConflictError
+		if errors.As(err, &batchErr) {
+			for i, conflict := range batchErr.Conflicts {
+				results[i] = batchProblemResult(newMACConflictError(instance, conflict))
+			}
+			failed = true
+		} else if err != nil {
+			return errorpb.NewInternalError(instance, fmt.Sprintf("failed to create machines: %v", err))
+		}
+	}
+
+	for i, m := range machines {
+		if results[i] != nil {
+			continue
+		}
+		if failed {
+			results[i] = batchProblemResult(errorpb.NewBatchAbortedError(instance))
+			continue
+		}
+		results[i] = &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_MachineId{MachineId: m.MachineID},
+		}
+	}
+	return nil
+}
+
+// createEach creates the valid machines one at a time, so a failure only
+// affects its own result.
+func (h *batchCreateMachinesHandler) createEach(r *http.Request, instance string, machines []*service.CreateMachineRequest, results []*endpointpb.BatchCreateMachineResult) {
+	for i, m := range machines {
+		if results[i] != nil {
+			continue
+		}
+
+		_, err := h.firestoreClient.CreateMachine(r.Context(), m)
+		if conflict, ok := asMACConflict(err); ok {
+			results[i] = batchProblemResult(newMACConflictError(instance, conflict))
+			continue
+		}
+		if err != nil {
+			results[i] = batchProblemResult(errorpb.NewInternalError(instance, fmt.Sprintf("failed to create machine: %v", err)))
+			continue
+		}
+		results[i] = &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_MachineId{MachineId: m.MachineID},
+		}
+	}
+}
+
+// validateBatch validates every request on its own and additionally rejects
+// MACs that an earlier request in the batch already uses.
+func validateBatch(reqs []*endpointpb.RegisterMachineRequest) [][]*errorpb.InvalidField {
+	invalid := make([][]*errorpb.InvalidField, len(reqs))
+	owners := make(map[service.MAC]int)
+	for i, req := range reqs {
+		invalid[i] = validateMachineRequest(req)
+
+		for j, nic := range req.GetNics() {
+			mac, err := service.ParseMAC(nic.GetMac())
+			if err != nil {
+				continue
+			}
+			owner, ok := owners[mac]
+			if !ok {
+				owners[mac] = i
+				continue
+			}
+			if owner != i {
+				invalid[i] = append(invalid[i], &errorpb.InvalidField{
+					Field:  proto.String(fmt.Sprintf("nics[%d].mac", j)),
+					Reason: proto.String(fmt.Sprintf("MAC address is also used by requests[%d]", owner)),
+				})
+			}
+		}
+	}
+	return invalid
+}
+
+func batchProblemResult(err error) *endpointpb.BatchCreateMachineResult {
+	switch e := err.(type) {
+	case *errorpb.ValidationProblem:
+		return &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_ValidationProblem{ValidationProblem: e},
+		}
+	case *errorpb.ConflictProblem:
+		return &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_ConflictProblem{ConflictProblem: e},
+		}
+	case *errorpb.Problem:
+		return &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_Problem{Problem: e},
+		}
+	default:
+		return &endpointpb.BatchCreateMachineResult{
+			Result: &endpointpb.BatchCreateMachineResult_Problem{Problem: errorpb.NewInternalError("", err.Error())},
+		}
+	}
+}
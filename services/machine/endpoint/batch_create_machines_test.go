@@ -0,0 +1,208 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBatchCreateMachinesHandler_ServeHTTP(t *testing.T) {
+	machine := func(macs ...string) *endpointpb.RegisterMachineRequest {
+		req := &endpointpb.RegisterMachineRequest{}
+		for _, mac := range macs {
+			req.Nics = append(req.Nics, &endpointpb.NIC{Mac: proto.String(mac)})
+		}
+		return req
+	}
+	batch := func(atomic bool, reqs ...*endpointpb.RegisterMachineRequest) []byte {
+		b, _ := proto.Marshal(&endpointpb.BatchCreateMachinesRequest{Requests: reqs, Atomic: proto.Bool(atomic)})
+		return b
+	}
+
+	const (
+		created    = "machine_id"
+		validation = "validation_problem"
+		conflict   = "conflict_problem"
+		problem    = "problem"
+	)
+	kind := func(result *endpointpb.BatchCreateMachineResult) string {
+		switch result.GetResult().(type) {
+		case *endpointpb.BatchCreateMachineResult_MachineId:
+			return created
+		case *endpointpb.BatchCreateMachineResult_ValidationProblem:
+			return validation
+		case *endpointpb.BatchCreateMachineResult_ConflictProblem:
+			return conflict
+		case *endpointpb.BatchCreateMachineResult_Problem:
+			return problem
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name        string
+		body        []byte
+		client      *mockFirestoreClient
+		wantCode    int
+		wantResults []string
+		check       func(t *testing.T, client *mockFirestoreClient, resp *endpointpb.BatchCreateMachinesResponse)
+	}{
+		{
+			name:     "empty batch",
+			body:     batch(false),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "independent items",
+			body: batch(false,
+				machine("02:00:00:00:00:01"),
+				machine("not-a-mac"),
+				machine("02:00:00:00:00:02", "02-00-00-00-00-01"),
+			),
+			client:      &mockFirestoreClient{},
+			wantCode:    http.StatusOK,
+			wantResults: []string{created, validation, validation},
+			check: func(t *testing.T, _ *mockFirestoreClient, resp *endpointpb.BatchCreateMachinesResponse) {
+				fields := resp.GetResults()[2].GetValidationProblem().GetInvalidFields()
+				if len(fields) != 1 || fields[0].GetField() != "nics[1].mac" {
+					t.Errorf("expected duplicate MAC on nics[1].mac, got %v", fields)
+				}
+			},
+		},
+		{
+			name: "independent items conflicting with storage",
+			body: batch(false, machine("02:00:00:00:00:01")),
+			client: &mockFirestoreClient{
+				createErr: fmt.Errorf("failed to create machine document: %w", &service.MACConflictError{MAC: "02:00:00:00:00:01", MachineID: "existing-id"}),
+			},
+			wantCode:    http.StatusOK,
+			wantResults: []string{conflict},
+		},
+		{
+			name:        "atomic batch with invalid item",
+			body:        batch(true, machine("02:00:00:00:00:01"), machine()),
+			client:      &mockFirestoreClient{},
+			wantCode:    http.StatusOK,
+			wantResults: []string{problem, validation},
+			check: func(t *testing.T, client *mockFirestoreClient, _ *endpointpb.BatchCreateMachinesResponse) {
+				if client.batchReq != nil {
+					t.Error("expected nothing to be written")
+				}
+			},
+		},
+		{
+			name: "atomic batch conflicting with storage",
+			body: batch(true, machine("02:00:00:00:00:01"), machine("02:00:00:00:00:02")),
+			client: &mockFirestoreClient{
+				batchErr: fmt.Errorf("failed to create machine documents: %w", &service.BatchConflictError{
+					Conflicts: map[int]*service.MACConflictError{
+						1: {MAC: "02:00:00:00:00:02", MachineID: "existing-id"},
+					},
+				}),
+			},
+			wantCode:    http.StatusOK,
+			wantResults: []string{problem, conflict},
+			check: func(t *testing.T, _ *mockFirestoreClient, resp *endpointpb.BatchCreateMachinesResponse) {
+				if got := resp.GetResults()[0].GetProblem().GetStatus(); got != http.StatusFailedDependency {
+					t.Errorf("want aborted item status %d, got %d", http.StatusFailedDependency, got)
+				}
+			},
+		},
+		{
+			name:        "atomic batch",
+			body:        batch(true, machine("02:00:00:00:00:01"), machine("02:00:00:00:00:02")),
+			client:      &mockFirestoreClient{},
+			wantCode:    http.StatusOK,
+			wantResults: []string{created, created},
+			check: func(t *testing.T, client *mockFirestoreClient, resp *endpointpb.BatchCreateMachinesResponse) {
+				if client.batchReq == nil || len(client.batchReq.Machines) != 2 {
+					t.Fatalf("expected both machines in one write, got %+v", client.batchReq)
+				}
+				for i, m := range client.batchReq.Machines {
+					if got := resp.GetResults()[i].GetMachineId(); got != m.MachineID {
+						t.Errorf("results[%d]: want machine_id %s, got %s", i, m.MachineID, got)
+					}
+				}
+			},
+		},
+		{
+			name: "atomic storage failure",
+			body: batch(true, machine("02:00:00:00:00:01")),
+			client: &mockFirestoreClient{
+				batchErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &batchCreateMachinesHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines:batchCreate", bytes.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp endpointpb.BatchCreateMachinesResponse
+			if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resp.GetResults()) != len(tt.wantResults) {
+				t.Fatalf("want %d results, got %d", len(tt.wantResults), len(resp.GetResults()))
+			}
+			for i, result := range resp.GetResults() {
+				if got := kind(result); got != tt.wantResults[i] {
+					t.Errorf("results[%d]: want %s, got %s", i, tt.wantResults[i], got)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, tt.client, &resp)
+			}
+		})
+	}
+}
+
+func TestBatchCreateMachines_Route(t *testing.T) {
+	client := &mockFirestoreClient{}
+	mux := chi.NewRouter()
+	RegisterMachines(mux, client, time.Hour)
+	BatchCreateMachines(mux, client)
+
+	body, _ := proto.Marshal(&endpointpb.BatchCreateMachinesRequest{
+		Requests: []*endpointpb.RegisterMachineRequest{
+			{Nics: []*endpointpb.NIC{{Mac: proto.String("02:00:00:00:00:01")}}},
+		},
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/machines:batchCreate", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	if client.createReq == nil {
+		t.Error("expected the batch handler to create the machine")
+	}
+}
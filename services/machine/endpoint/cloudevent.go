@@ -0,0 +1,17 @@
+package endpoint
+
+import (
+	"github.com/Zaba505/infra/services/machine/events"
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+// MachineCloudEvent encodes change as a CloudEvent carrying the same
+// MachineEvent JSON that the watch stream sends, so consumers of either see
+// one event format.
+func MachineCloudEvent(change *service.MachineChange) (*events.CloudEvent, error) {
+	data, err := jsonMarshalOptions.Marshal(convertMachineChangeToProto(change))
+	if err != nil {
+		return nil, err
+	}
+	return events.NewMachineEvent(change, data), nil
+}
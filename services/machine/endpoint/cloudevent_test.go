@@ -0,0 +1,38 @@
+package endpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+func TestMachineCloudEvent(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	event, err := MachineCloudEvent(&service.MachineChange{
+		Sequence:  7,
+		Type:      service.ChangeDeleted,
+		MachineID: machineID,
+		Machine:   service.Machine{Revision: 3},
+		Actor:     "alice",
+		At:        at,
+	})
+	if err != nil {
+		t.Fatalf("MachineCloudEvent: %v", err)
+	}
+
+	if event.ID != "7" || event.Type != "com.example.machine.deleted" || event.Subject != machineID || !event.Time.Equal(at) {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	var data endpointpb.MachineEvent
+	if err := jsonUnmarshalOptions.Unmarshal(event.Data, &data); err != nil {
+		t.Fatalf("failed to decode event data: %v", err)
+	}
+	if data.GetSequence() != 7 || data.GetType() != endpointpb.MachineEventType_MACHINE_EVENT_TYPE_DELETED || data.GetRevision() != 3 {
+		t.Errorf("unexpected event data %v", &data)
+	}
+}
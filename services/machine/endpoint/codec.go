@@ -0,0 +1,124 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	protobufContentType = "application/x-protobuf"
+	jsonContentType     = "application/json"
+)
+
+var (
+	protobufMediaTypes = []string{protobufContentType, "application/protobuf", errorpb.ProtobufContentType}
+	jsonMediaTypes     = []string{jsonContentType, errorpb.JSONContentType}
+)
+
+var (
+	jsonMarshalOptions   = protojson.MarshalOptions{UseProtoNames: true}
+	jsonUnmarshalOptions = protojson.UnmarshalOptions{}
+)
+
+// ContentNegotiation selects the encoding of response and problem bodies from
+// the Accept header. Protobuf remains the default for clients which do not ask
+// for JSON.
+func ContentNegotiation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		ctx := errorpb.WithEncoding(r.Context(), negotiateEncoding(r.Header.Get("Accept")))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality
+// value in accept. Wildcards do not express a preference.
+func negotiateEncoding(accept string) errorpb.Encoding {
+	best, bestQ := errorpb.EncodingProtobuf, 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+
+		switch {
+		case slices.Contains(jsonMediaTypes, mediaType):
+			best, bestQ = errorpb.EncodingJSON, q
+		case slices.Contains(protobufMediaTypes, mediaType):
+			best, bestQ = errorpb.EncodingProtobuf, q
+		}
+	}
+	return best
+}
+
+// readRequest decodes the request body into msg according to its
+// Content-Type. The returned error is always an errorpb problem suitable for
+// errorHandler.
+func readRequest(r *http.Request, instance string, msg proto.Message) error {
+	contentType := r.Header.Get("Content-Type")
+
+	unmarshal, format := proto.Unmarshal, "protobuf"
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		switch {
+		case err != nil:
+			return errorpb.NewUnsupportedMediaTypeError(instance, contentType)
+		case slices.Contains(jsonMediaTypes, mediaType):
+			unmarshal, format = jsonUnmarshalOptions.Unmarshal, "JSON"
+		case !slices.Contains(protobufMediaTypes, mediaType):
+			return errorpb.NewUnsupportedMediaTypeError(instance, contentType)
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errorpb.NewInternalError(instance, fmt.Sprintf("failed to read request body: %v", err))
+	}
+
+	if err := unmarshal(body, msg); err != nil {
+		return errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("body"), Reason: proto.String(fmt.Sprintf("invalid %s: %v", format, err))},
+		})
+	}
+	return nil
+}
+
+// writeResponse encodes msg in the encoding negotiated for ctx.
+func writeResponse(ctx context.Context, w http.ResponseWriter, instance string, status int, msg proto.Message) {
+	marshal, contentType := proto.Marshal, protobufContentType
+	if errorpb.EncodingFromContext(ctx) == errorpb.EncodingJSON {
+		marshal, contentType = jsonMarshalOptions.Marshal, jsonContentType
+	}
+
+	body, err := marshal(msg)
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to marshal response: %v", err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,191 @@
+package endpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   errorpb.Encoding
+	}{
+		{
+			name:   "no accept header",
+			accept: "",
+			want:   errorpb.EncodingProtobuf,
+		},
+		{
+			name:   "wildcard",
+			accept: "*/*",
+			want:   errorpb.EncodingProtobuf,
+		},
+		{
+			name:   "json",
+			accept: "application/json",
+			want:   errorpb.EncodingJSON,
+		},
+		{
+			name:   "problem json",
+			accept: "application/problem+json",
+			want:   errorpb.EncodingJSON,
+		},
+		{
+			name:   "protobuf",
+			accept: "application/x-protobuf",
+			want:   errorpb.EncodingProtobuf,
+		},
+		{
+			name:   "json preferred by quality",
+			accept: "application/x-protobuf;q=0.5, application/json",
+			want:   errorpb.EncodingJSON,
+		},
+		{
+			name:   "protobuf preferred by quality",
+			accept: "application/json;q=0.2, application/x-protobuf;q=0.9",
+			want:   errorpb.EncodingProtobuf,
+		},
+		{
+			name:   "first listed wins a tie",
+			accept: "application/json, application/x-protobuf",
+			want:   errorpb.EncodingJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := negotiateEncoding(tt.accept); got != tt.want {
+				t.Errorf("negotiateEncoding(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentNegotiation_JSON(t *testing.T) {
+	h := ContentNegotiation(&registerMachinesHandler{
+		tracer:          noop.NewTracerProvider().Tracer(""),
+		log:             slog.Default(),
+		firestoreClient: &mockFirestoreClient{},
+	})
+
+	t.Run("json request and response", func(t *testing.T) {
+		body := []byte(`{"nics": [{"mac": "52:54:00:12:34:56"}]}`)
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/machines", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != jsonContentType {
+			t.Errorf("want Content-Type %q, got %q", jsonContentType, ct)
+		}
+
+		var resp endpointpb.RegisterMachineResponse
+		if err := protojson.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.GetMachineId() == "" {
+			t.Error("expected non-empty machine_id")
+		}
+	})
+
+	t.Run("json problem", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/machines", bytes.NewReader([]byte(`{"nics": []}`)))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != errorpb.JSONContentType {
+			t.Errorf("want Content-Type %q, got %q", errorpb.JSONContentType, ct)
+		}
+
+		var problem map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("failed to decode problem: %v", err)
+		}
+		if problem["title"] != "Validation Error" {
+			t.Errorf("unexpected problem %v", problem)
+		}
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/machines", bytes.NewReader([]byte(`{`)))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("want status %d, got %d (body: %s)", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/machines", bytes.NewReader([]byte(`<machine/>`)))
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("want status %d, got %d (body: %s)", http.StatusUnsupportedMediaType, w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestContentNegotiation_MachineJSON(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	h := ContentNegotiation(&getMachineHandler{
+		tracer: noop.NewTracerProvider().Tracer(""),
+		log:    slog.Default(),
+		firestoreClient: &mockFirestoreClient{
+			getResp: &service.GetMachineResponse{
+				Found: true,
+				Machine: &service.Machine{
+					ID: machineID,
+					MachineRequest: service.MachineRequest{
+						MemoryModules: []service.MemoryModule{{Size: 17179869184}},
+						NICs:          []service.NIC{{MAC: "52:54:00:12:34:56"}},
+					},
+				},
+			},
+		},
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/machines/"+machineID, nil)
+	r = withURLParam(r, "id", machineID)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if m["id"] != machineID {
+		t.Errorf("want id %q, got %v", machineID, m["id"])
+	}
+	if _, ok := m["memory_modules"]; !ok {
+		t.Errorf("expected snake_case field names, got %v", m)
+	}
+}
@@ -0,0 +1,149 @@
+package endpoint
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeTypes maps the event types a webhook can filter on back to the
+// changes they are published for.
+var changeTypes = map[endpointpb.MachineEventType]service.ChangeType{
+	endpointpb.MachineEventType_MACHINE_EVENT_TYPE_CREATED:       service.ChangeCreated,
+	endpointpb.MachineEventType_MACHINE_EVENT_TYPE_UPDATED:       service.ChangeUpdated,
+	endpointpb.MachineEventType_MACHINE_EVENT_TYPE_DELETED:       service.ChangeDeleted,
+	endpointpb.MachineEventType_MACHINE_EVENT_TYPE_STATE_CHANGED: service.ChangeStateChanged,
+}
+
+type createWebhookHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// CreateWebhook registers the endpoint for subscribing a URL to machine
+// changes. The response is the only one to include the signing secret.
+func CreateWebhook(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &createWebhookHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/webhooks", handler)
+}
+
+func (h *createWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	var req endpointpb.WebhookRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+	eventTypes, invalidFields := validateWebhookRequest(&req)
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	webhookID, err := uuid.NewV7()
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to generate webhook ID: %v", err)))
+		return
+	}
+
+	secret := req.GetSecret()
+	if secret == "" {
+		secret = rand.Text()
+	}
+	now := time.Now().UTC()
+	webhook := &service.Webhook{
+		ID:            webhookID.String(),
+		URL:           req.GetUrl(),
+		Secret:        secret,
+		EventTypes:    eventTypes,
+		LabelSelector: req.GetLabelSelector(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	_, err = h.firestoreClient.CreateWebhook(ctx, &service.CreateWebhookRequest{Webhook: webhook})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to create webhook: %v", err)))
+		return
+	}
+
+	resp := convertWebhookToProto(webhook)
+	resp.Secret = proto.String(secret)
+	writeResponse(ctx, w, instance, http.StatusCreated, resp)
+}
+
+// validateWebhookRequest also returns the change types the request filters
+// on, deduplicated.
+func validateWebhookRequest(req *endpointpb.WebhookRequest) ([]service.ChangeType, []*errorpb.InvalidField) {
+	var v fieldValidator
+
+	u, err := url.Parse(req.GetUrl())
+	switch {
+	case req.GetUrl() == "":
+		v.add("url", "is required")
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		v.add("url", "must be an absolute http or https URL")
+	}
+
+	var eventTypes []service.ChangeType
+	seen := make(map[service.ChangeType]bool)
+	for i, eventType := range req.GetEventTypes() {
+		changeType, ok := changeTypes[eventType]
+		if !ok {
+			v.add(fmt.Sprintf("event_types[%d]", i), fmt.Sprintf("unknown event type %s", eventType))
+			continue
+		}
+		if !seen[changeType] {
+			eventTypes = append(eventTypes, changeType)
+		}
+		seen[changeType] = true
+	}
+
+	if labelSelector := req.GetLabelSelector(); labelSelector != "" {
+		if _, err := service.ParseSelector(labelSelector); err != nil {
+			v.add("label_selector", err.Error())
+		}
+	}
+
+	return eventTypes, v.invalidFields
+}
+
+// convertWebhookToProto leaves out the secret, which is only returned when
+// the webhook is created.
+func convertWebhookToProto(webhook *service.Webhook) *endpointpb.Webhook {
+	eventTypes := make([]endpointpb.MachineEventType, len(webhook.EventTypes))
+	for i, changeType := range webhook.EventTypes {
+		eventTypes[i] = machineEventTypes[changeType]
+	}
+
+	return &endpointpb.Webhook{
+		Id:            proto.String(webhook.ID),
+		Url:           proto.String(webhook.URL),
+		EventTypes:    eventTypes,
+		LabelSelector: proto.String(webhook.LabelSelector),
+		CreatedAt:     timestamppb.New(webhook.CreatedAt),
+		UpdatedAt:     timestamppb.New(webhook.UpdatedAt),
+	}
+}
@@ -0,0 +1,139 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCreateWebhookHandler_ServeHTTP(t *testing.T) {
+	created := endpointpb.MachineEventType_MACHINE_EVENT_TYPE_CREATED
+	webhookRequest := func(url, selector string, eventTypes ...endpointpb.MachineEventType) *endpointpb.WebhookRequest {
+		return &endpointpb.WebhookRequest{
+			Url:           proto.String(url),
+			EventTypes:    eventTypes,
+			LabelSelector: proto.String(selector),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		body      *endpointpb.WebhookRequest
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, client *mockFirestoreClient, body []byte)
+	}{
+		{
+			name:     "missing url",
+			body:     webhookRequest("", ""),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "relative url",
+			body:     webhookRequest("/hooks", ""),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unsupported scheme",
+			body:     webhookRequest("ftp://hooks.example.com", ""),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unspecified event type",
+			body:     webhookRequest("https://hooks.example.com", "", endpointpb.MachineEventType_MACHINE_EVENT_TYPE_UNSPECIFIED),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid label selector",
+			body:     webhookRequest("https://hooks.example.com", "rack in r1"),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "CreateWebhook error",
+			body:     webhookRequest("https://hooks.example.com", ""),
+			client:   &mockFirestoreClient{webhookCreateErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			body:     webhookRequest("https://hooks.example.com/machines", "rack=r1", created, created),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusCreated,
+			checkBody: func(t *testing.T, client *mockFirestoreClient, body []byte) {
+				var resp endpointpb.Webhook
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				webhook := client.webhookCreateReq.Webhook
+				if err := validateWebhookID(resp.GetId()); err != nil || resp.GetId() != webhook.ID {
+					t.Errorf("want the stored webhook ID, got %q (%v)", resp.GetId(), err)
+				}
+				// The secret is generated, and returned this once.
+				if webhook.Secret == "" || resp.GetSecret() != webhook.Secret {
+					t.Errorf("want the generated secret returned, got %q", resp.GetSecret())
+				}
+				if len(webhook.EventTypes) != 1 || webhook.EventTypes[0] != service.ChangeCreated || webhook.LabelSelector != "rack=r1" {
+					t.Errorf("unexpected webhook %+v", webhook)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			CreateWebhook(mux, tt.client)
+
+			body, err := proto.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, tt.client, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCreateWebhookHandler_KeepsGivenSecret(t *testing.T) {
+	client := &mockFirestoreClient{}
+	mux := chi.NewRouter()
+	CreateWebhook(mux, client)
+
+	body, err := proto.Marshal(&endpointpb.WebhookRequest{
+		Url:    proto.String("https://hooks.example.com"),
+		Secret: proto.String("s3cret"),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want status %d, got %d (body: %s)", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if got := client.webhookCreateReq.Webhook.Secret; got != "s3cret" {
+		t.Errorf("want the given secret stored, got %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type deleteMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func DeleteMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &deleteMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodDelete, "/api/v1/machines/{id}", handler)
+}
+
+func (h *deleteMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient}
+	if err := machines.delete(ctx, instance, machineID, requestActor(r), ifMatch(r)); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
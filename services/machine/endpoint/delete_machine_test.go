@@ -0,0 +1,108 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestDeleteMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID},
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		actor     string
+		client    *mockFirestoreClient
+		wantCode  int
+		wantActor string
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GetMachine error",
+			id:       machineID,
+			client:   &mockFirestoreClient{getErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "machine not found",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{Found: false},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "machine deleted concurrently",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp:   found,
+				deleteErr: &service.MachineNotFoundError{MachineID: machineID},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "DeleteMachine error",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp:   found,
+				deleteErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:      "success",
+			id:        machineID,
+			actor:     "admin@example.com",
+			client:    &mockFirestoreClient{getResp: found},
+			wantCode:  http.StatusNoContent,
+			wantActor: "admin@example.com",
+		},
+		{
+			name:      "success without actor",
+			id:        machineID,
+			client:    &mockFirestoreClient{getResp: found},
+			wantCode:  http.StatusNoContent,
+			wantActor: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &deleteMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodDelete, "/api/v1/machines/"+tt.id, nil)
+			r = withURLParam(r, "id", tt.id)
+			if tt.actor != "" {
+				r.Header.Set(actorHeader, tt.actor)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantActor != "" && tt.client.deleteReq.DeletedBy != tt.wantActor {
+				t.Errorf("want deleted_by %q, got %q", tt.wantActor, tt.client.deleteReq.DeletedBy)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type deleteWebhookHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// DeleteWebhook registers the endpoint for unsubscribing a webhook. Its
+// pending deliveries are abandoned and its delivery log is deleted with it.
+func DeleteWebhook(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &deleteWebhookHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodDelete, "/api/v1/webhooks/{id}", handler)
+}
+
+func (h *deleteWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	webhookID := chi.URLParam(r, "id")
+	if err := validateWebhookID(webhookID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	_, err := h.firestoreClient.DeleteWebhook(ctx, &service.DeleteWebhookRequest{
+		WebhookID: webhookID,
+	})
+	var notFound *service.WebhookNotFoundError
+	if errors.As(err, &notFound) {
+		errorHandler(ctx, w, errorpb.NewWebhookNotFoundError(instance, webhookID))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to delete webhook: %v", err)))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDeleteWebhookHandler_ServeHTTP(t *testing.T) {
+	webhookID := "018c7dbd-c000-7000-8000-0000000000f1"
+
+	tests := []struct {
+		name     string
+		id       string
+		client   *mockFirestoreClient
+		wantCode int
+	}{
+		{
+			name:     "invalid webhook ID",
+			id:       "018c7dbd-c000-4000-8000-0000000000f1",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			id:       webhookID,
+			client:   &mockFirestoreClient{webhookDeleteErr: fmt.Errorf("failed to delete webhook: %w", &service.WebhookNotFoundError{WebhookID: webhookID})},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "DeleteWebhook error",
+			id:       webhookID,
+			client:   &mockFirestoreClient{webhookDeleteErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			id:       webhookID,
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			DeleteWebhook(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
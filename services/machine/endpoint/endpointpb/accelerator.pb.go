@@ -24,6 +24,9 @@ const (
 type Accelerator struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Manufacturer  *string                `protobuf:"bytes,1,opt,name=manufacturer" json:"manufacturer,omitempty"`
+	Model         *string                `protobuf:"bytes,2,opt,name=model" json:"model,omitempty"`
+	SerialNumber  *string                `protobuf:"bytes,3,opt,name=serial_number,json=serialNumber" json:"serial_number,omitempty"`
+	PciAddress    *string                `protobuf:"bytes,4,opt,name=pci_address,json=pciAddress" json:"pci_address,omitempty"` // PCI bus address, e.g. "0000:3b:00.0"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,14 +68,39 @@ func (x *Accelerator) GetManufacturer() string {
 	return ""
 }
 
+func (x *Accelerator) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *Accelerator) GetSerialNumber() string {
+	if x != nil && x.SerialNumber != nil {
+		return *x.SerialNumber
+	}
+	return ""
+}
+
+func (x *Accelerator) GetPciAddress() string {
+	if x != nil && x.PciAddress != nil {
+		return *x.PciAddress
+	}
+	return ""
+}
+
 var File_accelerator_proto protoreflect.FileDescriptor
 
 const file_accelerator_proto_rawDesc = "" +
 	"\n" +
 	"\x11accelerator.proto\x12\n" +
-	"endpointpb\"1\n" +
+	"endpointpb\"\x8d\x01\n" +
 	"\vAccelerator\x12\"\n" +
-	"\fmanufacturer\x18\x01 \x01(\tR\fmanufacturerBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\fmanufacturer\x18\x01 \x01(\tR\fmanufacturer\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12#\n" +
+	"\rserial_number\x18\x03 \x01(\tR\fserialNumber\x12\x1f\n" +
+	"\vpci_address\x18\x04 \x01(\tR\n" +
+	"pciAddressBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_accelerator_proto_rawDescOnce sync.Once
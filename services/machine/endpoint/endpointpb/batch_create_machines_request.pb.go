@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: batch_create_machines_request.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BatchCreateMachinesRequest struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Requests      []*RegisterMachineRequest `protobuf:"bytes,1,rep,name=requests" json:"requests,omitempty"`
+	Atomic        *bool                     `protobuf:"varint,2,opt,name=atomic" json:"atomic,omitempty"` // create every machine or none
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateMachinesRequest) Reset() {
+	*x = BatchCreateMachinesRequest{}
+	mi := &file_batch_create_machines_request_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateMachinesRequest) ProtoMessage() {}
+
+func (x *BatchCreateMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_batch_create_machines_request_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateMachinesRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_batch_create_machines_request_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BatchCreateMachinesRequest) GetRequests() []*RegisterMachineRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *BatchCreateMachinesRequest) GetAtomic() bool {
+	if x != nil && x.Atomic != nil {
+		return *x.Atomic
+	}
+	return false
+}
+
+var File_batch_create_machines_request_proto protoreflect.FileDescriptor
+
+const file_batch_create_machines_request_proto_rawDesc = "" +
+	"\n" +
+	"#batch_create_machines_request.proto\x12\n" +
+	"endpointpb\x1a\x1eregister_machine_request.proto\"t\n" +
+	"\x1aBatchCreateMachinesRequest\x12>\n" +
+	"\brequests\x18\x01 \x03(\v2\".endpointpb.RegisterMachineRequestR\brequests\x12\x16\n" +
+	"\x06atomic\x18\x02 \x01(\bR\x06atomicBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_batch_create_machines_request_proto_rawDescOnce sync.Once
+	file_batch_create_machines_request_proto_rawDescData []byte
+)
+
+func file_batch_create_machines_request_proto_rawDescGZIP() []byte {
+	file_batch_create_machines_request_proto_rawDescOnce.Do(func() {
+		file_batch_create_machines_request_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_batch_create_machines_request_proto_rawDesc), len(file_batch_create_machines_request_proto_rawDesc)))
+	})
+	return file_batch_create_machines_request_proto_rawDescData
+}
+
+var file_batch_create_machines_request_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_batch_create_machines_request_proto_goTypes = []any{
+	(*BatchCreateMachinesRequest)(nil), // 0: endpointpb.BatchCreateMachinesRequest
+	(*RegisterMachineRequest)(nil),     // 1: endpointpb.RegisterMachineRequest
+}
+var file_batch_create_machines_request_proto_depIdxs = []int32{
+	1, // 0: endpointpb.BatchCreateMachinesRequest.requests:type_name -> endpointpb.RegisterMachineRequest
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_batch_create_machines_request_proto_init() }
+func file_batch_create_machines_request_proto_init() {
+	if File_batch_create_machines_request_proto != nil {
+		return
+	}
+	file_register_machine_request_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_batch_create_machines_request_proto_rawDesc), len(file_batch_create_machines_request_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_batch_create_machines_request_proto_goTypes,
+		DependencyIndexes: file_batch_create_machines_request_proto_depIdxs,
+		MessageInfos:      file_batch_create_machines_request_proto_msgTypes,
+	}.Build()
+	File_batch_create_machines_request_proto = out.File
+	file_batch_create_machines_request_proto_goTypes = nil
+	file_batch_create_machines_request_proto_depIdxs = nil
+}
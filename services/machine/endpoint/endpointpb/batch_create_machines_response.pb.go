@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: batch_create_machines_response.proto
+
+package endpointpb
+
+import (
+	errorpb "github.com/Zaba505/infra/pkg/errorpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BatchCreateMachinesResponse struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Results       []*BatchCreateMachineResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"` // one per request, in order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateMachinesResponse) Reset() {
+	*x = BatchCreateMachinesResponse{}
+	mi := &file_batch_create_machines_response_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateMachinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateMachinesResponse) ProtoMessage() {}
+
+func (x *BatchCreateMachinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_batch_create_machines_response_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateMachinesResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateMachinesResponse) Descriptor() ([]byte, []int) {
+	return file_batch_create_machines_response_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BatchCreateMachinesResponse) GetResults() []*BatchCreateMachineResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchCreateMachineResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*BatchCreateMachineResult_MachineId
+	//	*BatchCreateMachineResult_ValidationProblem
+	//	*BatchCreateMachineResult_ConflictProblem
+	//	*BatchCreateMachineResult_Problem
+	Result        isBatchCreateMachineResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateMachineResult) Reset() {
+	*x = BatchCreateMachineResult{}
+	mi := &file_batch_create_machines_response_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateMachineResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateMachineResult) ProtoMessage() {}
+
+func (x *BatchCreateMachineResult) ProtoReflect() protoreflect.Message {
+	mi := &file_batch_create_machines_response_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateMachineResult.ProtoReflect.Descriptor instead.
+func (*BatchCreateMachineResult) Descriptor() ([]byte, []int) {
+	return file_batch_create_machines_response_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchCreateMachineResult) GetResult() isBatchCreateMachineResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchCreateMachineResult) GetMachineId() string {
+	if x != nil {
+		if x, ok := x.Result.(*BatchCreateMachineResult_MachineId); ok {
+			return x.MachineId
+		}
+	}
+	return ""
+}
+
+func (x *BatchCreateMachineResult) GetValidationProblem() *errorpb.ValidationProblem {
+	if x != nil {
+		if x, ok := x.Result.(*BatchCreateMachineResult_ValidationProblem); ok {
+			return x.ValidationProblem
+		}
+	}
+	return nil
+}
+
+func (x *BatchCreateMachineResult) GetConflictProblem() *errorpb.ConflictProblem {
+	if x != nil {
+		if x, ok := x.Result.(*BatchCreateMachineResult_ConflictProblem); ok {
+			return x.ConflictProblem
+		}
+	}
+	return nil
+}
+
+func (x *BatchCreateMachineResult) GetProblem() *errorpb.Problem {
+	if x != nil {
+		if x, ok := x.Result.(*BatchCreateMachineResult_Problem); ok {
+			return x.Problem
+		}
+	}
+	return nil
+}
+
+type isBatchCreateMachineResult_Result interface {
+	isBatchCreateMachineResult_Result()
+}
+
+type BatchCreateMachineResult_MachineId struct {
+	MachineId string `protobuf:"bytes,1,opt,name=machine_id,json=machineId,oneof"` // UUIDv7 of the created machine
+}
+
+type BatchCreateMachineResult_ValidationProblem struct {
+	ValidationProblem *errorpb.ValidationProblem `protobuf:"bytes,2,opt,name=validation_problem,json=validationProblem,oneof"`
+}
+
+type BatchCreateMachineResult_ConflictProblem struct {
+	ConflictProblem *errorpb.ConflictProblem `protobuf:"bytes,3,opt,name=conflict_problem,json=conflictProblem,oneof"`
+}
+
+type BatchCreateMachineResult_Problem struct {
+	Problem *errorpb.Problem `protobuf:"bytes,4,opt,name=problem,oneof"`
+}
+
+func (*BatchCreateMachineResult_MachineId) isBatchCreateMachineResult_Result() {}
+
+func (*BatchCreateMachineResult_ValidationProblem) isBatchCreateMachineResult_Result() {}
+
+func (*BatchCreateMachineResult_ConflictProblem) isBatchCreateMachineResult_Result() {}
+
+func (*BatchCreateMachineResult_Problem) isBatchCreateMachineResult_Result() {}
+
+var File_batch_create_machines_response_proto protoreflect.FileDescriptor
+
+const file_batch_create_machines_response_proto_rawDesc = "" +
+	"\n" +
+	"$batch_create_machines_response.proto\x12\n" +
+	"endpointpb\x1a\x16conflict_problem.proto\x1a\rproblem.proto\x1a\x18validation_problem.proto\"]\n" +
+	"\x1bBatchCreateMachinesResponse\x12>\n" +
+	"\aresults\x18\x01 \x03(\v2$.endpointpb.BatchCreateMachineResultR\aresults\"\x87\x02\n" +
+	"\x18BatchCreateMachineResult\x12\x1f\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tH\x00R\tmachineId\x12K\n" +
+	"\x12validation_problem\x18\x02 \x01(\v2\x1a.errorpb.ValidationProblemH\x00R\x11validationProblem\x12E\n" +
+	"\x10conflict_problem\x18\x03 \x01(\v2\x18.errorpb.ConflictProblemH\x00R\x0fconflictProblem\x12,\n" +
+	"\aproblem\x18\x04 \x01(\v2\x10.errorpb.ProblemH\x00R\aproblemB\b\n" +
+	"\x06resultBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_batch_create_machines_response_proto_rawDescOnce sync.Once
+	file_batch_create_machines_response_proto_rawDescData []byte
+)
+
+func file_batch_create_machines_response_proto_rawDescGZIP() []byte {
+	file_batch_create_machines_response_proto_rawDescOnce.Do(func() {
+		file_batch_create_machines_response_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_batch_create_machines_response_proto_rawDesc), len(file_batch_create_machines_response_proto_rawDesc)))
+	})
+	return file_batch_create_machines_response_proto_rawDescData
+}
+
+var file_batch_create_machines_response_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_batch_create_machines_response_proto_goTypes = []any{
+	(*BatchCreateMachinesResponse)(nil), // 0: endpointpb.BatchCreateMachinesResponse
+	(*BatchCreateMachineResult)(nil),    // 1: endpointpb.BatchCreateMachineResult
+	(*errorpb.ValidationProblem)(nil),   // 2: errorpb.ValidationProblem
+	(*errorpb.ConflictProblem)(nil),     // 3: errorpb.ConflictProblem
+	(*errorpb.Problem)(nil),             // 4: errorpb.Problem
+}
+var file_batch_create_machines_response_proto_depIdxs = []int32{
+	1, // 0: endpointpb.BatchCreateMachinesResponse.results:type_name -> endpointpb.BatchCreateMachineResult
+	2, // 1: endpointpb.BatchCreateMachineResult.validation_problem:type_name -> errorpb.ValidationProblem
+	3, // 2: endpointpb.BatchCreateMachineResult.conflict_problem:type_name -> errorpb.ConflictProblem
+	4, // 3: endpointpb.BatchCreateMachineResult.problem:type_name -> errorpb.Problem
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_batch_create_machines_response_proto_init() }
+func file_batch_create_machines_response_proto_init() {
+	if File_batch_create_machines_response_proto != nil {
+		return
+	}
+	file_batch_create_machines_response_proto_msgTypes[1].OneofWrappers = []any{
+		(*BatchCreateMachineResult_MachineId)(nil),
+		(*BatchCreateMachineResult_ValidationProblem)(nil),
+		(*BatchCreateMachineResult_ConflictProblem)(nil),
+		(*BatchCreateMachineResult_Problem)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_batch_create_machines_response_proto_rawDesc), len(file_batch_create_machines_response_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_batch_create_machines_response_proto_goTypes,
+		DependencyIndexes: file_batch_create_machines_response_proto_depIdxs,
+		MessageInfos:      file_batch_create_machines_response_proto_msgTypes,
+	}.Build()
+	File_batch_create_machines_response_proto = out.File
+	file_batch_create_machines_response_proto_goTypes = nil
+	file_batch_create_machines_response_proto_depIdxs = nil
+}
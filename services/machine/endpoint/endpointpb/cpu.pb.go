@@ -26,6 +26,9 @@ type CPU struct {
 	Manufacturer   *string                `protobuf:"bytes,1,opt,name=manufacturer" json:"manufacturer,omitempty"`
 	ClockFrequency *int64                 `protobuf:"varint,2,opt,name=clock_frequency,json=clockFrequency" json:"clock_frequency,omitempty"` // measured in hertz
 	Cores          *int64                 `protobuf:"varint,3,opt,name=cores" json:"cores,omitempty"`                                         // number of cores
+	Model          *string                `protobuf:"bytes,4,opt,name=model" json:"model,omitempty"`                                          // e.g. "Intel(R) Xeon(R) CPU E5-2680 v3"
+	Socket         *string                `protobuf:"bytes,5,opt,name=socket" json:"socket,omitempty"`                                        // socket designation, e.g. "Proc 1"
+	Threads        *int64                 `protobuf:"varint,6,opt,name=threads" json:"threads,omitempty"`                                     // number of hardware threads
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -81,16 +84,40 @@ func (x *CPU) GetCores() int64 {
 	return 0
 }
 
+func (x *CPU) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *CPU) GetSocket() string {
+	if x != nil && x.Socket != nil {
+		return *x.Socket
+	}
+	return ""
+}
+
+func (x *CPU) GetThreads() int64 {
+	if x != nil && x.Threads != nil {
+		return *x.Threads
+	}
+	return 0
+}
+
 var File_cpu_proto protoreflect.FileDescriptor
 
 const file_cpu_proto_rawDesc = "" +
 	"\n" +
 	"\tcpu.proto\x12\n" +
-	"endpointpb\"h\n" +
+	"endpointpb\"\xb0\x01\n" +
 	"\x03CPU\x12\"\n" +
 	"\fmanufacturer\x18\x01 \x01(\tR\fmanufacturer\x12'\n" +
 	"\x0fclock_frequency\x18\x02 \x01(\x03R\x0eclockFrequency\x12\x14\n" +
-	"\x05cores\x18\x03 \x01(\x03R\x05coresBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\x05cores\x18\x03 \x01(\x03R\x05cores\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x16\n" +
+	"\x06socket\x18\x05 \x01(\tR\x06socket\x12\x18\n" +
+	"\athreads\x18\x06 \x01(\x03R\athreadsBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_cpu_proto_rawDescOnce sync.Once
@@ -21,9 +21,115 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type DriveMediaType int32
+
+const (
+	DriveMediaType_DRIVE_MEDIA_TYPE_UNSPECIFIED DriveMediaType = 0
+	DriveMediaType_DRIVE_MEDIA_TYPE_HDD         DriveMediaType = 1
+	DriveMediaType_DRIVE_MEDIA_TYPE_SSD         DriveMediaType = 2
+)
+
+// Enum value maps for DriveMediaType.
+var (
+	DriveMediaType_name = map[int32]string{
+		0: "DRIVE_MEDIA_TYPE_UNSPECIFIED",
+		1: "DRIVE_MEDIA_TYPE_HDD",
+		2: "DRIVE_MEDIA_TYPE_SSD",
+	}
+	DriveMediaType_value = map[string]int32{
+		"DRIVE_MEDIA_TYPE_UNSPECIFIED": 0,
+		"DRIVE_MEDIA_TYPE_HDD":         1,
+		"DRIVE_MEDIA_TYPE_SSD":         2,
+	}
+)
+
+func (x DriveMediaType) Enum() *DriveMediaType {
+	p := new(DriveMediaType)
+	*p = x
+	return p
+}
+
+func (x DriveMediaType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DriveMediaType) Descriptor() protoreflect.EnumDescriptor {
+	return file_drive_proto_enumTypes[0].Descriptor()
+}
+
+func (DriveMediaType) Type() protoreflect.EnumType {
+	return &file_drive_proto_enumTypes[0]
+}
+
+func (x DriveMediaType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DriveMediaType.Descriptor instead.
+func (DriveMediaType) EnumDescriptor() ([]byte, []int) {
+	return file_drive_proto_rawDescGZIP(), []int{0}
+}
+
+type DriveInterface int32
+
+const (
+	DriveInterface_DRIVE_INTERFACE_UNSPECIFIED DriveInterface = 0
+	DriveInterface_DRIVE_INTERFACE_SATA        DriveInterface = 1
+	DriveInterface_DRIVE_INTERFACE_SAS         DriveInterface = 2
+	DriveInterface_DRIVE_INTERFACE_NVME        DriveInterface = 3
+)
+
+// Enum value maps for DriveInterface.
+var (
+	DriveInterface_name = map[int32]string{
+		0: "DRIVE_INTERFACE_UNSPECIFIED",
+		1: "DRIVE_INTERFACE_SATA",
+		2: "DRIVE_INTERFACE_SAS",
+		3: "DRIVE_INTERFACE_NVME",
+	}
+	DriveInterface_value = map[string]int32{
+		"DRIVE_INTERFACE_UNSPECIFIED": 0,
+		"DRIVE_INTERFACE_SATA":        1,
+		"DRIVE_INTERFACE_SAS":         2,
+		"DRIVE_INTERFACE_NVME":        3,
+	}
+)
+
+func (x DriveInterface) Enum() *DriveInterface {
+	p := new(DriveInterface)
+	*p = x
+	return p
+}
+
+func (x DriveInterface) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DriveInterface) Descriptor() protoreflect.EnumDescriptor {
+	return file_drive_proto_enumTypes[1].Descriptor()
+}
+
+func (DriveInterface) Type() protoreflect.EnumType {
+	return &file_drive_proto_enumTypes[1]
+}
+
+func (x DriveInterface) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DriveInterface.Descriptor instead.
+func (DriveInterface) EnumDescriptor() ([]byte, []int) {
+	return file_drive_proto_rawDescGZIP(), []int{1}
+}
+
 type Drive struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Capacity      *int64                 `protobuf:"varint,1,opt,name=capacity" json:"capacity,omitempty"` // capacity in bytes
+	MediaType     *DriveMediaType        `protobuf:"varint,2,opt,name=media_type,json=mediaType,enum=endpointpb.DriveMediaType" json:"media_type,omitempty"`
+	Interface     *DriveInterface        `protobuf:"varint,3,opt,name=interface,enum=endpointpb.DriveInterface" json:"interface,omitempty"`
+	Model         *string                `protobuf:"bytes,4,opt,name=model" json:"model,omitempty"`
+	SerialNumber  *string                `protobuf:"bytes,5,opt,name=serial_number,json=serialNumber" json:"serial_number,omitempty"`
+	Slot          *string                `protobuf:"bytes,6,opt,name=slot" json:"slot,omitempty"` // bay or slot designation, e.g. "Port 1I Box 1 Bay 1"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,14 +171,64 @@ func (x *Drive) GetCapacity() int64 {
 	return 0
 }
 
+func (x *Drive) GetMediaType() DriveMediaType {
+	if x != nil && x.MediaType != nil {
+		return *x.MediaType
+	}
+	return DriveMediaType_DRIVE_MEDIA_TYPE_UNSPECIFIED
+}
+
+func (x *Drive) GetInterface() DriveInterface {
+	if x != nil && x.Interface != nil {
+		return *x.Interface
+	}
+	return DriveInterface_DRIVE_INTERFACE_UNSPECIFIED
+}
+
+func (x *Drive) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *Drive) GetSerialNumber() string {
+	if x != nil && x.SerialNumber != nil {
+		return *x.SerialNumber
+	}
+	return ""
+}
+
+func (x *Drive) GetSlot() string {
+	if x != nil && x.Slot != nil {
+		return *x.Slot
+	}
+	return ""
+}
+
 var File_drive_proto protoreflect.FileDescriptor
 
 const file_drive_proto_rawDesc = "" +
 	"\n" +
 	"\vdrive.proto\x12\n" +
-	"endpointpb\"#\n" +
+	"endpointpb\"\xe7\x01\n" +
 	"\x05Drive\x12\x1a\n" +
-	"\bcapacity\x18\x01 \x01(\x03R\bcapacityBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\bcapacity\x18\x01 \x01(\x03R\bcapacity\x129\n" +
+	"\n" +
+	"media_type\x18\x02 \x01(\x0e2\x1a.endpointpb.DriveMediaTypeR\tmediaType\x128\n" +
+	"\tinterface\x18\x03 \x01(\x0e2\x1a.endpointpb.DriveInterfaceR\tinterface\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12#\n" +
+	"\rserial_number\x18\x05 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04slot\x18\x06 \x01(\tR\x04slot*f\n" +
+	"\x0eDriveMediaType\x12 \n" +
+	"\x1cDRIVE_MEDIA_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14DRIVE_MEDIA_TYPE_HDD\x10\x01\x12\x18\n" +
+	"\x14DRIVE_MEDIA_TYPE_SSD\x10\x02*~\n" +
+	"\x0eDriveInterface\x12\x1f\n" +
+	"\x1bDRIVE_INTERFACE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14DRIVE_INTERFACE_SATA\x10\x01\x12\x17\n" +
+	"\x13DRIVE_INTERFACE_SAS\x10\x02\x12\x18\n" +
+	"\x14DRIVE_INTERFACE_NVME\x10\x03BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_drive_proto_rawDescOnce sync.Once
@@ -86,16 +242,21 @@ func file_drive_proto_rawDescGZIP() []byte {
 	return file_drive_proto_rawDescData
 }
 
+var file_drive_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
 var file_drive_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_drive_proto_goTypes = []any{
-	(*Drive)(nil), // 0: endpointpb.Drive
+	(DriveMediaType)(0), // 0: endpointpb.DriveMediaType
+	(DriveInterface)(0), // 1: endpointpb.DriveInterface
+	(*Drive)(nil),       // 2: endpointpb.Drive
 }
 var file_drive_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: endpointpb.Drive.media_type:type_name -> endpointpb.DriveMediaType
+	1, // 1: endpointpb.Drive.interface:type_name -> endpointpb.DriveInterface
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_drive_proto_init() }
@@ -108,13 +269,14 @@ func file_drive_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_drive_proto_rawDesc), len(file_drive_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      2,
 			NumMessages:   1,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_drive_proto_goTypes,
 		DependencyIndexes: file_drive_proto_depIdxs,
+		EnumInfos:         file_drive_proto_enumTypes,
 		MessageInfos:      file_drive_proto_msgTypes,
 	}.Build()
 	File_drive_proto = out.File
@@ -0,0 +1,263 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: inventory_summary.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LabelValueCounts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machines      map[string]int64       `protobuf:"bytes,1,rep,name=machines" json:"machines,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // machines per label value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LabelValueCounts) Reset() {
+	*x = LabelValueCounts{}
+	mi := &file_inventory_summary_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelValueCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelValueCounts) ProtoMessage() {}
+
+func (x *LabelValueCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_summary_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelValueCounts.ProtoReflect.Descriptor instead.
+func (*LabelValueCounts) Descriptor() ([]byte, []int) {
+	return file_inventory_summary_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LabelValueCounts) GetMachines() map[string]int64 {
+	if x != nil {
+		return x.Machines
+	}
+	return nil
+}
+
+type InventorySummary struct {
+	state                      protoimpl.MessageState       `protogen:"open.v1"`
+	Machines                   *int64                       `protobuf:"varint,1,opt,name=machines" json:"machines,omitempty"` // live machines only
+	Cores                      *int64                       `protobuf:"varint,2,opt,name=cores" json:"cores,omitempty"`
+	Memory                     *int64                       `protobuf:"varint,3,opt,name=memory" json:"memory,omitempty"`                                                                                                                                                       // bytes
+	StorageByMediaType         map[string]int64             `protobuf:"bytes,4,rep,name=storage_by_media_type,json=storageByMediaType" json:"storage_by_media_type,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`                       // bytes, keyed by DriveMediaType name
+	AcceleratorsByManufacturer map[string]int64             `protobuf:"bytes,5,rep,name=accelerators_by_manufacturer,json=acceleratorsByManufacturer" json:"accelerators_by_manufacturer,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // accelerators, not machines
+	MachinesByLifecycleState   map[string]int64             `protobuf:"bytes,6,rep,name=machines_by_lifecycle_state,json=machinesByLifecycleState" json:"machines_by_lifecycle_state,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`     // keyed by LifecycleState name
+	MachinesByLabel            map[string]*LabelValueCounts `protobuf:"bytes,7,rep,name=machines_by_label,json=machinesByLabel" json:"machines_by_label,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`                                   // keyed by label key
+	UpdatedAt                  *timestamppb.Timestamp       `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *InventorySummary) Reset() {
+	*x = InventorySummary{}
+	mi := &file_inventory_summary_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventorySummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventorySummary) ProtoMessage() {}
+
+func (x *InventorySummary) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_summary_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventorySummary.ProtoReflect.Descriptor instead.
+func (*InventorySummary) Descriptor() ([]byte, []int) {
+	return file_inventory_summary_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *InventorySummary) GetMachines() int64 {
+	if x != nil && x.Machines != nil {
+		return *x.Machines
+	}
+	return 0
+}
+
+func (x *InventorySummary) GetCores() int64 {
+	if x != nil && x.Cores != nil {
+		return *x.Cores
+	}
+	return 0
+}
+
+func (x *InventorySummary) GetMemory() int64 {
+	if x != nil && x.Memory != nil {
+		return *x.Memory
+	}
+	return 0
+}
+
+func (x *InventorySummary) GetStorageByMediaType() map[string]int64 {
+	if x != nil {
+		return x.StorageByMediaType
+	}
+	return nil
+}
+
+func (x *InventorySummary) GetAcceleratorsByManufacturer() map[string]int64 {
+	if x != nil {
+		return x.AcceleratorsByManufacturer
+	}
+	return nil
+}
+
+func (x *InventorySummary) GetMachinesByLifecycleState() map[string]int64 {
+	if x != nil {
+		return x.MachinesByLifecycleState
+	}
+	return nil
+}
+
+func (x *InventorySummary) GetMachinesByLabel() map[string]*LabelValueCounts {
+	if x != nil {
+		return x.MachinesByLabel
+	}
+	return nil
+}
+
+func (x *InventorySummary) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_inventory_summary_proto protoreflect.FileDescriptor
+
+const file_inventory_summary_proto_rawDesc = "" +
+	"\n" +
+	"\x17inventory_summary.proto\x12\n" +
+	"endpointpb\x1a\x1fgoogle/protobuf/timestamp.proto\"\x97\x01\n" +
+	"\x10LabelValueCounts\x12F\n" +
+	"\bmachines\x18\x01 \x03(\v2*.endpointpb.LabelValueCounts.MachinesEntryR\bmachines\x1a;\n" +
+	"\rMachinesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x9f\a\n" +
+	"\x10InventorySummary\x12\x1a\n" +
+	"\bmachines\x18\x01 \x01(\x03R\bmachines\x12\x14\n" +
+	"\x05cores\x18\x02 \x01(\x03R\x05cores\x12\x16\n" +
+	"\x06memory\x18\x03 \x01(\x03R\x06memory\x12g\n" +
+	"\x15storage_by_media_type\x18\x04 \x03(\v24.endpointpb.InventorySummary.StorageByMediaTypeEntryR\x12storageByMediaType\x12~\n" +
+	"\x1caccelerators_by_manufacturer\x18\x05 \x03(\v2<.endpointpb.InventorySummary.AcceleratorsByManufacturerEntryR\x1aacceleratorsByManufacturer\x12y\n" +
+	"\x1bmachines_by_lifecycle_state\x18\x06 \x03(\v2:.endpointpb.InventorySummary.MachinesByLifecycleStateEntryR\x18machinesByLifecycleState\x12]\n" +
+	"\x11machines_by_label\x18\a \x03(\v21.endpointpb.InventorySummary.MachinesByLabelEntryR\x0fmachinesByLabel\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1aE\n" +
+	"\x17StorageByMediaTypeEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1aM\n" +
+	"\x1fAcceleratorsByManufacturerEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1aK\n" +
+	"\x1dMachinesByLifecycleStateEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a`\n" +
+	"\x14MachinesByLabelEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x122\n" +
+	"\x05value\x18\x02 \x01(\v2\x1c.endpointpb.LabelValueCountsR\x05value:\x028\x01BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_inventory_summary_proto_rawDescOnce sync.Once
+	file_inventory_summary_proto_rawDescData []byte
+)
+
+func file_inventory_summary_proto_rawDescGZIP() []byte {
+	file_inventory_summary_proto_rawDescOnce.Do(func() {
+		file_inventory_summary_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_inventory_summary_proto_rawDesc), len(file_inventory_summary_proto_rawDesc)))
+	})
+	return file_inventory_summary_proto_rawDescData
+}
+
+var file_inventory_summary_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_inventory_summary_proto_goTypes = []any{
+	(*LabelValueCounts)(nil),      // 0: endpointpb.LabelValueCounts
+	(*InventorySummary)(nil),      // 1: endpointpb.InventorySummary
+	nil,                           // 2: endpointpb.LabelValueCounts.MachinesEntry
+	nil,                           // 3: endpointpb.InventorySummary.StorageByMediaTypeEntry
+	nil,                           // 4: endpointpb.InventorySummary.AcceleratorsByManufacturerEntry
+	nil,                           // 5: endpointpb.InventorySummary.MachinesByLifecycleStateEntry
+	nil,                           // 6: endpointpb.InventorySummary.MachinesByLabelEntry
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_inventory_summary_proto_depIdxs = []int32{
+	2, // 0: endpointpb.LabelValueCounts.machines:type_name -> endpointpb.LabelValueCounts.MachinesEntry
+	3, // 1: endpointpb.InventorySummary.storage_by_media_type:type_name -> endpointpb.InventorySummary.StorageByMediaTypeEntry
+	4, // 2: endpointpb.InventorySummary.accelerators_by_manufacturer:type_name -> endpointpb.InventorySummary.AcceleratorsByManufacturerEntry
+	5, // 3: endpointpb.InventorySummary.machines_by_lifecycle_state:type_name -> endpointpb.InventorySummary.MachinesByLifecycleStateEntry
+	6, // 4: endpointpb.InventorySummary.machines_by_label:type_name -> endpointpb.InventorySummary.MachinesByLabelEntry
+	7, // 5: endpointpb.InventorySummary.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 6: endpointpb.InventorySummary.MachinesByLabelEntry.value:type_name -> endpointpb.LabelValueCounts
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_inventory_summary_proto_init() }
+func file_inventory_summary_proto_init() {
+	if File_inventory_summary_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inventory_summary_proto_rawDesc), len(file_inventory_summary_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_inventory_summary_proto_goTypes,
+		DependencyIndexes: file_inventory_summary_proto_depIdxs,
+		MessageInfos:      file_inventory_summary_proto_msgTypes,
+	}.Build()
+	File_inventory_summary_proto = out.File
+	file_inventory_summary_proto_goTypes = nil
+	file_inventory_summary_proto_depIdxs = nil
+}
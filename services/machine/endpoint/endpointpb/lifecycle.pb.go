@@ -0,0 +1,233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: lifecycle.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LifecycleState int32
+
+const (
+	LifecycleState_LIFECYCLE_STATE_UNSPECIFIED    LifecycleState = 0
+	LifecycleState_LIFECYCLE_STATE_NEW            LifecycleState = 1 // registered, not yet provisioned
+	LifecycleState_LIFECYCLE_STATE_PROVISIONING   LifecycleState = 2
+	LifecycleState_LIFECYCLE_STATE_IN_SERVICE     LifecycleState = 3
+	LifecycleState_LIFECYCLE_STATE_MAINTENANCE    LifecycleState = 4
+	LifecycleState_LIFECYCLE_STATE_DECOMMISSIONED LifecycleState = 5 // terminal
+)
+
+// Enum value maps for LifecycleState.
+var (
+	LifecycleState_name = map[int32]string{
+		0: "LIFECYCLE_STATE_UNSPECIFIED",
+		1: "LIFECYCLE_STATE_NEW",
+		2: "LIFECYCLE_STATE_PROVISIONING",
+		3: "LIFECYCLE_STATE_IN_SERVICE",
+		4: "LIFECYCLE_STATE_MAINTENANCE",
+		5: "LIFECYCLE_STATE_DECOMMISSIONED",
+	}
+	LifecycleState_value = map[string]int32{
+		"LIFECYCLE_STATE_UNSPECIFIED":    0,
+		"LIFECYCLE_STATE_NEW":            1,
+		"LIFECYCLE_STATE_PROVISIONING":   2,
+		"LIFECYCLE_STATE_IN_SERVICE":     3,
+		"LIFECYCLE_STATE_MAINTENANCE":    4,
+		"LIFECYCLE_STATE_DECOMMISSIONED": 5,
+	}
+)
+
+func (x LifecycleState) Enum() *LifecycleState {
+	p := new(LifecycleState)
+	*p = x
+	return p
+}
+
+func (x LifecycleState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LifecycleState) Descriptor() protoreflect.EnumDescriptor {
+	return file_lifecycle_proto_enumTypes[0].Descriptor()
+}
+
+func (LifecycleState) Type() protoreflect.EnumType {
+	return &file_lifecycle_proto_enumTypes[0]
+}
+
+func (x LifecycleState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LifecycleState.Descriptor instead.
+func (LifecycleState) EnumDescriptor() ([]byte, []int) {
+	return file_lifecycle_proto_rawDescGZIP(), []int{0}
+}
+
+type LifecycleTransition struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FromState      *LifecycleState        `protobuf:"varint,1,opt,name=from_state,json=fromState,enum=endpointpb.LifecycleState" json:"from_state,omitempty"`
+	ToState        *LifecycleState        `protobuf:"varint,2,opt,name=to_state,json=toState,enum=endpointpb.LifecycleState" json:"to_state,omitempty"`
+	TransitionedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=transitioned_at,json=transitionedAt" json:"transitioned_at,omitempty"`
+	Actor          *string                `protobuf:"bytes,4,opt,name=actor" json:"actor,omitempty"` // caller that requested the transition
+	Reason         *string                `protobuf:"bytes,5,opt,name=reason" json:"reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LifecycleTransition) Reset() {
+	*x = LifecycleTransition{}
+	mi := &file_lifecycle_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleTransition) ProtoMessage() {}
+
+func (x *LifecycleTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_lifecycle_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleTransition.ProtoReflect.Descriptor instead.
+func (*LifecycleTransition) Descriptor() ([]byte, []int) {
+	return file_lifecycle_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LifecycleTransition) GetFromState() LifecycleState {
+	if x != nil && x.FromState != nil {
+		return *x.FromState
+	}
+	return LifecycleState_LIFECYCLE_STATE_UNSPECIFIED
+}
+
+func (x *LifecycleTransition) GetToState() LifecycleState {
+	if x != nil && x.ToState != nil {
+		return *x.ToState
+	}
+	return LifecycleState_LIFECYCLE_STATE_UNSPECIFIED
+}
+
+func (x *LifecycleTransition) GetTransitionedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TransitionedAt
+	}
+	return nil
+}
+
+func (x *LifecycleTransition) GetActor() string {
+	if x != nil && x.Actor != nil {
+		return *x.Actor
+	}
+	return ""
+}
+
+func (x *LifecycleTransition) GetReason() string {
+	if x != nil && x.Reason != nil {
+		return *x.Reason
+	}
+	return ""
+}
+
+var File_lifecycle_proto protoreflect.FileDescriptor
+
+const file_lifecycle_proto_rawDesc = "" +
+	"\n" +
+	"\x0flifecycle.proto\x12\n" +
+	"endpointpb\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfa\x01\n" +
+	"\x13LifecycleTransition\x129\n" +
+	"\n" +
+	"from_state\x18\x01 \x01(\x0e2\x1a.endpointpb.LifecycleStateR\tfromState\x125\n" +
+	"\bto_state\x18\x02 \x01(\x0e2\x1a.endpointpb.LifecycleStateR\atoState\x12C\n" +
+	"\x0ftransitioned_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0etransitionedAt\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason*\xd1\x01\n" +
+	"\x0eLifecycleState\x12\x1f\n" +
+	"\x1bLIFECYCLE_STATE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13LIFECYCLE_STATE_NEW\x10\x01\x12 \n" +
+	"\x1cLIFECYCLE_STATE_PROVISIONING\x10\x02\x12\x1e\n" +
+	"\x1aLIFECYCLE_STATE_IN_SERVICE\x10\x03\x12\x1f\n" +
+	"\x1bLIFECYCLE_STATE_MAINTENANCE\x10\x04\x12\"\n" +
+	"\x1eLIFECYCLE_STATE_DECOMMISSIONED\x10\x05BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_lifecycle_proto_rawDescOnce sync.Once
+	file_lifecycle_proto_rawDescData []byte
+)
+
+func file_lifecycle_proto_rawDescGZIP() []byte {
+	file_lifecycle_proto_rawDescOnce.Do(func() {
+		file_lifecycle_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_lifecycle_proto_rawDesc), len(file_lifecycle_proto_rawDesc)))
+	})
+	return file_lifecycle_proto_rawDescData
+}
+
+var file_lifecycle_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_lifecycle_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_lifecycle_proto_goTypes = []any{
+	(LifecycleState)(0),           // 0: endpointpb.LifecycleState
+	(*LifecycleTransition)(nil),   // 1: endpointpb.LifecycleTransition
+	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_lifecycle_proto_depIdxs = []int32{
+	0, // 0: endpointpb.LifecycleTransition.from_state:type_name -> endpointpb.LifecycleState
+	0, // 1: endpointpb.LifecycleTransition.to_state:type_name -> endpointpb.LifecycleState
+	2, // 2: endpointpb.LifecycleTransition.transitioned_at:type_name -> google.protobuf.Timestamp
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_lifecycle_proto_init() }
+func file_lifecycle_proto_init() {
+	if File_lifecycle_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lifecycle_proto_rawDesc), len(file_lifecycle_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_lifecycle_proto_goTypes,
+		DependencyIndexes: file_lifecycle_proto_depIdxs,
+		EnumInfos:         file_lifecycle_proto_enumTypes,
+		MessageInfos:      file_lifecycle_proto_msgTypes,
+	}.Build()
+	File_lifecycle_proto = out.File
+	file_lifecycle_proto_goTypes = nil
+	file_lifecycle_proto_depIdxs = nil
+}
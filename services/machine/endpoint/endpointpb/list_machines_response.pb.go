@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: list_machines_response.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListMachinesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machines      []*Machine             `protobuf:"bytes,1,rep,name=machines" json:"machines,omitempty"`
+	Pagination    *Pagination            `protobuf:"bytes,2,opt,name=pagination" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesResponse) Reset() {
+	*x = ListMachinesResponse{}
+	mi := &file_list_machines_response_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesResponse) ProtoMessage() {}
+
+func (x *ListMachinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_list_machines_response_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesResponse.ProtoReflect.Descriptor instead.
+func (*ListMachinesResponse) Descriptor() ([]byte, []int) {
+	return file_list_machines_response_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListMachinesResponse) GetMachines() []*Machine {
+	if x != nil {
+		return x.Machines
+	}
+	return nil
+}
+
+func (x *ListMachinesResponse) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+var File_list_machines_response_proto protoreflect.FileDescriptor
+
+const file_list_machines_response_proto_rawDesc = "" +
+	"\n" +
+	"\x1clist_machines_response.proto\x12\n" +
+	"endpointpb\x1a\rmachine.proto\x1a\x10pagination.proto\"\x7f\n" +
+	"\x14ListMachinesResponse\x12/\n" +
+	"\bmachines\x18\x01 \x03(\v2\x13.endpointpb.MachineR\bmachines\x126\n" +
+	"\n" +
+	"pagination\x18\x02 \x01(\v2\x16.endpointpb.PaginationR\n" +
+	"paginationBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_list_machines_response_proto_rawDescOnce sync.Once
+	file_list_machines_response_proto_rawDescData []byte
+)
+
+func file_list_machines_response_proto_rawDescGZIP() []byte {
+	file_list_machines_response_proto_rawDescOnce.Do(func() {
+		file_list_machines_response_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_list_machines_response_proto_rawDesc), len(file_list_machines_response_proto_rawDesc)))
+	})
+	return file_list_machines_response_proto_rawDescData
+}
+
+var file_list_machines_response_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_list_machines_response_proto_goTypes = []any{
+	(*ListMachinesResponse)(nil), // 0: endpointpb.ListMachinesResponse
+	(*Machine)(nil),              // 1: endpointpb.Machine
+	(*Pagination)(nil),           // 2: endpointpb.Pagination
+}
+var file_list_machines_response_proto_depIdxs = []int32{
+	1, // 0: endpointpb.ListMachinesResponse.machines:type_name -> endpointpb.Machine
+	2, // 1: endpointpb.ListMachinesResponse.pagination:type_name -> endpointpb.Pagination
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_list_machines_response_proto_init() }
+func file_list_machines_response_proto_init() {
+	if File_list_machines_response_proto != nil {
+		return
+	}
+	file_machine_proto_init()
+	file_pagination_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_list_machines_response_proto_rawDesc), len(file_list_machines_response_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_list_machines_response_proto_goTypes,
+		DependencyIndexes: file_list_machines_response_proto_depIdxs,
+		MessageInfos:      file_list_machines_response_proto_msgTypes,
+	}.Build()
+	File_list_machines_response_proto = out.File
+	file_list_machines_response_proto_goTypes = nil
+	file_list_machines_response_proto_depIdxs = nil
+}
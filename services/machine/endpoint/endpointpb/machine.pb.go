@@ -22,15 +22,18 @@ const (
 )
 
 type Machine struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            *string                `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"` // UUIDv7 machine identifier
-	Cpus          []*CPU                 `protobuf:"bytes,2,rep,name=cpus" json:"cpus,omitempty"`
-	MemoryModules []*MemoryModule        `protobuf:"bytes,3,rep,name=memory_modules,json=memoryModules" json:"memory_modules,omitempty"`
-	Accelerators  []*Accelerator         `protobuf:"bytes,4,rep,name=accelerators" json:"accelerators,omitempty"`
-	Nics          []*NIC                 `protobuf:"bytes,5,rep,name=nics" json:"nics,omitempty"`
-	Drives        []*Drive               `protobuf:"bytes,6,rep,name=drives" json:"drives,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               *string                `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"` // UUIDv7 machine identifier
+	Cpus             []*CPU                 `protobuf:"bytes,2,rep,name=cpus" json:"cpus,omitempty"`
+	MemoryModules    []*MemoryModule        `protobuf:"bytes,3,rep,name=memory_modules,json=memoryModules" json:"memory_modules,omitempty"`
+	Accelerators     []*Accelerator         `protobuf:"bytes,4,rep,name=accelerators" json:"accelerators,omitempty"`
+	Nics             []*NIC                 `protobuf:"bytes,5,rep,name=nics" json:"nics,omitempty"`
+	Drives           []*Drive               `protobuf:"bytes,6,rep,name=drives" json:"drives,omitempty"`
+	LifecycleState   *LifecycleState        `protobuf:"varint,7,opt,name=lifecycle_state,json=lifecycleState,enum=endpointpb.LifecycleState" json:"lifecycle_state,omitempty"`     // changed only via :transition
+	LifecycleHistory []*LifecycleTransition `protobuf:"bytes,8,rep,name=lifecycle_history,json=lifecycleHistory" json:"lifecycle_history,omitempty"`                               // oldest first
+	Labels           map[string]string      `protobuf:"bytes,9,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // changed only via /labels
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Machine) Reset() {
@@ -105,19 +108,46 @@ func (x *Machine) GetDrives() []*Drive {
 	return nil
 }
 
+func (x *Machine) GetLifecycleState() LifecycleState {
+	if x != nil && x.LifecycleState != nil {
+		return *x.LifecycleState
+	}
+	return LifecycleState_LIFECYCLE_STATE_UNSPECIFIED
+}
+
+func (x *Machine) GetLifecycleHistory() []*LifecycleTransition {
+	if x != nil {
+		return x.LifecycleHistory
+	}
+	return nil
+}
+
+func (x *Machine) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 var File_machine_proto protoreflect.FileDescriptor
 
 const file_machine_proto_rawDesc = "" +
 	"\n" +
 	"\rmachine.proto\x12\n" +
-	"endpointpb\x1a\x11accelerator.proto\x1a\tcpu.proto\x1a\vdrive.proto\x1a\x13memory_module.proto\x1a\tnic.proto\"\x8c\x02\n" +
+	"endpointpb\x1a\x11accelerator.proto\x1a\tcpu.proto\x1a\vdrive.proto\x1a\x0flifecycle.proto\x1a\x13memory_module.proto\x1a\tnic.proto\"\x93\x04\n" +
 	"\aMachine\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
 	"\x04cpus\x18\x02 \x03(\v2\x0f.endpointpb.CPUR\x04cpus\x12?\n" +
 	"\x0ememory_modules\x18\x03 \x03(\v2\x18.endpointpb.MemoryModuleR\rmemoryModules\x12;\n" +
 	"\faccelerators\x18\x04 \x03(\v2\x17.endpointpb.AcceleratorR\faccelerators\x12#\n" +
 	"\x04nics\x18\x05 \x03(\v2\x0f.endpointpb.NICR\x04nics\x12)\n" +
-	"\x06drives\x18\x06 \x03(\v2\x11.endpointpb.DriveR\x06drivesBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\x06drives\x18\x06 \x03(\v2\x11.endpointpb.DriveR\x06drives\x12C\n" +
+	"\x0flifecycle_state\x18\a \x01(\x0e2\x1a.endpointpb.LifecycleStateR\x0elifecycleState\x12L\n" +
+	"\x11lifecycle_history\x18\b \x03(\v2\x1f.endpointpb.LifecycleTransitionR\x10lifecycleHistory\x127\n" +
+	"\x06labels\x18\t \x03(\v2\x1f.endpointpb.Machine.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_machine_proto_rawDescOnce sync.Once
@@ -131,26 +161,32 @@ func file_machine_proto_rawDescGZIP() []byte {
 	return file_machine_proto_rawDescData
 }
 
-var file_machine_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_machine_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_machine_proto_goTypes = []any{
-	(*Machine)(nil),      // 0: endpointpb.Machine
-	(*CPU)(nil),          // 1: endpointpb.CPU
-	(*MemoryModule)(nil), // 2: endpointpb.MemoryModule
-	(*Accelerator)(nil),  // 3: endpointpb.Accelerator
-	(*NIC)(nil),          // 4: endpointpb.NIC
-	(*Drive)(nil),        // 5: endpointpb.Drive
+	(*Machine)(nil),             // 0: endpointpb.Machine
+	nil,                         // 1: endpointpb.Machine.LabelsEntry
+	(*CPU)(nil),                 // 2: endpointpb.CPU
+	(*MemoryModule)(nil),        // 3: endpointpb.MemoryModule
+	(*Accelerator)(nil),         // 4: endpointpb.Accelerator
+	(*NIC)(nil),                 // 5: endpointpb.NIC
+	(*Drive)(nil),               // 6: endpointpb.Drive
+	(LifecycleState)(0),         // 7: endpointpb.LifecycleState
+	(*LifecycleTransition)(nil), // 8: endpointpb.LifecycleTransition
 }
 var file_machine_proto_depIdxs = []int32{
-	1, // 0: endpointpb.Machine.cpus:type_name -> endpointpb.CPU
-	2, // 1: endpointpb.Machine.memory_modules:type_name -> endpointpb.MemoryModule
-	3, // 2: endpointpb.Machine.accelerators:type_name -> endpointpb.Accelerator
-	4, // 3: endpointpb.Machine.nics:type_name -> endpointpb.NIC
-	5, // 4: endpointpb.Machine.drives:type_name -> endpointpb.Drive
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	2, // 0: endpointpb.Machine.cpus:type_name -> endpointpb.CPU
+	3, // 1: endpointpb.Machine.memory_modules:type_name -> endpointpb.MemoryModule
+	4, // 2: endpointpb.Machine.accelerators:type_name -> endpointpb.Accelerator
+	5, // 3: endpointpb.Machine.nics:type_name -> endpointpb.NIC
+	6, // 4: endpointpb.Machine.drives:type_name -> endpointpb.Drive
+	7, // 5: endpointpb.Machine.lifecycle_state:type_name -> endpointpb.LifecycleState
+	8, // 6: endpointpb.Machine.lifecycle_history:type_name -> endpointpb.LifecycleTransition
+	1, // 7: endpointpb.Machine.labels:type_name -> endpointpb.Machine.LabelsEntry
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_machine_proto_init() }
@@ -161,6 +197,7 @@ func file_machine_proto_init() {
 	file_accelerator_proto_init()
 	file_cpu_proto_init()
 	file_drive_proto_init()
+	file_lifecycle_proto_init()
 	file_memory_module_proto_init()
 	file_nic_proto_init()
 	type x struct{}
@@ -169,7 +206,7 @@ func file_machine_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_machine_proto_rawDesc), len(file_machine_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,250 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: machine_event.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MachineEventType int32
+
+const (
+	MachineEventType_MACHINE_EVENT_TYPE_UNSPECIFIED   MachineEventType = 0
+	MachineEventType_MACHINE_EVENT_TYPE_CREATED       MachineEventType = 1 // registered, or restored after a delete
+	MachineEventType_MACHINE_EVENT_TYPE_UPDATED       MachineEventType = 2 // hardware or labels changed
+	MachineEventType_MACHINE_EVENT_TYPE_DELETED       MachineEventType = 3
+	MachineEventType_MACHINE_EVENT_TYPE_STATE_CHANGED MachineEventType = 4 // lifecycle transition
+)
+
+// Enum value maps for MachineEventType.
+var (
+	MachineEventType_name = map[int32]string{
+		0: "MACHINE_EVENT_TYPE_UNSPECIFIED",
+		1: "MACHINE_EVENT_TYPE_CREATED",
+		2: "MACHINE_EVENT_TYPE_UPDATED",
+		3: "MACHINE_EVENT_TYPE_DELETED",
+		4: "MACHINE_EVENT_TYPE_STATE_CHANGED",
+	}
+	MachineEventType_value = map[string]int32{
+		"MACHINE_EVENT_TYPE_UNSPECIFIED":   0,
+		"MACHINE_EVENT_TYPE_CREATED":       1,
+		"MACHINE_EVENT_TYPE_UPDATED":       2,
+		"MACHINE_EVENT_TYPE_DELETED":       3,
+		"MACHINE_EVENT_TYPE_STATE_CHANGED": 4,
+	}
+)
+
+func (x MachineEventType) Enum() *MachineEventType {
+	p := new(MachineEventType)
+	*p = x
+	return p
+}
+
+func (x MachineEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MachineEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_machine_event_proto_enumTypes[0].Descriptor()
+}
+
+func (MachineEventType) Type() protoreflect.EnumType {
+	return &file_machine_event_proto_enumTypes[0]
+}
+
+func (x MachineEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MachineEventType.Descriptor instead.
+func (MachineEventType) EnumDescriptor() ([]byte, []int) {
+	return file_machine_event_proto_rawDescGZIP(), []int{0}
+}
+
+type MachineEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      *int64                 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"` // resume token, sent as the SSE event ID
+	Type          *MachineEventType      `protobuf:"varint,2,opt,name=type,enum=endpointpb.MachineEventType" json:"type,omitempty"`
+	MachineId     *string                `protobuf:"bytes,3,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	Revision      *int64                 `protobuf:"varint,4,opt,name=revision" json:"revision,omitempty"` // the machine's revision after the change
+	Machine       *Machine               `protobuf:"bytes,5,opt,name=machine" json:"machine,omitempty"`    // the machine after the change
+	Actor         *string                `protobuf:"bytes,6,opt,name=actor" json:"actor,omitempty"`        // caller that made the change
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=occurred_at,json=occurredAt" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineEvent) Reset() {
+	*x = MachineEvent{}
+	mi := &file_machine_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineEvent) ProtoMessage() {}
+
+func (x *MachineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineEvent.ProtoReflect.Descriptor instead.
+func (*MachineEvent) Descriptor() ([]byte, []int) {
+	return file_machine_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineEvent) GetSequence() int64 {
+	if x != nil && x.Sequence != nil {
+		return *x.Sequence
+	}
+	return 0
+}
+
+func (x *MachineEvent) GetType() MachineEventType {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return MachineEventType_MACHINE_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *MachineEvent) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineEvent) GetRevision() int64 {
+	if x != nil && x.Revision != nil {
+		return *x.Revision
+	}
+	return 0
+}
+
+func (x *MachineEvent) GetMachine() *Machine {
+	if x != nil {
+		return x.Machine
+	}
+	return nil
+}
+
+func (x *MachineEvent) GetActor() string {
+	if x != nil && x.Actor != nil {
+		return *x.Actor
+	}
+	return ""
+}
+
+func (x *MachineEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+var File_machine_event_proto protoreflect.FileDescriptor
+
+const file_machine_event_proto_rawDesc = "" +
+	"\n" +
+	"\x13machine_event.proto\x12\n" +
+	"endpointpb\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\rmachine.proto\"\x99\x02\n" +
+	"\fMachineEvent\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x120\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1c.endpointpb.MachineEventTypeR\x04type\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x03 \x01(\tR\tmachineId\x12\x1a\n" +
+	"\brevision\x18\x04 \x01(\x03R\brevision\x12-\n" +
+	"\amachine\x18\x05 \x01(\v2\x13.endpointpb.MachineR\amachine\x12\x14\n" +
+	"\x05actor\x18\x06 \x01(\tR\x05actor\x12;\n" +
+	"\voccurred_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt*\xbc\x01\n" +
+	"\x10MachineEventType\x12\"\n" +
+	"\x1eMACHINE_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aMACHINE_EVENT_TYPE_CREATED\x10\x01\x12\x1e\n" +
+	"\x1aMACHINE_EVENT_TYPE_UPDATED\x10\x02\x12\x1e\n" +
+	"\x1aMACHINE_EVENT_TYPE_DELETED\x10\x03\x12$\n" +
+	" MACHINE_EVENT_TYPE_STATE_CHANGED\x10\x04BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_machine_event_proto_rawDescOnce sync.Once
+	file_machine_event_proto_rawDescData []byte
+)
+
+func file_machine_event_proto_rawDescGZIP() []byte {
+	file_machine_event_proto_rawDescOnce.Do(func() {
+		file_machine_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_machine_event_proto_rawDesc), len(file_machine_event_proto_rawDesc)))
+	})
+	return file_machine_event_proto_rawDescData
+}
+
+var file_machine_event_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_machine_event_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_machine_event_proto_goTypes = []any{
+	(MachineEventType)(0),         // 0: endpointpb.MachineEventType
+	(*MachineEvent)(nil),          // 1: endpointpb.MachineEvent
+	(*Machine)(nil),               // 2: endpointpb.Machine
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_machine_event_proto_depIdxs = []int32{
+	0, // 0: endpointpb.MachineEvent.type:type_name -> endpointpb.MachineEventType
+	2, // 1: endpointpb.MachineEvent.machine:type_name -> endpointpb.Machine
+	3, // 2: endpointpb.MachineEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_machine_event_proto_init() }
+func file_machine_event_proto_init() {
+	if File_machine_event_proto != nil {
+		return
+	}
+	file_machine_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_machine_event_proto_rawDesc), len(file_machine_event_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_machine_event_proto_goTypes,
+		DependencyIndexes: file_machine_event_proto_depIdxs,
+		EnumInfos:         file_machine_event_proto_enumTypes,
+		MessageInfos:      file_machine_event_proto_msgTypes,
+	}.Build()
+	File_machine_event_proto = out.File
+	file_machine_event_proto_goTypes = nil
+	file_machine_event_proto_depIdxs = nil
+}
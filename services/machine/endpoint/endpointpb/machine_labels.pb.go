@@ -0,0 +1,128 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: machine_labels.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MachineLabels struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // e.g. {"rack": "a", "role": "storage"}
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineLabels) Reset() {
+	*x = MachineLabels{}
+	mi := &file_machine_labels_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineLabels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineLabels) ProtoMessage() {}
+
+func (x *MachineLabels) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_labels_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineLabels.ProtoReflect.Descriptor instead.
+func (*MachineLabels) Descriptor() ([]byte, []int) {
+	return file_machine_labels_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineLabels) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+var File_machine_labels_proto protoreflect.FileDescriptor
+
+const file_machine_labels_proto_rawDesc = "" +
+	"\n" +
+	"\x14machine_labels.proto\x12\n" +
+	"endpointpb\"\x89\x01\n" +
+	"\rMachineLabels\x12=\n" +
+	"\x06labels\x18\x01 \x03(\v2%.endpointpb.MachineLabels.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_machine_labels_proto_rawDescOnce sync.Once
+	file_machine_labels_proto_rawDescData []byte
+)
+
+func file_machine_labels_proto_rawDescGZIP() []byte {
+	file_machine_labels_proto_rawDescOnce.Do(func() {
+		file_machine_labels_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_machine_labels_proto_rawDesc), len(file_machine_labels_proto_rawDesc)))
+	})
+	return file_machine_labels_proto_rawDescData
+}
+
+var file_machine_labels_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_machine_labels_proto_goTypes = []any{
+	(*MachineLabels)(nil), // 0: endpointpb.MachineLabels
+	nil,                   // 1: endpointpb.MachineLabels.LabelsEntry
+}
+var file_machine_labels_proto_depIdxs = []int32{
+	1, // 0: endpointpb.MachineLabels.labels:type_name -> endpointpb.MachineLabels.LabelsEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_machine_labels_proto_init() }
+func file_machine_labels_proto_init() {
+	if File_machine_labels_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_machine_labels_proto_rawDesc), len(file_machine_labels_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_machine_labels_proto_goTypes,
+		DependencyIndexes: file_machine_labels_proto_depIdxs,
+		MessageInfos:      file_machine_labels_proto_msgTypes,
+	}.Build()
+	File_machine_labels_proto = out.File
+	file_machine_labels_proto_goTypes = nil
+	file_machine_labels_proto_depIdxs = nil
+}
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: machine_revision.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FieldChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          *string                `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`     // e.g. cpus[0].cores or labels["rack"]
+	Before        *string                `protobuf:"bytes,2,opt,name=before" json:"before,omitempty"` // unset if the write added the field
+	After         *string                `protobuf:"bytes,3,opt,name=after" json:"after,omitempty"`   // unset if the write removed the field
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_machine_revision_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_revision_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_machine_revision_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FieldChange) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *FieldChange) GetBefore() string {
+	if x != nil && x.Before != nil {
+		return *x.Before
+	}
+	return ""
+}
+
+func (x *FieldChange) GetAfter() string {
+	if x != nil && x.After != nil {
+		return *x.After
+	}
+	return ""
+}
+
+type MachineRevision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      *int64                 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"`
+	Machine       *Machine               `protobuf:"bytes,2,opt,name=machine" json:"machine,omitempty"`                      // the machine as it was at this revision
+	ChangedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=changed_at,json=changedAt" json:"changed_at,omitempty"` // when the next write superseded it
+	ChangedBy     *string                `protobuf:"bytes,4,opt,name=changed_by,json=changedBy" json:"changed_by,omitempty"` // caller that made that write
+	Changes       []*FieldChange         `protobuf:"bytes,5,rep,name=changes" json:"changes,omitempty"`                      // what that write changed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineRevision) Reset() {
+	*x = MachineRevision{}
+	mi := &file_machine_revision_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineRevision) ProtoMessage() {}
+
+func (x *MachineRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_revision_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineRevision.ProtoReflect.Descriptor instead.
+func (*MachineRevision) Descriptor() ([]byte, []int) {
+	return file_machine_revision_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MachineRevision) GetRevision() int64 {
+	if x != nil && x.Revision != nil {
+		return *x.Revision
+	}
+	return 0
+}
+
+func (x *MachineRevision) GetMachine() *Machine {
+	if x != nil {
+		return x.Machine
+	}
+	return nil
+}
+
+func (x *MachineRevision) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+func (x *MachineRevision) GetChangedBy() string {
+	if x != nil && x.ChangedBy != nil {
+		return *x.ChangedBy
+	}
+	return ""
+}
+
+func (x *MachineRevision) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type ListMachineRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*MachineRevision     `protobuf:"bytes,1,rep,name=revisions" json:"revisions,omitempty"` // newest first
+	Pagination    *Pagination            `protobuf:"bytes,2,opt,name=pagination" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachineRevisionsResponse) Reset() {
+	*x = ListMachineRevisionsResponse{}
+	mi := &file_machine_revision_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachineRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachineRevisionsResponse) ProtoMessage() {}
+
+func (x *ListMachineRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_machine_revision_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachineRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListMachineRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_machine_revision_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListMachineRevisionsResponse) GetRevisions() []*MachineRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *ListMachineRevisionsResponse) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+var File_machine_revision_proto protoreflect.FileDescriptor
+
+const file_machine_revision_proto_rawDesc = "" +
+	"\n" +
+	"\x16machine_revision.proto\x12\n" +
+	"endpointpb\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\rmachine.proto\x1a\x10pagination.proto\"O\n" +
+	"\vFieldChange\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"\xe9\x01\n" +
+	"\x0fMachineRevision\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x03R\brevision\x12-\n" +
+	"\amachine\x18\x02 \x01(\v2\x13.endpointpb.MachineR\amachine\x129\n" +
+	"\n" +
+	"changed_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAt\x12\x1d\n" +
+	"\n" +
+	"changed_by\x18\x04 \x01(\tR\tchangedBy\x121\n" +
+	"\achanges\x18\x05 \x03(\v2\x17.endpointpb.FieldChangeR\achanges\"\x91\x01\n" +
+	"\x1cListMachineRevisionsResponse\x129\n" +
+	"\trevisions\x18\x01 \x03(\v2\x1b.endpointpb.MachineRevisionR\trevisions\x126\n" +
+	"\n" +
+	"pagination\x18\x02 \x01(\v2\x16.endpointpb.PaginationR\n" +
+	"paginationBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_machine_revision_proto_rawDescOnce sync.Once
+	file_machine_revision_proto_rawDescData []byte
+)
+
+func file_machine_revision_proto_rawDescGZIP() []byte {
+	file_machine_revision_proto_rawDescOnce.Do(func() {
+		file_machine_revision_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_machine_revision_proto_rawDesc), len(file_machine_revision_proto_rawDesc)))
+	})
+	return file_machine_revision_proto_rawDescData
+}
+
+var file_machine_revision_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_machine_revision_proto_goTypes = []any{
+	(*FieldChange)(nil),                  // 0: endpointpb.FieldChange
+	(*MachineRevision)(nil),              // 1: endpointpb.MachineRevision
+	(*ListMachineRevisionsResponse)(nil), // 2: endpointpb.ListMachineRevisionsResponse
+	(*Machine)(nil),                      // 3: endpointpb.Machine
+	(*timestamppb.Timestamp)(nil),        // 4: google.protobuf.Timestamp
+	(*Pagination)(nil),                   // 5: endpointpb.Pagination
+}
+var file_machine_revision_proto_depIdxs = []int32{
+	3, // 0: endpointpb.MachineRevision.machine:type_name -> endpointpb.Machine
+	4, // 1: endpointpb.MachineRevision.changed_at:type_name -> google.protobuf.Timestamp
+	0, // 2: endpointpb.MachineRevision.changes:type_name -> endpointpb.FieldChange
+	1, // 3: endpointpb.ListMachineRevisionsResponse.revisions:type_name -> endpointpb.MachineRevision
+	5, // 4: endpointpb.ListMachineRevisionsResponse.pagination:type_name -> endpointpb.Pagination
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_machine_revision_proto_init() }
+func file_machine_revision_proto_init() {
+	if File_machine_revision_proto != nil {
+		return
+	}
+	file_machine_proto_init()
+	file_pagination_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_machine_revision_proto_rawDesc), len(file_machine_revision_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_machine_revision_proto_goTypes,
+		DependencyIndexes: file_machine_revision_proto_depIdxs,
+		MessageInfos:      file_machine_revision_proto_msgTypes,
+	}.Build()
+	File_machine_revision_proto = out.File
+	file_machine_revision_proto_goTypes = nil
+	file_machine_revision_proto_depIdxs = nil
+}
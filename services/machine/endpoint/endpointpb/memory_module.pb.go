@@ -21,9 +21,67 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type MemoryType int32
+
+const (
+	MemoryType_MEMORY_TYPE_UNSPECIFIED MemoryType = 0
+	MemoryType_MEMORY_TYPE_DDR3        MemoryType = 1
+	MemoryType_MEMORY_TYPE_DDR4        MemoryType = 2
+	MemoryType_MEMORY_TYPE_DDR5        MemoryType = 3
+)
+
+// Enum value maps for MemoryType.
+var (
+	MemoryType_name = map[int32]string{
+		0: "MEMORY_TYPE_UNSPECIFIED",
+		1: "MEMORY_TYPE_DDR3",
+		2: "MEMORY_TYPE_DDR4",
+		3: "MEMORY_TYPE_DDR5",
+	}
+	MemoryType_value = map[string]int32{
+		"MEMORY_TYPE_UNSPECIFIED": 0,
+		"MEMORY_TYPE_DDR3":        1,
+		"MEMORY_TYPE_DDR4":        2,
+		"MEMORY_TYPE_DDR5":        3,
+	}
+)
+
+func (x MemoryType) Enum() *MemoryType {
+	p := new(MemoryType)
+	*p = x
+	return p
+}
+
+func (x MemoryType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MemoryType) Descriptor() protoreflect.EnumDescriptor {
+	return file_memory_module_proto_enumTypes[0].Descriptor()
+}
+
+func (MemoryType) Type() protoreflect.EnumType {
+	return &file_memory_module_proto_enumTypes[0]
+}
+
+func (x MemoryType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MemoryType.Descriptor instead.
+func (MemoryType) EnumDescriptor() ([]byte, []int) {
+	return file_memory_module_proto_rawDescGZIP(), []int{0}
+}
+
 type MemoryModule struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Size          *int64                 `protobuf:"varint,1,opt,name=size" json:"size,omitempty"` // measured in bytes
+	Type          *MemoryType            `protobuf:"varint,2,opt,name=type,enum=endpointpb.MemoryType" json:"type,omitempty"`
+	Speed         *int64                 `protobuf:"varint,3,opt,name=speed" json:"speed,omitempty"` // measured in megatransfers per second
+	Slot          *string                `protobuf:"bytes,4,opt,name=slot" json:"slot,omitempty"`    // slot designation, e.g. "PROC 1 DIMM 3"
+	Manufacturer  *string                `protobuf:"bytes,5,opt,name=manufacturer" json:"manufacturer,omitempty"`
+	Model         *string                `protobuf:"bytes,6,opt,name=model" json:"model,omitempty"` // manufacturer part number
+	SerialNumber  *string                `protobuf:"bytes,7,opt,name=serial_number,json=serialNumber" json:"serial_number,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,14 +123,68 @@ func (x *MemoryModule) GetSize() int64 {
 	return 0
 }
 
+func (x *MemoryModule) GetType() MemoryType {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return MemoryType_MEMORY_TYPE_UNSPECIFIED
+}
+
+func (x *MemoryModule) GetSpeed() int64 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+func (x *MemoryModule) GetSlot() string {
+	if x != nil && x.Slot != nil {
+		return *x.Slot
+	}
+	return ""
+}
+
+func (x *MemoryModule) GetManufacturer() string {
+	if x != nil && x.Manufacturer != nil {
+		return *x.Manufacturer
+	}
+	return ""
+}
+
+func (x *MemoryModule) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *MemoryModule) GetSerialNumber() string {
+	if x != nil && x.SerialNumber != nil {
+		return *x.SerialNumber
+	}
+	return ""
+}
+
 var File_memory_module_proto protoreflect.FileDescriptor
 
 const file_memory_module_proto_rawDesc = "" +
 	"\n" +
 	"\x13memory_module.proto\x12\n" +
-	"endpointpb\"\"\n" +
+	"endpointpb\"\xd7\x01\n" +
 	"\fMemoryModule\x12\x12\n" +
-	"\x04size\x18\x01 \x01(\x03R\x04sizeBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.endpointpb.MemoryTypeR\x04type\x12\x14\n" +
+	"\x05speed\x18\x03 \x01(\x03R\x05speed\x12\x12\n" +
+	"\x04slot\x18\x04 \x01(\tR\x04slot\x12\"\n" +
+	"\fmanufacturer\x18\x05 \x01(\tR\fmanufacturer\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12#\n" +
+	"\rserial_number\x18\a \x01(\tR\fserialNumber*k\n" +
+	"\n" +
+	"MemoryType\x12\x1b\n" +
+	"\x17MEMORY_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10MEMORY_TYPE_DDR3\x10\x01\x12\x14\n" +
+	"\x10MEMORY_TYPE_DDR4\x10\x02\x12\x14\n" +
+	"\x10MEMORY_TYPE_DDR5\x10\x03BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_memory_module_proto_rawDescOnce sync.Once
@@ -86,16 +198,19 @@ func file_memory_module_proto_rawDescGZIP() []byte {
 	return file_memory_module_proto_rawDescData
 }
 
+var file_memory_module_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_memory_module_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
 var file_memory_module_proto_goTypes = []any{
-	(*MemoryModule)(nil), // 0: endpointpb.MemoryModule
+	(MemoryType)(0),      // 0: endpointpb.MemoryType
+	(*MemoryModule)(nil), // 1: endpointpb.MemoryModule
 }
 var file_memory_module_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: endpointpb.MemoryModule.type:type_name -> endpointpb.MemoryType
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_memory_module_proto_init() }
@@ -108,13 +223,14 @@ func file_memory_module_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_memory_module_proto_rawDesc), len(file_memory_module_proto_rawDesc)),
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   1,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_memory_module_proto_goTypes,
 		DependencyIndexes: file_memory_module_proto_depIdxs,
+		EnumInfos:         file_memory_module_proto_enumTypes,
 		MessageInfos:      file_memory_module_proto_msgTypes,
 	}.Build()
 	File_memory_module_proto = out.File
@@ -23,7 +23,12 @@ const (
 
 type NIC struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Mac           *string                `protobuf:"bytes,1,opt,name=mac" json:"mac,omitempty"` // mac address
+	Mac           *string                `protobuf:"bytes,1,opt,name=mac" json:"mac,omitempty"`                                 // mac address
+	Name          *string                `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`                               // interface name, e.g. "eno1"
+	Speed         *int64                 `protobuf:"varint,3,opt,name=speed" json:"speed,omitempty"`                            // link speed in bits per second
+	Driver        *string                `protobuf:"bytes,4,opt,name=driver" json:"driver,omitempty"`                           // kernel driver, e.g. "ixgbe"
+	PciAddress    *string                `protobuf:"bytes,5,opt,name=pci_address,json=pciAddress" json:"pci_address,omitempty"` // PCI bus address, e.g. "0000:3b:00.0"
+	Model         *string                `protobuf:"bytes,6,opt,name=model" json:"model,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,14 +70,55 @@ func (x *NIC) GetMac() string {
 	return ""
 }
 
+func (x *NIC) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *NIC) GetSpeed() int64 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+func (x *NIC) GetDriver() string {
+	if x != nil && x.Driver != nil {
+		return *x.Driver
+	}
+	return ""
+}
+
+func (x *NIC) GetPciAddress() string {
+	if x != nil && x.PciAddress != nil {
+		return *x.PciAddress
+	}
+	return ""
+}
+
+func (x *NIC) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
 var File_nic_proto protoreflect.FileDescriptor
 
 const file_nic_proto_rawDesc = "" +
 	"\n" +
 	"\tnic.proto\x12\n" +
-	"endpointpb\"\x17\n" +
+	"endpointpb\"\x90\x01\n" +
 	"\x03NIC\x12\x10\n" +
-	"\x03mac\x18\x01 \x01(\tR\x03macBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\x03mac\x18\x01 \x01(\tR\x03mac\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05speed\x18\x03 \x01(\x03R\x05speed\x12\x16\n" +
+	"\x06driver\x18\x04 \x01(\tR\x06driver\x12\x1f\n" +
+	"\vpci_address\x18\x05 \x01(\tR\n" +
+	"pciAddress\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05modelBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
 
 var (
 	file_nic_proto_rawDescOnce sync.Once
@@ -0,0 +1,139 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: patch_machine_request.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PatchMachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machine       *Machine               `protobuf:"bytes,1,opt,name=machine" json:"machine,omitempty"`                         // fields named in update_mask are copied from here
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask" json:"update_mask,omitempty"` // paths relative to Machine, e.g. "nics"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchMachineRequest) Reset() {
+	*x = PatchMachineRequest{}
+	mi := &file_patch_machine_request_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchMachineRequest) ProtoMessage() {}
+
+func (x *PatchMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_patch_machine_request_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchMachineRequest.ProtoReflect.Descriptor instead.
+func (*PatchMachineRequest) Descriptor() ([]byte, []int) {
+	return file_patch_machine_request_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PatchMachineRequest) GetMachine() *Machine {
+	if x != nil {
+		return x.Machine
+	}
+	return nil
+}
+
+func (x *PatchMachineRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+var File_patch_machine_request_proto protoreflect.FileDescriptor
+
+const file_patch_machine_request_proto_rawDesc = "" +
+	"\n" +
+	"\x1bpatch_machine_request.proto\x12\n" +
+	"endpointpb\x1a google/protobuf/field_mask.proto\x1a\rmachine.proto\"\x81\x01\n" +
+	"\x13PatchMachineRequest\x12-\n" +
+	"\amachine\x18\x01 \x01(\v2\x13.endpointpb.MachineR\amachine\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMaskBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_patch_machine_request_proto_rawDescOnce sync.Once
+	file_patch_machine_request_proto_rawDescData []byte
+)
+
+func file_patch_machine_request_proto_rawDescGZIP() []byte {
+	file_patch_machine_request_proto_rawDescOnce.Do(func() {
+		file_patch_machine_request_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_patch_machine_request_proto_rawDesc), len(file_patch_machine_request_proto_rawDesc)))
+	})
+	return file_patch_machine_request_proto_rawDescData
+}
+
+var file_patch_machine_request_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_patch_machine_request_proto_goTypes = []any{
+	(*PatchMachineRequest)(nil),   // 0: endpointpb.PatchMachineRequest
+	(*Machine)(nil),               // 1: endpointpb.Machine
+	(*fieldmaskpb.FieldMask)(nil), // 2: google.protobuf.FieldMask
+}
+var file_patch_machine_request_proto_depIdxs = []int32{
+	1, // 0: endpointpb.PatchMachineRequest.machine:type_name -> endpointpb.Machine
+	2, // 1: endpointpb.PatchMachineRequest.update_mask:type_name -> google.protobuf.FieldMask
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_patch_machine_request_proto_init() }
+func file_patch_machine_request_proto_init() {
+	if File_patch_machine_request_proto != nil {
+		return
+	}
+	file_machine_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_patch_machine_request_proto_rawDesc), len(file_patch_machine_request_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_patch_machine_request_proto_goTypes,
+		DependencyIndexes: file_patch_machine_request_proto_depIdxs,
+		MessageInfos:      file_patch_machine_request_proto_msgTypes,
+	}.Build()
+	File_patch_machine_request_proto = out.File
+	file_patch_machine_request_proto_goTypes = nil
+	file_patch_machine_request_proto_depIdxs = nil
+}
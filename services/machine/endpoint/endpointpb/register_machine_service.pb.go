@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
+	sync "sync"
 	unsafe "unsafe"
 )
 
@@ -20,27 +21,391 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type GetMachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MachineId     *string                `protobuf:"bytes,1,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMachineRequest) Reset() {
+	*x = GetMachineRequest{}
+	mi := &file_register_machine_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMachineRequest) ProtoMessage() {}
+
+func (x *GetMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMachineRequest.ProtoReflect.Descriptor instead.
+func (*GetMachineRequest) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetMachineRequest) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+type ListMachinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PerPage       *int32                 `protobuf:"varint,1,opt,name=per_page,json=perPage" json:"per_page,omitempty"` // defaults to 20, at most 100
+	PageToken     *string                `protobuf:"bytes,2,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	Mac           *string                `protobuf:"bytes,3,opt,name=mac" json:"mac,omitempty"`
+	LabelSelector *string                `protobuf:"bytes,4,opt,name=label_selector,json=labelSelector" json:"label_selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMachinesRequest) Reset() {
+	*x = ListMachinesRequest{}
+	mi := &file_register_machine_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMachinesRequest) ProtoMessage() {}
+
+func (x *ListMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMachinesRequest.ProtoReflect.Descriptor instead.
+func (*ListMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListMachinesRequest) GetPerPage() int32 {
+	if x != nil && x.PerPage != nil {
+		return *x.PerPage
+	}
+	return 0
+}
+
+func (x *ListMachinesRequest) GetPageToken() string {
+	if x != nil && x.PageToken != nil {
+		return *x.PageToken
+	}
+	return ""
+}
+
+func (x *ListMachinesRequest) GetMac() string {
+	if x != nil && x.Mac != nil {
+		return *x.Mac
+	}
+	return ""
+}
+
+func (x *ListMachinesRequest) GetLabelSelector() string {
+	if x != nil && x.LabelSelector != nil {
+		return *x.LabelSelector
+	}
+	return ""
+}
+
+type UpdateMachineRequest struct {
+	state            protoimpl.MessageState  `protogen:"open.v1"`
+	MachineId        *string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	Machine          *RegisterMachineRequest `protobuf:"bytes,2,opt,name=machine" json:"machine,omitempty"`
+	ExpectedRevision *int64                  `protobuf:"varint,3,opt,name=expected_revision,json=expectedRevision" json:"expected_revision,omitempty"` // when set, the update fails unless it is still current
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UpdateMachineRequest) Reset() {
+	*x = UpdateMachineRequest{}
+	mi := &file_register_machine_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMachineRequest) ProtoMessage() {}
+
+func (x *UpdateMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMachineRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMachineRequest) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdateMachineRequest) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+func (x *UpdateMachineRequest) GetMachine() *RegisterMachineRequest {
+	if x != nil {
+		return x.Machine
+	}
+	return nil
+}
+
+func (x *UpdateMachineRequest) GetExpectedRevision() int64 {
+	if x != nil && x.ExpectedRevision != nil {
+		return *x.ExpectedRevision
+	}
+	return 0
+}
+
+type DeleteMachineRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MachineId        *string                `protobuf:"bytes,1,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	ExpectedRevision *int64                 `protobuf:"varint,2,opt,name=expected_revision,json=expectedRevision" json:"expected_revision,omitempty"` // when set, the delete fails unless it is still current
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeleteMachineRequest) Reset() {
+	*x = DeleteMachineRequest{}
+	mi := &file_register_machine_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMachineRequest) ProtoMessage() {}
+
+func (x *DeleteMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMachineRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMachineRequest) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeleteMachineRequest) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+func (x *DeleteMachineRequest) GetExpectedRevision() int64 {
+	if x != nil && x.ExpectedRevision != nil {
+		return *x.ExpectedRevision
+	}
+	return 0
+}
+
+type DeleteMachineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMachineResponse) Reset() {
+	*x = DeleteMachineResponse{}
+	mi := &file_register_machine_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMachineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMachineResponse) ProtoMessage() {}
+
+func (x *DeleteMachineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMachineResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMachineResponse) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{4}
+}
+
+type WatchMachinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	After         *int64                 `protobuf:"varint,1,opt,name=after" json:"after,omitempty"` // sequence to resume after; unset starts at the end of the log
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMachinesRequest) Reset() {
+	*x = WatchMachinesRequest{}
+	mi := &file_register_machine_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMachinesRequest) ProtoMessage() {}
+
+func (x *WatchMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_register_machine_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMachinesRequest.ProtoReflect.Descriptor instead.
+func (*WatchMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_register_machine_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchMachinesRequest) GetAfter() int64 {
+	if x != nil && x.After != nil {
+		return *x.After
+	}
+	return 0
+}
+
 var File_register_machine_service_proto protoreflect.FileDescriptor
 
 const file_register_machine_service_proto_rawDesc = "" +
 	"\n" +
 	"\x1eregister_machine_service.proto\x12\n" +
-	"endpointpb\x1a\x1eregister_machine_request.proto\x1a\x1fregister_machine_response.proto2t\n" +
+	"endpointpb\x1a\x1clist_machines_response.proto\x1a\rmachine.proto\x1a\x13machine_event.proto\x1a\x1eregister_machine_request.proto\x1a\x1fregister_machine_response.proto\"2\n" +
+	"\x11GetMachineRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\"\x88\x01\n" +
+	"\x13ListMachinesRequest\x12\x19\n" +
+	"\bper_page\x18\x01 \x01(\x05R\aperPage\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x10\n" +
+	"\x03mac\x18\x03 \x01(\tR\x03mac\x12%\n" +
+	"\x0elabel_selector\x18\x04 \x01(\tR\rlabelSelector\"\xa0\x01\n" +
+	"\x14UpdateMachineRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x12<\n" +
+	"\amachine\x18\x02 \x01(\v2\".endpointpb.RegisterMachineRequestR\amachine\x12+\n" +
+	"\x11expected_revision\x18\x03 \x01(\x03R\x10expectedRevision\"b\n" +
+	"\x14DeleteMachineRequest\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x12+\n" +
+	"\x11expected_revision\x18\x02 \x01(\x03R\x10expectedRevision\"\x17\n" +
+	"\x15DeleteMachineResponse\",\n" +
+	"\x14WatchMachinesRequest\x12\x14\n" +
+	"\x05after\x18\x01 \x01(\x03R\x05after2\xf6\x03\n" +
 	"\x16RegisterMachineService\x12Z\n" +
-	"\x0fRegisterMachine\x12\".endpointpb.RegisterMachineRequest\x1a#.endpointpb.RegisterMachineResponseBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+	"\x0fRegisterMachine\x12\".endpointpb.RegisterMachineRequest\x1a#.endpointpb.RegisterMachineResponse\x12@\n" +
+	"\n" +
+	"GetMachine\x12\x1d.endpointpb.GetMachineRequest\x1a\x13.endpointpb.Machine\x12Q\n" +
+	"\fListMachines\x12\x1f.endpointpb.ListMachinesRequest\x1a .endpointpb.ListMachinesResponse\x12F\n" +
+	"\rUpdateMachine\x12 .endpointpb.UpdateMachineRequest\x1a\x13.endpointpb.Machine\x12T\n" +
+	"\rDeleteMachine\x12 .endpointpb.DeleteMachineRequest\x1a!.endpointpb.DeleteMachineResponse\x12M\n" +
+	"\rWatchMachines\x12 .endpointpb.WatchMachinesRequest\x1a\x18.endpointpb.MachineEvent0\x01BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_register_machine_service_proto_rawDescOnce sync.Once
+	file_register_machine_service_proto_rawDescData []byte
+)
+
+func file_register_machine_service_proto_rawDescGZIP() []byte {
+	file_register_machine_service_proto_rawDescOnce.Do(func() {
+		file_register_machine_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_register_machine_service_proto_rawDesc), len(file_register_machine_service_proto_rawDesc)))
+	})
+	return file_register_machine_service_proto_rawDescData
+}
 
+var file_register_machine_service_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_register_machine_service_proto_goTypes = []any{
-	(*RegisterMachineRequest)(nil),  // 0: endpointpb.RegisterMachineRequest
-	(*RegisterMachineResponse)(nil), // 1: endpointpb.RegisterMachineResponse
+	(*GetMachineRequest)(nil),       // 0: endpointpb.GetMachineRequest
+	(*ListMachinesRequest)(nil),     // 1: endpointpb.ListMachinesRequest
+	(*UpdateMachineRequest)(nil),    // 2: endpointpb.UpdateMachineRequest
+	(*DeleteMachineRequest)(nil),    // 3: endpointpb.DeleteMachineRequest
+	(*DeleteMachineResponse)(nil),   // 4: endpointpb.DeleteMachineResponse
+	(*WatchMachinesRequest)(nil),    // 5: endpointpb.WatchMachinesRequest
+	(*RegisterMachineRequest)(nil),  // 6: endpointpb.RegisterMachineRequest
+	(*RegisterMachineResponse)(nil), // 7: endpointpb.RegisterMachineResponse
+	(*Machine)(nil),                 // 8: endpointpb.Machine
+	(*ListMachinesResponse)(nil),    // 9: endpointpb.ListMachinesResponse
+	(*MachineEvent)(nil),            // 10: endpointpb.MachineEvent
 }
 var file_register_machine_service_proto_depIdxs = []int32{
-	0, // 0: endpointpb.RegisterMachineService.RegisterMachine:input_type -> endpointpb.RegisterMachineRequest
-	1, // 1: endpointpb.RegisterMachineService.RegisterMachine:output_type -> endpointpb.RegisterMachineResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6,  // 0: endpointpb.UpdateMachineRequest.machine:type_name -> endpointpb.RegisterMachineRequest
+	6,  // 1: endpointpb.RegisterMachineService.RegisterMachine:input_type -> endpointpb.RegisterMachineRequest
+	0,  // 2: endpointpb.RegisterMachineService.GetMachine:input_type -> endpointpb.GetMachineRequest
+	1,  // 3: endpointpb.RegisterMachineService.ListMachines:input_type -> endpointpb.ListMachinesRequest
+	2,  // 4: endpointpb.RegisterMachineService.UpdateMachine:input_type -> endpointpb.UpdateMachineRequest
+	3,  // 5: endpointpb.RegisterMachineService.DeleteMachine:input_type -> endpointpb.DeleteMachineRequest
+	5,  // 6: endpointpb.RegisterMachineService.WatchMachines:input_type -> endpointpb.WatchMachinesRequest
+	7,  // 7: endpointpb.RegisterMachineService.RegisterMachine:output_type -> endpointpb.RegisterMachineResponse
+	8,  // 8: endpointpb.RegisterMachineService.GetMachine:output_type -> endpointpb.Machine
+	9,  // 9: endpointpb.RegisterMachineService.ListMachines:output_type -> endpointpb.ListMachinesResponse
+	8,  // 10: endpointpb.RegisterMachineService.UpdateMachine:output_type -> endpointpb.Machine
+	4,  // 11: endpointpb.RegisterMachineService.DeleteMachine:output_type -> endpointpb.DeleteMachineResponse
+	10, // 12: endpointpb.RegisterMachineService.WatchMachines:output_type -> endpointpb.MachineEvent
+	7,  // [7:13] is the sub-list for method output_type
+	1,  // [1:7] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_register_machine_service_proto_init() }
@@ -48,6 +413,9 @@ func file_register_machine_service_proto_init() {
 	if File_register_machine_service_proto != nil {
 		return
 	}
+	file_list_machines_response_proto_init()
+	file_machine_proto_init()
+	file_machine_event_proto_init()
 	file_register_machine_request_proto_init()
 	file_register_machine_response_proto_init()
 	type x struct{}
@@ -56,12 +424,13 @@ func file_register_machine_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_register_machine_service_proto_rawDesc), len(file_register_machine_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   0,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_register_machine_service_proto_goTypes,
 		DependencyIndexes: file_register_machine_service_proto_depIdxs,
+		MessageInfos:      file_register_machine_service_proto_msgTypes,
 	}.Build()
 	File_register_machine_service_proto = out.File
 	file_register_machine_service_proto_goTypes = nil
@@ -0,0 +1,123 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: rollback_machine_request.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RollbackMachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      *int64                 `protobuf:"varint,1,opt,name=revision" json:"revision,omitempty"` // revision whose hardware and labels are restored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackMachineRequest) Reset() {
+	*x = RollbackMachineRequest{}
+	mi := &file_rollback_machine_request_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackMachineRequest) ProtoMessage() {}
+
+func (x *RollbackMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rollback_machine_request_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackMachineRequest.ProtoReflect.Descriptor instead.
+func (*RollbackMachineRequest) Descriptor() ([]byte, []int) {
+	return file_rollback_machine_request_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RollbackMachineRequest) GetRevision() int64 {
+	if x != nil && x.Revision != nil {
+		return *x.Revision
+	}
+	return 0
+}
+
+var File_rollback_machine_request_proto protoreflect.FileDescriptor
+
+const file_rollback_machine_request_proto_rawDesc = "" +
+	"\n" +
+	"\x1erollback_machine_request.proto\x12\n" +
+	"endpointpb\"4\n" +
+	"\x16RollbackMachineRequest\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x03R\brevisionBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_rollback_machine_request_proto_rawDescOnce sync.Once
+	file_rollback_machine_request_proto_rawDescData []byte
+)
+
+func file_rollback_machine_request_proto_rawDescGZIP() []byte {
+	file_rollback_machine_request_proto_rawDescOnce.Do(func() {
+		file_rollback_machine_request_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rollback_machine_request_proto_rawDesc), len(file_rollback_machine_request_proto_rawDesc)))
+	})
+	return file_rollback_machine_request_proto_rawDescData
+}
+
+var file_rollback_machine_request_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_rollback_machine_request_proto_goTypes = []any{
+	(*RollbackMachineRequest)(nil), // 0: endpointpb.RollbackMachineRequest
+}
+var file_rollback_machine_request_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rollback_machine_request_proto_init() }
+func file_rollback_machine_request_proto_init() {
+	if File_rollback_machine_request_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rollback_machine_request_proto_rawDesc), len(file_rollback_machine_request_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rollback_machine_request_proto_goTypes,
+		DependencyIndexes: file_rollback_machine_request_proto_depIdxs,
+		MessageInfos:      file_rollback_machine_request_proto_msgTypes,
+	}.Build()
+	File_rollback_machine_request_proto = out.File
+	file_rollback_machine_request_proto_goTypes = nil
+	file_rollback_machine_request_proto_depIdxs = nil
+}
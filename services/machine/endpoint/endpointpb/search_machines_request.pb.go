@@ -0,0 +1,190 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: search_machines_request.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchMachinesRequest struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	MinCores                *int64                 `protobuf:"varint,1,opt,name=min_cores,json=minCores" json:"min_cores,omitempty"`                                                            // total cores across all CPUs
+	MinMemory               *int64                 `protobuf:"varint,2,opt,name=min_memory,json=minMemory" json:"min_memory,omitempty"`                                                         // total memory in bytes
+	MinDriveCapacity        *int64                 `protobuf:"varint,3,opt,name=min_drive_capacity,json=minDriveCapacity" json:"min_drive_capacity,omitempty"`                                  // total drive capacity in bytes
+	MinNics                 *int64                 `protobuf:"varint,4,opt,name=min_nics,json=minNics" json:"min_nics,omitempty"`                                                               // number of NICs
+	AcceleratorManufacturer *string                `protobuf:"bytes,5,opt,name=accelerator_manufacturer,json=acceleratorManufacturer" json:"accelerator_manufacturer,omitempty"`                // at least one accelerator by this manufacturer
+	LifecycleStates         []LifecycleState       `protobuf:"varint,6,rep,packed,name=lifecycle_states,json=lifecycleStates,enum=endpointpb.LifecycleState" json:"lifecycle_states,omitempty"` // any of these states
+	LabelSelector           *string                `protobuf:"bytes,7,opt,name=label_selector,json=labelSelector" json:"label_selector,omitempty"`                                              // as in GET /api/v1/machines
+	Limit                   *int32                 `protobuf:"varint,8,opt,name=limit" json:"limit,omitempty"`                                                                                  // maximum number of matches returned
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *SearchMachinesRequest) Reset() {
+	*x = SearchMachinesRequest{}
+	mi := &file_search_machines_request_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMachinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMachinesRequest) ProtoMessage() {}
+
+func (x *SearchMachinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_machines_request_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMachinesRequest.ProtoReflect.Descriptor instead.
+func (*SearchMachinesRequest) Descriptor() ([]byte, []int) {
+	return file_search_machines_request_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchMachinesRequest) GetMinCores() int64 {
+	if x != nil && x.MinCores != nil {
+		return *x.MinCores
+	}
+	return 0
+}
+
+func (x *SearchMachinesRequest) GetMinMemory() int64 {
+	if x != nil && x.MinMemory != nil {
+		return *x.MinMemory
+	}
+	return 0
+}
+
+func (x *SearchMachinesRequest) GetMinDriveCapacity() int64 {
+	if x != nil && x.MinDriveCapacity != nil {
+		return *x.MinDriveCapacity
+	}
+	return 0
+}
+
+func (x *SearchMachinesRequest) GetMinNics() int64 {
+	if x != nil && x.MinNics != nil {
+		return *x.MinNics
+	}
+	return 0
+}
+
+func (x *SearchMachinesRequest) GetAcceleratorManufacturer() string {
+	if x != nil && x.AcceleratorManufacturer != nil {
+		return *x.AcceleratorManufacturer
+	}
+	return ""
+}
+
+func (x *SearchMachinesRequest) GetLifecycleStates() []LifecycleState {
+	if x != nil {
+		return x.LifecycleStates
+	}
+	return nil
+}
+
+func (x *SearchMachinesRequest) GetLabelSelector() string {
+	if x != nil && x.LabelSelector != nil {
+		return *x.LabelSelector
+	}
+	return ""
+}
+
+func (x *SearchMachinesRequest) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+var File_search_machines_request_proto protoreflect.FileDescriptor
+
+const file_search_machines_request_proto_rawDesc = "" +
+	"\n" +
+	"\x1dsearch_machines_request.proto\x12\n" +
+	"endpointpb\x1a\x0flifecycle.proto\"\xdb\x02\n" +
+	"\x15SearchMachinesRequest\x12\x1b\n" +
+	"\tmin_cores\x18\x01 \x01(\x03R\bminCores\x12\x1d\n" +
+	"\n" +
+	"min_memory\x18\x02 \x01(\x03R\tminMemory\x12,\n" +
+	"\x12min_drive_capacity\x18\x03 \x01(\x03R\x10minDriveCapacity\x12\x19\n" +
+	"\bmin_nics\x18\x04 \x01(\x03R\aminNics\x129\n" +
+	"\x18accelerator_manufacturer\x18\x05 \x01(\tR\x17acceleratorManufacturer\x12E\n" +
+	"\x10lifecycle_states\x18\x06 \x03(\x0e2\x1a.endpointpb.LifecycleStateR\x0flifecycleStates\x12%\n" +
+	"\x0elabel_selector\x18\a \x01(\tR\rlabelSelector\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limitBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_search_machines_request_proto_rawDescOnce sync.Once
+	file_search_machines_request_proto_rawDescData []byte
+)
+
+func file_search_machines_request_proto_rawDescGZIP() []byte {
+	file_search_machines_request_proto_rawDescOnce.Do(func() {
+		file_search_machines_request_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_search_machines_request_proto_rawDesc), len(file_search_machines_request_proto_rawDesc)))
+	})
+	return file_search_machines_request_proto_rawDescData
+}
+
+var file_search_machines_request_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_search_machines_request_proto_goTypes = []any{
+	(*SearchMachinesRequest)(nil), // 0: endpointpb.SearchMachinesRequest
+	(LifecycleState)(0),           // 1: endpointpb.LifecycleState
+}
+var file_search_machines_request_proto_depIdxs = []int32{
+	1, // 0: endpointpb.SearchMachinesRequest.lifecycle_states:type_name -> endpointpb.LifecycleState
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_search_machines_request_proto_init() }
+func file_search_machines_request_proto_init() {
+	if File_search_machines_request_proto != nil {
+		return
+	}
+	file_lifecycle_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_machines_request_proto_rawDesc), len(file_search_machines_request_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_search_machines_request_proto_goTypes,
+		DependencyIndexes: file_search_machines_request_proto_depIdxs,
+		MessageInfos:      file_search_machines_request_proto_msgTypes,
+	}.Build()
+	File_search_machines_request_proto = out.File
+	file_search_machines_request_proto_goTypes = nil
+	file_search_machines_request_proto_depIdxs = nil
+}
@@ -0,0 +1,183 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: search_machines_response.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MachineMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machine       *Machine               `protobuf:"bytes,1,opt,name=machine" json:"machine,omitempty"`
+	Fit           *float64               `protobuf:"fixed64,2,opt,name=fit" json:"fit,omitempty"` // 1 is an exact fit, lower leaves more capacity unused
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineMatch) Reset() {
+	*x = MachineMatch{}
+	mi := &file_search_machines_response_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineMatch) ProtoMessage() {}
+
+func (x *MachineMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_search_machines_response_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineMatch.ProtoReflect.Descriptor instead.
+func (*MachineMatch) Descriptor() ([]byte, []int) {
+	return file_search_machines_response_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineMatch) GetMachine() *Machine {
+	if x != nil {
+		return x.Machine
+	}
+	return nil
+}
+
+func (x *MachineMatch) GetFit() float64 {
+	if x != nil && x.Fit != nil {
+		return *x.Fit
+	}
+	return 0
+}
+
+type SearchMachinesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*MachineMatch        `protobuf:"bytes,1,rep,name=matches" json:"matches,omitempty"` // best fit first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchMachinesResponse) Reset() {
+	*x = SearchMachinesResponse{}
+	mi := &file_search_machines_response_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMachinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMachinesResponse) ProtoMessage() {}
+
+func (x *SearchMachinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_machines_response_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMachinesResponse.ProtoReflect.Descriptor instead.
+func (*SearchMachinesResponse) Descriptor() ([]byte, []int) {
+	return file_search_machines_response_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchMachinesResponse) GetMatches() []*MachineMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+var File_search_machines_response_proto protoreflect.FileDescriptor
+
+const file_search_machines_response_proto_rawDesc = "" +
+	"\n" +
+	"\x1esearch_machines_response.proto\x12\n" +
+	"endpointpb\x1a\rmachine.proto\"O\n" +
+	"\fMachineMatch\x12-\n" +
+	"\amachine\x18\x01 \x01(\v2\x13.endpointpb.MachineR\amachine\x12\x10\n" +
+	"\x03fit\x18\x02 \x01(\x01R\x03fit\"L\n" +
+	"\x16SearchMachinesResponse\x122\n" +
+	"\amatches\x18\x01 \x03(\v2\x18.endpointpb.MachineMatchR\amatchesBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_search_machines_response_proto_rawDescOnce sync.Once
+	file_search_machines_response_proto_rawDescData []byte
+)
+
+func file_search_machines_response_proto_rawDescGZIP() []byte {
+	file_search_machines_response_proto_rawDescOnce.Do(func() {
+		file_search_machines_response_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_search_machines_response_proto_rawDesc), len(file_search_machines_response_proto_rawDesc)))
+	})
+	return file_search_machines_response_proto_rawDescData
+}
+
+var file_search_machines_response_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_search_machines_response_proto_goTypes = []any{
+	(*MachineMatch)(nil),           // 0: endpointpb.MachineMatch
+	(*SearchMachinesResponse)(nil), // 1: endpointpb.SearchMachinesResponse
+	(*Machine)(nil),                // 2: endpointpb.Machine
+}
+var file_search_machines_response_proto_depIdxs = []int32{
+	2, // 0: endpointpb.MachineMatch.machine:type_name -> endpointpb.Machine
+	0, // 1: endpointpb.SearchMachinesResponse.matches:type_name -> endpointpb.MachineMatch
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_search_machines_response_proto_init() }
+func file_search_machines_response_proto_init() {
+	if File_search_machines_response_proto != nil {
+		return
+	}
+	file_machine_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_machines_response_proto_rawDesc), len(file_search_machines_response_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_search_machines_response_proto_goTypes,
+		DependencyIndexes: file_search_machines_response_proto_depIdxs,
+		MessageInfos:      file_search_machines_response_proto_msgTypes,
+	}.Build()
+	File_search_machines_response_proto = out.File
+	file_search_machines_response_proto_goTypes = nil
+	file_search_machines_response_proto_depIdxs = nil
+}
@@ -0,0 +1,135 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: transition_machine_request.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransitionMachineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         *LifecycleState        `protobuf:"varint,1,opt,name=state,enum=endpointpb.LifecycleState" json:"state,omitempty"` // state to move the machine to
+	Reason        *string                `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`                               // recorded in the machine's lifecycle history
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransitionMachineRequest) Reset() {
+	*x = TransitionMachineRequest{}
+	mi := &file_transition_machine_request_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransitionMachineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransitionMachineRequest) ProtoMessage() {}
+
+func (x *TransitionMachineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transition_machine_request_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransitionMachineRequest.ProtoReflect.Descriptor instead.
+func (*TransitionMachineRequest) Descriptor() ([]byte, []int) {
+	return file_transition_machine_request_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransitionMachineRequest) GetState() LifecycleState {
+	if x != nil && x.State != nil {
+		return *x.State
+	}
+	return LifecycleState_LIFECYCLE_STATE_UNSPECIFIED
+}
+
+func (x *TransitionMachineRequest) GetReason() string {
+	if x != nil && x.Reason != nil {
+		return *x.Reason
+	}
+	return ""
+}
+
+var File_transition_machine_request_proto protoreflect.FileDescriptor
+
+const file_transition_machine_request_proto_rawDesc = "" +
+	"\n" +
+	" transition_machine_request.proto\x12\n" +
+	"endpointpb\x1a\x0flifecycle.proto\"d\n" +
+	"\x18TransitionMachineRequest\x120\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x1a.endpointpb.LifecycleStateR\x05state\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reasonBJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_transition_machine_request_proto_rawDescOnce sync.Once
+	file_transition_machine_request_proto_rawDescData []byte
+)
+
+func file_transition_machine_request_proto_rawDescGZIP() []byte {
+	file_transition_machine_request_proto_rawDescOnce.Do(func() {
+		file_transition_machine_request_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transition_machine_request_proto_rawDesc), len(file_transition_machine_request_proto_rawDesc)))
+	})
+	return file_transition_machine_request_proto_rawDescData
+}
+
+var file_transition_machine_request_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_transition_machine_request_proto_goTypes = []any{
+	(*TransitionMachineRequest)(nil), // 0: endpointpb.TransitionMachineRequest
+	(LifecycleState)(0),              // 1: endpointpb.LifecycleState
+}
+var file_transition_machine_request_proto_depIdxs = []int32{
+	1, // 0: endpointpb.TransitionMachineRequest.state:type_name -> endpointpb.LifecycleState
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_transition_machine_request_proto_init() }
+func file_transition_machine_request_proto_init() {
+	if File_transition_machine_request_proto != nil {
+		return
+	}
+	file_lifecycle_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transition_machine_request_proto_rawDesc), len(file_transition_machine_request_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transition_machine_request_proto_goTypes,
+		DependencyIndexes: file_transition_machine_request_proto_depIdxs,
+		MessageInfos:      file_transition_machine_request_proto_msgTypes,
+	}.Build()
+	File_transition_machine_request_proto = out.File
+	file_transition_machine_request_proto_goTypes = nil
+	file_transition_machine_request_proto_depIdxs = nil
+}
@@ -0,0 +1,362 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v7.35.0--dev
+// source: webhook_delivery.proto
+
+package endpointpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DeliveryStatus int32
+
+const (
+	DeliveryStatus_DELIVERY_STATUS_UNSPECIFIED DeliveryStatus = 0
+	DeliveryStatus_DELIVERY_STATUS_PENDING     DeliveryStatus = 1
+	DeliveryStatus_DELIVERY_STATUS_SUCCEEDED   DeliveryStatus = 2
+	DeliveryStatus_DELIVERY_STATUS_FAILED      DeliveryStatus = 3 // given up on; can be redelivered
+)
+
+// Enum value maps for DeliveryStatus.
+var (
+	DeliveryStatus_name = map[int32]string{
+		0: "DELIVERY_STATUS_UNSPECIFIED",
+		1: "DELIVERY_STATUS_PENDING",
+		2: "DELIVERY_STATUS_SUCCEEDED",
+		3: "DELIVERY_STATUS_FAILED",
+	}
+	DeliveryStatus_value = map[string]int32{
+		"DELIVERY_STATUS_UNSPECIFIED": 0,
+		"DELIVERY_STATUS_PENDING":     1,
+		"DELIVERY_STATUS_SUCCEEDED":   2,
+		"DELIVERY_STATUS_FAILED":      3,
+	}
+)
+
+func (x DeliveryStatus) Enum() *DeliveryStatus {
+	p := new(DeliveryStatus)
+	*p = x
+	return p
+}
+
+func (x DeliveryStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeliveryStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_webhook_delivery_proto_enumTypes[0].Descriptor()
+}
+
+func (DeliveryStatus) Type() protoreflect.EnumType {
+	return &file_webhook_delivery_proto_enumTypes[0]
+}
+
+func (x DeliveryStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeliveryStatus.Descriptor instead.
+func (DeliveryStatus) EnumDescriptor() ([]byte, []int) {
+	return file_webhook_delivery_proto_rawDescGZIP(), []int{0}
+}
+
+type WebhookDelivery struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId      *string                `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId" json:"webhook_id,omitempty"`
+	Sequence       *int64                 `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"` // the delivered event's sequence
+	Type           *MachineEventType      `protobuf:"varint,3,opt,name=type,enum=endpointpb.MachineEventType" json:"type,omitempty"`
+	MachineId      *string                `protobuf:"bytes,4,opt,name=machine_id,json=machineId" json:"machine_id,omitempty"`
+	Status         *DeliveryStatus        `protobuf:"varint,5,opt,name=status,enum=endpointpb.DeliveryStatus" json:"status,omitempty"`
+	Attempts       *int32                 `protobuf:"varint,6,opt,name=attempts" json:"attempts,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt" json:"created_at,omitempty"`
+	NextAttemptAt  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=next_attempt_at,json=nextAttemptAt" json:"next_attempt_at,omitempty"` // unset unless pending
+	GiveUpAt       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=give_up_at,json=giveUpAt" json:"give_up_at,omitempty"`
+	LastAttemptAt  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_attempt_at,json=lastAttemptAt" json:"last_attempt_at,omitempty"`
+	LastStatusCode *int32                 `protobuf:"varint,11,opt,name=last_status_code,json=lastStatusCode" json:"last_status_code,omitempty"` // unset if no response was received
+	LastError      *string                `protobuf:"bytes,12,opt,name=last_error,json=lastError" json:"last_error,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WebhookDelivery) Reset() {
+	*x = WebhookDelivery{}
+	mi := &file_webhook_delivery_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDelivery) ProtoMessage() {}
+
+func (x *WebhookDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_delivery_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDelivery.ProtoReflect.Descriptor instead.
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return file_webhook_delivery_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WebhookDelivery) GetWebhookId() string {
+	if x != nil && x.WebhookId != nil {
+		return *x.WebhookId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetSequence() int64 {
+	if x != nil && x.Sequence != nil {
+		return *x.Sequence
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetType() MachineEventType {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return MachineEventType_MACHINE_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *WebhookDelivery) GetMachineId() string {
+	if x != nil && x.MachineId != nil {
+		return *x.MachineId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetStatus() DeliveryStatus {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return DeliveryStatus_DELIVERY_STATUS_UNSPECIFIED
+}
+
+func (x *WebhookDelivery) GetAttempts() int32 {
+	if x != nil && x.Attempts != nil {
+		return *x.Attempts
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *WebhookDelivery) GetNextAttemptAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextAttemptAt
+	}
+	return nil
+}
+
+func (x *WebhookDelivery) GetGiveUpAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.GiveUpAt
+	}
+	return nil
+}
+
+func (x *WebhookDelivery) GetLastAttemptAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAttemptAt
+	}
+	return nil
+}
+
+func (x *WebhookDelivery) GetLastStatusCode() int32 {
+	if x != nil && x.LastStatusCode != nil {
+		return *x.LastStatusCode
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetLastError() string {
+	if x != nil && x.LastError != nil {
+		return *x.LastError
+	}
+	return ""
+}
+
+type ListWebhookDeliveriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deliveries    []*WebhookDelivery     `protobuf:"bytes,1,rep,name=deliveries" json:"deliveries,omitempty"` // newest first
+	Pagination    *Pagination            `protobuf:"bytes,2,opt,name=pagination" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesResponse) Reset() {
+	*x = ListWebhookDeliveriesResponse{}
+	mi := &file_webhook_delivery_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesResponse) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webhook_delivery_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return file_webhook_delivery_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListWebhookDeliveriesResponse) GetDeliveries() []*WebhookDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+func (x *ListWebhookDeliveriesResponse) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+var File_webhook_delivery_proto protoreflect.FileDescriptor
+
+const file_webhook_delivery_proto_rawDesc = "" +
+	"\n" +
+	"\x16webhook_delivery.proto\x12\n" +
+	"endpointpb\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x13machine_event.proto\x1a\x10pagination.proto\"\xb3\x04\n" +
+	"\x0fWebhookDelivery\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x03R\bsequence\x120\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1c.endpointpb.MachineEventTypeR\x04type\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x04 \x01(\tR\tmachineId\x122\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x1a.endpointpb.DeliveryStatusR\x06status\x12\x1a\n" +
+	"\battempts\x18\x06 \x01(\x05R\battempts\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12B\n" +
+	"\x0fnext_attempt_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\rnextAttemptAt\x128\n" +
+	"\n" +
+	"give_up_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\bgiveUpAt\x12B\n" +
+	"\x0flast_attempt_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rlastAttemptAt\x12(\n" +
+	"\x10last_status_code\x18\v \x01(\x05R\x0elastStatusCode\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\f \x01(\tR\tlastError\"\x94\x01\n" +
+	"\x1dListWebhookDeliveriesResponse\x12;\n" +
+	"\n" +
+	"deliveries\x18\x01 \x03(\v2\x1b.endpointpb.WebhookDeliveryR\n" +
+	"deliveries\x126\n" +
+	"\n" +
+	"pagination\x18\x02 \x01(\v2\x16.endpointpb.PaginationR\n" +
+	"pagination*\x89\x01\n" +
+	"\x0eDeliveryStatus\x12\x1f\n" +
+	"\x1bDELIVERY_STATUS_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17DELIVERY_STATUS_PENDING\x10\x01\x12\x1d\n" +
+	"\x19DELIVERY_STATUS_SUCCEEDED\x10\x02\x12\x1a\n" +
+	"\x16DELIVERY_STATUS_FAILED\x10\x03BJZHgithub.com/Zaba505/infra/services/machine/endpoint/endpointpb;endpointpbb\beditionsp\xe8\a"
+
+var (
+	file_webhook_delivery_proto_rawDescOnce sync.Once
+	file_webhook_delivery_proto_rawDescData []byte
+)
+
+func file_webhook_delivery_proto_rawDescGZIP() []byte {
+	file_webhook_delivery_proto_rawDescOnce.Do(func() {
+		file_webhook_delivery_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_webhook_delivery_proto_rawDesc), len(file_webhook_delivery_proto_rawDesc)))
+	})
+	return file_webhook_delivery_proto_rawDescData
+}
+
+var file_webhook_delivery_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_webhook_delivery_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_webhook_delivery_proto_goTypes = []any{
+	(DeliveryStatus)(0),                   // 0: endpointpb.DeliveryStatus
+	(*WebhookDelivery)(nil),               // 1: endpointpb.WebhookDelivery
+	(*ListWebhookDeliveriesResponse)(nil), // 2: endpointpb.ListWebhookDeliveriesResponse
+	(MachineEventType)(0),                 // 3: endpointpb.MachineEventType
+	(*timestamppb.Timestamp)(nil),         // 4: google.protobuf.Timestamp
+	(*Pagination)(nil),                    // 5: endpointpb.Pagination
+}
+var file_webhook_delivery_proto_depIdxs = []int32{
+	3, // 0: endpointpb.WebhookDelivery.type:type_name -> endpointpb.MachineEventType
+	0, // 1: endpointpb.WebhookDelivery.status:type_name -> endpointpb.DeliveryStatus
+	4, // 2: endpointpb.WebhookDelivery.created_at:type_name -> google.protobuf.Timestamp
+	4, // 3: endpointpb.WebhookDelivery.next_attempt_at:type_name -> google.protobuf.Timestamp
+	4, // 4: endpointpb.WebhookDelivery.give_up_at:type_name -> google.protobuf.Timestamp
+	4, // 5: endpointpb.WebhookDelivery.last_attempt_at:type_name -> google.protobuf.Timestamp
+	1, // 6: endpointpb.ListWebhookDeliveriesResponse.deliveries:type_name -> endpointpb.WebhookDelivery
+	5, // 7: endpointpb.ListWebhookDeliveriesResponse.pagination:type_name -> endpointpb.Pagination
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_webhook_delivery_proto_init() }
+func file_webhook_delivery_proto_init() {
+	if File_webhook_delivery_proto != nil {
+		return
+	}
+	file_machine_event_proto_init()
+	file_pagination_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_webhook_delivery_proto_rawDesc), len(file_webhook_delivery_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_webhook_delivery_proto_goTypes,
+		DependencyIndexes: file_webhook_delivery_proto_depIdxs,
+		EnumInfos:         file_webhook_delivery_proto_enumTypes,
+		MessageInfos:      file_webhook_delivery_proto_msgTypes,
+	}.Build()
+	File_webhook_delivery_proto = out.File
+	file_webhook_delivery_proto_goTypes = nil
+	file_webhook_delivery_proto_depIdxs = nil
+}
@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+// machineETag formats a machine revision as a strong entity tag.
+func machineETag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// checkIfMatch evaluates the If-Match precondition against the current
+// revision. When the header is present, the returned revision should be
+// passed on to the write so a concurrent change between the read and the
+// write is still caught.
+func checkIfMatch(r *http.Request, revision int64) (*int64, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil, true
+	}
+	// If-Match requires strong comparison, so weak tags never match.
+	if !etagListContains(header, machineETag(revision), false) {
+		return nil, false
+	}
+	return &revision, true
+}
+
+// ifMatch is the precondition of a write carrying an If-Match header.
+func ifMatch(r *http.Request) precondition {
+	return func(revision int64) (*int64, bool) {
+		return checkIfMatch(r, revision)
+	}
+}
+
+// notModified reports whether the If-None-Match header already lists the
+// current revision.
+func notModified(r *http.Request, revision int64) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	return etagListContains(header, machineETag(revision), true)
+}
+
+func etagListContains(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if after, ok := strings.CutPrefix(tag, "W/"); ok {
+			if !weak {
+				continue
+			}
+			tag = after
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func isRevisionMismatch(err error) bool {
+	var mismatch *service.RevisionMismatchError
+	return errors.As(err, &mismatch)
+}
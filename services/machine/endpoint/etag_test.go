@@ -0,0 +1,200 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		wantOK       bool
+		wantExpected bool
+	}{
+		{name: "no header", header: "", wantOK: true, wantExpected: false},
+		{name: "matching tag", header: `"3"`, wantOK: true, wantExpected: true},
+		{name: "matching tag in list", header: `"1", "3"`, wantOK: true, wantExpected: true},
+		{name: "wildcard", header: "*", wantOK: true, wantExpected: true},
+		{name: "stale tag", header: `"2"`, wantOK: false},
+		{name: "weak tag never matches", header: `W/"3"`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			expected, ok := checkIfMatch(r, 3)
+			if ok != tt.wantOK {
+				t.Errorf("want ok %v, got %v", tt.wantOK, ok)
+			}
+			if (expected != nil) != tt.wantExpected {
+				t.Errorf("want expected revision set %v, got %v", tt.wantExpected, expected)
+			}
+			if expected != nil && *expected != 3 {
+				t.Errorf("want expected revision 3, got %d", *expected)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "no header", header: "", want: false},
+		{name: "matching tag", header: `"3"`, want: true},
+		{name: "weak matching tag", header: `W/"3"`, want: true},
+		{name: "wildcard", header: "*", want: true},
+		{name: "stale tag", header: `"2"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+			if got := notModified(r, 3); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMachinePreconditions(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	validBody, _ := proto.Marshal(&endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("aa:bb:cc:dd:ee:ff")}},
+	})
+	found := func() *service.GetMachineResponse {
+		return &service.GetMachineResponse{
+			Found:   true,
+			Machine: &service.Machine{ID: machineID, Revision: 3},
+		}
+	}
+	tracer := noop.NewTracerProvider().Tracer("")
+
+	tests := []struct {
+		name     string
+		method   string
+		header   string
+		value    string
+		handler  func(client *mockFirestoreClient) http.Handler
+		client   *mockFirestoreClient
+		wantCode int
+		wantETag string
+	}{
+		{
+			name:   "GET returns ETag",
+			method: http.MethodGet,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &getMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client:   &mockFirestoreClient{getResp: found()},
+			wantCode: http.StatusOK,
+			wantETag: `"3"`,
+		},
+		{
+			name:   "GET with current If-None-Match",
+			method: http.MethodGet,
+			header: "If-None-Match",
+			value:  `"3"`,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &getMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client:   &mockFirestoreClient{getResp: found()},
+			wantCode: http.StatusNotModified,
+			wantETag: `"3"`,
+		},
+		{
+			name:   "PUT with stale If-Match",
+			method: http.MethodPut,
+			header: "If-Match",
+			value:  `"2"`,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &updateMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client:   &mockFirestoreClient{getResp: found()},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "PUT losing a concurrent write",
+			method: http.MethodPut,
+			header: "If-Match",
+			value:  `"3"`,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &updateMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client: &mockFirestoreClient{
+				getResp:   found(),
+				updateErr: fmt.Errorf("failed to update machine document: %w", &service.RevisionMismatchError{MachineID: machineID, Expected: 3, Actual: 4}),
+			},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "PUT with current If-Match",
+			method: http.MethodPut,
+			header: "If-Match",
+			value:  `"3"`,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &updateMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client: &mockFirestoreClient{
+				getResp:    found(),
+				updateResp: &service.UpdateMachineResponse{Revision: 4},
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"4"`,
+		},
+		{
+			name:   "DELETE with stale If-Match",
+			method: http.MethodDelete,
+			header: "If-Match",
+			value:  `"2"`,
+			handler: func(c *mockFirestoreClient) http.Handler {
+				return &deleteMachineHandler{tracer: tracer, log: slog.Default(), firestoreClient: c}
+			},
+			client:   &mockFirestoreClient{getResp: found()},
+			wantCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/machines/"+machineID, bytes.NewReader(validBody))
+			r = withURLParam(r, "id", machineID)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			tt.handler(tt.client).ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantETag != "" && w.Header().Get("ETag") != tt.wantETag {
+				t.Errorf("want ETag %s, got %s", tt.wantETag, w.Header().Get("ETag"))
+			}
+			if tt.method == http.MethodPut && tt.client.updateReq != nil {
+				if rev := tt.client.updateReq.ExpectedRevision; rev == nil || *rev != 3 {
+					t.Errorf("want expected revision 3, got %v", rev)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type getInventorySummaryHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// GetInventorySummary registers the endpoint for fleet wide capacity totals.
+// The totals are maintained as machines are written, so the request costs a
+// single document read however large the fleet is.
+func GetInventorySummary(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &getInventorySummaryHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/inventory/summary", handler)
+}
+
+func (h *getInventorySummaryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	resp, err := h.firestoreClient.GetInventorySummary(ctx, &service.GetInventorySummaryRequest{})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get inventory summary: %v", err)))
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, convertInventorySummaryToProto(resp.Summary))
+}
+
+// convertInventorySummaryToProto keys media types and lifecycle states by
+// their API enum names, matching how machines report them.
+func convertInventorySummaryToProto(summary *service.InventorySummary) *endpointpb.InventorySummary {
+	storage := make(map[string]int64, len(summary.StorageByMediaType))
+	for mediaType, capacity := range summary.StorageByMediaType {
+		storage[protoEnum(driveMediaTypes, service.DriveMediaType(mediaType)).String()] += capacity
+	}
+
+	states := make(map[string]int64, len(summary.MachinesByLifecycleState))
+	for state, n := range summary.MachinesByLifecycleState {
+		states[protoEnum(lifecycleStates, service.LifecycleState(state)).String()] += n
+	}
+
+	labels := make(map[string]*endpointpb.LabelValueCounts, len(summary.MachinesByLabel))
+	for key, values := range summary.MachinesByLabel {
+		labels[key] = &endpointpb.LabelValueCounts{Machines: values}
+	}
+
+	result := &endpointpb.InventorySummary{
+		Machines:                   proto.Int64(summary.Machines),
+		Cores:                      proto.Int64(summary.Cores),
+		Memory:                     proto.Int64(summary.Memory),
+		StorageByMediaType:         storage,
+		AcceleratorsByManufacturer: summary.AcceleratorsByManufacturer,
+		MachinesByLifecycleState:   states,
+		MachinesByLabel:            labels,
+	}
+	if !summary.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(summary.UpdatedAt)
+	}
+	return result
+}
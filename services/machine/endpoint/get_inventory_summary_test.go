@@ -0,0 +1,104 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetInventorySummaryHandler_ServeHTTP(t *testing.T) {
+	summary := &service.InventorySummary{
+		Machines:                   3,
+		Cores:                      40,
+		Memory:                     192 << 30,
+		StorageByMediaType:         map[string]int64{"SSD": 2 << 40, "": 1 << 40},
+		AcceleratorsByManufacturer: map[string]int64{"NVIDIA": 2},
+		MachinesByLifecycleState:   map[string]int64{"new": 1, "in_service": 2},
+		MachinesByLabel:            map[string]map[string]int64{"rack": {"a": 2, "b": 1}},
+		UpdatedAt:                  time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name      string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "GetInventorySummary error",
+			client:   &mockFirestoreClient{summaryErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "empty inventory",
+			client:   &mockFirestoreClient{summaryResp: &service.GetInventorySummaryResponse{Summary: &service.InventorySummary{}}},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var got endpointpb.InventorySummary
+				if err := proto.Unmarshal(body, &got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if got.GetMachines() != 0 || got.UpdatedAt != nil {
+					t.Errorf("unexpected summary %v", &got)
+				}
+			},
+		},
+		{
+			name:     "success",
+			client:   &mockFirestoreClient{summaryResp: &service.GetInventorySummaryResponse{Summary: summary}},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var got endpointpb.InventorySummary
+				if err := proto.Unmarshal(body, &got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if got.GetMachines() != 3 || got.GetCores() != 40 || got.GetMemory() != 192<<30 {
+					t.Errorf("unexpected totals %v", &got)
+				}
+				storage := got.GetStorageByMediaType()
+				if storage["DRIVE_MEDIA_TYPE_SSD"] != 2<<40 || storage["DRIVE_MEDIA_TYPE_UNSPECIFIED"] != 1<<40 {
+					t.Errorf("unexpected storage %v", storage)
+				}
+				states := got.GetMachinesByLifecycleState()
+				if states["LIFECYCLE_STATE_IN_SERVICE"] != 2 || states["LIFECYCLE_STATE_NEW"] != 1 {
+					t.Errorf("unexpected lifecycle states %v", states)
+				}
+				if got.GetMachinesByLabel()["rack"].GetMachines()["a"] != 2 {
+					t.Errorf("unexpected labels %v", got.GetMachinesByLabel())
+				}
+				if !got.GetUpdatedAt().AsTime().Equal(summary.UpdatedAt) {
+					t.Errorf("want updated_at %v, got %v", summary.UpdatedAt, got.GetUpdatedAt().AsTime())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &getInventorySummaryHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/inventory/summary", nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type getMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func GetMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &getMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines/{id}", handler)
+}
+
+func (h *getMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient}
+	machine, err := machines.get(ctx, instance, machineID)
+	if err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", machineETag(machine.Revision))
+	if notModified(r, machine.Revision) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineToProto(machine))
+}
+
+func validateMachineID(id string) error {
+	return validateResourceID("machine", id)
+}
+
+// validateResourceID checks that id is a UUIDv7, which the service issues
+// for every kind of resource.
+func validateResourceID(kind, id string) error {
+	if id == "" {
+		return fmt.Errorf("%s ID cannot be empty", kind)
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid %s ID format, expected a UUIDv7", kind)
+	}
+	if parsed.Version() != 7 {
+		return fmt.Errorf("invalid %s ID version, expected a UUIDv7", kind)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type getMachineLabelsHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// GetMachineLabels registers the endpoint for reading a machine's labels. The
+// ETag is the machine's, so it can be used as If-Match when replacing them.
+func GetMachineLabels(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &getMachineLabelsHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines/{id}/labels", handler)
+}
+
+func (h *getMachineLabelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	resp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	if !resp.Found {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	w.Header().Set("ETag", machineETag(resp.Machine.Revision))
+	if notModified(r, resp.Machine.Revision) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.MachineLabels{
+		Labels: resp.Machine.Labels,
+	})
+}
@@ -0,0 +1,104 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetMachineLabelsHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found: true,
+		Machine: &service.Machine{
+			ID:       machineID,
+			Revision: 5,
+			Labels:   map[string]string{"rack": "a", "role": "storage"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		ifNoneMatch string
+		client      *mockFirestoreClient
+		wantCode    int
+		checkBody   func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GetMachine error",
+			id:       machineID,
+			client:   &mockFirestoreClient{getErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "machine not found",
+			id:       machineID,
+			client:   &mockFirestoreClient{getResp: &service.GetMachineResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:        "not modified",
+			id:          machineID,
+			ifNoneMatch: `"5"`,
+			client:      &mockFirestoreClient{getResp: found},
+			wantCode:    http.StatusNotModified,
+		},
+		{
+			name:     "success",
+			id:       machineID,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var labels endpointpb.MachineLabels
+				if err := proto.Unmarshal(body, &labels); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(labels.GetLabels()) != 2 || labels.GetLabels()["rack"] != "a" {
+					t.Errorf("unexpected labels %v", labels.GetLabels())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &getMachineLabelsHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/machines/"+tt.id+"/labels", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("ETag"); tt.wantCode < 400 && got != `"5"` {
+				t.Errorf("want ETag %q, got %q", `"5"`, got)
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
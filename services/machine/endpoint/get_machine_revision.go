@@ -0,0 +1,67 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type getMachineRevisionHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// GetMachineRevision registers the endpoint for reading a single superseded
+// version of a machine. Revisions never change once written.
+func GetMachineRevision(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &getMachineRevisionHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines/{id}/revisions/{revision}", handler)
+}
+
+func (h *getMachineRevisionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	var v fieldValidator
+	if err := validateMachineID(machineID); err != nil {
+		v.add("id", err.Error())
+	}
+	revision, err := strconv.ParseInt(chi.URLParam(r, "revision"), 10, 64)
+	if err != nil || revision < 1 {
+		v.add("revision", "must be a positive integer")
+	}
+	if len(v.invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, v.invalidFields))
+		return
+	}
+
+	resp, err := h.firestoreClient.GetMachineRevision(ctx, &service.GetMachineRevisionRequest{
+		MachineID: machineID,
+		Revision:  revision,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine revision: %v", err)))
+		return
+	}
+	if !resp.Found {
+		errorHandler(ctx, w, errorpb.NewRevisionNotFoundError(instance, machineID, revision))
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineRevisionToProto(machineID, resp.Revision))
+}
@@ -0,0 +1,89 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetMachineRevisionHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+
+	tests := []struct {
+		name      string
+		path      string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			path:     "/api/v1/machines/not-a-uuid/revisions/1",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid revision",
+			path:     "/api/v1/machines/" + machineID + "/revisions/0",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GetMachineRevision error",
+			path:     "/api/v1/machines/" + machineID + "/revisions/1",
+			client:   &mockFirestoreClient{revisionErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "revision not found",
+			path:     "/api/v1/machines/" + machineID + "/revisions/9",
+			client:   &mockFirestoreClient{revisionResp: &service.GetMachineRevisionResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "success",
+			path: "/api/v1/machines/" + machineID + "/revisions/2",
+			client: &mockFirestoreClient{revisionResp: &service.GetMachineRevisionResponse{
+				Found: true,
+				Revision: &service.MachineRevision{
+					Machine:   service.Machine{ID: machineID, Revision: 2, Labels: map[string]string{"rack": "a"}},
+					ChangedBy: "admin@example.com",
+				},
+			}},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var revision endpointpb.MachineRevision
+				if err := proto.Unmarshal(body, &revision); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if revision.GetRevision() != 2 || revision.GetMachine().GetLabels()["rack"] != "a" {
+					t.Errorf("unexpected revision %v", &revision)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			GetMachineRevision(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func withURLParam(r *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestValidateMachineID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{
+			name:    "valid UUIDv7",
+			id:      "018c7dbd-c000-7000-8000-fedcba987654",
+			wantErr: false,
+		},
+		{
+			name:    "empty ID",
+			id:      "",
+			wantErr: true,
+		},
+		{
+			name:    "not a UUID",
+			id:      "not-a-uuid",
+			wantErr: true,
+		},
+		{
+			name:    "UUIDv4",
+			id:      "9b2c8f5e-3d4a-4f6b-8c7d-1e2f3a4b5c6d",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMachineID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMachineID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+
+	tests := []struct {
+		name      string
+		id        string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "id" {
+					t.Errorf("expected invalid field 'id', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name:     "GetMachine error",
+			id:       machineID,
+			client:   &mockFirestoreClient{getErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "machine not found",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{Found: false},
+			},
+			wantCode: http.StatusNotFound,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.MachineNotFoundProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if p.GetMachineId() != machineID {
+					t.Errorf("want machine_id %q, got %q", machineID, p.GetMachineId())
+				}
+			},
+		},
+		{
+			name: "success",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{
+					Found: true,
+					Machine: &service.Machine{
+						ID: machineID,
+						MachineRequest: service.MachineRequest{
+							CPUs: []service.CPU{{Manufacturer: "Intel", ClockFrequency: 2400000000, Cores: 8}},
+							NICs: []service.NIC{{MAC: "52:54:00:12:34:56"}},
+						},
+					},
+				},
+			},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if m.GetId() != machineID {
+					t.Errorf("want id %q, got %q", machineID, m.GetId())
+				}
+				if len(m.GetCpus()) != 1 || m.GetCpus()[0].GetCores() != 8 {
+					t.Errorf("unexpected cpus: %v", m.GetCpus())
+				}
+				if len(m.GetNics()) != 1 || m.GetNics()[0].GetMac() != "52:54:00:12:34:56" {
+					t.Errorf("unexpected nics: %v", m.GetNics())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &getMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/machines/"+tt.id, nil)
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
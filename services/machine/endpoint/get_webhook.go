@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type getWebhookHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func GetWebhook(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &getWebhookHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/webhooks/{id}", handler)
+}
+
+func (h *getWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	webhookID := chi.URLParam(r, "id")
+	if err := validateWebhookID(webhookID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	resp, err := h.firestoreClient.GetWebhook(ctx, &service.GetWebhookRequest{
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get webhook: %v", err)))
+		return
+	}
+	if !resp.Found {
+		errorHandler(ctx, w, errorpb.NewWebhookNotFoundError(instance, webhookID))
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, convertWebhookToProto(resp.Webhook))
+}
+
+func validateWebhookID(id string) error {
+	return validateResourceID("webhook", id)
+}
@@ -0,0 +1,80 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetWebhookHandler_ServeHTTP(t *testing.T) {
+	webhookID := "018c7dbd-c000-7000-8000-0000000000f1"
+
+	tests := []struct {
+		name      string
+		id        string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid webhook ID",
+			id:       "not-a-uuid",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GetWebhook error",
+			id:       webhookID,
+			client:   &mockFirestoreClient{webhookGetErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "not found",
+			id:       webhookID,
+			client:   &mockFirestoreClient{webhookGetResp: &service.GetWebhookResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "success",
+			id:   webhookID,
+			client: &mockFirestoreClient{webhookGetResp: &service.GetWebhookResponse{
+				Found:   true,
+				Webhook: &service.Webhook{ID: webhookID, URL: "https://hooks.example.com", Secret: "s3cret", LabelSelector: "rack=r1"},
+			}},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.Webhook
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.GetId() != webhookID || resp.GetLabelSelector() != "rack=r1" || resp.Secret != nil {
+					t.Errorf("unexpected webhook %v", &resp)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			GetWebhook(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// newIdempotencyRecord starts a record for a request made with key. The request
+// hash is taken over the decoded message so a retry matches regardless of the
+// encoding it was sent in.
+func newIdempotencyRecord(key string, req *endpointpb.RegisterMachineRequest, ttl time.Duration) (*service.IdempotencyRecord, error) {
+	if key == "" {
+		return nil, fmt.Errorf("idempotency key cannot be empty")
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("idempotency key cannot be longer than %d characters", maxIdempotencyKeyLength)
+	}
+
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash request: %w", err)
+	}
+	sum := sha256.Sum256(b)
+
+	return &service.IdempotencyRecord{
+		Key:         key,
+		RequestHash: hex.EncodeToString(sum[:]),
+		ExpiresAt:   time.Now().Add(ttl),
+	}, nil
+}
+
+// replayIdempotentResponse returns the response stored in existing for a
+// retried request, as long as the retry carries the same body as the
+// original.
+func replayIdempotentResponse(instance string, record, existing *service.IdempotencyRecord) (*endpointpb.RegisterMachineResponse, error) {
+	if existing.RequestHash != record.RequestHash {
+		return nil, errorpb.NewIdempotencyKeyMismatchError(instance, record.Key)
+	}
+
+	var resp endpointpb.RegisterMachineResponse
+	if err := proto.Unmarshal(existing.Response, &resp); err != nil {
+		return nil, errorpb.NewInternalError(instance, fmt.Sprintf("failed to decode stored response: %v", err))
+	}
+	return &resp, nil
+}
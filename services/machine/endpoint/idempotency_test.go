@@ -0,0 +1,178 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRegisterMachinesHandler_IdempotencyKey(t *testing.T) {
+	req := &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("aa:bb:cc:dd:ee:ff")}},
+	}
+	body, _ := proto.Marshal(req)
+
+	record, err := newIdempotencyRecord("key-1", req, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := proto.Marshal(&endpointpb.RegisterMachineResponse{MachineId: proto.String("stored-id")})
+	existing := &service.IdempotencyRecord{
+		Key:         "key-1",
+		RequestHash: record.RequestHash,
+		Response:    stored,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	mismatched := &service.IdempotencyRecord{
+		Key:         "key-1",
+		RequestHash: "other",
+		Response:    stored,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name         string
+		key          string
+		client       *mockFirestoreClient
+		wantCode     int
+		wantReplayed bool
+		check        func(t *testing.T, client *mockFirestoreClient, body []byte)
+	}{
+		{
+			name:     "first request stores response",
+			key:      "key-1",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusCreated,
+			check: func(t *testing.T, client *mockFirestoreClient, body []byte) {
+				rec := client.createReq.IdempotencyRecord
+				if rec == nil {
+					t.Fatal("expected idempotency record to be stored")
+				}
+				if rec.Key != "key-1" || rec.RequestHash != record.RequestHash {
+					t.Errorf("unexpected record %+v", rec)
+				}
+				if !bytes.Equal(rec.Response, body) {
+					t.Error("expected stored response to match the response body")
+				}
+			},
+		},
+		{
+			name: "replay with same body",
+			key:  "key-1",
+			client: &mockFirestoreClient{
+				idemResp: &service.GetIdempotencyRecordResponse{Record: existing, Found: true},
+			},
+			wantCode:     http.StatusCreated,
+			wantReplayed: true,
+			check: func(t *testing.T, client *mockFirestoreClient, body []byte) {
+				if client.createReq != nil {
+					t.Error("expected CreateMachine not to be called on replay")
+				}
+				var resp endpointpb.RegisterMachineResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.GetMachineId() != "stored-id" {
+					t.Errorf("want machine_id 'stored-id', got %q", resp.GetMachineId())
+				}
+			},
+		},
+		{
+			name: "replay with different body",
+			key:  "key-1",
+			client: &mockFirestoreClient{
+				idemResp: &service.GetIdempotencyRecordResponse{Record: mismatched, Found: true},
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			check: func(t *testing.T, _ *mockFirestoreClient, body []byte) {
+				var p errorpb.IdempotencyKeyMismatchProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if p.GetIdempotencyKey() != "key-1" {
+					t.Errorf("want idempotency_key 'key-1', got %q", p.GetIdempotencyKey())
+				}
+			},
+		},
+		{
+			name: "concurrent request won the race",
+			key:  "key-1",
+			client: &mockFirestoreClient{
+				createErr: fmt.Errorf("failed to create machine document: %w", &service.IdempotencyKeyExistsError{Record: existing}),
+			},
+			wantCode:     http.StatusCreated,
+			wantReplayed: true,
+		},
+		{
+			name:     "key too long",
+			key:      strings.Repeat("k", maxIdempotencyKeyLength+1),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "lookup error",
+			key:  "key-1",
+			client: &mockFirestoreClient{
+				idemErr: fmt.Errorf("read failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &registerMachinesHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+				idempotencyTTL:  time.Hour,
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines", bytes.NewReader(body))
+			r.Header.Set(idempotencyKeyHeader, tt.key)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if replayed := w.Header().Get(idempotentReplayedHeader) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("want replayed %v, got %v", tt.wantReplayed, replayed)
+			}
+			if tt.check != nil {
+				tt.check(t, tt.client, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestNewIdempotencyRecord_RequestHash(t *testing.T) {
+	a := &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("aa:bb:cc:dd:ee:ff")}},
+	}
+	b := proto.Clone(a).(*endpointpb.RegisterMachineRequest)
+	c := &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("02:bb:cc:dd:ee:ff")}},
+	}
+
+	ra, _ := newIdempotencyRecord("k", a, time.Hour)
+	rb, _ := newIdempotencyRecord("k", b, time.Hour)
+	rc, _ := newIdempotencyRecord("k", c, time.Hour)
+	if ra.RequestHash != rb.RequestHash {
+		t.Error("expected equal requests to hash the same")
+	}
+	if ra.RequestHash == rc.RequestHash {
+		t.Error("expected different requests to hash differently")
+	}
+}
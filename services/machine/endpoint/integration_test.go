@@ -0,0 +1,219 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestIntegration drives the public HTTP handlers against real storage
+// backends. The Firestore backend only runs when FIRESTORE_EMULATOR_HOST
+// points at a running emulator.
+func TestIntegration(t *testing.T) {
+	backends := map[string]func(t *testing.T) FirestoreClient{
+		"memory": func(t *testing.T) FirestoreClient {
+			return service.NewMemoryStore()
+		},
+		"sqlite": func(t *testing.T) FirestoreClient {
+			s, err := service.NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "machines.db"))
+			if err != nil {
+				t.Fatalf("failed to open sqlite store: %v", err)
+			}
+			return s
+		},
+		"firestore": func(t *testing.T) FirestoreClient {
+			host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+			if host == "" {
+				t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+			}
+			const projectID = "machine-endpoint-test"
+			if err := resetEmulator(host, projectID); err != nil {
+				t.Fatalf("failed to reset firestore emulator: %v", err)
+			}
+			c, err := service.NewFirestoreClient(context.Background(), projectID)
+			if err != nil {
+				t.Fatalf("failed to create firestore client: %v", err)
+			}
+			return c
+		},
+	}
+
+	for name, newClient := range backends {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t)
+			t.Cleanup(func() { client.Close() })
+
+			mux := chi.NewRouter()
+			mux.Use(ContentNegotiation)
+			RegisterMachines(mux, client, time.Hour)
+			BatchCreateMachines(mux, client)
+			ListMachines(mux, client)
+			GetMachine(mux, client)
+			testIntegration(t, mux)
+		})
+	}
+}
+
+func testIntegration(t *testing.T, mux http.Handler) {
+	do := func(t *testing.T, method, target string, msg proto.Message) *httptest.ResponseRecorder {
+		t.Helper()
+		var body []byte
+		if msg != nil {
+			var err error
+			if body, err = proto.Marshal(msg); err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+		}
+		r := httptest.NewRequest(method, target, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	register := func(macs ...string) *endpointpb.RegisterMachineRequest {
+		req := &endpointpb.RegisterMachineRequest{}
+		for _, mac := range macs {
+			req.Nics = append(req.Nics, &endpointpb.NIC{Mac: proto.String(mac)})
+		}
+		return req
+	}
+
+	var machineID string
+	t.Run("create", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/machines", register("52:54:00:12:34:56"))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusCreated, w.Code, w.Body.String())
+		}
+		var resp endpointpb.RegisterMachineResponse
+		if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		machineID = resp.GetMachineId()
+
+		w = do(t, http.MethodGet, "/api/v1/machines/"+machineID, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("lookup by MAC in any notation", func(t *testing.T) {
+		for _, mac := range []string{"52:54:00:12:34:56", "52-54-00-12-34-56", "5254.0012.3456", "525400123456"} {
+			w := do(t, http.MethodGet, "/api/v1/machines?mac="+mac, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+			}
+			var resp endpointpb.ListMachinesResponse
+			if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(resp.GetMachines()) != 1 || resp.GetMachines()[0].GetId() != machineID {
+				t.Errorf("mac=%s: want machine %s, got %v", mac, machineID, resp.GetMachines())
+			}
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/machines", register("52:54:00:AA:BB:CC", "52:54:00:12:34:56"))
+		if w.Code != http.StatusConflict {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusConflict, w.Code, w.Body.String())
+		}
+		var p errorpb.ConflictProblem
+		if err := proto.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("failed to decode problem: %v", err)
+		}
+		if p.GetExistingResourceId() != machineID {
+			t.Errorf("want existing_resource_id %s, got %s", machineID, p.GetExistingResourceId())
+		}
+
+		// The rejected registration must not have claimed its other MAC.
+		w = do(t, http.MethodPost, "/api/v1/machines", register("52:54:00:aa:bb:cc"))
+		if w.Code != http.StatusCreated {
+			t.Errorf("want status %d, got %d (body: %s)", http.StatusCreated, w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("atomic batch", func(t *testing.T) {
+		w := do(t, http.MethodPost, "/api/v1/machines:batchCreate", &endpointpb.BatchCreateMachinesRequest{
+			Requests: []*endpointpb.RegisterMachineRequest{
+				register("52:54:00:ba:00:01"),
+				register("52:54:00:12:34:56"),
+			},
+			Atomic: proto.Bool(true),
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+		}
+		var resp endpointpb.BatchCreateMachinesResponse
+		if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.GetResults()[1].GetConflictProblem().GetExistingResourceId() != machineID {
+			t.Errorf("expected results[1] to conflict with %s, got %v", machineID, resp.GetResults()[1])
+		}
+
+		w = do(t, http.MethodGet, "/api/v1/machines?mac=52:54:00:ba:00:01", nil)
+		var list endpointpb.ListMachinesResponse
+		if err := proto.Unmarshal(w.Body.Bytes(), &list); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(list.GetMachines()) != 0 {
+			t.Errorf("expected the aborted batch to create nothing, got %v", list.GetMachines())
+		}
+	})
+
+	t.Run("concurrent registrations", func(t *testing.T) {
+		const n = 4
+		codes := make([]int, n)
+
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Go(func() {
+				codes[i] = do(t, http.MethodPost, "/api/v1/machines", register("52:54:00:de:ad:01")).Code
+			})
+		}
+		wg.Wait()
+
+		var created int
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				created++
+			}
+		}
+		if created != 1 {
+			t.Errorf("want exactly one registration to succeed, got status codes %v", codes)
+		}
+	})
+}
+
+// resetEmulator deletes every document in the emulator project so the suite
+// starts from an empty database.
+func resetEmulator(host, projectID string) error {
+	url := fmt.Sprintf("http://%s/emulator/v1/projects/%s/databases/(default)/documents", host, projectID)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type listMachineRevisionsHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// ListMachineRevisions registers the endpoint for paging through a machine's
+// superseded versions, newest first. Deleted machines keep their history
+// until they are purged.
+func ListMachineRevisions(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &listMachineRevisionsHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines/{id}/revisions", handler)
+}
+
+func (h *listMachineRevisionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	listReq, invalidFields := parseListMachineRevisionsQuery(r.URL.Query())
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+	listReq.MachineID = machineID
+
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID:      machineID,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	resp, err := h.firestoreClient.ListMachineRevisions(ctx, listReq)
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list machine revisions: %v", err)))
+		return
+	}
+
+	revisions := make([]*endpointpb.MachineRevision, len(resp.Revisions))
+	for i, revision := range resp.Revisions {
+		revisions[i] = convertMachineRevisionToProto(machineID, revision)
+	}
+
+	var nextPageToken string
+	if resp.NextPageToken != 0 {
+		nextPageToken = strconv.FormatInt(resp.NextPageToken, 10)
+	}
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.ListMachineRevisionsResponse{
+		Revisions: revisions,
+		Pagination: &endpointpb.Pagination{
+			PerPage:       proto.Int32(int32(listReq.PageSize)),
+			NextPageToken: proto.String(nextPageToken),
+		},
+	})
+}
+
+func parseListMachineRevisionsQuery(query url.Values) (*service.ListMachineRevisionsRequest, []*errorpb.InvalidField) {
+	req := &service.ListMachineRevisionsRequest{
+		PageSize: defaultPerPage,
+	}
+
+	var v fieldValidator
+	if perPage := query.Get("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 || n > maxPerPage {
+			v.add("per_page", fmt.Sprintf("must be an integer between 1 and %d", maxPerPage))
+		}
+		req.PageSize = n
+	}
+
+	if pageToken := query.Get("page_token"); pageToken != "" {
+		n, err := strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || n < 1 {
+			v.add("page_token", "invalid page token")
+		}
+		req.PageToken = n
+	}
+
+	return req, v.invalidFields
+}
+
+// convertMachineRevisionToProto fills in the machine ID, which machines
+// written before IDs were stored in their documents lack.
+func convertMachineRevisionToProto(machineID string, revision *service.MachineRevision) *endpointpb.MachineRevision {
+	machine := revision.Machine
+	machine.ID = machineID
+
+	changes := make([]*endpointpb.FieldChange, len(revision.Changes))
+	for i, change := range revision.Changes {
+		changes[i] = &endpointpb.FieldChange{
+			Path:   proto.String(change.Path),
+			Before: change.Before,
+			After:  change.After,
+		}
+	}
+
+	return &endpointpb.MachineRevision{
+		Revision:  proto.Int64(machine.Revision),
+		Machine:   convertMachineToProto(&machine),
+		ChangedAt: timestamppb.New(revision.ChangedAt),
+		ChangedBy: proto.String(revision.ChangedBy),
+		Changes:   changes,
+	}
+}
@@ -0,0 +1,128 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListMachineRevisionsHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID, Revision: 4},
+	}
+	before, after := "8", "16"
+	revisions := &service.ListMachineRevisionsResponse{
+		Revisions: []*service.MachineRevision{
+			{
+				Machine:   service.Machine{Revision: 3, MachineRequest: service.MachineRequest{CPUs: []service.CPU{{Cores: 8}}}},
+				ChangedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+				ChangedBy: "admin@example.com",
+				Changes:   []service.FieldChange{{Path: "cpus[0].cores", Before: &before, After: &after}},
+			},
+		},
+		NextPageToken: 3,
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid per_page",
+			query:    "?per_page=0",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid page_token",
+			query:    "?page_token=abc",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "machine not found",
+			client:   &mockFirestoreClient{getResp: &service.GetMachineResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "ListMachineRevisions error",
+			client:   &mockFirestoreClient{getResp: found, revisionsErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			query:    "?per_page=1&page_token=4",
+			client:   &mockFirestoreClient{getResp: found, revisionsResp: revisions},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.ListMachineRevisionsResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp.GetRevisions()) != 1 || resp.GetPagination().GetNextPageToken() != "3" {
+					t.Fatalf("unexpected response %v", &resp)
+				}
+				revision := resp.GetRevisions()[0]
+				if revision.GetRevision() != 3 || revision.GetMachine().GetId() != machineID || revision.GetChangedBy() != "admin@example.com" {
+					t.Errorf("unexpected revision %v", revision)
+				}
+				change := revision.GetChanges()[0]
+				if change.GetPath() != "cpus[0].cores" || change.GetBefore() != "8" || change.GetAfter() != "16" {
+					t.Errorf("unexpected change %v", change)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			ListMachineRevisions(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/machines/"+machineID+"/revisions"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestListMachineRevisionsHandler_PassesPaging(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	client := &mockFirestoreClient{
+		getResp:       &service.GetMachineResponse{Found: true, Machine: &service.Machine{ID: machineID}},
+		revisionsResp: &service.ListMachineRevisionsResponse{},
+	}
+
+	mux := chi.NewRouter()
+	ListMachineRevisions(mux, client)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/machines/"+machineID+"/revisions?per_page=5&page_token=12", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d (body: %s)", w.Code, w.Body.String())
+	}
+	if got := client.revisionsReq; got.MachineID != machineID || got.PageSize != 5 || got.PageToken != 12 {
+		t.Errorf("unexpected request %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+type listMachinesHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func ListMachines(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &listMachinesHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines", handler)
+}
+
+func (h *listMachinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	listReq, invalidFields := parseListMachinesQuery(r.URL.Query())
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError("/api/v1/machines", invalidFields))
+		return
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient}
+	resp, err := machines.list(ctx, "/api/v1/machines", listReq)
+	if err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, "/api/v1/machines", http.StatusOK, resp)
+}
+
+func parseListMachinesQuery(query url.Values) (*service.ListMachinesRequest, []*errorpb.InvalidField) {
+	var v fieldValidator
+	req := &endpointpb.ListMachinesRequest{
+		PageToken:     proto.String(query.Get("page_token")),
+		Mac:           proto.String(query.Get("mac")),
+		LabelSelector: proto.String(query.Get("label_selector")),
+	}
+	if perPage := query.Get("per_page"); perPage != "" {
+		n, err := strconv.ParseInt(perPage, 10, 32)
+		if err != nil {
+			v.add("per_page", fmt.Sprintf("must be an integer between 1 and %d", maxPerPage))
+		} else {
+			req.PerPage = proto.Int32(int32(n))
+		}
+	}
+
+	listReq, invalidFields := convertListMachinesRequest(req)
+	return listReq, append(v.invalidFields, invalidFields...)
+}
+
+// convertListMachinesRequest validates the parameters of a machine listing,
+// however they were sent.
+func convertListMachinesRequest(req *endpointpb.ListMachinesRequest) (*service.ListMachinesRequest, []*errorpb.InvalidField) {
+	var v fieldValidator
+	listReq := &service.ListMachinesRequest{
+		PageSize: defaultPerPage,
+	}
+
+	if req.PerPage != nil {
+		n := int(req.GetPerPage())
+		if n < 1 || n > maxPerPage {
+			v.add("per_page", fmt.Sprintf("must be an integer between 1 and %d", maxPerPage))
+		}
+		listReq.PageSize = n
+	}
+
+	if pageToken := req.GetPageToken(); pageToken != "" {
+		if err := validateMachineID(pageToken); err != nil {
+			v.add("page_token", "invalid page token")
+		}
+		listReq.PageToken = pageToken
+	}
+
+	if mac := req.GetMac(); mac != "" {
+		parsed, err := service.ParseMAC(mac)
+		if err != nil {
+			v.add("mac", err.Error())
+		}
+		listReq.MAC = parsed
+	}
+
+	if labelSelector := req.GetLabelSelector(); labelSelector != "" {
+		selector, err := service.ParseSelector(labelSelector)
+		if err != nil {
+			v.add("label_selector", err.Error())
+		}
+		listReq.LabelSelector = selector
+	}
+
+	return listReq, v.invalidFields
+}
@@ -0,0 +1,186 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListMachinesHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+
+	tests := []struct {
+		name      string
+		query     string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkReq  func(t *testing.T, req *service.ListMachinesRequest)
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "per_page out of range",
+			query:    "?per_page=101",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "per_page" {
+					t.Errorf("expected invalid field 'per_page', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name:     "per_page not a number",
+			query:    "?per_page=ten",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid page token",
+			query:    "?page_token=abc",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "page_token" {
+					t.Errorf("expected invalid field 'page_token', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name:     "invalid mac filter",
+			query:    "?mac=not-a-mac",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid label selector",
+			query:    "?label_selector=" + url.QueryEscape("role in (a,b"),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "label_selector" {
+					t.Errorf("expected invalid field 'label_selector', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name:  "label selector",
+			query: "?label_selector=" + url.QueryEscape("env=homelab,role notin (gpu),!spare"),
+			client: &mockFirestoreClient{
+				listResp: &service.ListMachinesResponse{},
+			},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.ListMachinesRequest) {
+				if got := req.LabelSelector.String(); got != "env=homelab,role notin (gpu),!spare" {
+					t.Errorf("unexpected label selector %q", got)
+				}
+			},
+		},
+		{
+			name:     "ListMachines error",
+			client:   &mockFirestoreClient{listErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "defaults",
+			query: "",
+			client: &mockFirestoreClient{
+				listResp: &service.ListMachinesResponse{},
+			},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.ListMachinesRequest) {
+				if req.PageSize != defaultPerPage {
+					t.Errorf("want page size %d, got %d", defaultPerPage, req.PageSize)
+				}
+			},
+		},
+		{
+			name:  "success with filters",
+			query: "?per_page=1&page_token=" + machineID + "&mac=52:54:00:12:34:56",
+			client: &mockFirestoreClient{
+				listResp: &service.ListMachinesResponse{
+					Machines: []*service.Machine{
+						{
+							ID: "018c7dbd-c001-7000-8000-fedcba987654",
+							MachineRequest: service.MachineRequest{
+								NICs: []service.NIC{{MAC: "52:54:00:12:34:56"}},
+							},
+						},
+					},
+					NextPageToken: "018c7dbd-c001-7000-8000-fedcba987654",
+				},
+			},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.ListMachinesRequest) {
+				if req.PageSize != 1 {
+					t.Errorf("want page size 1, got %d", req.PageSize)
+				}
+				if req.PageToken != machineID {
+					t.Errorf("want page token %q, got %q", machineID, req.PageToken)
+				}
+				if req.MAC != "52:54:00:12:34:56" {
+					t.Errorf("want mac filter, got %q", req.MAC)
+				}
+			},
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.ListMachinesResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp.GetMachines()) != 1 {
+					t.Fatalf("want 1 machine, got %d", len(resp.GetMachines()))
+				}
+				if resp.GetPagination().GetPerPage() != 1 {
+					t.Errorf("want per_page 1, got %d", resp.GetPagination().GetPerPage())
+				}
+				if resp.GetPagination().GetNextPageToken() != "018c7dbd-c001-7000-8000-fedcba987654" {
+					t.Errorf("unexpected next_page_token %q", resp.GetPagination().GetNextPageToken())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &listMachinesHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/machines"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkReq != nil {
+				tt.checkReq(t, tt.client.listReq)
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
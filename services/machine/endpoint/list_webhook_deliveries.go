@@ -0,0 +1,149 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var deliveryStatuses = map[service.DeliveryStatus]endpointpb.DeliveryStatus{
+	service.DeliveryPending:   endpointpb.DeliveryStatus_DELIVERY_STATUS_PENDING,
+	service.DeliverySucceeded: endpointpb.DeliveryStatus_DELIVERY_STATUS_SUCCEEDED,
+	service.DeliveryFailed:    endpointpb.DeliveryStatus_DELIVERY_STATUS_FAILED,
+}
+
+type listWebhookDeliveriesHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// ListWebhookDeliveries registers the endpoint for paging through a
+// webhook's delivery log, newest first.
+func ListWebhookDeliveries(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &listWebhookDeliveriesHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/webhooks/{id}/deliveries", handler)
+}
+
+func (h *listWebhookDeliveriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	webhookID := chi.URLParam(r, "id")
+	if err := validateWebhookID(webhookID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	listReq, invalidFields := parseListWebhookDeliveriesQuery(r.URL.Query())
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+	listReq.WebhookID = webhookID
+
+	getResp, err := h.firestoreClient.GetWebhook(ctx, &service.GetWebhookRequest{
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get webhook: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewWebhookNotFoundError(instance, webhookID))
+		return
+	}
+
+	resp, err := h.firestoreClient.ListWebhookDeliveries(ctx, listReq)
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list webhook deliveries: %v", err)))
+		return
+	}
+
+	deliveries := make([]*endpointpb.WebhookDelivery, len(resp.Deliveries))
+	for i, delivery := range resp.Deliveries {
+		deliveries[i] = convertWebhookDeliveryToProto(delivery)
+	}
+
+	var nextPageToken string
+	if resp.NextPageToken != 0 {
+		nextPageToken = strconv.FormatInt(resp.NextPageToken, 10)
+	}
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Pagination: &endpointpb.Pagination{
+			PerPage:       proto.Int32(int32(listReq.PageSize)),
+			NextPageToken: proto.String(nextPageToken),
+		},
+	})
+}
+
+func parseListWebhookDeliveriesQuery(query url.Values) (*service.ListWebhookDeliveriesRequest, []*errorpb.InvalidField) {
+	req := &service.ListWebhookDeliveriesRequest{
+		PageSize: defaultPerPage,
+	}
+
+	var v fieldValidator
+	if perPage := query.Get("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 || n > maxPerPage {
+			v.add("per_page", fmt.Sprintf("must be an integer between 1 and %d", maxPerPage))
+		}
+		req.PageSize = n
+	}
+
+	if pageToken := query.Get("page_token"); pageToken != "" {
+		n, err := strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || n < 1 {
+			v.add("page_token", "invalid page token")
+		}
+		req.PageToken = n
+	}
+
+	return req, v.invalidFields
+}
+
+func convertWebhookDeliveryToProto(delivery *service.WebhookDelivery) *endpointpb.WebhookDelivery {
+	pb := &endpointpb.WebhookDelivery{
+		WebhookId: proto.String(delivery.WebhookID),
+		Sequence:  proto.Int64(delivery.Sequence),
+		Type:      machineEventTypes[delivery.EventType].Enum(),
+		MachineId: proto.String(delivery.MachineID),
+		Status:    deliveryStatuses[delivery.Status].Enum(),
+		Attempts:  proto.Int32(int32(delivery.Attempts)),
+		CreatedAt: timestamppb.New(delivery.CreatedAt),
+		GiveUpAt:  timestamppb.New(delivery.GiveUpAt),
+	}
+	if delivery.NextAttemptAt != nil {
+		pb.NextAttemptAt = timestamppb.New(*delivery.NextAttemptAt)
+	}
+	if delivery.LastAttemptAt != nil {
+		pb.LastAttemptAt = timestamppb.New(*delivery.LastAttemptAt)
+	}
+	if delivery.LastStatusCode != 0 {
+		pb.LastStatusCode = proto.Int32(int32(delivery.LastStatusCode))
+	}
+	if delivery.LastError != "" {
+		pb.LastError = proto.String(delivery.LastError)
+	}
+	return pb
+}
@@ -0,0 +1,114 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListWebhookDeliveriesHandler_ServeHTTP(t *testing.T) {
+	webhookID := "018c7dbd-c000-7000-8000-0000000000f1"
+	found := &service.GetWebhookResponse{
+		Found:   true,
+		Webhook: &service.Webhook{ID: webhookID},
+	}
+	attemptedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	deliveries := &service.ListWebhookDeliveriesResponse{
+		Deliveries: []*service.WebhookDelivery{
+			{
+				WebhookID:      webhookID,
+				Sequence:       42,
+				EventType:      service.ChangeStateChanged,
+				MachineID:      "018c7dbd-c000-7000-8000-fedcba987654",
+				Status:         service.DeliveryFailed,
+				Attempts:       7,
+				LastAttemptAt:  &attemptedAt,
+				LastStatusCode: http.StatusServiceUnavailable,
+				LastError:      "503 Service Unavailable",
+			},
+		},
+		NextPageToken: 42,
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid per_page",
+			query:    "?per_page=101",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "webhook not found",
+			client:   &mockFirestoreClient{webhookGetResp: &service.GetWebhookResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "ListWebhookDeliveries error",
+			client:   &mockFirestoreClient{webhookGetResp: found, deliveriesErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			query:    "?per_page=1&page_token=43",
+			client:   &mockFirestoreClient{webhookGetResp: found, deliveriesResp: deliveries},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.ListWebhookDeliveriesResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp.GetDeliveries()) != 1 || resp.GetPagination().GetNextPageToken() != "42" {
+					t.Fatalf("unexpected response %v", &resp)
+				}
+				delivery := resp.GetDeliveries()[0]
+				if delivery.GetSequence() != 42 ||
+					delivery.GetType() != endpointpb.MachineEventType_MACHINE_EVENT_TYPE_STATE_CHANGED ||
+					delivery.GetStatus() != endpointpb.DeliveryStatus_DELIVERY_STATUS_FAILED ||
+					delivery.GetAttempts() != 7 ||
+					delivery.GetLastStatusCode() != http.StatusServiceUnavailable ||
+					!delivery.GetLastAttemptAt().AsTime().Equal(attemptedAt) {
+					t.Errorf("unexpected delivery %v", delivery)
+				}
+				if delivery.NextAttemptAt != nil {
+					t.Errorf("expected a failed delivery to have no next attempt, got %v", delivery.NextAttemptAt)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			ListWebhookDeliveries(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+webhookID+"/deliveries"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				if req := tt.client.deliveriesReq; req.WebhookID != webhookID || req.PageSize != 1 || req.PageToken != 43 {
+					t.Errorf("unexpected list request %+v", req)
+				}
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
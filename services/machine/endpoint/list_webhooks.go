@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type listWebhooksHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// ListWebhooks registers the endpoint for listing every webhook. There are
+// few enough that the list is not paginated.
+func ListWebhooks(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &listWebhooksHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/webhooks", handler)
+}
+
+func (h *listWebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	resp, err := h.firestoreClient.ListWebhooks(ctx, &service.ListWebhooksRequest{})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list webhooks: %v", err)))
+		return
+	}
+
+	webhooks := make([]*endpointpb.Webhook, len(resp.Webhooks))
+	for i, webhook := range resp.Webhooks {
+		webhooks[i] = convertWebhookToProto(webhook)
+	}
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.ListWebhooksResponse{
+		Webhooks: webhooks,
+	})
+}
@@ -0,0 +1,71 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestListWebhooksHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "ListWebhooks error",
+			client:   &mockFirestoreClient{webhooksErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success",
+			client: &mockFirestoreClient{webhooksResp: &service.ListWebhooksResponse{
+				Webhooks: []*service.Webhook{
+					{ID: "018c7dbd-c000-7000-8000-0000000000f1", URL: "https://hooks.example.com", Secret: "s3cret", EventTypes: []service.ChangeType{service.ChangeDeleted}},
+				},
+			}},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.ListWebhooksResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp.GetWebhooks()) != 1 {
+					t.Fatalf("want 1 webhook, got %v", &resp)
+				}
+				webhook := resp.GetWebhooks()[0]
+				if webhook.GetUrl() != "https://hooks.example.com" || webhook.GetEventTypes()[0] != endpointpb.MachineEventType_MACHINE_EVENT_TYPE_DELETED {
+					t.Errorf("unexpected webhook %v", webhook)
+				}
+				if webhook.Secret != nil {
+					t.Errorf("expected the secret to be left out, got %q", webhook.GetSecret())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			ListWebhooks(mux, tt.client)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
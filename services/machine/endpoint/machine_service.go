@@ -0,0 +1,251 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// machineService holds the machine operations served over both HTTP and
+// gRPC, so that the two cannot drift apart. Callers parse IDs and parameters
+// from their own transport; every error returned is an errorpb problem about
+// instance.
+type machineService struct {
+	firestoreClient FirestoreClient
+	idempotencyTTL  time.Duration
+}
+
+// precondition checks a write against the current revision of the machine.
+// Like checkIfMatch, it returns the revision the write must still find.
+type precondition func(revision int64) (*int64, bool)
+
+// expectRevision is the precondition of a write naming the revision it
+// expects, if any.
+func expectRevision(expected *int64) precondition {
+	return func(revision int64) (*int64, bool) {
+		if expected == nil {
+			return nil, true
+		}
+		return expected, *expected == revision
+	}
+}
+
+// register creates a machine. When idempotencyKey is set, a retry with the
+// same key gets the response to the original request, and replayed is true.
+func (s machineService) register(ctx context.Context, instance string, req *endpointpb.RegisterMachineRequest, actor string, idempotencyKey *string) (resp *endpointpb.RegisterMachineResponse, replayed bool, err error) {
+	if invalidFields := validateMachineRequest(req); len(invalidFields) > 0 {
+		return nil, false, errorpb.NewValidationError(instance, invalidFields)
+	}
+
+	var record *service.IdempotencyRecord
+	if idempotencyKey != nil {
+		record, err = newIdempotencyRecord(*idempotencyKey, req, s.idempotencyTTL)
+		if err != nil {
+			return nil, false, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+				{Field: proto.String(idempotencyKeyHeader), Reason: proto.String(err.Error())},
+			})
+		}
+
+		getResp, err := s.firestoreClient.GetIdempotencyRecord(ctx, &service.GetIdempotencyRecordRequest{
+			Key: record.Key,
+		})
+		if err != nil {
+			return nil, false, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get idempotency record: %v", err))
+		}
+		if getResp.Found {
+			resp, err := replayIdempotentResponse(instance, record, getResp.Record)
+			return resp, err == nil, err
+		}
+	}
+
+	machineID, err := uuid.NewV7()
+	if err != nil {
+		return nil, false, errorpb.NewInternalError(instance, fmt.Sprintf("failed to generate machine ID: %v", err))
+	}
+
+	resp = &endpointpb.RegisterMachineResponse{
+		MachineId: proto.String(machineID.String()),
+	}
+	if record != nil {
+		record.Response, err = proto.Marshal(resp)
+		if err != nil {
+			return nil, false, errorpb.NewInternalError(instance, fmt.Sprintf("failed to marshal response: %v", err))
+		}
+	}
+
+	_, err = s.firestoreClient.CreateMachine(ctx, &service.CreateMachineRequest{
+		MachineID:         machineID.String(),
+		Machine:           convertMachineRequest(req),
+		Actor:             actor,
+		IdempotencyRecord: record,
+	})
+	if conflict, ok := asMACConflict(err); ok {
+		return nil, false, newMACConflictError(instance, conflict)
+	}
+	// A concurrent retry with the same key won the race.
+	var exists *service.IdempotencyKeyExistsError
+	if errors.As(err, &exists) {
+		resp, err := replayIdempotentResponse(instance, record, exists.Record)
+		return resp, err == nil, err
+	}
+	if err != nil {
+		return nil, false, errorpb.NewInternalError(instance, fmt.Sprintf("failed to create machine: %v", err))
+	}
+	return resp, false, nil
+}
+
+func (s machineService) get(ctx context.Context, instance, machineID string) (*service.Machine, error) {
+	resp, err := s.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		return nil, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err))
+	}
+	if !resp.Found {
+		return nil, errorpb.NewMachineNotFoundError(instance, machineID)
+	}
+	return resp.Machine, nil
+}
+
+// isMachineNotFound reports whether a write found the machine deleted, which
+// can happen after it was read.
+func isMachineNotFound(err error) bool {
+	var notFound *service.MachineNotFoundError
+	return errors.As(err, &notFound)
+}
+
+func (s machineService) list(ctx context.Context, instance string, req *service.ListMachinesRequest) (*endpointpb.ListMachinesResponse, error) {
+	resp, err := s.firestoreClient.ListMachines(ctx, req)
+	if err != nil {
+		return nil, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list machines: %v", err))
+	}
+
+	machines := make([]*endpointpb.Machine, len(resp.Machines))
+	for i, machine := range resp.Machines {
+		machines[i] = convertMachineToProto(machine)
+	}
+	return &endpointpb.ListMachinesResponse{
+		Machines: machines,
+		Pagination: &endpointpb.Pagination{
+			PerPage:       proto.Int32(int32(req.PageSize)),
+			NextPageToken: proto.String(resp.NextPageToken),
+		},
+	}, nil
+}
+
+// update replaces the hardware profile of a machine, returning the machine
+// as it is after the update.
+func (s machineService) update(ctx context.Context, instance, machineID string, req *endpointpb.RegisterMachineRequest, actor string, check precondition) (*service.Machine, error) {
+	if invalidFields := validateMachineRequest(req); len(invalidFields) > 0 {
+		return nil, errorpb.NewValidationError(instance, invalidFields)
+	}
+
+	current, err := s.get(ctx, instance, machineID)
+	if err != nil {
+		return nil, err
+	}
+	expectedRevision, ok := check(current.Revision)
+	if !ok {
+		return nil, errorpb.NewPreconditionFailedError(instance, machineID)
+	}
+
+	machine := convertMachineRequest(req)
+	updateResp, err := s.firestoreClient.UpdateMachine(ctx, &service.UpdateMachineRequest{
+		MachineID:        machineID,
+		Machine:          machine,
+		Actor:            actor,
+		ExpectedRevision: expectedRevision,
+	})
+	if isRevisionMismatch(err) {
+		return nil, errorpb.NewPreconditionFailedError(instance, machineID)
+	}
+	if isMachineNotFound(err) {
+		return nil, errorpb.NewMachineNotFoundError(instance, machineID)
+	}
+	if conflict, ok := asMACConflict(err); ok {
+		return nil, newMACConflictError(instance, conflict)
+	}
+	if err != nil {
+		return nil, errorpb.NewInternalError(instance, fmt.Sprintf("failed to update machine: %v", err))
+	}
+
+	return &service.Machine{
+		ID:               machineID,
+		MachineRequest:   *machine,
+		Revision:         updateResp.Revision,
+		LifecycleState:   current.LifecycleState,
+		LifecycleHistory: current.LifecycleHistory,
+		Labels:           current.Labels,
+	}, nil
+}
+
+func (s machineService) delete(ctx context.Context, instance, machineID, actor string, check precondition) error {
+	current, err := s.get(ctx, instance, machineID)
+	if err != nil {
+		return err
+	}
+	expectedRevision, ok := check(current.Revision)
+	if !ok {
+		return errorpb.NewPreconditionFailedError(instance, machineID)
+	}
+
+	_, err = s.firestoreClient.DeleteMachine(ctx, &service.DeleteMachineRequest{
+		MachineID:        machineID,
+		DeletedBy:        actor,
+		ExpectedRevision: expectedRevision,
+	})
+	if isRevisionMismatch(err) {
+		return errorpb.NewPreconditionFailedError(instance, machineID)
+	}
+	if isMachineNotFound(err) {
+		return errorpb.NewMachineNotFoundError(instance, machineID)
+	}
+	if err != nil {
+		return errorpb.NewInternalError(instance, fmt.Sprintf("failed to delete machine: %v", err))
+	}
+	return nil
+}
+
+// watch starts a watch of the change log after the sequence after, or at
+// the current end of the log when after is nil. It returns the first
+// changes, and the sequence they follow.
+func (s machineService) watch(ctx context.Context, instance string, after *int64) (*service.ListMachineChangesResponse, int64, error) {
+	// Starting at the end of the log only needs the latest sequence.
+	listReq := &service.ListMachineChangesRequest{}
+	if after != nil {
+		listReq = &service.ListMachineChangesRequest{After: *after, PageSize: watchPageSize}
+	}
+
+	resp, err := s.firestoreClient.ListMachineChanges(ctx, listReq)
+	if err != nil {
+		return nil, 0, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list machine changes: %v", err))
+	}
+	start := listReq.After
+	if after == nil {
+		start = resp.Latest
+	}
+	if changesMissed(start, resp) {
+		return nil, 0, errorpb.NewResumeTokenExpiredError(instance, start)
+	}
+	return resp, start, nil
+}
+
+// nextChanges continues a watch with the changes after the sequence after.
+func (s machineService) nextChanges(ctx context.Context, instance string, after int64) (*service.ListMachineChangesResponse, error) {
+	resp, err := s.firestoreClient.ListMachineChanges(ctx, &service.ListMachineChangesRequest{After: after, PageSize: watchPageSize})
+	if err != nil {
+		return nil, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list machine changes: %v", err))
+	}
+	if changesMissed(after, resp) {
+		return nil, errorpb.NewResumeTokenExpiredError(instance, after)
+	}
+	return resp, nil
+}
@@ -0,0 +1,524 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+const openAPIPath = "/openapi.json"
+
+// openAPIDocument is an OpenAPI 3.1 document, limited to what the service
+// needs to describe itself.
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Headers     map[string]*openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string      `json:"description,omitempty"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+// openAPIMediaType describes a body. Protobuf bodies have no JSON Schema, so
+// they name their message in the x-protobuf-message extension instead.
+type openAPIMediaType struct {
+	Schema          *jsonSchema `json:"schema,omitempty"`
+	ProtobufMessage string      `json:"x-protobuf-message,omitempty"`
+	EventSchema     *jsonSchema `json:"x-event-data-schema,omitempty"`
+}
+
+// apiRoute is the metadata of a route that the OpenAPI document is built
+// from. The message types come from endpointpb and errorpb, so the schemas
+// follow the protos. Every route registered on the router must be listed in
+// apiRoutes, which the app's contract test checks.
+type apiRoute struct {
+	method      string
+	path        string
+	operationID string
+	summary     string
+	params      []*openAPIParameter
+	request     proto.Message
+
+	status   int
+	response proto.Message
+	// contentType is set for responses that are not content negotiated.
+	contentType string
+	etag        bool
+	notModified bool
+
+	// problems lists the problems specific to the route. Internal errors,
+	// and validation errors of routes taking input, apply to all routes.
+	problems []routeProblem
+}
+
+type routeProblem struct {
+	status  int
+	problem proto.Message
+}
+
+var (
+	machineIDParam = &openAPIParameter{
+		Name: "id", In: "path", Required: true,
+		Description: "Machine ID, a UUIDv7",
+		Schema:      &jsonSchema{Type: "string", Format: "uuid"},
+	}
+	webhookIDParam = &openAPIParameter{
+		Name: "id", In: "path", Required: true,
+		Description: "Webhook ID, a UUIDv7",
+		Schema:      &jsonSchema{Type: "string", Format: "uuid"},
+	}
+	revisionParam = &openAPIParameter{
+		Name: "revision", In: "path", Required: true,
+		Schema: &jsonSchema{Type: "integer", Format: "int64", Minimum: proto.Int64(1)},
+	}
+	sequenceParam = &openAPIParameter{
+		Name: "sequence", In: "path", Required: true,
+		Description: "Sequence of the change the delivery is for",
+		Schema:      &jsonSchema{Type: "integer", Format: "int64", Minimum: proto.Int64(1)},
+	}
+	perPageParam = &openAPIParameter{
+		Name: "per_page", In: "query",
+		Schema: &jsonSchema{Type: "integer", Minimum: proto.Int64(1), Maximum: proto.Int64(maxPerPage), Default: defaultPerPage},
+	}
+	pageTokenParam = &openAPIParameter{
+		Name: "page_token", In: "query",
+		Description: "next_page_token of the previous page",
+		Schema:      &jsonSchema{Type: "string"},
+	}
+	ifMatchParam = &openAPIParameter{
+		Name: "If-Match", In: "header",
+		Description: "Entity tags the machine's current one must be among for the write to go ahead",
+		Schema:      &jsonSchema{Type: "string"},
+	}
+	ifNoneMatchParam = &openAPIParameter{
+		Name: "If-None-Match", In: "header",
+		Schema: &jsonSchema{Type: "string"},
+	}
+	idempotencyKeyParam = &openAPIParameter{
+		Name: idempotencyKeyHeader, In: "header",
+		Description: "Makes retries replay the response to the first request with the same key",
+		Schema:      &jsonSchema{Type: "string"},
+	}
+	lastEventIDParam = &openAPIParameter{
+		Name: "Last-Event-ID", In: "header",
+		Description: "ID of the last event received, to resume after",
+		Schema:      &jsonSchema{Type: "integer", Format: "int64", Minimum: proto.Int64(0)},
+	}
+)
+
+var (
+	machineNotFound    = routeProblem{http.StatusNotFound, &errorpb.MachineNotFoundProblem{}}
+	resourceNotFound   = routeProblem{http.StatusNotFound, &errorpb.Problem{}}
+	macConflict        = routeProblem{http.StatusConflict, &errorpb.ConflictProblem{}}
+	preconditionFailed = routeProblem{http.StatusPreconditionFailed, &errorpb.Problem{}}
+)
+
+var apiRoutes = []apiRoute{
+	{
+		method: http.MethodPost, path: "/api/v1/machines",
+		operationID: "registerMachine", summary: "Register a new machine with hardware specifications",
+		params:  []*openAPIParameter{idempotencyKeyParam},
+		request: &endpointpb.RegisterMachineRequest{},
+		status:  http.StatusCreated, response: &endpointpb.RegisterMachineResponse{},
+		problems: []routeProblem{
+			macConflict,
+			{http.StatusUnprocessableEntity, &errorpb.IdempotencyKeyMismatchProblem{}},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/machines:batchCreate",
+		operationID: "batchCreateMachines", summary: "Register many machines in one request",
+		request: &endpointpb.BatchCreateMachinesRequest{},
+		status:  http.StatusOK, response: &endpointpb.BatchCreateMachinesResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/machines:search",
+		operationID: "searchMachines", summary: "Find machines that satisfy hardware requirements",
+		request: &endpointpb.SearchMachinesRequest{},
+		status:  http.StatusOK, response: &endpointpb.SearchMachinesResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines:watch",
+		operationID: "watchMachines", summary: "Stream machine changes as Server-Sent Events",
+		params: []*openAPIParameter{lastEventIDParam},
+		status: http.StatusOK, response: &endpointpb.MachineEvent{}, contentType: eventStreamContentType,
+		problems: []routeProblem{
+			{http.StatusGone, &errorpb.Problem{}},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines",
+		operationID: "listMachines", summary: "List registered machines",
+		params: []*openAPIParameter{
+			perPageParam,
+			pageTokenParam,
+			{Name: "mac", In: "query", Description: "Only machines with a NIC with this MAC address", Schema: &jsonSchema{Type: "string"}},
+			{Name: "label_selector", In: "query", Description: "Only machines whose labels match, e.g. rack=r1,env!=prod", Schema: &jsonSchema{Type: "string"}},
+		},
+		status: http.StatusOK, response: &endpointpb.ListMachinesResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines/{id}",
+		operationID: "getMachine", summary: "Retrieve a machine",
+		params: []*openAPIParameter{machineIDParam, ifNoneMatchParam},
+		status: http.StatusOK, response: &endpointpb.Machine{}, etag: true, notModified: true,
+		problems: []routeProblem{machineNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/machines/{id}",
+		operationID: "updateMachine", summary: "Replace a machine's hardware profile",
+		params:  []*openAPIParameter{machineIDParam, ifMatchParam},
+		request: &endpointpb.RegisterMachineRequest{},
+		status:  http.StatusOK, response: &endpointpb.Machine{}, etag: true,
+		problems: []routeProblem{machineNotFound, macConflict, preconditionFailed},
+	},
+	{
+		method: http.MethodPatch, path: "/api/v1/machines/{id}",
+		operationID: "patchMachine", summary: "Partially update a machine's hardware profile",
+		params:  []*openAPIParameter{machineIDParam, ifMatchParam},
+		request: &endpointpb.PatchMachineRequest{},
+		status:  http.StatusOK, response: &endpointpb.Machine{}, etag: true,
+		problems: []routeProblem{machineNotFound, macConflict, preconditionFailed},
+	},
+	{
+		method: http.MethodDelete, path: "/api/v1/machines/{id}",
+		operationID: "deleteMachine", summary: "Delete a machine",
+		params:   []*openAPIParameter{machineIDParam, ifMatchParam},
+		status:   http.StatusNoContent,
+		problems: []routeProblem{machineNotFound, preconditionFailed},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/machines/{id}:restore",
+		operationID: "restoreMachine", summary: "Restore a deleted machine",
+		params: []*openAPIParameter{machineIDParam},
+		status: http.StatusOK, response: &endpointpb.Machine{}, etag: true,
+		problems: []routeProblem{machineNotFound, macConflict},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/machines/{id}:transition",
+		operationID: "transitionMachine", summary: "Move a machine to another lifecycle state",
+		params:  []*openAPIParameter{machineIDParam, ifMatchParam},
+		request: &endpointpb.TransitionMachineRequest{},
+		status:  http.StatusOK, response: &endpointpb.Machine{}, etag: true,
+		problems: []routeProblem{
+			machineNotFound,
+			{http.StatusConflict, &errorpb.IllegalTransitionProblem{}},
+			preconditionFailed,
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines/{id}/labels",
+		operationID: "getMachineLabels", summary: "Retrieve a machine's labels",
+		params: []*openAPIParameter{machineIDParam, ifNoneMatchParam},
+		status: http.StatusOK, response: &endpointpb.MachineLabels{}, etag: true, notModified: true,
+		problems: []routeProblem{machineNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/machines/{id}/labels",
+		operationID: "updateMachineLabels", summary: "Replace a machine's labels",
+		params:  []*openAPIParameter{machineIDParam, ifMatchParam},
+		request: &endpointpb.MachineLabels{},
+		status:  http.StatusOK, response: &endpointpb.MachineLabels{}, etag: true,
+		problems: []routeProblem{machineNotFound, preconditionFailed},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines/{id}/revisions",
+		operationID: "listMachineRevisions", summary: "List a machine's previous versions",
+		params: []*openAPIParameter{machineIDParam, perPageParam, pageTokenParam},
+		status: http.StatusOK, response: &endpointpb.ListMachineRevisionsResponse{},
+		problems: []routeProblem{machineNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/machines/{id}/revisions/{revision}",
+		operationID: "getMachineRevision", summary: "Retrieve a previous version of a machine",
+		params: []*openAPIParameter{machineIDParam, revisionParam},
+		status: http.StatusOK, response: &endpointpb.MachineRevision{},
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/machines/{id}:rollback",
+		operationID: "rollbackMachine", summary: "Restore hardware and labels from a previous revision",
+		params:  []*openAPIParameter{machineIDParam, ifMatchParam},
+		request: &endpointpb.RollbackMachineRequest{},
+		status:  http.StatusOK, response: &endpointpb.Machine{}, etag: true,
+		problems: []routeProblem{machineNotFound, resourceNotFound, macConflict, preconditionFailed},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/inventory/summary",
+		operationID: "getInventorySummary", summary: "Retrieve fleet wide capacity totals",
+		status: http.StatusOK, response: &endpointpb.InventorySummary{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/webhooks",
+		operationID: "createWebhook", summary: "Register a webhook for machine changes",
+		request: &endpointpb.WebhookRequest{},
+		status:  http.StatusCreated, response: &endpointpb.Webhook{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks",
+		operationID: "listWebhooks", summary: "List webhooks",
+		status: http.StatusOK, response: &endpointpb.ListWebhooksResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks/{id}",
+		operationID: "getWebhook", summary: "Retrieve a webhook",
+		params: []*openAPIParameter{webhookIDParam},
+		status: http.StatusOK, response: &endpointpb.Webhook{},
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/webhooks/{id}",
+		operationID: "updateWebhook", summary: "Update a webhook's URL, filters or secret",
+		params:  []*openAPIParameter{webhookIDParam},
+		request: &endpointpb.WebhookRequest{},
+		status:  http.StatusOK, response: &endpointpb.Webhook{},
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/api/v1/webhooks/{id}",
+		operationID: "deleteWebhook", summary: "Delete a webhook and its delivery log",
+		params:   []*openAPIParameter{webhookIDParam},
+		status:   http.StatusNoContent,
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks/{id}/deliveries",
+		operationID: "listWebhookDeliveries", summary: "List a webhook's deliveries",
+		params: []*openAPIParameter{
+			webhookIDParam,
+			perPageParam,
+			{Name: "page_token", In: "query", Description: pageTokenParam.Description, Schema: &jsonSchema{Type: "integer", Format: "int64", Minimum: proto.Int64(1)}},
+		},
+		status: http.StatusOK, response: &endpointpb.ListWebhookDeliveriesResponse{},
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/webhooks/{id}/deliveries/{sequence}:redeliver",
+		operationID: "redeliverWebhookDelivery", summary: "Retry a delivery",
+		params: []*openAPIParameter{webhookIDParam, sequenceParam},
+		status: http.StatusAccepted, response: &endpointpb.WebhookDelivery{},
+		problems: []routeProblem{resourceNotFound},
+	},
+	{
+		method: http.MethodGet, path: openAPIPath,
+		operationID: "getOpenAPI", summary: "Retrieve this document",
+		status: http.StatusOK, contentType: "application/json",
+	},
+}
+
+type openAPIHandler struct {
+	tracer trace.Tracer
+	log    *slog.Logger
+	doc    []byte
+}
+
+// OpenAPI registers the endpoint serving the OpenAPI document of the
+// service, built once from apiRoutes.
+func OpenAPI(mux *chi.Mux) {
+	doc, err := json.Marshal(buildOpenAPIDocument(apiRoutes))
+	if err != nil {
+		// Only a bug in the document types can make it fail.
+		panic(fmt.Sprintf("failed to encode OpenAPI document: %v", err))
+	}
+
+	handler := &openAPIHandler{
+		tracer: otel.Tracer("machine/endpoint"),
+		log:    slog.Default(),
+		doc:    doc,
+	}
+
+	mux.Method(http.MethodGet, openAPIPath, handler)
+}
+
+func (h *openAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.doc)
+}
+
+func buildOpenAPIDocument(routes []apiRoute) *openAPIDocument {
+	b := newSchemaBuilder()
+	doc := &openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: openAPIInfo{
+			Title:   "Machine Management Service",
+			Version: "v1",
+			Description: "Bodies are exchanged as protobuf or, when asked for with Content-Type and Accept, " +
+				"as JSON following the protobuf JSON mapping with the original field names. " +
+				"Problems are RFC 7807 problem documents.",
+		},
+		Paths: make(map[string]map[string]*openAPIOperation),
+	}
+
+	for _, route := range routes {
+		if doc.Paths[route.path] == nil {
+			doc.Paths[route.path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[route.path][strings.ToLower(route.method)] = b.operation(route)
+	}
+
+	doc.Components.Schemas = b.components
+	return doc
+}
+
+func (b *schemaBuilder) operation(route apiRoute) *openAPIOperation {
+	op := &openAPIOperation{
+		OperationID: route.operationID,
+		Summary:     route.summary,
+		Parameters:  route.params,
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	problems := slices.Clone(route.problems)
+	if route.request != nil {
+		op.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  b.negotiatedContent(route.request),
+		}
+		problems = append(problems, routeProblem{http.StatusUnsupportedMediaType, &errorpb.Problem{}})
+	}
+	if route.request != nil || len(route.params) > 0 {
+		problems = append(problems, routeProblem{http.StatusBadRequest, &errorpb.ValidationProblem{}})
+	}
+	problems = append(problems, routeProblem{http.StatusInternalServerError, &errorpb.Problem{}})
+
+	op.Responses[strconv.Itoa(route.status)] = b.response(route)
+	if route.notModified {
+		op.Responses[strconv.Itoa(http.StatusNotModified)] = &openAPIResponse{
+			Description: http.StatusText(http.StatusNotModified),
+		}
+	}
+	for status, schemas := range b.problemSchemas(problems) {
+		schema := schemas[0]
+		if len(schemas) > 1 {
+			schema = &jsonSchema{OneOf: schemas}
+		}
+		op.Responses[strconv.Itoa(status)] = &openAPIResponse{
+			Description: http.StatusText(status),
+			Content: map[string]*openAPIMediaType{
+				errorpb.JSONContentType:     {Schema: schema},
+				errorpb.ProtobufContentType: {ProtobufMessage: protobufMessages(problems, status)},
+			},
+		}
+	}
+	return op
+}
+
+func (b *schemaBuilder) response(route apiRoute) *openAPIResponse {
+	resp := &openAPIResponse{Description: http.StatusText(route.status)}
+	if route.etag {
+		resp.Headers = map[string]*openAPIHeader{
+			"ETag": {Description: "The machine's revision", Schema: &jsonSchema{Type: "string"}},
+		}
+	}
+
+	switch {
+	case route.contentType == eventStreamContentType:
+		resp.Content = map[string]*openAPIMediaType{
+			eventStreamContentType: {
+				Schema:      &jsonSchema{Type: "string"},
+				EventSchema: b.message(route.response.ProtoReflect().Descriptor()),
+			},
+		}
+	case route.contentType != "":
+		resp.Content = map[string]*openAPIMediaType{
+			route.contentType: {Schema: &jsonSchema{Type: "object"}},
+		}
+	case route.response != nil:
+		resp.Content = b.negotiatedContent(route.response)
+	}
+	return resp
+}
+
+// negotiatedContent describes a body exchanged as JSON or protobuf.
+func (b *schemaBuilder) negotiatedContent(msg proto.Message) map[string]*openAPIMediaType {
+	desc := msg.ProtoReflect().Descriptor()
+	return map[string]*openAPIMediaType{
+		jsonContentType:     {Schema: b.message(desc)},
+		protobufContentType: {ProtobufMessage: string(desc.FullName())},
+	}
+}
+
+// problemSchemas groups the distinct problem document schemas by status.
+func (b *schemaBuilder) problemSchemas(problems []routeProblem) map[int][]*jsonSchema {
+	schemas := make(map[int][]*jsonSchema)
+	for _, p := range problems {
+		schema := b.problemDocument(p.problem.ProtoReflect().Descriptor())
+		if !containsRef(schemas[p.status], schema.Ref) {
+			schemas[p.status] = append(schemas[p.status], schema)
+		}
+	}
+	return schemas
+}
+
+// protobufMessages names the problem messages that may be sent with status.
+func protobufMessages(problems []routeProblem, status int) string {
+	var names []string
+	for _, p := range problems {
+		name := string(p.problem.ProtoReflect().Descriptor().FullName())
+		if p.status == status && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " | ")
+}
+
+func containsRef(schemas []*jsonSchema, ref string) bool {
+	for _, schema := range schemas {
+		if schema.Ref == ref {
+			return true
+		}
+	}
+	return false
+}
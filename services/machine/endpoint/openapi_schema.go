@@ -0,0 +1,171 @@
+package endpoint
+
+import (
+	"github.com/Zaba505/infra/pkg/errorpb"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonSchema is the subset of JSON Schema 2020-12, the dialect of OpenAPI
+// 3.1, needed to describe the protobuf JSON mapping.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Minimum              *int64                 `json:"minimum,omitempty"`
+	Maximum              *int64                 `json:"maximum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AllOf                []*jsonSchema          `json:"allOf,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+}
+
+// problemDocumentSuffix names the schema of a problem extension message as
+// written by errorpb.MarshalJSON, with the embedded Problem hoisted. The
+// message schema itself describes it nested in another message, where it is
+// not hoisted.
+const problemDocumentSuffix = "Document"
+
+var problemFullName = (&errorpb.Problem{}).ProtoReflect().Descriptor().FullName()
+
+// schemaBuilder derives schemas from message descriptors, collecting every
+// message it meets as a component so that each is described once.
+type schemaBuilder struct {
+	components map[string]*jsonSchema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: make(map[string]*jsonSchema)}
+}
+
+// message returns a reference to the schema of msg in the JSON mapping.
+func (b *schemaBuilder) message(msg protoreflect.MessageDescriptor) *jsonSchema {
+	if schema, ok := wellKnownSchemas[msg.FullName()]; ok {
+		return schema
+	}
+
+	name := string(msg.FullName())
+	if _, ok := b.components[name]; !ok {
+		// Registered before the fields are walked, so that recursive
+		// messages terminate.
+		schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		b.components[name] = schema
+
+		fields := msg.Fields()
+		for i := range fields.Len() {
+			field := fields.Get(i)
+			schema.Properties[string(field.Name())] = b.field(field)
+		}
+
+		// A set oneof member is the only one encoded.
+		oneofs := msg.Oneofs()
+		for i := range oneofs.Len() {
+			if oneof := oneofs.Get(i); !oneof.IsSynthetic() {
+				schema.OneOf = oneofVariants(oneof)
+			}
+		}
+	}
+	return &jsonSchema{Ref: componentRef(name)}
+}
+
+// problemDocument returns a reference to the schema of msg written as a
+// problem document.
+func (b *schemaBuilder) problemDocument(msg protoreflect.MessageDescriptor) *jsonSchema {
+	ref := b.message(msg)
+	problemField := msg.Fields().ByName("problem")
+	if problemField == nil || problemField.Message().FullName() != problemFullName {
+		return ref
+	}
+
+	name := string(msg.FullName()) + problemDocumentSuffix
+	if _, ok := b.components[name]; !ok {
+		members := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		for field, schema := range b.components[string(msg.FullName())].Properties {
+			if field != string(problemField.Name()) {
+				members.Properties[field] = schema
+			}
+		}
+		b.components[name] = &jsonSchema{
+			AllOf: []*jsonSchema{b.message(problemField.Message()), members},
+		}
+	}
+	return &jsonSchema{Ref: componentRef(name)}
+}
+
+func (b *schemaBuilder) field(field protoreflect.FieldDescriptor) *jsonSchema {
+	switch {
+	case field.IsMap():
+		return &jsonSchema{
+			Type:                 "object",
+			AdditionalProperties: b.singular(field.MapValue()),
+		}
+	case field.IsList():
+		return &jsonSchema{Type: "array", Items: b.singular(field)}
+	default:
+		return b.singular(field)
+	}
+}
+
+// singular is the schema of one value of field.
+func (b *schemaBuilder) singular(field protoreflect.FieldDescriptor) *jsonSchema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.message(field.Message())
+	case protoreflect.EnumKind:
+		return enumSchema(field.Enum())
+	case protoreflect.BoolKind:
+		return &jsonSchema{Type: "boolean"}
+	case protoreflect.StringKind:
+		return &jsonSchema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &jsonSchema{Type: "number"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &jsonSchema{Type: "integer", Minimum: proto.Int64(0)}
+	default:
+		// 64-bit integers are written as strings so that JavaScript
+		// clients do not lose precision, but numbers are accepted too.
+		return &jsonSchema{Type: []string{"string", "integer"}, Format: "int64"}
+	}
+}
+
+// enumSchema lists the value names, which is how enums are written.
+func enumSchema(enum protoreflect.EnumDescriptor) *jsonSchema {
+	values := enum.Values()
+	names := make([]string, values.Len())
+	for i := range values.Len() {
+		names[i] = string(values.Get(i).Name())
+	}
+	return &jsonSchema{Type: "string", Enum: names}
+}
+
+func oneofVariants(oneof protoreflect.OneofDescriptor) []*jsonSchema {
+	fields := oneof.Fields()
+	variants := make([]*jsonSchema, fields.Len())
+	for i := range fields.Len() {
+		variants[i] = &jsonSchema{Required: []string{string(fields.Get(i).Name())}}
+	}
+	return variants
+}
+
+func componentRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// wellKnownSchemas describes the well-known types with a special JSON
+// mapping.
+var wellKnownSchemas = map[protoreflect.FullName]*jsonSchema{
+	"google.protobuf.Timestamp": {Type: "string", Format: "date-time"},
+	"google.protobuf.Duration":  {Type: "string", Description: "Seconds with an s suffix, e.g. 1.5s"},
+	"google.protobuf.FieldMask": {Type: "string", Description: "Comma separated field paths in lowerCamelCase"},
+}
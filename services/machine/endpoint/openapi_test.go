@@ -0,0 +1,150 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// propertiesOf collects the properties a component schema allows, following
+// allOf into the hoisted Problem of problem documents.
+func propertiesOf(t *testing.T, doc *openAPIDocument, ref string) map[string]*jsonSchema {
+	t.Helper()
+
+	schema, ok := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	if !ok {
+		t.Fatalf("unresolved reference %s", ref)
+	}
+	properties := make(map[string]*jsonSchema)
+	for name, property := range schema.Properties {
+		properties[name] = property
+	}
+	for _, part := range schema.AllOf {
+		if part.Ref != "" {
+			for name, property := range propertiesOf(t, doc, part.Ref) {
+				properties[name] = property
+			}
+		}
+		for name, property := range part.Properties {
+			properties[name] = property
+		}
+	}
+	return properties
+}
+
+func TestBuildOpenAPIDocument_JSONMapping(t *testing.T) {
+	doc := buildOpenAPIDocument(apiRoutes)
+
+	machine := convertMachineToProto(&service.Machine{
+		ID: "018c7dbd-c000-7000-8000-fedcba987654",
+		MachineRequest: service.MachineRequest{
+			CPUs: []service.CPU{{Manufacturer: "AMD", Cores: 64}},
+		},
+		LifecycleState: service.LifecycleStateNew,
+		Labels:         map[string]string{"rack": "r1"},
+	})
+	machineJSON, _ := jsonMarshalOptions.Marshal(machine)
+	validationJSON, _ := errorpb.MarshalJSON(errorpb.NewValidationError("/api/v1/machines", []*errorpb.InvalidField{
+		{Field: proto.String("nics"), Reason: proto.String("is required")},
+	}))
+
+	tests := []struct {
+		name string
+		ref  string
+		body []byte
+	}{
+		{"message", componentRef("endpointpb.Machine"), machineJSON},
+		{"problem document", componentRef("errorpb.ValidationProblem" + problemDocumentSuffix), validationJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var members map[string]json.RawMessage
+			if err := json.Unmarshal(tt.body, &members); err != nil {
+				t.Fatalf("failed to decode %s: %v", tt.body, err)
+			}
+			properties := propertiesOf(t, doc, tt.ref)
+			for name := range members {
+				if _, ok := properties[name]; !ok {
+					t.Errorf("member %s is not described by %s", name, tt.ref)
+				}
+			}
+		})
+	}
+
+	cpu := doc.Components.Schemas["endpointpb.CPU"]
+	if cores := cpu.Properties["cores"]; cores.Format != "int64" {
+		t.Errorf("want cores described as an int64, got %+v", cores)
+	}
+	state := propertiesOf(t, doc, componentRef("endpointpb.Machine"))["lifecycle_state"]
+	if state.Type != "string" || len(state.Enum) == 0 {
+		t.Errorf("want lifecycle_state described as a string enum, got %+v", state)
+	}
+}
+
+func TestBuildOpenAPIDocument_References(t *testing.T) {
+	doc := buildOpenAPIDocument(apiRoutes)
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+
+	for _, ref := range strings.Split(string(b), `"$ref":"`)[1:] {
+		ref = ref[:strings.IndexByte(ref, '"')]
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; !ok {
+			t.Errorf("unresolved reference %s", ref)
+		}
+	}
+
+	op := doc.Paths["/api/v1/machines/{id}:transition"]["post"]
+	for _, status := range []string{"200", "400", "404", "409", "412", "415", "500"} {
+		if _, ok := op.Responses[status]; !ok {
+			t.Errorf("want a %s response for transitions, got %v", status, op.Responses)
+		}
+	}
+	conflict := op.Responses["409"].Content[errorpb.JSONContentType].Schema
+	if conflict.Ref != componentRef("errorpb.IllegalTransitionProblem"+problemDocumentSuffix) {
+		t.Errorf("unexpected conflict schema %+v", conflict)
+	}
+	if got := op.RequestBody.Content[protobufContentType].ProtobufMessage; got != "endpointpb.TransitionMachineRequest" {
+		t.Errorf("want the protobuf request named, got %s", got)
+	}
+
+	// Two kinds of not found share the status.
+	notFound := doc.Paths["/api/v1/machines/{id}:rollback"]["post"].Responses["404"].Content[errorpb.JSONContentType].Schema
+	if len(notFound.OneOf) != 2 {
+		t.Errorf("want either not found problem, got %+v", notFound)
+	}
+}
+
+func TestOpenAPIHandler_ServeHTTP(t *testing.T) {
+	mux := chi.NewRouter()
+	OpenAPI(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("want a JSON document, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" || len(doc.Paths) == 0 {
+		t.Errorf("unexpected document %s", w.Body.String())
+	}
+	if _, ok := doc.Paths[openAPIPath]; !ok {
+		t.Error("expected the document to describe itself")
+	}
+}
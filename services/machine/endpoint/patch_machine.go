@@ -0,0 +1,193 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// immutableMachinePaths may never appear in an update mask. The lifecycle and
+// labels are only changed through their own endpoints.
+var immutableMachinePaths = []string{"id", "lifecycle_state", "lifecycle_history", "labels"}
+
+// patchAttempts bounds how often a patch without If-Match is merged again
+// after losing to a concurrent write.
+const patchAttempts = 5
+
+type patchMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func PatchMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &patchMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPatch, "/api/v1/machines/{id}", handler)
+}
+
+func (h *patchMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.PatchMachineRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if invalidFields := validateUpdateMask(paths); len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	merged, revision, err := h.patch(ctx, r, instance, machineID, &req)
+	if err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", machineETag(revision))
+	writeResponse(ctx, w, instance, http.StatusOK, merged)
+}
+
+// patch merges the masked fields of req into the stored machine and writes
+// the result, returning the merged machine and its new revision. The write
+// always expects the revision that was merged into, so a concurrent write
+// is never overwritten: without If-Match the machine is read and merged
+// again, up to patchAttempts times, while with If-Match the request fails
+// its precondition.
+func (h *patchMachineHandler) patch(ctx context.Context, r *http.Request, instance, machineID string, req *endpointpb.PatchMachineRequest) (*endpointpb.Machine, int64, error) {
+	for attempt := 1; ; attempt++ {
+		merged, revision, err := h.tryPatch(ctx, r, instance, machineID, req)
+		if !isRevisionMismatch(err) {
+			return merged, revision, err
+		}
+		if r.Header.Get("If-Match") != "" {
+			return nil, 0, errorpb.NewPreconditionFailedError(instance, machineID)
+		}
+		if attempt == patchAttempts {
+			return nil, 0, errorpb.NewInternalError(instance, fmt.Sprintf("machine changed concurrently on each of %d attempts", patchAttempts))
+		}
+	}
+}
+
+// tryPatch makes one read, merge and write of a patch. A write losing to a
+// concurrent one fails with a *service.RevisionMismatchError; every other
+// error is a problem about instance.
+func (h *patchMachineHandler) tryPatch(ctx context.Context, r *http.Request, instance, machineID string, req *endpointpb.PatchMachineRequest) (*endpointpb.Machine, int64, error) {
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		return nil, 0, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err))
+	}
+	if !getResp.Found {
+		return nil, 0, errorpb.NewMachineNotFoundError(instance, machineID)
+	}
+
+	if _, ok := checkIfMatch(r, getResp.Machine.Revision); !ok {
+		return nil, 0, errorpb.NewPreconditionFailedError(instance, machineID)
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	merged := convertMachineToProto(getResp.Machine)
+	applyUpdateMask(merged, req.GetMachine(), paths)
+
+	if invalidFields := validateMachineRequest(merged); len(invalidFields) > 0 {
+		return nil, 0, errorpb.NewValidationError(instance, invalidFields)
+	}
+
+	updateResp, err := h.firestoreClient.UpdateMachine(ctx, &service.UpdateMachineRequest{
+		MachineID:        machineID,
+		Machine:          convertMachineRequest(merged),
+		Actor:            requestActor(r),
+		ExpectedRevision: &getResp.Machine.Revision,
+	})
+	if isRevisionMismatch(err) {
+		return nil, 0, err
+	}
+	if isMachineNotFound(err) {
+		return nil, 0, errorpb.NewMachineNotFoundError(instance, machineID)
+	}
+	if conflict, ok := asMACConflict(err); ok {
+		return nil, 0, newMACConflictError(instance, conflict)
+	}
+	if err != nil {
+		return nil, 0, errorpb.NewInternalError(instance, fmt.Sprintf("failed to update machine: %v", err))
+	}
+	return merged, updateResp.Revision, nil
+}
+
+// validateUpdateMask checks each path against the Machine message descriptor.
+// Every mutable Machine field is a repeated field, which a field mask cannot
+// traverse, so valid paths are always top-level field names.
+func validateUpdateMask(paths []string) []*errorpb.InvalidField {
+	if len(paths) == 0 {
+		return []*errorpb.InvalidField{
+			{Field: proto.String("update_mask"), Reason: proto.String("at least one path is required")},
+		}
+	}
+
+	var invalidFields []*errorpb.InvalidField
+	for i, path := range paths {
+		field := fmt.Sprintf("update_mask.paths[%d]", i)
+		if _, err := fieldmaskpb.New(&endpointpb.Machine{}, path); err != nil {
+			invalidFields = append(invalidFields, &errorpb.InvalidField{
+				Field:  proto.String(field),
+				Reason: proto.String(fmt.Sprintf("%q is not a field of Machine", path)),
+			})
+			continue
+		}
+		if slices.Contains(immutableMachinePaths, path) {
+			invalidFields = append(invalidFields, &errorpb.InvalidField{
+				Field:  proto.String(field),
+				Reason: proto.String(fmt.Sprintf("%q cannot be updated", path)),
+			})
+		}
+	}
+	return invalidFields
+}
+
+// applyUpdateMask copies the fields named by paths from src into dst. A field
+// named in the mask but unset in src is cleared in dst.
+func applyUpdateMask(dst, src *endpointpb.Machine, paths []string) {
+	dstMsg := dst.ProtoReflect()
+	srcMsg := src.ProtoReflect()
+	fields := dstMsg.Descriptor().Fields()
+
+	for _, path := range paths {
+		fd := fields.ByName(protoreflect.Name(path))
+		if srcMsg.Has(fd) {
+			dstMsg.Set(fd, srcMsg.Get(fd))
+			continue
+		}
+		dstMsg.Clear(fd)
+	}
+}
@@ -0,0 +1,343 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestValidateUpdateMask(t *testing.T) {
+	tests := []struct {
+		name       string
+		paths      []string
+		wantFields []string
+	}{
+		{
+			name:  "single top-level path",
+			paths: []string{"nics"},
+		},
+		{
+			name:  "multiple top-level paths",
+			paths: []string{"drives", "memory_modules"},
+		},
+		{
+			name:       "empty mask",
+			paths:      nil,
+			wantFields: []string{"update_mask"},
+		},
+		{
+			name:       "unknown field",
+			paths:      []string{"nics", "gpus"},
+			wantFields: []string{"update_mask.paths[1]"},
+		},
+		{
+			name:       "path into repeated field",
+			paths:      []string{"cpus.cores"},
+			wantFields: []string{"update_mask.paths[0]"},
+		},
+		{
+			name:       "immutable field",
+			paths:      []string{"id"},
+			wantFields: []string{"update_mask.paths[0]"},
+		},
+		{
+			name:       "lifecycle is changed by transition only",
+			paths:      []string{"nics", "lifecycle_state"},
+			wantFields: []string{"update_mask.paths[1]"},
+		},
+		{
+			name:       "labels are changed by their sub-resource only",
+			paths:      []string{"labels"},
+			wantFields: []string{"update_mask.paths[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidFields := validateUpdateMask(tt.paths)
+			if len(invalidFields) != len(tt.wantFields) {
+				t.Fatalf("want %d invalid fields, got %v", len(tt.wantFields), invalidFields)
+			}
+			for i, field := range tt.wantFields {
+				if invalidFields[i].GetField() != field {
+					t.Errorf("want invalid field %q, got %q", field, invalidFields[i].GetField())
+				}
+			}
+		})
+	}
+}
+
+func TestPatchMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	existing := func() *service.GetMachineResponse {
+		return &service.GetMachineResponse{
+			Found: true,
+			Machine: &service.Machine{
+				ID: machineID,
+				MachineRequest: service.MachineRequest{
+					NICs:   []service.NIC{{MAC: "aa:bb:cc:dd:ee:ff"}},
+					Drives: []service.Drive{{Capacity: 500107862016}},
+				},
+			},
+		}
+	}
+	patchBody := func(machine *endpointpb.Machine, paths ...string) []byte {
+		b, _ := proto.Marshal(&endpointpb.PatchMachineRequest{
+			Machine:    machine,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+		})
+		return b
+	}
+	newMAC := "02:22:33:44:55:66"
+
+	tests := []struct {
+		name      string
+		id        string
+		body      []byte
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+		checkReq  func(t *testing.T, req *service.UpdateMachineRequest)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			body:     patchBody(nil, "drives"),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid proto body",
+			id:       machineID,
+			body:     []byte{0xFF},
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid mask path",
+			id:       machineID,
+			body:     patchBody(nil, "gpus"),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "update_mask.paths[0]" {
+					t.Errorf("expected invalid field 'update_mask.paths[0]', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name: "machine not found",
+			id:   machineID,
+			body: patchBody(nil, "drives"),
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{Found: false},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "clearing nics fails validation",
+			id:       machineID,
+			body:     patchBody(&endpointpb.Machine{}, "nics"),
+			client:   &mockFirestoreClient{getResp: existing()},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "MAC owned by another machine",
+			id:   machineID,
+			body: patchBody(&endpointpb.Machine{
+				Nics: []*endpointpb.NIC{{Mac: &newMAC}},
+			}, "nics"),
+			client: &mockFirestoreClient{
+				getResp:   existing(),
+				updateErr: &service.MACConflictError{MAC: service.MAC(newMAC), MachineID: "other-id"},
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "UpdateMachine error",
+			id:   machineID,
+			body: patchBody(nil, "drives"),
+			client: &mockFirestoreClient{
+				getResp:   existing(),
+				updateErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "patch nics keeps drives",
+			id:   machineID,
+			body: patchBody(&endpointpb.Machine{
+				Nics:   []*endpointpb.NIC{{Mac: &newMAC}},
+				Drives: []*endpointpb.Drive{{Capacity: proto.Int64(1)}},
+			}, "nics"),
+			client:   &mockFirestoreClient{getResp: existing()},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.UpdateMachineRequest) {
+				if len(req.Machine.NICs) != 1 || req.Machine.NICs[0].MAC.String() != newMAC {
+					t.Errorf("expected nics to be replaced, got %v", req.Machine.NICs)
+				}
+				if len(req.Machine.Drives) != 1 || req.Machine.Drives[0].Capacity != 500107862016 {
+					t.Errorf("expected drives to be untouched, got %v", req.Machine.Drives)
+				}
+			},
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if m.GetId() != machineID {
+					t.Errorf("want id %q, got %q", machineID, m.GetId())
+				}
+			},
+		},
+		{
+			name:     "clear drives",
+			id:       machineID,
+			body:     patchBody(nil, "drives"),
+			client:   &mockFirestoreClient{getResp: existing()},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.UpdateMachineRequest) {
+				if len(req.Machine.Drives) != 0 {
+					t.Errorf("expected drives to be cleared, got %v", req.Machine.Drives)
+				}
+				if len(req.Machine.NICs) != 1 {
+					t.Errorf("expected nics to be untouched, got %v", req.Machine.NICs)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &patchMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodPatch, "/api/v1/machines/"+tt.id, bytes.NewReader(tt.body))
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+			if tt.checkReq != nil {
+				tt.checkReq(t, tt.client.updateReq)
+			}
+		})
+	}
+}
+
+// interleavedWrite lands write between the first read of a machine and the
+// first update written after it.
+type interleavedWrite struct {
+	FirestoreClient
+	write func() error
+}
+
+func (c *interleavedWrite) UpdateMachine(ctx context.Context, req *service.UpdateMachineRequest) (*service.UpdateMachineResponse, error) {
+	if write := c.write; write != nil {
+		c.write = nil
+		if err := write(); err != nil {
+			return nil, err
+		}
+	}
+	return c.FirestoreClient.UpdateMachine(ctx, req)
+}
+
+func TestPatchMachineHandler_ConcurrentWrite(t *testing.T) {
+	ctx := context.Background()
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	newMAC := "02:22:33:44:55:66"
+	body, _ := proto.Marshal(&endpointpb.PatchMachineRequest{
+		Machine:    &endpointpb.Machine{Nics: []*endpointpb.NIC{{Mac: &newMAC}}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"nics"}},
+	})
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		wantCode int
+		wantNICs string
+	}{
+		{name: "without If-Match merges again", wantCode: http.StatusOK, wantNICs: newMAC},
+		{name: "with If-Match fails", ifMatch: `"1"`, wantCode: http.StatusPreconditionFailed, wantNICs: "aa:bb:cc:dd:ee:ff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := service.NewMemoryStore()
+			_, err := store.CreateMachine(ctx, &service.CreateMachineRequest{
+				MachineID: machineID,
+				Machine: &service.MachineRequest{
+					NICs:   []service.NIC{{MAC: "aa:bb:cc:dd:ee:ff"}},
+					Drives: []service.Drive{{Capacity: 500107862016}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("CreateMachine: %v", err)
+			}
+			client := &interleavedWrite{
+				FirestoreClient: store,
+				write: func() error {
+					_, err := store.UpdateMachine(ctx, &service.UpdateMachineRequest{
+						MachineID: machineID,
+						Machine: &service.MachineRequest{
+							NICs:   []service.NIC{{MAC: "aa:bb:cc:dd:ee:ff"}},
+							Drives: []service.Drive{{Capacity: 1}},
+						},
+					})
+					return err
+				},
+			}
+			h := &patchMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: client,
+			}
+
+			r := httptest.NewRequest(http.MethodPatch, "/api/v1/machines/"+machineID, bytes.NewReader(body))
+			r = withURLParam(r, "id", machineID)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			getResp, err := store.GetMachine(ctx, &service.GetMachineRequest{MachineID: machineID})
+			if err != nil {
+				t.Fatalf("GetMachine: %v", err)
+			}
+			machine := getResp.Machine
+			if len(machine.Drives) != 1 || machine.Drives[0].Capacity != 1 {
+				t.Errorf("expected the concurrent write to the drives to be kept, got %v", machine.Drives)
+			}
+			if len(machine.NICs) != 1 || machine.NICs[0].MAC.String() != tt.wantNICs {
+				t.Errorf("want nics [%s], got %v", tt.wantNICs, machine.NICs)
+			}
+		})
+	}
+}
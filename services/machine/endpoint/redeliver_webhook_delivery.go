@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type redeliverWebhookDeliveryHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+	horizon         time.Duration
+}
+
+// RedeliverWebhookDelivery registers the endpoint for attempting a delivery
+// again, typically one that was given up on. The delivery is retried as if
+// it had just been enqueued, for up to horizon.
+func RedeliverWebhookDelivery(mux *chi.Mux, firestoreClient FirestoreClient, horizon time.Duration) {
+	handler := &redeliverWebhookDeliveryHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+		horizon:         horizon,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/webhooks/{id}/deliveries/{sequence}:redeliver", handler)
+}
+
+func (h *redeliverWebhookDeliveryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	webhookID := chi.URLParam(r, "id")
+	var v fieldValidator
+	if err := validateWebhookID(webhookID); err != nil {
+		v.add("id", err.Error())
+	}
+	sequence, err := strconv.ParseInt(chi.URLParam(r, "sequence"), 10, 64)
+	if err != nil || sequence < 1 {
+		v.add("sequence", "must be a positive integer")
+	}
+	if len(v.invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, v.invalidFields))
+		return
+	}
+
+	resp, err := h.firestoreClient.RedeliverWebhookDelivery(ctx, &service.RedeliverWebhookDeliveryRequest{
+		WebhookID: webhookID,
+		Sequence:  sequence,
+		GiveUpAt:  time.Now().UTC().Add(h.horizon),
+	})
+	var notFound *service.DeliveryNotFoundError
+	if errors.As(err, &notFound) {
+		errorHandler(ctx, w, errorpb.NewDeliveryNotFoundError(instance, webhookID, sequence))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to redeliver webhook delivery: %v", err)))
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusAccepted, convertWebhookDeliveryToProto(resp.Delivery))
+}
@@ -0,0 +1,91 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedeliverWebhookDeliveryHandler_ServeHTTP(t *testing.T) {
+	webhookID := "018c7dbd-c000-7000-8000-0000000000f1"
+	now := time.Now().UTC()
+	redelivered := &service.RedeliverWebhookDeliveryResponse{
+		Delivery: &service.WebhookDelivery{
+			WebhookID:     webhookID,
+			Sequence:      42,
+			EventType:     service.ChangeCreated,
+			Status:        service.DeliveryPending,
+			Attempts:      7,
+			NextAttemptAt: &now,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		sequence string
+		client   *mockFirestoreClient
+		wantCode int
+	}{
+		{
+			name:     "invalid sequence",
+			sequence: "0",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "delivery not found",
+			sequence: "42",
+			client:   &mockFirestoreClient{redeliverErr: fmt.Errorf("failed to redeliver webhook delivery: %w", &service.DeliveryNotFoundError{WebhookID: webhookID, Sequence: 42})},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "RedeliverWebhookDelivery error",
+			sequence: "42",
+			client:   &mockFirestoreClient{redeliverErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			sequence: "42",
+			client:   &mockFirestoreClient{redeliverResp: redelivered},
+			wantCode: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			RedeliverWebhookDelivery(mux, tt.client, time.Hour)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+webhookID+"/deliveries/"+tt.sequence+":redeliver", nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+
+			req := tt.client.redeliverReq
+			if req.WebhookID != webhookID || req.Sequence != 42 || req.GiveUpAt.Before(now.Add(time.Hour)) {
+				t.Errorf("unexpected redeliver request %+v", req)
+			}
+			var resp endpointpb.WebhookDelivery
+			if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.GetStatus() != endpointpb.DeliveryStatus_DELIVERY_STATUS_PENDING || resp.GetSequence() != 42 {
+				t.Errorf("unexpected delivery %v", &resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+type registerMachineServiceServer struct {
+	endpointpb.UnimplementedRegisterMachineServiceServer
+
+	tracer       trace.Tracer
+	log          *slog.Logger
+	machines     machineService
+	pollInterval time.Duration
+}
+
+// RegisterMachineService registers the gRPC machine service, which shares
+// its implementation with the HTTP endpoints. Errors are the problems the
+// HTTP endpoints respond with, carried as the status detail.
+func RegisterMachineService(s grpc.ServiceRegistrar, firestoreClient FirestoreClient, idempotencyTTL, pollInterval time.Duration) {
+	endpointpb.RegisterRegisterMachineServiceServer(s, &registerMachineServiceServer{
+		tracer: otel.Tracer("machine/endpoint"),
+		log:    slog.Default(),
+		machines: machineService{
+			firestoreClient: firestoreClient,
+			idempotencyTTL:  idempotencyTTL,
+		},
+		pollInterval: pollInterval,
+	})
+}
+
+func (s *registerMachineServiceServer) RegisterMachine(ctx context.Context, req *endpointpb.RegisterMachineRequest) (*endpointpb.RegisterMachineResponse, error) {
+	instance := endpointpb.RegisterMachineService_RegisterMachine_FullMethodName
+
+	var idempotencyKey *string
+	if keys := metadata.ValueFromIncomingContext(ctx, idempotencyKeyHeader); len(keys) > 0 {
+		idempotencyKey = &keys[0]
+	}
+
+	resp, replayed, err := s.machines.register(ctx, instance, req, callActor(ctx), idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(idempotentReplayedHeader), "true"))
+	}
+	return resp, nil
+}
+
+func (s *registerMachineServiceServer) GetMachine(ctx context.Context, req *endpointpb.GetMachineRequest) (*endpointpb.Machine, error) {
+	instance := endpointpb.RegisterMachineService_GetMachine_FullMethodName
+
+	if err := validateMachineID(req.GetMachineId()); err != nil {
+		return nil, invalidField(instance, "machine_id", err.Error())
+	}
+
+	machine, err := s.machines.get(ctx, instance, req.GetMachineId())
+	if err != nil {
+		return nil, err
+	}
+	return convertMachineToProto(machine), nil
+}
+
+func (s *registerMachineServiceServer) ListMachines(ctx context.Context, req *endpointpb.ListMachinesRequest) (*endpointpb.ListMachinesResponse, error) {
+	instance := endpointpb.RegisterMachineService_ListMachines_FullMethodName
+
+	listReq, invalidFields := convertListMachinesRequest(req)
+	if len(invalidFields) > 0 {
+		return nil, errorpb.NewValidationError(instance, invalidFields)
+	}
+	return s.machines.list(ctx, instance, listReq)
+}
+
+func (s *registerMachineServiceServer) UpdateMachine(ctx context.Context, req *endpointpb.UpdateMachineRequest) (*endpointpb.Machine, error) {
+	instance := endpointpb.RegisterMachineService_UpdateMachine_FullMethodName
+
+	if err := validateMachineID(req.GetMachineId()); err != nil {
+		return nil, invalidField(instance, "machine_id", err.Error())
+	}
+	if req.GetMachine() == nil {
+		return nil, invalidField(instance, "machine", "is required")
+	}
+
+	machine, err := s.machines.update(ctx, instance, req.GetMachineId(), req.GetMachine(), callActor(ctx), expectRevision(req.ExpectedRevision))
+	if err != nil {
+		return nil, withFieldPrefix(err, "machine.")
+	}
+	return convertMachineToProto(machine), nil
+}
+
+func (s *registerMachineServiceServer) DeleteMachine(ctx context.Context, req *endpointpb.DeleteMachineRequest) (*endpointpb.DeleteMachineResponse, error) {
+	instance := endpointpb.RegisterMachineService_DeleteMachine_FullMethodName
+
+	if err := validateMachineID(req.GetMachineId()); err != nil {
+		return nil, invalidField(instance, "machine_id", err.Error())
+	}
+
+	err := s.machines.delete(ctx, instance, req.GetMachineId(), callActor(ctx), expectRevision(req.ExpectedRevision))
+	if err != nil {
+		return nil, err
+	}
+	return &endpointpb.DeleteMachineResponse{}, nil
+}
+
+// WatchMachines streams changes until the client cancels the call. Unlike
+// the SSE stream, falling behind the retained log ends the call with the
+// resume-token-expired problem rather than silently.
+func (s *registerMachineServiceServer) WatchMachines(req *endpointpb.WatchMachinesRequest, stream grpc.ServerStreamingServer[endpointpb.MachineEvent]) error {
+	ctx := stream.Context()
+	instance := endpointpb.RegisterMachineService_WatchMachines_FullMethodName
+
+	if req.GetAfter() < 0 {
+		return invalidField(instance, "after", "must be the sequence of a previously received event")
+	}
+
+	resp, after, err := s.machines.watch(ctx, instance, req.After)
+	if err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+
+	for {
+		for _, change := range resp.Changes {
+			if err := stream.Send(convertMachineChangeToProto(change)); err != nil {
+				return err
+			}
+			after = change.Sequence
+		}
+
+		if len(resp.Changes) < watchPageSize {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-poll.C:
+			}
+		}
+
+		resp, err = s.machines.nextChanges(ctx, instance, after)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func invalidField(instance, field, reason string) *errorpb.ValidationProblem {
+	return errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+		{Field: proto.String(field), Reason: proto.String(reason)},
+	})
+}
+
+// withFieldPrefix qualifies the fields of a validation problem about a
+// message nested in the request.
+func withFieldPrefix(err error, prefix string) error {
+	var vp *errorpb.ValidationProblem
+	if errors.As(err, &vp) {
+		for _, field := range vp.GetInvalidFields() {
+			field.Field = proto.String(prefix + field.GetField())
+		}
+	}
+	return err
+}
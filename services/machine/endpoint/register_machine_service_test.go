@@ -0,0 +1,322 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// dialMachineService serves the gRPC machine service backed by client over
+// an in-memory connection.
+func dialMachineService(t *testing.T, client FirestoreClient) endpointpb.RegisterMachineServiceClient {
+	t.Helper()
+
+	ls := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterMachineService(srv, client, time.Hour, time.Millisecond)
+	go srv.Serve(ls)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ls.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return endpointpb.NewRegisterMachineServiceClient(conn)
+}
+
+// problemDetail returns the errorpb problem carried by a status error.
+func problemDetail(t *testing.T, err error, wantCode codes.Code) proto.Message {
+	t.Helper()
+
+	st, _ := status.FromError(err)
+	if st.Code() != wantCode {
+		t.Fatalf("want code %s, got %s (%v)", wantCode, st.Code(), err)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("want one problem detail, got %v", details)
+	}
+	detail, ok := details[0].(proto.Message)
+	if !ok {
+		t.Fatalf("unexpected detail %T", details[0])
+	}
+	return detail
+}
+
+func TestRegisterMachineServiceServer_RegisterMachine(t *testing.T) {
+	client := &mockFirestoreClient{}
+	c := dialMachineService(t, client)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "idempotency-key", "k1", "x-actor", "alice")
+	resp, err := c.RegisterMachine(ctx, &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("AA-BB-CC-DD-EE-FF")}},
+	})
+	if err != nil {
+		t.Fatalf("RegisterMachine: %v", err)
+	}
+	if resp.GetMachineId() != client.createReq.MachineID {
+		t.Errorf("want machine ID %s, got %s", client.createReq.MachineID, resp.GetMachineId())
+	}
+	if client.createReq.Actor != "alice" {
+		t.Errorf("want actor alice, got %s", client.createReq.Actor)
+	}
+	if record := client.createReq.IdempotencyRecord; record == nil || record.Key != "k1" {
+		t.Errorf("expected an idempotency record for k1, got %+v", record)
+	}
+
+	_, err = c.RegisterMachine(context.Background(), &endpointpb.RegisterMachineRequest{})
+	vp, ok := problemDetail(t, err, codes.InvalidArgument).(*errorpb.ValidationProblem)
+	if !ok || vp.GetInvalidFields()[0].GetField() != "nics" {
+		t.Errorf("expected a validation problem about nics, got %v", vp)
+	}
+}
+
+func TestRegisterMachineServiceServer_GetMachine(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+
+	tests := []struct {
+		name      string
+		machineID string
+		client    *mockFirestoreClient
+		wantCode  codes.Code
+	}{
+		{
+			name:      "invalid machine ID",
+			machineID: "not-a-uuid",
+			client:    &mockFirestoreClient{},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "not found",
+			machineID: machineID,
+			client:    &mockFirestoreClient{getResp: &service.GetMachineResponse{}},
+			wantCode:  codes.NotFound,
+		},
+		{
+			name:      "storage error",
+			machineID: machineID,
+			client:    &mockFirestoreClient{getErr: errors.New("firestore unavailable")},
+			wantCode:  codes.Internal,
+		},
+		{
+			name:      "found",
+			machineID: machineID,
+			client: &mockFirestoreClient{getResp: &service.GetMachineResponse{
+				Found:   true,
+				Machine: &service.Machine{ID: machineID, Revision: 3},
+			}},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dialMachineService(t, tt.client)
+
+			machine, err := c.GetMachine(context.Background(), &endpointpb.GetMachineRequest{MachineId: proto.String(tt.machineID)})
+			if tt.wantCode != codes.OK {
+				detail := problemDetail(t, err, tt.wantCode)
+				if nf, ok := detail.(*errorpb.MachineNotFoundProblem); ok && nf.GetMachineId() != machineID {
+					t.Errorf("want machine ID %s in the problem, got %s", machineID, nf.GetMachineId())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetMachine: %v", err)
+			}
+			if machine.GetId() != machineID {
+				t.Errorf("want machine %s, got %s", machineID, machine.GetId())
+			}
+		})
+	}
+}
+
+func TestRegisterMachineServiceServer_ListMachines(t *testing.T) {
+	client := &mockFirestoreClient{listResp: &service.ListMachinesResponse{NextPageToken: "next"}}
+	c := dialMachineService(t, client)
+
+	resp, err := c.ListMachines(context.Background(), &endpointpb.ListMachinesRequest{
+		PerPage:       proto.Int32(5),
+		LabelSelector: proto.String("rack=r1"),
+	})
+	if err != nil {
+		t.Fatalf("ListMachines: %v", err)
+	}
+	if client.listReq.PageSize != 5 || client.listReq.LabelSelector == nil {
+		t.Errorf("unexpected list request %+v", client.listReq)
+	}
+	if resp.GetPagination().GetNextPageToken() != "next" {
+		t.Errorf("want next page token, got %v", resp.GetPagination())
+	}
+
+	_, err = c.ListMachines(context.Background(), &endpointpb.ListMachinesRequest{PerPage: proto.Int32(0)})
+	problemDetail(t, err, codes.InvalidArgument)
+}
+
+func TestRegisterMachineServiceServer_UpdateMachine(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	current := &service.GetMachineResponse{Found: true, Machine: &service.Machine{ID: machineID, Revision: 2}}
+	machine := &endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: proto.String("aa:bb:cc:dd:ee:ff")}},
+	}
+
+	tests := []struct {
+		name      string
+		req       *endpointpb.UpdateMachineRequest
+		client    *mockFirestoreClient
+		wantCode  codes.Code
+		wantField string
+	}{
+		{
+			name:      "missing machine",
+			req:       &endpointpb.UpdateMachineRequest{MachineId: proto.String(machineID)},
+			client:    &mockFirestoreClient{},
+			wantCode:  codes.InvalidArgument,
+			wantField: "machine",
+		},
+		{
+			name: "invalid machine",
+			req: &endpointpb.UpdateMachineRequest{
+				MachineId: proto.String(machineID),
+				Machine:   &endpointpb.RegisterMachineRequest{},
+			},
+			client:    &mockFirestoreClient{},
+			wantCode:  codes.InvalidArgument,
+			wantField: "machine.nics",
+		},
+		{
+			name: "stale expected revision",
+			req: &endpointpb.UpdateMachineRequest{
+				MachineId:        proto.String(machineID),
+				Machine:          machine,
+				ExpectedRevision: proto.Int64(1),
+			},
+			client:   &mockFirestoreClient{getResp: current},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "MAC conflict",
+			req:  &endpointpb.UpdateMachineRequest{MachineId: proto.String(machineID), Machine: machine},
+			client: &mockFirestoreClient{
+				getResp:   current,
+				updateErr: &service.MACConflictError{MachineID: "other"},
+			},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name: "updated",
+			req: &endpointpb.UpdateMachineRequest{
+				MachineId:        proto.String(machineID),
+				Machine:          machine,
+				ExpectedRevision: proto.Int64(2),
+			},
+			client: &mockFirestoreClient{
+				getResp:    current,
+				updateResp: &service.UpdateMachineResponse{Revision: 3},
+			},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dialMachineService(t, tt.client)
+
+			updated, err := c.UpdateMachine(context.Background(), tt.req)
+			if tt.wantCode != codes.OK {
+				detail := problemDetail(t, err, tt.wantCode)
+				if tt.wantField == "" {
+					return
+				}
+				vp, ok := detail.(*errorpb.ValidationProblem)
+				if !ok || vp.GetInvalidFields()[0].GetField() != tt.wantField {
+					t.Errorf("expected a validation problem about %s, got %v", tt.wantField, detail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateMachine: %v", err)
+			}
+			if got := tt.client.updateReq.ExpectedRevision; got == nil || *got != 2 {
+				t.Errorf("want the update to expect revision 2, got %v", got)
+			}
+			if updated.GetId() != machineID || len(updated.GetNics()) != 1 {
+				t.Errorf("unexpected machine %v", updated)
+			}
+		})
+	}
+}
+
+func TestRegisterMachineServiceServer_DeleteMachine(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	client := &mockFirestoreClient{getResp: &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID, Revision: 2},
+	}}
+	c := dialMachineService(t, client)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "alice")
+	if _, err := c.DeleteMachine(ctx, &endpointpb.DeleteMachineRequest{MachineId: proto.String(machineID)}); err != nil {
+		t.Fatalf("DeleteMachine: %v", err)
+	}
+	if client.deleteReq.DeletedBy != "alice" || client.deleteReq.ExpectedRevision != nil {
+		t.Errorf("unexpected delete request %+v", client.deleteReq)
+	}
+}
+
+func TestRegisterMachineServiceServer_WatchMachines(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	change := func(sequence int64) *service.MachineChange {
+		return &service.MachineChange{Sequence: sequence, Type: service.ChangeUpdated, MachineID: machineID}
+	}
+
+	client := &mockFirestoreClient{changesResps: []*service.ListMachineChangesResponse{
+		{Changes: []*service.MachineChange{change(4)}, Latest: 4},
+		{Latest: 4},
+		{Changes: []*service.MachineChange{change(5)}, Latest: 5},
+		// A gap means the changes in between were purged.
+		{Changes: []*service.MachineChange{change(9)}, Latest: 9},
+	}}
+	c := dialMachineService(t, client)
+
+	stream, err := c.WatchMachines(context.Background(), &endpointpb.WatchMachinesRequest{After: proto.Int64(3)})
+	if err != nil {
+		t.Fatalf("WatchMachines: %v", err)
+	}
+
+	var sequences []int64
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			t.Fatal("expected the stream to end with an error")
+		}
+		if err != nil {
+			problemDetail(t, err, codes.OutOfRange)
+			break
+		}
+		sequences = append(sequences, event.GetSequence())
+	}
+	if len(sequences) != 2 || sequences[0] != 4 || sequences[1] != 5 {
+		t.Errorf("want events 4 and 5, got %v", sequences)
+	}
+}
@@ -2,26 +2,49 @@ package endpoint
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
-	"regexp"
+	"time"
 
 	"github.com/Zaba505/infra/pkg/errorpb"
 	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
 	"github.com/Zaba505/infra/services/machine/service"
 	"github.com/go-chi/chi/v5"
 
-	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 )
 
+// FirestoreClient is the storage interface the handlers depend on. Despite
+// its name it is implemented by every storage backend in package service,
+// not only Firestore.
 type FirestoreClient interface {
 	CreateMachine(ctx context.Context, req *service.CreateMachineRequest) (*service.CreateMachineResponse, error)
+	CreateMachines(ctx context.Context, req *service.CreateMachinesRequest) (*service.CreateMachinesResponse, error)
+	GetMachine(ctx context.Context, req *service.GetMachineRequest) (*service.GetMachineResponse, error)
+	UpdateMachine(ctx context.Context, req *service.UpdateMachineRequest) (*service.UpdateMachineResponse, error)
+	DeleteMachine(ctx context.Context, req *service.DeleteMachineRequest) (*service.DeleteMachineResponse, error)
+	RestoreMachine(ctx context.Context, req *service.RestoreMachineRequest) (*service.RestoreMachineResponse, error)
+	ListMachines(ctx context.Context, req *service.ListMachinesRequest) (*service.ListMachinesResponse, error)
 	FindMachineByMAC(ctx context.Context, req *service.FindMachineByMACRequest) (*service.FindMachineByMACResponse, error)
+	GetIdempotencyRecord(ctx context.Context, req *service.GetIdempotencyRecordRequest) (*service.GetIdempotencyRecordResponse, error)
+	TransitionMachine(ctx context.Context, req *service.TransitionMachineRequest) (*service.TransitionMachineResponse, error)
+	SetMachineLabels(ctx context.Context, req *service.SetMachineLabelsRequest) (*service.SetMachineLabelsResponse, error)
+	GetInventorySummary(ctx context.Context, req *service.GetInventorySummaryRequest) (*service.GetInventorySummaryResponse, error)
+	ListMachineRevisions(ctx context.Context, req *service.ListMachineRevisionsRequest) (*service.ListMachineRevisionsResponse, error)
+	GetMachineRevision(ctx context.Context, req *service.GetMachineRevisionRequest) (*service.GetMachineRevisionResponse, error)
+	RollbackMachine(ctx context.Context, req *service.RollbackMachineRequest) (*service.RollbackMachineResponse, error)
+	ListMachineChanges(ctx context.Context, req *service.ListMachineChangesRequest) (*service.ListMachineChangesResponse, error)
+	CreateWebhook(ctx context.Context, req *service.CreateWebhookRequest) (*service.CreateWebhookResponse, error)
+	GetWebhook(ctx context.Context, req *service.GetWebhookRequest) (*service.GetWebhookResponse, error)
+	ListWebhooks(ctx context.Context, req *service.ListWebhooksRequest) (*service.ListWebhooksResponse, error)
+	UpdateWebhook(ctx context.Context, req *service.UpdateWebhookRequest) (*service.UpdateWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, req *service.DeleteWebhookRequest) (*service.DeleteWebhookResponse, error)
+	ListWebhookDeliveries(ctx context.Context, req *service.ListWebhookDeliveriesRequest) (*service.ListWebhookDeliveriesResponse, error)
+	RedeliverWebhookDelivery(ctx context.Context, req *service.RedeliverWebhookDeliveryRequest) (*service.RedeliverWebhookDeliveryResponse, error)
 	Close() error
 }
 
@@ -29,13 +52,17 @@ type registerMachinesHandler struct {
 	tracer          trace.Tracer
 	log             *slog.Logger
 	firestoreClient FirestoreClient
+	idempotencyTTL  time.Duration
 }
 
-func RegisterMachines(mux *chi.Mux, firestoreClient FirestoreClient) {
+// RegisterMachines registers the machine registration endpoint. Responses to
+// requests carrying an Idempotency-Key are replayed for idempotencyTTL.
+func RegisterMachines(mux *chi.Mux, firestoreClient FirestoreClient, idempotencyTTL time.Duration) {
 	handler := &registerMachinesHandler{
 		tracer:          otel.Tracer("machine/endpoint"),
 		log:             slog.Default(),
 		firestoreClient: firestoreClient,
+		idempotencyTTL:  idempotencyTTL,
 	}
 
 	mux.Method(http.MethodPost, "/api/v1/machines", handler)
@@ -44,104 +71,203 @@ func RegisterMachines(mux *chi.Mux, firestoreClient FirestoreClient) {
 func (h *registerMachinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		errorHandler(ctx, w, errorpb.NewInternalError("/api/v1/machines", fmt.Sprintf("failed to read request body: %v", err)))
+	var req endpointpb.RegisterMachineRequest
+	if err := readRequest(r, "/api/v1/machines", &req); err != nil {
+		errorHandler(ctx, w, err)
 		return
 	}
 
-	var req endpointpb.RegisterMachineRequest
-	if err := proto.Unmarshal(body, &req); err != nil {
-		errorHandler(ctx, w, errorpb.NewValidationError("/api/v1/machines", []*errorpb.InvalidField{
-			{Field: proto.String("body"), Reason: proto.String(fmt.Sprintf("invalid protobuf: %v", err))},
-		}))
+	var idempotencyKey *string
+	if key, ok := r.Header[idempotencyKeyHeader]; ok {
+		idempotencyKey = &key[0]
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient, idempotencyTTL: h.idempotencyTTL}
+	resp, replayed, err := machines.register(ctx, "/api/v1/machines", &req, requestActor(r), idempotencyKey)
+	if err != nil {
+		errorHandler(ctx, w, err)
 		return
 	}
 
+	if replayed {
+		w.Header().Set(idempotentReplayedHeader, "true")
+	}
+	writeResponse(ctx, w, "/api/v1/machines", http.StatusCreated, resp)
+}
+
+// machineProfile is the hardware profile shared by RegisterMachineRequest and
+// Machine, letting both go through the same validation and conversion.
+type machineProfile interface {
+	GetCpus() []*endpointpb.CPU
+	GetMemoryModules() []*endpointpb.MemoryModule
+	GetAccelerators() []*endpointpb.Accelerator
+	GetNics() []*endpointpb.NIC
+	GetDrives() []*endpointpb.Drive
+}
+
+func validateMachineRequest(req machineProfile) []*errorpb.InvalidField {
 	nics := req.GetNics()
 	if len(nics) == 0 {
-		errorHandler(ctx, w, errorpb.NewValidationError("/api/v1/machines", []*errorpb.InvalidField{
+		return []*errorpb.InvalidField{
 			{Field: proto.String("nics"), Reason: proto.String("at least one NIC is required")},
-		}))
-		return
+		}
 	}
 
-	var invalidFields []*errorpb.InvalidField
+	var v fieldValidator
+	seen := make(map[service.MAC]bool, len(nics))
 	for i, nic := range nics {
-		if err := validateMACAddress(nic.GetMac()); err != nil {
-			invalidFields = append(invalidFields, &errorpb.InvalidField{
-				Field:  proto.String(fmt.Sprintf("nics[%d].mac", i)),
-				Reason: proto.String(err.Error()),
-			})
+		field := fmt.Sprintf("nics[%d]", i)
+		mac, err := service.ParseMAC(nic.GetMac())
+		switch {
+		case err != nil:
+			v.add(field+".mac", err.Error())
+		case seen[mac]:
+			v.add(field+".mac", "duplicate MAC address")
 		}
-	}
-	if len(invalidFields) > 0 {
-		errorHandler(ctx, w, errorpb.NewValidationError("/api/v1/machines", invalidFields))
-		return
+		seen[mac] = true
+
+		v.nonNegative(field+".speed", nic.GetSpeed())
+		v.pciAddress(field+".pci_address", nic.GetPciAddress())
 	}
 
-	for _, nic := range nics {
-		resp, err := h.firestoreClient.FindMachineByMAC(ctx, &service.FindMachineByMACRequest{
-			MAC: nic.GetMac(),
-		})
-		if err != nil {
-			errorHandler(ctx, w, errorpb.NewInternalError("/api/v1/machines", fmt.Sprintf("failed to check MAC uniqueness: %v", err)))
-			return
+	sockets := make(map[string]bool)
+	for i, cpu := range req.GetCpus() {
+		field := fmt.Sprintf("cpus[%d]", i)
+		v.nonNegative(field+".clock_frequency", cpu.GetClockFrequency())
+		v.nonNegative(field+".cores", cpu.GetCores())
+		v.nonNegative(field+".threads", cpu.GetThreads())
+		if cpu.GetThreads() > 0 && cpu.GetThreads() < cpu.GetCores() {
+			v.add(field+".threads", "cannot be fewer than cores")
 		}
-		if resp.Found {
-			errorHandler(ctx, w, errorpb.NewConflictError("/api/v1/machines", resp.MachineID, map[string]string{"mac_address": nic.GetMac()}))
-			return
+		v.unique(field+".socket", cpu.GetSocket(), sockets)
+	}
+
+	slots := make(map[string]bool)
+	for i, module := range req.GetMemoryModules() {
+		field := fmt.Sprintf("memory_modules[%d]", i)
+		v.nonNegative(field+".size", module.GetSize())
+		v.nonNegative(field+".speed", module.GetSpeed())
+		if _, ok := memoryTypes[module.GetType()]; !ok {
+			v.add(field+".type", "unknown memory type")
 		}
+		v.unique(field+".slot", module.GetSlot(), slots)
 	}
 
-	machineID, err := uuid.NewV7()
-	if err != nil {
-		errorHandler(ctx, w, errorpb.NewInternalError("/api/v1/machines", fmt.Sprintf("failed to generate machine ID: %v", err)))
-		return
+	for i, accelerator := range req.GetAccelerators() {
+		v.pciAddress(fmt.Sprintf("accelerators[%d].pci_address", i), accelerator.GetPciAddress())
 	}
 
-	serviceReq := &service.MachineRequest{
-		CPUs:          convertCPUs(req.GetCpus()),
-		MemoryModules: convertMemoryModules(req.GetMemoryModules()),
-		Accelerators:  convertAccelerators(req.GetAccelerators()),
-		NICs:          convertNICs(nics),
-		Drives:        convertDrives(req.GetDrives()),
+	bays := make(map[string]bool)
+	for i, drive := range req.GetDrives() {
+		field := fmt.Sprintf("drives[%d]", i)
+		v.nonNegative(field+".capacity", drive.GetCapacity())
+		if _, ok := driveMediaTypes[drive.GetMediaType()]; !ok {
+			v.add(field+".media_type", "unknown drive media type")
+		}
+		if _, ok := driveInterfaces[drive.GetInterface()]; !ok {
+			v.add(field+".interface", "unknown drive interface")
+		}
+		v.unique(field+".slot", drive.GetSlot(), bays)
 	}
 
-	_, err = h.firestoreClient.CreateMachine(ctx, &service.CreateMachineRequest{
-		MachineID: machineID.String(),
-		Machine:   serviceReq,
+	return v.invalidFields
+}
+
+// fieldValidator collects the invalid fields of a hardware profile.
+type fieldValidator struct {
+	invalidFields []*errorpb.InvalidField
+}
+
+func (v *fieldValidator) add(field, reason string) {
+	v.invalidFields = append(v.invalidFields, &errorpb.InvalidField{
+		Field:  proto.String(field),
+		Reason: proto.String(reason),
 	})
-	if err != nil {
-		errorHandler(ctx, w, errorpb.NewInternalError("/api/v1/machines", fmt.Sprintf("failed to create machine: %v", err)))
-		return
+}
+
+func (v *fieldValidator) nonNegative(field string, n int64) {
+	if n < 0 {
+		v.add(field, "cannot be negative")
 	}
+}
 
-	machineIDStr := machineID.String()
-	resp := &endpointpb.RegisterMachineResponse{
-		MachineId: &machineIDStr,
+// pciAddress checks an optional PCI address.
+func (v *fieldValidator) pciAddress(field, addr string) {
+	if addr == "" {
+		return
 	}
-	respBody, err := proto.Marshal(resp)
-	if err != nil {
-		errorHandler(ctx, w, errorpb.NewInternalError("/api/v1/machines", fmt.Sprintf("failed to marshal response: %v", err)))
+	if _, err := service.ParsePCIAddress(addr); err != nil {
+		v.add(field, err.Error())
+	}
+}
+
+// unique checks that an optional location, such as a DIMM slot, is not
+// claimed by two components of the same kind.
+func (v *fieldValidator) unique(field, location string, seen map[string]bool) {
+	if location == "" {
 		return
 	}
+	if seen[location] {
+		v.add(field, fmt.Sprintf("duplicate location %q", location))
+	}
+	seen[location] = true
+}
 
-	w.Header().Set("Content-Type", "application/x-protobuf")
-	w.WriteHeader(http.StatusCreated)
-	w.Write(respBody)
+func validateMACAddress(mac string) error {
+	_, err := service.ParseMAC(mac)
+	return err
 }
 
-var macAddressRegex = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+var (
+	memoryTypes = map[endpointpb.MemoryType]service.MemoryType{
+		endpointpb.MemoryType_MEMORY_TYPE_UNSPECIFIED: "",
+		endpointpb.MemoryType_MEMORY_TYPE_DDR3:        service.MemoryTypeDDR3,
+		endpointpb.MemoryType_MEMORY_TYPE_DDR4:        service.MemoryTypeDDR4,
+		endpointpb.MemoryType_MEMORY_TYPE_DDR5:        service.MemoryTypeDDR5,
+	}
+	driveMediaTypes = map[endpointpb.DriveMediaType]service.DriveMediaType{
+		endpointpb.DriveMediaType_DRIVE_MEDIA_TYPE_UNSPECIFIED: "",
+		endpointpb.DriveMediaType_DRIVE_MEDIA_TYPE_HDD:         service.DriveMediaTypeHDD,
+		endpointpb.DriveMediaType_DRIVE_MEDIA_TYPE_SSD:         service.DriveMediaTypeSSD,
+	}
+	driveInterfaces = map[endpointpb.DriveInterface]service.DriveInterface{
+		endpointpb.DriveInterface_DRIVE_INTERFACE_UNSPECIFIED: "",
+		endpointpb.DriveInterface_DRIVE_INTERFACE_SATA:        service.DriveInterfaceSATA,
+		endpointpb.DriveInterface_DRIVE_INTERFACE_SAS:         service.DriveInterfaceSAS,
+		endpointpb.DriveInterface_DRIVE_INTERFACE_NVME:        service.DriveInterfaceNVMe,
+	}
+)
 
-func validateMACAddress(mac string) error {
-	if mac == "" {
-		return fmt.Errorf("MAC address cannot be empty")
+// protoEnum finds the proto value mapped to v, falling back to the zero
+// (unspecified) value for anything stored that this version does not know.
+func protoEnum[P comparable, S comparable](m map[P]S, v S) P {
+	for p, s := range m {
+		if s == v {
+			return p
+		}
 	}
-	if !macAddressRegex.MatchString(mac) {
-		return fmt.Errorf("invalid MAC address format, expected format: aa:bb:cc:dd:ee:ff")
+	var zero P
+	return zero
+}
+
+// canonicalPCIAddress returns the canonical form of an already validated,
+// optional PCI address.
+func canonicalPCIAddress(addr string) service.PCIAddress {
+	if addr == "" {
+		return ""
+	}
+	canonical, _ := service.ParsePCIAddress(addr)
+	return canonical
+}
+
+func convertMachineRequest(req machineProfile) *service.MachineRequest {
+	return &service.MachineRequest{
+		CPUs:          convertCPUs(req.GetCpus()),
+		MemoryModules: convertMemoryModules(req.GetMemoryModules()),
+		Accelerators:  convertAccelerators(req.GetAccelerators()),
+		NICs:          convertNICs(req.GetNics()),
+		Drives:        convertDrives(req.GetDrives()),
 	}
-	return nil
 }
 
 func convertCPUs(cpus []*endpointpb.CPU) []service.CPU {
@@ -149,8 +275,11 @@ func convertCPUs(cpus []*endpointpb.CPU) []service.CPU {
 	for i, cpu := range cpus {
 		result[i] = service.CPU{
 			Manufacturer:   cpu.GetManufacturer(),
+			Model:          cpu.GetModel(),
+			Socket:         cpu.GetSocket(),
 			ClockFrequency: cpu.GetClockFrequency(),
 			Cores:          cpu.GetCores(),
+			Threads:        cpu.GetThreads(),
 		}
 	}
 	return result
@@ -160,7 +289,13 @@ func convertMemoryModules(modules []*endpointpb.MemoryModule) []service.MemoryMo
 	result := make([]service.MemoryModule, len(modules))
 	for i, module := range modules {
 		result[i] = service.MemoryModule{
-			Size: module.GetSize(),
+			Size:         module.GetSize(),
+			Type:         memoryTypes[module.GetType()],
+			Speed:        module.GetSpeed(),
+			Slot:         module.GetSlot(),
+			Manufacturer: module.GetManufacturer(),
+			Model:        module.GetModel(),
+			SerialNumber: module.GetSerialNumber(),
 		}
 	}
 	return result
@@ -171,6 +306,9 @@ func convertAccelerators(accelerators []*endpointpb.Accelerator) []service.Accel
 	for i, accelerator := range accelerators {
 		result[i] = service.Accelerator{
 			Manufacturer: accelerator.GetManufacturer(),
+			Model:        accelerator.GetModel(),
+			SerialNumber: accelerator.GetSerialNumber(),
+			PCIAddress:   canonicalPCIAddress(accelerator.GetPciAddress()),
 		}
 	}
 	return result
@@ -179,8 +317,16 @@ func convertAccelerators(accelerators []*endpointpb.Accelerator) []service.Accel
 func convertNICs(nics []*endpointpb.NIC) []service.NIC {
 	result := make([]service.NIC, len(nics))
 	for i, nic := range nics {
+		// Already checked by validateMachineRequest, so only the
+		// canonical form is of interest here.
+		mac, _ := service.ParseMAC(nic.GetMac())
 		result[i] = service.NIC{
-			MAC: nic.GetMac(),
+			MAC:        mac,
+			Name:       nic.GetName(),
+			Speed:      nic.GetSpeed(),
+			Driver:     nic.GetDriver(),
+			PCIAddress: canonicalPCIAddress(nic.GetPciAddress()),
+			Model:      nic.GetModel(),
 		}
 	}
 	return result
@@ -190,18 +336,128 @@ func convertDrives(drives []*endpointpb.Drive) []service.Drive {
 	result := make([]service.Drive, len(drives))
 	for i, drive := range drives {
 		result[i] = service.Drive{
-			Capacity: drive.GetCapacity(),
+			Capacity:     drive.GetCapacity(),
+			MediaType:    driveMediaTypes[drive.GetMediaType()],
+			Interface:    driveInterfaces[drive.GetInterface()],
+			Model:        drive.GetModel(),
+			SerialNumber: drive.GetSerialNumber(),
+			Slot:         drive.GetSlot(),
 		}
 	}
 	return result
 }
 
+func convertMachineToProto(machine *service.Machine) *endpointpb.Machine {
+	return &endpointpb.Machine{
+		Id:            proto.String(machine.ID),
+		Cpus:          convertCPUsToProto(machine.CPUs),
+		MemoryModules: convertMemoryModulesToProto(machine.MemoryModules),
+		Accelerators:  convertAcceleratorsToProto(machine.Accelerators),
+		Nics:          convertNICsToProto(machine.NICs),
+		Drives:        convertDrivesToProto(machine.Drives),
+
+		LifecycleState:   protoEnum(lifecycleStates, machine.CurrentLifecycleState()).Enum(),
+		LifecycleHistory: convertLifecycleHistoryToProto(machine.LifecycleHistory),
+		Labels:           machine.Labels,
+	}
+}
+
+func convertCPUsToProto(cpus []service.CPU) []*endpointpb.CPU {
+	result := make([]*endpointpb.CPU, len(cpus))
+	for i, cpu := range cpus {
+		result[i] = &endpointpb.CPU{
+			Manufacturer:   proto.String(cpu.Manufacturer),
+			Model:          proto.String(cpu.Model),
+			Socket:         proto.String(cpu.Socket),
+			ClockFrequency: proto.Int64(cpu.ClockFrequency),
+			Cores:          proto.Int64(cpu.Cores),
+			Threads:        proto.Int64(cpu.Threads),
+		}
+	}
+	return result
+}
+
+func convertMemoryModulesToProto(modules []service.MemoryModule) []*endpointpb.MemoryModule {
+	result := make([]*endpointpb.MemoryModule, len(modules))
+	for i, module := range modules {
+		result[i] = &endpointpb.MemoryModule{
+			Size:         proto.Int64(module.Size),
+			Type:         protoEnum(memoryTypes, module.Type).Enum(),
+			Speed:        proto.Int64(module.Speed),
+			Slot:         proto.String(module.Slot),
+			Manufacturer: proto.String(module.Manufacturer),
+			Model:        proto.String(module.Model),
+			SerialNumber: proto.String(module.SerialNumber),
+		}
+	}
+	return result
+}
+
+func convertAcceleratorsToProto(accelerators []service.Accelerator) []*endpointpb.Accelerator {
+	result := make([]*endpointpb.Accelerator, len(accelerators))
+	for i, accelerator := range accelerators {
+		result[i] = &endpointpb.Accelerator{
+			Manufacturer: proto.String(accelerator.Manufacturer),
+			Model:        proto.String(accelerator.Model),
+			SerialNumber: proto.String(accelerator.SerialNumber),
+			PciAddress:   proto.String(accelerator.PCIAddress.String()),
+		}
+	}
+	return result
+}
+
+func convertNICsToProto(nics []service.NIC) []*endpointpb.NIC {
+	result := make([]*endpointpb.NIC, len(nics))
+	for i, nic := range nics {
+		result[i] = &endpointpb.NIC{
+			Mac:        proto.String(nic.MAC.String()),
+			Name:       proto.String(nic.Name),
+			Speed:      proto.Int64(nic.Speed),
+			Driver:     proto.String(nic.Driver),
+			PciAddress: proto.String(nic.PCIAddress.String()),
+			Model:      proto.String(nic.Model),
+		}
+	}
+	return result
+}
+
+func convertDrivesToProto(drives []service.Drive) []*endpointpb.Drive {
+	result := make([]*endpointpb.Drive, len(drives))
+	for i, drive := range drives {
+		result[i] = &endpointpb.Drive{
+			Capacity:     proto.Int64(drive.Capacity),
+			MediaType:    protoEnum(driveMediaTypes, drive.MediaType).Enum(),
+			Interface:    protoEnum(driveInterfaces, drive.Interface).Enum(),
+			Model:        proto.String(drive.Model),
+			SerialNumber: proto.String(drive.SerialNumber),
+			Slot:         proto.String(drive.Slot),
+		}
+	}
+	return result
+}
+
+func asMACConflict(err error) (*service.MACConflictError, bool) {
+	var conflict *service.MACConflictError
+	ok := errors.As(err, &conflict)
+	return conflict, ok
+}
+
+func newMACConflictError(instance string, conflict *service.MACConflictError) *errorpb.ConflictProblem {
+	return errorpb.NewConflictError(instance, conflict.MachineID, map[string]string{"mac_address": conflict.MAC.String()})
+}
+
 func errorHandler(ctx context.Context, w http.ResponseWriter, err error) {
 	switch e := err.(type) {
 	case *errorpb.ValidationProblem:
 		e.WriteHttpResponse(ctx, w)
 	case *errorpb.ConflictProblem:
 		e.WriteHttpResponse(ctx, w)
+	case *errorpb.MachineNotFoundProblem:
+		e.WriteHttpResponse(ctx, w)
+	case *errorpb.IdempotencyKeyMismatchProblem:
+		e.WriteHttpResponse(ctx, w)
+	case *errorpb.IllegalTransitionProblem:
+		e.WriteHttpResponse(ctx, w)
 	case *errorpb.Problem:
 		e.WriteHttpResponse(ctx, w)
 	default:
@@ -17,19 +17,188 @@ import (
 )
 
 type mockFirestoreClient struct {
-	findResp  *service.FindMachineByMACResponse
-	findErr   error
-	createErr error
+	findResp   *service.FindMachineByMACResponse
+	findErr    error
+	createReq  *service.CreateMachineRequest
+	createErr  error
+	batchReq   *service.CreateMachinesRequest
+	batchErr   error
+	idemResp   *service.GetIdempotencyRecordResponse
+	idemErr    error
+	getResp    *service.GetMachineResponse
+	getErr     error
+	listReq    *service.ListMachinesRequest
+	listResp   *service.ListMachinesResponse
+	listErr    error
+	updateReq  *service.UpdateMachineRequest
+	updateResp *service.UpdateMachineResponse
+	updateErr  error
+	deleteReq  *service.DeleteMachineRequest
+	deleteErr  error
+	restoreErr error
+
+	transitionReq  *service.TransitionMachineRequest
+	transitionResp *service.TransitionMachineResponse
+	transitionErr  error
+
+	labelsReq *service.SetMachineLabelsRequest
+	labelsErr error
+
+	summaryResp *service.GetInventorySummaryResponse
+	summaryErr  error
+
+	revisionsReq  *service.ListMachineRevisionsRequest
+	revisionsResp *service.ListMachineRevisionsResponse
+	revisionsErr  error
+	revisionResp  *service.GetMachineRevisionResponse
+	revisionErr   error
+	rollbackReq   *service.RollbackMachineRequest
+	rollbackResp  *service.RollbackMachineResponse
+	rollbackErr   error
+
+	// changesResps are returned by successive ListMachineChanges calls,
+	// after which changesErr is.
+	changesReqs  []*service.ListMachineChangesRequest
+	changesResps []*service.ListMachineChangesResponse
+	changesErr   error
+
+	webhookCreateReq *service.CreateWebhookRequest
+	webhookCreateErr error
+	webhookGetResp   *service.GetWebhookResponse
+	webhookGetErr    error
+	webhooksResp     *service.ListWebhooksResponse
+	webhooksErr      error
+	webhookUpdateReq *service.UpdateWebhookRequest
+	webhookUpdateErr error
+	webhookDeleteErr error
+	deliveriesReq    *service.ListWebhookDeliveriesRequest
+	deliveriesResp   *service.ListWebhookDeliveriesResponse
+	deliveriesErr    error
+	redeliverReq     *service.RedeliverWebhookDeliveryRequest
+	redeliverResp    *service.RedeliverWebhookDeliveryResponse
+	redeliverErr     error
 }
 
 func (m *mockFirestoreClient) FindMachineByMAC(_ context.Context, _ *service.FindMachineByMACRequest) (*service.FindMachineByMACResponse, error) {
 	return m.findResp, m.findErr
 }
 
-func (m *mockFirestoreClient) CreateMachine(_ context.Context, _ *service.CreateMachineRequest) (*service.CreateMachineResponse, error) {
+func (m *mockFirestoreClient) CreateMachine(_ context.Context, req *service.CreateMachineRequest) (*service.CreateMachineResponse, error) {
+	m.createReq = req
 	return &service.CreateMachineResponse{}, m.createErr
 }
 
+func (m *mockFirestoreClient) CreateMachines(_ context.Context, req *service.CreateMachinesRequest) (*service.CreateMachinesResponse, error) {
+	m.batchReq = req
+	return &service.CreateMachinesResponse{}, m.batchErr
+}
+
+func (m *mockFirestoreClient) GetMachine(_ context.Context, _ *service.GetMachineRequest) (*service.GetMachineResponse, error) {
+	return m.getResp, m.getErr
+}
+
+func (m *mockFirestoreClient) ListMachines(_ context.Context, req *service.ListMachinesRequest) (*service.ListMachinesResponse, error) {
+	m.listReq = req
+	return m.listResp, m.listErr
+}
+
+func (m *mockFirestoreClient) UpdateMachine(_ context.Context, req *service.UpdateMachineRequest) (*service.UpdateMachineResponse, error) {
+	m.updateReq = req
+	if m.updateResp == nil {
+		return &service.UpdateMachineResponse{}, m.updateErr
+	}
+	return m.updateResp, m.updateErr
+}
+
+func (m *mockFirestoreClient) DeleteMachine(_ context.Context, req *service.DeleteMachineRequest) (*service.DeleteMachineResponse, error) {
+	m.deleteReq = req
+	return &service.DeleteMachineResponse{}, m.deleteErr
+}
+
+func (m *mockFirestoreClient) RestoreMachine(_ context.Context, _ *service.RestoreMachineRequest) (*service.RestoreMachineResponse, error) {
+	return &service.RestoreMachineResponse{}, m.restoreErr
+}
+
+func (m *mockFirestoreClient) GetIdempotencyRecord(_ context.Context, _ *service.GetIdempotencyRecordRequest) (*service.GetIdempotencyRecordResponse, error) {
+	if m.idemResp == nil {
+		return &service.GetIdempotencyRecordResponse{}, m.idemErr
+	}
+	return m.idemResp, m.idemErr
+}
+
+func (m *mockFirestoreClient) TransitionMachine(_ context.Context, req *service.TransitionMachineRequest) (*service.TransitionMachineResponse, error) {
+	m.transitionReq = req
+	return m.transitionResp, m.transitionErr
+}
+
+func (m *mockFirestoreClient) SetMachineLabels(_ context.Context, req *service.SetMachineLabelsRequest) (*service.SetMachineLabelsResponse, error) {
+	m.labelsReq = req
+	return &service.SetMachineLabelsResponse{Revision: 1}, m.labelsErr
+}
+
+func (m *mockFirestoreClient) GetInventorySummary(_ context.Context, _ *service.GetInventorySummaryRequest) (*service.GetInventorySummaryResponse, error) {
+	return m.summaryResp, m.summaryErr
+}
+
+func (m *mockFirestoreClient) ListMachineRevisions(_ context.Context, req *service.ListMachineRevisionsRequest) (*service.ListMachineRevisionsResponse, error) {
+	m.revisionsReq = req
+	return m.revisionsResp, m.revisionsErr
+}
+
+func (m *mockFirestoreClient) GetMachineRevision(_ context.Context, _ *service.GetMachineRevisionRequest) (*service.GetMachineRevisionResponse, error) {
+	return m.revisionResp, m.revisionErr
+}
+
+func (m *mockFirestoreClient) RollbackMachine(_ context.Context, req *service.RollbackMachineRequest) (*service.RollbackMachineResponse, error) {
+	m.rollbackReq = req
+	return m.rollbackResp, m.rollbackErr
+}
+
+func (m *mockFirestoreClient) ListMachineChanges(_ context.Context, req *service.ListMachineChangesRequest) (*service.ListMachineChangesResponse, error) {
+	m.changesReqs = append(m.changesReqs, req)
+	if len(m.changesResps) == 0 {
+		if m.changesErr == nil {
+			return nil, fmt.Errorf("no more changes")
+		}
+		return nil, m.changesErr
+	}
+	resp := m.changesResps[0]
+	m.changesResps = m.changesResps[1:]
+	return resp, nil
+}
+
+func (m *mockFirestoreClient) CreateWebhook(_ context.Context, req *service.CreateWebhookRequest) (*service.CreateWebhookResponse, error) {
+	m.webhookCreateReq = req
+	return &service.CreateWebhookResponse{}, m.webhookCreateErr
+}
+
+func (m *mockFirestoreClient) GetWebhook(_ context.Context, _ *service.GetWebhookRequest) (*service.GetWebhookResponse, error) {
+	return m.webhookGetResp, m.webhookGetErr
+}
+
+func (m *mockFirestoreClient) ListWebhooks(_ context.Context, _ *service.ListWebhooksRequest) (*service.ListWebhooksResponse, error) {
+	return m.webhooksResp, m.webhooksErr
+}
+
+func (m *mockFirestoreClient) UpdateWebhook(_ context.Context, req *service.UpdateWebhookRequest) (*service.UpdateWebhookResponse, error) {
+	m.webhookUpdateReq = req
+	return &service.UpdateWebhookResponse{}, m.webhookUpdateErr
+}
+
+func (m *mockFirestoreClient) DeleteWebhook(_ context.Context, _ *service.DeleteWebhookRequest) (*service.DeleteWebhookResponse, error) {
+	return &service.DeleteWebhookResponse{}, m.webhookDeleteErr
+}
+
+func (m *mockFirestoreClient) ListWebhookDeliveries(_ context.Context, req *service.ListWebhookDeliveriesRequest) (*service.ListWebhookDeliveriesResponse, error) {
+	m.deliveriesReq = req
+	return m.deliveriesResp, m.deliveriesErr
+}
+
+func (m *mockFirestoreClient) RedeliverWebhookDelivery(_ context.Context, req *service.RedeliverWebhookDeliveryRequest) (*service.RedeliverWebhookDeliveryResponse, error) {
+	m.redeliverReq = req
+	return m.redeliverResp, m.redeliverErr
+}
+
 func (m *mockFirestoreClient) Close() error { return nil }
 
 func TestValidateMACAddress(t *testing.T) {
@@ -59,13 +228,33 @@ func TestValidateMACAddress(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "invalid format - missing colons",
+			name:    "valid MAC address bare hex",
 			mac:     "aabbccddeeff",
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name:    "invalid format - wrong separator",
+			name:    "valid MAC address dash separated",
 			mac:     "aa-bb-cc-dd-ee-ff",
+			wantErr: false,
+		},
+		{
+			name:    "valid MAC address cisco dot notation",
+			mac:     "aabb.ccdd.eeff",
+			wantErr: false,
+		},
+		{
+			name:    "invalid format - mixed separators",
+			mac:     "aa-bb:cc-dd:ee-ff",
+			wantErr: true,
+		},
+		{
+			name:    "multicast MAC address",
+			mac:     "01:00:5e:00:00:01",
+			wantErr: true,
+		},
+		{
+			name:    "broadcast MAC address",
+			mac:     "ff:ff:ff:ff:ff:ff",
 			wantErr: true,
 		},
 		{
@@ -163,16 +352,30 @@ func TestRegisterMachinesHandler_ServeHTTP(t *testing.T) {
 			},
 		},
 		{
-			name:     "FindMachineByMAC error",
-			body:     validBody,
-			client:   &mockFirestoreClient{findErr: fmt.Errorf("firestore unavailable")},
-			wantCode: http.StatusInternalServerError,
+			name: "duplicate MAC in request",
+			body: func() []byte {
+				b, _ := proto.Marshal(&endpointpb.RegisterMachineRequest{
+					Nics: []*endpointpb.NIC{{Mac: &mac}, {Mac: proto.String("AA:BB:CC:DD:EE:FF")}},
+				})
+				return b
+			}(),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "nics[1].mac" {
+					t.Errorf("expected invalid field 'nics[1].mac', got %v", p.GetInvalidFields())
+				}
+			},
 		},
 		{
 			name: "duplicate MAC conflict",
 			body: validBody,
 			client: &mockFirestoreClient{
-				findResp: &service.FindMachineByMACResponse{Found: true, MachineID: "existing-id"},
+				createErr: fmt.Errorf("failed to create machine document: %w", &service.MACConflictError{MAC: service.MAC(mac), MachineID: "existing-id"}),
 			},
 			wantCode: http.StatusConflict,
 			checkBody: func(t *testing.T, body []byte) {
@@ -189,17 +392,14 @@ func TestRegisterMachinesHandler_ServeHTTP(t *testing.T) {
 			name: "CreateMachine error",
 			body: validBody,
 			client: &mockFirestoreClient{
-				findResp:  &service.FindMachineByMACResponse{Found: false},
 				createErr: fmt.Errorf("write failed"),
 			},
 			wantCode: http.StatusInternalServerError,
 		},
 		{
-			name: "success",
-			body: validBody,
-			client: &mockFirestoreClient{
-				findResp: &service.FindMachineByMACResponse{Found: false},
-			},
+			name:     "success",
+			body:     validBody,
+			client:   &mockFirestoreClient{},
 			wantCode: http.StatusCreated,
 			checkBody: func(t *testing.T, body []byte) {
 				var resp endpointpb.RegisterMachineResponse
@@ -234,3 +434,202 @@ func TestRegisterMachinesHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertNICs_CanonicalMAC(t *testing.T) {
+	nics := convertNICs([]*endpointpb.NIC{
+		{Mac: proto.String("AA-BB-CC-DD-EE-FF")},
+		{Mac: proto.String("5254.0012.3456")},
+	})
+
+	want := []service.MAC{"aa:bb:cc:dd:ee:ff", "52:54:00:12:34:56"}
+	for i, nic := range nics {
+		if nic.MAC != want[i] {
+			t.Errorf("nics[%d]: want %q, got %q", i, want[i], nic.MAC)
+		}
+	}
+}
+
+func TestValidateMachineRequest_Hardware(t *testing.T) {
+	nic := func() *endpointpb.NIC {
+		return &endpointpb.NIC{Mac: proto.String("52:54:00:12:34:56")}
+	}
+
+	tests := []struct {
+		name      string
+		req       *endpointpb.RegisterMachineRequest
+		wantField string
+	}{
+		{
+			name: "valid hardware",
+			req: &endpointpb.RegisterMachineRequest{
+				Cpus: []*endpointpb.CPU{
+					{Socket: proto.String("Proc 1"), Cores: proto.Int64(12), Threads: proto.Int64(24)},
+					{Socket: proto.String("Proc 2"), Cores: proto.Int64(12), Threads: proto.Int64(24)},
+				},
+				MemoryModules: []*endpointpb.MemoryModule{
+					{Slot: proto.String("PROC 1 DIMM 1"), Type: endpointpb.MemoryType_MEMORY_TYPE_DDR4.Enum()},
+					{Slot: proto.String("PROC 1 DIMM 2"), Type: endpointpb.MemoryType_MEMORY_TYPE_DDR4.Enum()},
+				},
+				Accelerators: []*endpointpb.Accelerator{{PciAddress: proto.String("af:00.0")}},
+				Nics:         []*endpointpb.NIC{{Mac: proto.String("52:54:00:12:34:56"), PciAddress: proto.String("0000:3b:00.0")}},
+				Drives: []*endpointpb.Drive{
+					{MediaType: endpointpb.DriveMediaType_DRIVE_MEDIA_TYPE_SSD.Enum(), Interface: endpointpb.DriveInterface_DRIVE_INTERFACE_NVME.Enum()},
+				},
+			},
+		},
+		{
+			name: "negative cores",
+			req: &endpointpb.RegisterMachineRequest{
+				Cpus: []*endpointpb.CPU{{Cores: proto.Int64(-1)}},
+				Nics: []*endpointpb.NIC{nic()},
+			},
+			wantField: "cpus[0].cores",
+		},
+		{
+			name: "fewer threads than cores",
+			req: &endpointpb.RegisterMachineRequest{
+				Cpus: []*endpointpb.CPU{{Cores: proto.Int64(8), Threads: proto.Int64(4)}},
+				Nics: []*endpointpb.NIC{nic()},
+			},
+			wantField: "cpus[0].threads",
+		},
+		{
+			name: "duplicate CPU socket",
+			req: &endpointpb.RegisterMachineRequest{
+				Cpus: []*endpointpb.CPU{{Socket: proto.String("Proc 1")}, {Socket: proto.String("Proc 1")}},
+				Nics: []*endpointpb.NIC{nic()},
+			},
+			wantField: "cpus[1].socket",
+		},
+		{
+			name: "duplicate DIMM slot",
+			req: &endpointpb.RegisterMachineRequest{
+				MemoryModules: []*endpointpb.MemoryModule{{Slot: proto.String("A1")}, {Slot: proto.String("A1")}},
+				Nics:          []*endpointpb.NIC{nic()},
+			},
+			wantField: "memory_modules[1].slot",
+		},
+		{
+			name: "unknown memory type",
+			req: &endpointpb.RegisterMachineRequest{
+				MemoryModules: []*endpointpb.MemoryModule{{Type: endpointpb.MemoryType(42).Enum()}},
+				Nics:          []*endpointpb.NIC{nic()},
+			},
+			wantField: "memory_modules[0].type",
+		},
+		{
+			name: "invalid NIC PCI address",
+			req: &endpointpb.RegisterMachineRequest{
+				Nics: []*endpointpb.NIC{{Mac: proto.String("52:54:00:12:34:56"), PciAddress: proto.String("3b:00")}},
+			},
+			wantField: "nics[0].pci_address",
+		},
+		{
+			name: "invalid accelerator PCI address",
+			req: &endpointpb.RegisterMachineRequest{
+				Accelerators: []*endpointpb.Accelerator{{PciAddress: proto.String("zz:00.0")}},
+				Nics:         []*endpointpb.NIC{nic()},
+			},
+			wantField: "accelerators[0].pci_address",
+		},
+		{
+			name: "unknown drive interface",
+			req: &endpointpb.RegisterMachineRequest{
+				Drives: []*endpointpb.Drive{{Interface: endpointpb.DriveInterface(42).Enum()}},
+				Nics:   []*endpointpb.NIC{nic()},
+			},
+			wantField: "drives[0].interface",
+		},
+		{
+			name: "negative drive capacity",
+			req: &endpointpb.RegisterMachineRequest{
+				Drives: []*endpointpb.Drive{{Capacity: proto.Int64(-1)}},
+				Nics:   []*endpointpb.NIC{nic()},
+			},
+			wantField: "drives[0].capacity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidFields := validateMachineRequest(tt.req)
+			if tt.wantField == "" {
+				if len(invalidFields) > 0 {
+					t.Fatalf("expected no invalid fields, got %v", invalidFields)
+				}
+				return
+			}
+			if len(invalidFields) != 1 || invalidFields[0].GetField() != tt.wantField {
+				t.Errorf("want invalid field %s, got %v", tt.wantField, invalidFields)
+			}
+		})
+	}
+}
+
+func TestConvertMachine_RoundTrip(t *testing.T) {
+	req := &endpointpb.RegisterMachineRequest{
+		Cpus: []*endpointpb.CPU{{
+			Manufacturer:   proto.String("Intel"),
+			Model:          proto.String("Intel(R) Xeon(R) CPU E5-2680 v3"),
+			Socket:         proto.String("Proc 1"),
+			ClockFrequency: proto.Int64(2500000000),
+			Cores:          proto.Int64(12),
+			Threads:        proto.Int64(24),
+		}},
+		MemoryModules: []*endpointpb.MemoryModule{{
+			Size:         proto.Int64(17179869184),
+			Type:         endpointpb.MemoryType_MEMORY_TYPE_DDR4.Enum(),
+			Speed:        proto.Int64(2133),
+			Slot:         proto.String("PROC 1 DIMM 1"),
+			Manufacturer: proto.String("HP"),
+			Model:        proto.String("752369-081"),
+			SerialNumber: proto.String("1A2B3C4D"),
+		}},
+		Accelerators: []*endpointpb.Accelerator{{
+			Manufacturer: proto.String("NVIDIA"),
+			Model:        proto.String("Tesla T4"),
+			SerialNumber: proto.String("1321019001234"),
+			PciAddress:   proto.String("0000:af:00.0"),
+		}},
+		Nics: []*endpointpb.NIC{{
+			Mac:        proto.String("52:54:00:12:34:56"),
+			Name:       proto.String("eno1"),
+			Speed:      proto.Int64(10000000000),
+			Driver:     proto.String("ixgbe"),
+			PciAddress: proto.String("0000:3b:00.0"),
+			Model:      proto.String("Intel 82599ES"),
+		}},
+		Drives: []*endpointpb.Drive{{
+			Capacity:     proto.Int64(960197124096),
+			MediaType:    endpointpb.DriveMediaType_DRIVE_MEDIA_TYPE_SSD.Enum(),
+			Interface:    endpointpb.DriveInterface_DRIVE_INTERFACE_NVME.Enum(),
+			Model:        proto.String("MZ1LB960HAJQ"),
+			SerialNumber: proto.String("S435NA0M123456"),
+			Slot:         proto.String("Box 1 Bay 1"),
+		}},
+	}
+
+	m := convertMachineRequest(req)
+	got := convertMachineToProto(&service.Machine{ID: "machine-1", MachineRequest: *m})
+
+	want := &endpointpb.Machine{
+		Id:            proto.String("machine-1"),
+		Cpus:          req.Cpus,
+		MemoryModules: req.MemoryModules,
+		Accelerators:  req.Accelerators,
+		Nics:          req.Nics,
+		Drives:        req.Drives,
+
+		LifecycleState: endpointpb.LifecycleState_LIFECYCLE_STATE_NEW.Enum(),
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("round trip mismatch:\nwant %v\ngot  %v", want, got)
+	}
+}
+
+func TestConvertAccelerators_CanonicalPCIAddress(t *testing.T) {
+	accelerators := convertAccelerators([]*endpointpb.Accelerator{{PciAddress: proto.String("AF:00.0")}})
+	if got := accelerators[0].PCIAddress; got != "0000:af:00.0" {
+		t.Errorf("want %q, got %q", "0000:af:00.0", got)
+	}
+}
@@ -0,0 +1,101 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type restoreMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+	retention       time.Duration
+}
+
+// RestoreMachine registers the endpoint for undoing a machine deletion. Only
+// machines deleted less than retention ago can be restored.
+func RestoreMachine(mux *chi.Mux, firestoreClient FirestoreClient, retention time.Duration) {
+	handler := &restoreMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+		retention:       retention,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/machines/{id}:restore", handler)
+}
+
+func (h *restoreMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID:      machineID,
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	// Tombstones past the retention window are awaiting purge and are
+	// treated as already gone.
+	if !getResp.Found || (getResp.Machine.DeletedAt != nil && time.Since(*getResp.Machine.DeletedAt) > h.retention) {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	machine := getResp.Machine
+	if machine.DeletedAt != nil {
+		restoreResp, err := h.firestoreClient.RestoreMachine(ctx, &service.RestoreMachineRequest{
+			MachineID: machineID,
+			Actor:     requestActor(r),
+		})
+		var notDeleted *service.MachineNotDeletedError
+		conflict, isMACConflict := asMACConflict(err)
+		switch {
+		case errors.As(err, &notDeleted):
+			// A concurrent restore got there first, which leaves the
+			// machine as this one would have.
+			machines := machineService{firestoreClient: h.firestoreClient}
+			if machine, err = machines.get(ctx, instance, machineID); err != nil {
+				errorHandler(ctx, w, err)
+				return
+			}
+		case isMachineNotFound(err):
+			errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+			return
+		case isMACConflict:
+			errorHandler(ctx, w, newMACConflictError(instance, conflict))
+			return
+		case err != nil:
+			errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to restore machine: %v", err)))
+			return
+		default:
+			machine.DeletedAt = nil
+			machine.DeletedBy = ""
+			machine.Revision = restoreResp.Revision
+		}
+	}
+
+	w.Header().Set("ETag", machineETag(machine.Revision))
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineToProto(machine))
+}
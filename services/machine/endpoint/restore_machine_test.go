@@ -0,0 +1,161 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRestoreMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	mac := "aa:bb:cc:dd:ee:ff"
+	deletedMachine := func(deletedAt time.Time) *service.GetMachineResponse {
+		return &service.GetMachineResponse{
+			Found: true,
+			Machine: &service.Machine{
+				ID: machineID,
+				MachineRequest: service.MachineRequest{
+					NICs: []service.NIC{{MAC: service.MAC(mac)}},
+				},
+				DeletedAt: &deletedAt,
+				DeletedBy: "admin@example.com",
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "GetMachine error",
+			id:       machineID,
+			client:   &mockFirestoreClient{getErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name: "machine not found",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{Found: false},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "retention window expired",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: deletedMachine(time.Now().Add(-48 * time.Hour)),
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "MAC re-registered by another machine",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp:    deletedMachine(time.Now().Add(-time.Hour)),
+				restoreErr: &service.MACConflictError{MAC: service.MAC(mac), MachineID: "other-id"},
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "RestoreMachine error",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp:    deletedMachine(time.Now().Add(-time.Hour)),
+				restoreErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			id:       machineID,
+			client:   &mockFirestoreClient{getResp: deletedMachine(time.Now().Add(-time.Hour))},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if m.GetId() != machineID {
+					t.Errorf("want id %q, got %q", machineID, m.GetId())
+				}
+			},
+		},
+		{
+			name: "machine not deleted",
+			id:   machineID,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{
+					Found:   true,
+					Machine: &service.Machine{ID: machineID},
+				},
+				restoreErr: fmt.Errorf("should not be called"),
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &restoreMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+				retention:       24 * time.Hour,
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines/"+tt.id+":restore", nil)
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestRestoreMachine_Route(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	client := &mockFirestoreClient{
+		getResp: &service.GetMachineResponse{
+			Found:   true,
+			Machine: &service.Machine{ID: machineID},
+		},
+	}
+
+	mux := chi.NewRouter()
+	GetMachine(mux, client)
+	RestoreMachine(mux, client, time.Hour)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/machines/"+machineID+":restore", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,111 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type rollbackMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// RollbackMachine registers the endpoint for restoring the hardware and labels
+// a machine had at an earlier revision. The rollback is itself a new
+// revision, so it can be rolled back in turn.
+func RollbackMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &rollbackMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/machines/{id}:rollback", handler)
+}
+
+func (h *rollbackMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.RollbackMachineRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+	if req.GetRevision() < 1 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("revision"), Reason: proto.String("must be a positive integer")},
+		}))
+		return
+	}
+
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	expectedRevision, ok := checkIfMatch(r, getResp.Machine.Revision)
+	if !ok {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+
+	rollbackResp, err := h.firestoreClient.RollbackMachine(ctx, &service.RollbackMachineRequest{
+		MachineID:        machineID,
+		Revision:         req.GetRevision(),
+		Actor:            requestActor(r),
+		ExpectedRevision: expectedRevision,
+	})
+	if isRevisionMismatch(err) {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+	if isMachineNotFound(err) {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+	var notFound *service.RevisionNotFoundError
+	if errors.As(err, &notFound) {
+		errorHandler(ctx, w, errorpb.NewRevisionNotFoundError(instance, machineID, notFound.Revision))
+		return
+	}
+	if conflict, ok := asMACConflict(err); ok {
+		errorHandler(ctx, w, newMACConflictError(instance, conflict))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to roll back machine: %v", err)))
+		return
+	}
+
+	machine := rollbackResp.Machine
+	w.Header().Set("ETag", machineETag(machine.Revision))
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineToProto(machine))
+}
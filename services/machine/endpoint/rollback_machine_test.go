@@ -0,0 +1,173 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRollbackMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID, Revision: 5},
+	}
+	rollback := func(revision int64) *endpointpb.RollbackMachineRequest {
+		return &endpointpb.RollbackMachineRequest{Revision: proto.Int64(revision)}
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		body      *endpointpb.RollbackMachineRequest
+		ifMatch   string
+		client    *mockFirestoreClient
+		wantCode  int
+		wantETag  string
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			body:     rollback(2),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing revision",
+			id:       machineID,
+			body:     &endpointpb.RollbackMachineRequest{},
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "machine not found",
+			id:       machineID,
+			body:     rollback(2),
+			client:   &mockFirestoreClient{getResp: &service.GetMachineResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "stale If-Match",
+			id:       machineID,
+			body:     rollback(2),
+			ifMatch:  `"4"`,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name: "revision not found",
+			id:   machineID,
+			body: rollback(42),
+			client: &mockFirestoreClient{
+				getResp:     found,
+				rollbackErr: fmt.Errorf("failed to roll back machine document: %w", &service.RevisionNotFoundError{MachineID: machineID, Revision: 42}),
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "machine deleted concurrently",
+			id:   machineID,
+			body: rollback(2),
+			client: &mockFirestoreClient{
+				getResp:     found,
+				rollbackErr: fmt.Errorf("failed to roll back machine document: %w", &service.MachineNotFoundError{MachineID: machineID}),
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "MAC taken since the revision",
+			id:   machineID,
+			body: rollback(2),
+			client: &mockFirestoreClient{
+				getResp:     found,
+				rollbackErr: &service.MACConflictError{MAC: "02:00:00:00:00:01", MachineID: "018c7dbd-c000-7000-8000-000000000001"},
+			},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "RollbackMachine error",
+			id:   machineID,
+			body: rollback(2),
+			client: &mockFirestoreClient{
+				getResp:     found,
+				rollbackErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "success",
+			id:      machineID,
+			body:    rollback(2),
+			ifMatch: `"5"`,
+			client: &mockFirestoreClient{
+				getResp: found,
+				rollbackResp: &service.RollbackMachineResponse{
+					Machine: &service.Machine{
+						ID:             machineID,
+						Revision:       6,
+						MachineRequest: service.MachineRequest{CPUs: []service.CPU{{Cores: 8}}},
+					},
+				},
+			},
+			wantCode: http.StatusOK,
+			wantETag: `"6"`,
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(m.GetCpus()) != 1 || m.GetCpus()[0].GetCores() != 8 {
+					t.Errorf("unexpected machine %v", &m)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &rollbackMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			body, err := proto.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines/"+tt.id+":rollback", bytes.NewReader(body))
+			r.Header.Set(actorHeader, "admin@example.com")
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("want ETag %q, got %q", tt.wantETag, got)
+			}
+			if tt.wantCode == http.StatusOK {
+				req := tt.client.rollbackReq
+				if req.Revision != 2 || req.Actor != "admin@example.com" || req.ExpectedRevision == nil || *req.ExpectedRevision != 5 {
+					t.Errorf("unexpected rollback request %+v", req)
+				}
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package endpoint
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+const defaultSearchLimit = 20
+
+type searchMachinesHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// SearchMachines registers the endpoint for finding machines that satisfy a
+// workload's hardware requirements, ranked by how tightly they fit.
+func SearchMachines(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &searchMachinesHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/machines:search", handler)
+}
+
+type machineMatch struct {
+	machine *service.Machine
+	fit     float64
+}
+
+// ServeHTTP ranks every live machine, so the whole collection is read on each
+// search. That is fine at home lab scale and keeps ranking exact.
+func (h *searchMachinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	var req endpointpb.SearchMachinesRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	requirements, limit, invalidFields := convertSearchRequest(&req)
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	var matches []machineMatch
+	listReq := &service.ListMachinesRequest{
+		PageSize:      maxPerPage,
+		LabelSelector: requirements.LabelSelector,
+	}
+	for {
+		resp, err := h.firestoreClient.ListMachines(ctx, listReq)
+		if err != nil {
+			errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to list machines: %v", err)))
+			return
+		}
+		for _, machine := range resp.Machines {
+			if fit, ok := requirements.Fit(machine); ok {
+				matches = append(matches, machineMatch{machine: machine, fit: fit})
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		listReq.PageToken = resp.NextPageToken
+	}
+
+	// Best fit first, so larger machines stay free for larger workloads.
+	// Ties fall back to registration order.
+	slices.SortStableFunc(matches, func(a, b machineMatch) int {
+		return cmp.Compare(b.fit, a.fit)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]*endpointpb.MachineMatch, len(matches))
+	for i, match := range matches {
+		results[i] = &endpointpb.MachineMatch{
+			Machine: convertMachineToProto(match.machine),
+			Fit:     proto.Float64(match.fit),
+		}
+	}
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.SearchMachinesResponse{
+		Matches: results,
+	})
+}
+
+func convertSearchRequest(req *endpointpb.SearchMachinesRequest) (*service.HardwareRequirements, int, []*errorpb.InvalidField) {
+	var v fieldValidator
+	v.nonNegative("min_cores", req.GetMinCores())
+	v.nonNegative("min_memory", req.GetMinMemory())
+	v.nonNegative("min_drive_capacity", req.GetMinDriveCapacity())
+	v.nonNegative("min_nics", req.GetMinNics())
+
+	requirements := &service.HardwareRequirements{
+		MinCores:                req.GetMinCores(),
+		MinMemory:               req.GetMinMemory(),
+		MinDriveCapacity:        req.GetMinDriveCapacity(),
+		MinNICs:                 req.GetMinNics(),
+		AcceleratorManufacturer: req.GetAcceleratorManufacturer(),
+	}
+
+	for i, state := range req.GetLifecycleStates() {
+		s, ok := lifecycleStates[state]
+		if !ok {
+			v.add(fmt.Sprintf("lifecycle_states[%d]", i), "must be a known lifecycle state")
+			continue
+		}
+		requirements.LifecycleStates = append(requirements.LifecycleStates, s)
+	}
+
+	if labelSelector := req.GetLabelSelector(); labelSelector != "" {
+		selector, err := service.ParseSelector(labelSelector)
+		if err != nil {
+			v.add("label_selector", err.Error())
+		}
+		requirements.LabelSelector = selector
+	}
+
+	limit := defaultSearchLimit
+	if req.Limit != nil {
+		limit = int(req.GetLimit())
+		if limit < 1 || limit > maxPerPage {
+			v.add("limit", fmt.Sprintf("must be between 1 and %d", maxPerPage))
+		}
+	}
+
+	return requirements, limit, v.invalidFields
+}
@@ -0,0 +1,119 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSearchMachinesHandler_ServeHTTP(t *testing.T) {
+	withCores := func(id string, cores ...int64) *service.Machine {
+		m := &service.Machine{ID: id}
+		for _, c := range cores {
+			m.CPUs = append(m.CPUs, service.CPU{Cores: c})
+		}
+		return m
+	}
+	fleet := &service.ListMachinesResponse{
+		Machines: []*service.Machine{
+			withCores("018c7dbd-c000-7000-8000-000000000001", 32, 32),
+			withCores("018c7dbd-c000-7000-8000-000000000002", 4),
+			withCores("018c7dbd-c000-7000-8000-000000000003", 8, 8),
+			withCores("018c7dbd-c000-7000-8000-000000000004", 16),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		req       *endpointpb.SearchMachinesRequest
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name: "invalid requirements",
+			req: &endpointpb.SearchMachinesRequest{
+				MinCores:        proto.Int64(-1),
+				LifecycleStates: []endpointpb.LifecycleState{endpointpb.LifecycleState_LIFECYCLE_STATE_UNSPECIFIED},
+				LabelSelector:   proto.String("rack in (a"),
+				Limit:           proto.Int32(0),
+			},
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode problem: %v", err)
+				}
+				var fields []string
+				for _, f := range p.GetInvalidFields() {
+					fields = append(fields, f.GetField())
+				}
+				if fmt.Sprint(fields) != "[min_cores lifecycle_states[0] label_selector limit]" {
+					t.Errorf("unexpected invalid fields %v", fields)
+				}
+			},
+		},
+		{
+			name:     "ListMachines error",
+			req:      &endpointpb.SearchMachinesRequest{},
+			client:   &mockFirestoreClient{listErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "ranked by fit",
+			req:      &endpointpb.SearchMachinesRequest{MinCores: proto.Int64(16), Limit: proto.Int32(2)},
+			client:   &mockFirestoreClient{listResp: fleet},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var resp endpointpb.SearchMachinesResponse
+				if err := proto.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				var got []string
+				for _, m := range resp.GetMatches() {
+					got = append(got, fmt.Sprintf("%s:%v", m.GetMachine().GetId()[len(m.GetMachine().GetId())-1:], m.GetFit()))
+				}
+				// Machines 3 and 4 fit exactly and keep registration order;
+				// the limit drops machine 1, which fits at 0.25.
+				if fmt.Sprint(got) != "[3:1 4:1]" {
+					t.Errorf("unexpected matches %v", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &searchMachinesHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			body, err := proto.Marshal(tt.req)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines:search", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
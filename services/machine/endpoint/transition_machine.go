@@ -0,0 +1,161 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxTransitionReasonLength = 1024
+
+var lifecycleStates = map[endpointpb.LifecycleState]service.LifecycleState{
+	endpointpb.LifecycleState_LIFECYCLE_STATE_NEW:            service.LifecycleStateNew,
+	endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING:   service.LifecycleStateProvisioning,
+	endpointpb.LifecycleState_LIFECYCLE_STATE_IN_SERVICE:     service.LifecycleStateInService,
+	endpointpb.LifecycleState_LIFECYCLE_STATE_MAINTENANCE:    service.LifecycleStateMaintenance,
+	endpointpb.LifecycleState_LIFECYCLE_STATE_DECOMMISSIONED: service.LifecycleStateDecommissioned,
+}
+
+type transitionMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// TransitionMachine registers the endpoint for moving a machine through its
+// lifecycle. Only the transitions allowed by service.LifecycleState are
+// accepted, and each one is recorded in the machine's lifecycle history.
+func TransitionMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &transitionMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPost, "/api/v1/machines/{id}:transition", handler)
+}
+
+func (h *transitionMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.TransitionMachineRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+	if invalidFields := validateTransitionRequest(&req); len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	expectedRevision, ok := checkIfMatch(r, getResp.Machine.Revision)
+	if !ok {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+
+	transitionResp, err := h.firestoreClient.TransitionMachine(ctx, &service.TransitionMachineRequest{
+		MachineID:        machineID,
+		To:               lifecycleStates[req.GetState()],
+		Actor:            requestActor(r),
+		Reason:           req.GetReason(),
+		ExpectedRevision: expectedRevision,
+	})
+	if isRevisionMismatch(err) {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+	if isMachineNotFound(err) {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+	var illegal *service.IllegalTransitionError
+	if errors.As(err, &illegal) {
+		errorHandler(ctx, w, newIllegalTransitionError(instance, illegal))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to transition machine: %v", err)))
+		return
+	}
+
+	machine := transitionResp.Machine
+	w.Header().Set("ETag", machineETag(machine.Revision))
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineToProto(machine))
+}
+
+func validateTransitionRequest(req *endpointpb.TransitionMachineRequest) []*errorpb.InvalidField {
+	var v fieldValidator
+	if _, ok := lifecycleStates[req.GetState()]; !ok {
+		v.add("state", "must be a known lifecycle state")
+	}
+	switch reason := req.GetReason(); {
+	case reason == "":
+		v.add("reason", "is required")
+	case len(reason) > maxTransitionReasonLength:
+		v.add("reason", fmt.Sprintf("must be at most %d characters", maxTransitionReasonLength))
+	}
+	return v.invalidFields
+}
+
+// newIllegalTransitionError reports states by their API names, so clients
+// can feed allowed_states straight back into a transition request.
+func newIllegalTransitionError(instance string, illegal *service.IllegalTransitionError) *errorpb.IllegalTransitionProblem {
+	var allowed []string
+	for _, state := range illegal.From.AllowedTransitions() {
+		allowed = append(allowed, protoEnum(lifecycleStates, state).String())
+	}
+	return errorpb.NewIllegalTransitionError(
+		instance,
+		illegal.MachineID,
+		protoEnum(lifecycleStates, illegal.From).String(),
+		protoEnum(lifecycleStates, illegal.To).String(),
+		allowed,
+	)
+}
+
+func convertLifecycleHistoryToProto(history []service.LifecycleTransition) []*endpointpb.LifecycleTransition {
+	result := make([]*endpointpb.LifecycleTransition, len(history))
+	for i, transition := range history {
+		result[i] = &endpointpb.LifecycleTransition{
+			FromState:      protoEnum(lifecycleStates, transition.From).Enum(),
+			ToState:        protoEnum(lifecycleStates, transition.To).Enum(),
+			TransitionedAt: timestamppb.New(transition.At),
+			Actor:          proto.String(transition.Actor),
+			Reason:         proto.String(transition.Reason),
+		}
+	}
+	return result
+}
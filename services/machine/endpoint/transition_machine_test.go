@@ -0,0 +1,216 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTransitionMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID, Revision: 3},
+	}
+	transition := func(state endpointpb.LifecycleState, reason string) *endpointpb.TransitionMachineRequest {
+		return &endpointpb.TransitionMachineRequest{State: state.Enum(), Reason: proto.String(reason)}
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		body      *endpointpb.TransitionMachineRequest
+		ifMatch   string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			body:     transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, "racked"),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unspecified state",
+			id:       machineID,
+			body:     transition(endpointpb.LifecycleState_LIFECYCLE_STATE_UNSPECIFIED, "racked"),
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "missing reason",
+			id:       machineID,
+			body:     transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, ""),
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "machine not found",
+			id:       machineID,
+			body:     transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, "racked"),
+			client:   &mockFirestoreClient{getResp: &service.GetMachineResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "stale If-Match",
+			id:       machineID,
+			body:     transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, "racked"),
+			ifMatch:  `"2"`,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name: "illegal transition",
+			id:   machineID,
+			body: transition(endpointpb.LifecycleState_LIFECYCLE_STATE_NEW, "reinstall"),
+			client: &mockFirestoreClient{
+				getResp: found,
+				transitionErr: fmt.Errorf("failed to transition machine document: %w", &service.IllegalTransitionError{
+					MachineID: machineID,
+					From:      service.LifecycleStateInService,
+					To:        service.LifecycleStateNew,
+				}),
+			},
+			wantCode: http.StatusConflict,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.IllegalTransitionProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode problem: %v", err)
+				}
+				if p.GetFromState() != "LIFECYCLE_STATE_IN_SERVICE" || p.GetToState() != "LIFECYCLE_STATE_NEW" {
+					t.Errorf("unexpected states %q -> %q", p.GetFromState(), p.GetToState())
+				}
+				want := []string{"LIFECYCLE_STATE_MAINTENANCE", "LIFECYCLE_STATE_DECOMMISSIONED"}
+				if fmt.Sprint(p.GetAllowedStates()) != fmt.Sprint(want) {
+					t.Errorf("want allowed states %v, got %v", want, p.GetAllowedStates())
+				}
+			},
+		},
+		{
+			name: "TransitionMachine error",
+			id:   machineID,
+			body: transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, "racked"),
+			client: &mockFirestoreClient{
+				getResp:       found,
+				transitionErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:    "success",
+			id:      machineID,
+			body:    transition(endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, "racked"),
+			ifMatch: `"3"`,
+			client: &mockFirestoreClient{
+				getResp: found,
+				transitionResp: &service.TransitionMachineResponse{
+					Machine: &service.Machine{
+						ID:             machineID,
+						Revision:       4,
+						LifecycleState: service.LifecycleStateProvisioning,
+						LifecycleHistory: []service.LifecycleTransition{
+							{From: service.LifecycleStateNew, To: service.LifecycleStateProvisioning, Actor: "admin@example.com", Reason: "racked"},
+						},
+					},
+				},
+			},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if m.GetLifecycleState() != endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING {
+					t.Errorf("want state %s, got %s", endpointpb.LifecycleState_LIFECYCLE_STATE_PROVISIONING, m.GetLifecycleState())
+				}
+				if len(m.GetLifecycleHistory()) != 1 || m.GetLifecycleHistory()[0].GetReason() != "racked" {
+					t.Errorf("unexpected lifecycle history %v", m.GetLifecycleHistory())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &transitionMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			body, err := proto.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/machines/"+tt.id+":transition", bytes.NewReader(body))
+			r.Header.Set(actorHeader, "admin@example.com")
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestTransitionMachineHandler_PassesActorAndRevision(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	client := &mockFirestoreClient{
+		getResp: &service.GetMachineResponse{
+			Found:   true,
+			Machine: &service.Machine{ID: machineID, Revision: 3},
+		},
+		transitionResp: &service.TransitionMachineResponse{
+			Machine: &service.Machine{ID: machineID, Revision: 4},
+		},
+	}
+
+	mux := chi.NewRouter()
+	GetMachine(mux, client)
+	TransitionMachine(mux, client)
+
+	body, _ := proto.Marshal(&endpointpb.TransitionMachineRequest{
+		State:  endpointpb.LifecycleState_LIFECYCLE_STATE_MAINTENANCE.Enum(),
+		Reason: proto.String("failed DIMM"),
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/machines/"+machineID+":transition", bytes.NewReader(body))
+	r.Header.Set(actorHeader, "ops@example.com")
+	r.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != `"4"` {
+		t.Errorf("want ETag %q, got %q", `"4"`, got)
+	}
+
+	req := client.transitionReq
+	if req.To != service.LifecycleStateMaintenance || req.Actor != "ops@example.com" || req.Reason != "failed DIMM" {
+		t.Errorf("unexpected transition request %+v", req)
+	}
+	if req.ExpectedRevision == nil || *req.ExpectedRevision != 3 {
+		t.Errorf("want expected revision 3, got %v", req.ExpectedRevision)
+	}
+}
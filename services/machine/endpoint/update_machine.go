@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type updateMachineHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+func UpdateMachine(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &updateMachineHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPut, "/api/v1/machines/{id}", handler)
+}
+
+func (h *updateMachineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.RegisterMachineRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient}
+	machine, err := machines.update(ctx, instance, machineID, &req, requestActor(r), ifMatch(r))
+	if err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("ETag", machineETag(machine.Revision))
+	writeResponse(ctx, w, instance, http.StatusOK, convertMachineToProto(machine))
+}
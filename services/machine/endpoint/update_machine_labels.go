@@ -0,0 +1,128 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type updateMachineLabelsHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// UpdateMachineLabels registers the endpoint for replacing all labels of a
+// machine. Labels are not part of the hardware profile, so PUT and PATCH on
+// the machine itself leave them alone.
+func UpdateMachineLabels(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &updateMachineLabelsHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPut, "/api/v1/machines/{id}/labels", handler)
+}
+
+func (h *updateMachineLabelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	machineID := chi.URLParam(r, "id")
+	if err := validateMachineID(machineID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.MachineLabels
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+	if invalidFields := validateLabels(req.GetLabels()); len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	getResp, err := h.firestoreClient.GetMachine(ctx, &service.GetMachineRequest{
+		MachineID: machineID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get machine: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+
+	expectedRevision, ok := checkIfMatch(r, getResp.Machine.Revision)
+	if !ok {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+
+	setResp, err := h.firestoreClient.SetMachineLabels(ctx, &service.SetMachineLabelsRequest{
+		MachineID:        machineID,
+		Labels:           req.GetLabels(),
+		Actor:            requestActor(r),
+		ExpectedRevision: expectedRevision,
+	})
+	if isRevisionMismatch(err) {
+		errorHandler(ctx, w, errorpb.NewPreconditionFailedError(instance, machineID))
+		return
+	}
+	if isMachineNotFound(err) {
+		errorHandler(ctx, w, errorpb.NewMachineNotFoundError(instance, machineID))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to set machine labels: %v", err)))
+		return
+	}
+
+	w.Header().Set("ETag", machineETag(setResp.Revision))
+	writeResponse(ctx, w, instance, http.StatusOK, &endpointpb.MachineLabels{
+		Labels: req.GetLabels(),
+	})
+}
+
+// validateLabels reports invalid keys and values in key order, so the
+// response does not depend on map iteration order.
+func validateLabels(labels map[string]string) []*errorpb.InvalidField {
+	var v fieldValidator
+	if len(labels) > service.MaxLabels {
+		v.add("labels", fmt.Sprintf("a machine can have at most %d labels", service.MaxLabels))
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		field := fmt.Sprintf("labels[%q]", key)
+		if err := service.ValidateLabelKey(key); err != nil {
+			v.add(field, err.Error())
+			continue
+		}
+		if err := service.ValidateLabelValue(labels[key]); err != nil {
+			v.add(field, err.Error())
+		}
+	}
+	return v.invalidFields
+}
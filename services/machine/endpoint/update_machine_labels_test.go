@@ -0,0 +1,151 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateMachineLabelsHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID, Revision: 2},
+	}
+	labels := func(kv map[string]string) []byte {
+		b, err := proto.Marshal(&endpointpb.MachineLabels{Labels: kv})
+		if err != nil {
+			t.Fatalf("failed to marshal labels: %v", err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		body      []byte
+		ifMatch   string
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+		checkReq  func(t *testing.T, req *service.SetMachineLabelsRequest)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			body:     labels(nil),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid labels",
+			id:       machineID,
+			body:     labels(map[string]string{"rack": "a", "-bad": "x", "role": "a b"}),
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode problem: %v", err)
+				}
+				var fields []string
+				for _, f := range p.GetInvalidFields() {
+					fields = append(fields, f.GetField())
+				}
+				if fmt.Sprint(fields) != `[labels["-bad"] labels["role"]]` {
+					t.Errorf("unexpected invalid fields %v", fields)
+				}
+			},
+		},
+		{
+			name:     "machine not found",
+			id:       machineID,
+			body:     labels(map[string]string{"rack": "a"}),
+			client:   &mockFirestoreClient{getResp: &service.GetMachineResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "stale If-Match",
+			id:       machineID,
+			body:     labels(map[string]string{"rack": "a"}),
+			ifMatch:  `"1"`,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name: "SetMachineLabels error",
+			id:   machineID,
+			body: labels(map[string]string{"rack": "a"}),
+			client: &mockFirestoreClient{
+				getResp:   found,
+				labelsErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			id:       machineID,
+			body:     labels(map[string]string{"rack": "a", "example.com/role": "storage"}),
+			ifMatch:  `"2"`,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.SetMachineLabelsRequest) {
+				if req.Labels["example.com/role"] != "storage" || len(req.Labels) != 2 {
+					t.Errorf("unexpected labels %v", req.Labels)
+				}
+				if req.ExpectedRevision == nil || *req.ExpectedRevision != 2 {
+					t.Errorf("want expected revision 2, got %v", req.ExpectedRevision)
+				}
+			},
+		},
+		{
+			name:     "clear all labels",
+			id:       machineID,
+			body:     labels(nil),
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusOK,
+			checkReq: func(t *testing.T, req *service.SetMachineLabelsRequest) {
+				if len(req.Labels) != 0 {
+					t.Errorf("expected no labels, got %v", req.Labels)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &updateMachineLabelsHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/machines/"+tt.id+"/labels", bytes.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+			if tt.checkReq != nil {
+				tt.checkReq(t, tt.client.labelsReq)
+			}
+		})
+	}
+}
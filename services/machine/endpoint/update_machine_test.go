@@ -0,0 +1,160 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateMachineHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	mac := "aa:bb:cc:dd:ee:ff"
+	validBody, _ := proto.Marshal(&endpointpb.RegisterMachineRequest{
+		Nics: []*endpointpb.NIC{{Mac: &mac}},
+	})
+	found := &service.GetMachineResponse{
+		Found:   true,
+		Machine: &service.Machine{ID: machineID},
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		body      []byte
+		client    *mockFirestoreClient
+		wantCode  int
+		checkBody func(t *testing.T, body []byte)
+	}{
+		{
+			name:     "invalid machine ID",
+			id:       "not-a-uuid",
+			body:     validBody,
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid proto body",
+			id:       machineID,
+			body:     []byte{0xFF},
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "no NICs",
+			id:   machineID,
+			body: func() []byte {
+				b, _ := proto.Marshal(&endpointpb.RegisterMachineRequest{})
+				return b
+			}(),
+			client:   &mockFirestoreClient{},
+			wantCode: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ValidationProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(p.GetInvalidFields()) == 0 || p.GetInvalidFields()[0].GetField() != "nics" {
+					t.Errorf("expected invalid field 'nics', got %v", p.GetInvalidFields())
+				}
+			},
+		},
+		{
+			name: "machine not found",
+			id:   machineID,
+			body: validBody,
+			client: &mockFirestoreClient{
+				getResp: &service.GetMachineResponse{Found: false},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "MAC owned by another machine",
+			id:   machineID,
+			body: validBody,
+			client: &mockFirestoreClient{
+				getResp:   found,
+				updateErr: &service.MACConflictError{MAC: service.MAC(mac), MachineID: "other-id"},
+			},
+			wantCode: http.StatusConflict,
+			checkBody: func(t *testing.T, body []byte) {
+				var p errorpb.ConflictProblem
+				if err := proto.Unmarshal(body, &p); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if p.GetExistingResourceId() != "other-id" {
+					t.Errorf("want existing_resource_id 'other-id', got %q", p.GetExistingResourceId())
+				}
+			},
+		},
+		{
+			name: "machine deleted concurrently",
+			id:   machineID,
+			body: validBody,
+			client: &mockFirestoreClient{
+				getResp:   found,
+				updateErr: &service.MachineNotFoundError{MachineID: machineID},
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "UpdateMachine error",
+			id:   machineID,
+			body: validBody,
+			client: &mockFirestoreClient{
+				getResp:   found,
+				updateErr: fmt.Errorf("write failed"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "success",
+			id:       machineID,
+			body:     validBody,
+			client:   &mockFirestoreClient{getResp: found},
+			wantCode: http.StatusOK,
+			checkBody: func(t *testing.T, body []byte) {
+				var m endpointpb.Machine
+				if err := proto.Unmarshal(body, &m); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if m.GetId() != machineID {
+					t.Errorf("want id %q, got %q", machineID, m.GetId())
+				}
+				if len(m.GetNics()) != 1 || m.GetNics()[0].GetMac() != mac {
+					t.Errorf("unexpected nics: %v", m.GetNics())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &updateMachineHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+			}
+
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/machines/"+tt.id, bytes.NewReader(tt.body))
+			r = withURLParam(r, "id", tt.id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.Bytes())
+			}
+		})
+	}
+}
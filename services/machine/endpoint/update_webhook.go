@@ -0,0 +1,96 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+type updateWebhookHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+}
+
+// UpdateWebhook registers the endpoint for replacing a webhook's URL and
+// filters. The secret is only replaced if the request sets one, so that it
+// can be rotated without being read back. Deliveries already enqueued are
+// made to the new URL with the new secret.
+func UpdateWebhook(mux *chi.Mux, firestoreClient FirestoreClient) {
+	handler := &updateWebhookHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+	}
+
+	mux.Method(http.MethodPut, "/api/v1/webhooks/{id}", handler)
+}
+
+func (h *updateWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	webhookID := chi.URLParam(r, "id")
+	if err := validateWebhookID(webhookID); err != nil {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+			{Field: proto.String("id"), Reason: proto.String(err.Error())},
+		}))
+		return
+	}
+
+	var req endpointpb.WebhookRequest
+	if err := readRequest(r, instance, &req); err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+	eventTypes, invalidFields := validateWebhookRequest(&req)
+	if len(invalidFields) > 0 {
+		errorHandler(ctx, w, errorpb.NewValidationError(instance, invalidFields))
+		return
+	}
+
+	getResp, err := h.firestoreClient.GetWebhook(ctx, &service.GetWebhookRequest{
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to get webhook: %v", err)))
+		return
+	}
+	if !getResp.Found {
+		errorHandler(ctx, w, errorpb.NewWebhookNotFoundError(instance, webhookID))
+		return
+	}
+
+	webhook := *getResp.Webhook
+	webhook.URL = req.GetUrl()
+	webhook.EventTypes = eventTypes
+	webhook.LabelSelector = req.GetLabelSelector()
+	webhook.UpdatedAt = time.Now().UTC()
+	if req.GetSecret() != "" {
+		webhook.Secret = req.GetSecret()
+	}
+
+	_, err = h.firestoreClient.UpdateWebhook(ctx, &service.UpdateWebhookRequest{Webhook: &webhook})
+	var notFound *service.WebhookNotFoundError
+	if errors.As(err, &notFound) {
+		errorHandler(ctx, w, errorpb.NewWebhookNotFoundError(instance, webhookID))
+		return
+	}
+	if err != nil {
+		errorHandler(ctx, w, errorpb.NewInternalError(instance, fmt.Sprintf("failed to update webhook: %v", err)))
+		return
+	}
+
+	writeResponse(ctx, w, instance, http.StatusOK, convertWebhookToProto(&webhook))
+}
@@ -0,0 +1,98 @@
+package endpoint
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateWebhookHandler_ServeHTTP(t *testing.T) {
+	webhookID := "018c7dbd-c000-7000-8000-0000000000f1"
+	found := func() *service.GetWebhookResponse {
+		return &service.GetWebhookResponse{
+			Found:   true,
+			Webhook: &service.Webhook{ID: webhookID, URL: "https://old.example.com", Secret: "old", EventTypes: []service.ChangeType{service.ChangeCreated}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       *endpointpb.WebhookRequest
+		client     *mockFirestoreClient
+		wantCode   int
+		wantSecret string
+	}{
+		{
+			name:     "invalid url",
+			body:     &endpointpb.WebhookRequest{Url: proto.String("hooks.example.com")},
+			client:   &mockFirestoreClient{webhookGetResp: found()},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			body:     &endpointpb.WebhookRequest{Url: proto.String("https://new.example.com")},
+			client:   &mockFirestoreClient{webhookGetResp: &service.GetWebhookResponse{Found: false}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "deleted concurrently",
+			body:     &endpointpb.WebhookRequest{Url: proto.String("https://new.example.com")},
+			client:   &mockFirestoreClient{webhookGetResp: found(), webhookUpdateErr: &service.WebhookNotFoundError{WebhookID: webhookID}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:       "keeps the secret",
+			body:       &endpointpb.WebhookRequest{Url: proto.String("https://new.example.com")},
+			client:     &mockFirestoreClient{webhookGetResp: found()},
+			wantCode:   http.StatusOK,
+			wantSecret: "old",
+		},
+		{
+			name:       "rotates the secret",
+			body:       &endpointpb.WebhookRequest{Url: proto.String("https://new.example.com"), Secret: proto.String("new")},
+			client:     &mockFirestoreClient{webhookGetResp: found()},
+			wantCode:   http.StatusOK,
+			wantSecret: "new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			UpdateWebhook(mux, tt.client)
+
+			body, err := proto.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("failed to marshal request: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/"+webhookID, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			webhook := tt.client.webhookUpdateReq.Webhook
+			if webhook.URL != "https://new.example.com" || webhook.Secret != tt.wantSecret || len(webhook.EventTypes) != 0 {
+				t.Errorf("unexpected update %+v", webhook)
+			}
+			var resp endpointpb.Webhook
+			if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Secret != nil {
+				t.Errorf("expected the secret to be left out, got %q", resp.GetSecret())
+			}
+		})
+	}
+}
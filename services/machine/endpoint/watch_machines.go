@@ -0,0 +1,182 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zaba505/infra/pkg/errorpb"
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	// watchPageSize bounds how many changes are read from the log at once.
+	// A full page is followed by another read straight away.
+	watchPageSize = 100
+
+	// watchKeepAlive is how often an idle stream sends a comment, so proxies
+	// and clients do not time out connections with nothing to report.
+	watchKeepAlive = 15 * time.Second
+)
+
+var machineEventTypes = map[service.ChangeType]endpointpb.MachineEventType{
+	service.ChangeCreated:      endpointpb.MachineEventType_MACHINE_EVENT_TYPE_CREATED,
+	service.ChangeUpdated:      endpointpb.MachineEventType_MACHINE_EVENT_TYPE_UPDATED,
+	service.ChangeDeleted:      endpointpb.MachineEventType_MACHINE_EVENT_TYPE_DELETED,
+	service.ChangeStateChanged: endpointpb.MachineEventType_MACHINE_EVENT_TYPE_STATE_CHANGED,
+}
+
+type watchMachinesHandler struct {
+	tracer          trace.Tracer
+	log             *slog.Logger
+	firestoreClient FirestoreClient
+	pollInterval    time.Duration
+}
+
+// WatchMachines registers the Server-Sent Events stream of machine changes.
+// The change log is polled every pollInterval while a client is connected.
+func WatchMachines(mux *chi.Mux, firestoreClient FirestoreClient, pollInterval time.Duration) {
+	handler := &watchMachinesHandler{
+		tracer:          otel.Tracer("machine/endpoint"),
+		log:             slog.Default(),
+		firestoreClient: firestoreClient,
+		pollInterval:    pollInterval,
+	}
+
+	mux.Method(http.MethodGet, "/api/v1/machines:watch", handler)
+}
+
+func (h *watchMachinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	instance := r.URL.Path
+
+	// Without a Last-Event-ID the stream starts at the current end of the
+	// log.
+	var resumeAfter *int64
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		after, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || after < 0 {
+			errorHandler(ctx, w, errorpb.NewValidationError(instance, []*errorpb.InvalidField{
+				{Field: proto.String("Last-Event-ID"), Reason: proto.String("must be the ID of a previously received event")},
+			}))
+			return
+		}
+		resumeAfter = &after
+	}
+
+	machines := machineService{firestoreClient: h.firestoreClient}
+	resp, after, err := machines.watch(ctx, instance, resumeAfter)
+	if err != nil {
+		errorHandler(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(watchKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, change := range resp.Changes {
+			if err := writeMachineEvent(w, change); err != nil {
+				h.log.ErrorContext(ctx, "failed to write machine event", slog.Any("error", err))
+				return
+			}
+			after = change.Sequence
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		if len(resp.Changes) < watchPageSize {
+			if !waitForPoll(ctx, w, rc, poll.C, keepAlive.C) {
+				return
+			}
+		}
+
+		resp, err = machines.nextChanges(ctx, instance, after)
+		if err != nil {
+			// The client resumes from the last event it received when it
+			// reconnects, so ending the stream loses nothing. If changes
+			// were missed, reconnecting gets it a 410 telling it to resync.
+			h.log.ErrorContext(ctx, "failed to continue machine change stream", slog.Any("error", err))
+			return
+		}
+	}
+}
+
+// waitForPoll blocks until the next poll, sending keep-alive comments
+// meanwhile. It reports false once the client has gone away.
+func waitForPoll(ctx context.Context, w io.Writer, rc *http.ResponseController, poll, keepAlive <-chan time.Time) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-poll:
+			return true
+		case <-keepAlive:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return false
+			}
+			if err := rc.Flush(); err != nil {
+				return false
+			}
+		}
+	}
+}
+
+// changesMissed reports whether changes after the sequence after are no
+// longer in the log. Sequences have no gaps, so resp must start right after
+// after unless nothing has happened since. A sequence past the latest one
+// comes from some other log, and cannot be resumed either.
+func changesMissed(after int64, resp *service.ListMachineChangesResponse) bool {
+	if after >= resp.Latest {
+		return after > resp.Latest
+	}
+	return len(resp.Changes) == 0 || resp.Changes[0].Sequence != after+1
+}
+
+// writeMachineEvent writes change as an SSE event, named after its type and
+// identified by its sequence so clients resume from it with Last-Event-ID.
+// The data is always JSON, which is what EventSource clients expect.
+func writeMachineEvent(w io.Writer, change *service.MachineChange) error {
+	data, err := jsonMarshalOptions.Marshal(convertMachineChangeToProto(change))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.Sequence, change.Type, data)
+	return err
+}
+
+func convertMachineChangeToProto(change *service.MachineChange) *endpointpb.MachineEvent {
+	machine := change.Machine
+	machine.ID = change.MachineID
+
+	return &endpointpb.MachineEvent{
+		Sequence:   proto.Int64(change.Sequence),
+		Type:       machineEventTypes[change.Type].Enum(),
+		MachineId:  proto.String(change.MachineID),
+		Revision:   proto.Int64(machine.Revision),
+		Machine:    convertMachineToProto(&machine),
+		Actor:      proto.String(change.Actor),
+		OccurredAt: timestamppb.New(change.At),
+	}
+}
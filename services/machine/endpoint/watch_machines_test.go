@@ -0,0 +1,171 @@
+package endpoint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/endpoint/endpointpb"
+	"github.com/Zaba505/infra/services/machine/service"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestWatchMachinesHandler_ServeHTTP(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	change := func(sequence int64, changeType service.ChangeType) *service.MachineChange {
+		return &service.MachineChange{
+			Sequence:  sequence,
+			Type:      changeType,
+			MachineID: machineID,
+			Machine:   service.Machine{Revision: sequence, LifecycleState: service.LifecycleStateNew},
+			Actor:     "alice",
+			At:        time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		client      *mockFirestoreClient
+		wantCode    int
+		wantAfter   []int64
+		wantEvents  string
+	}{
+		{
+			name:        "invalid Last-Event-ID",
+			lastEventID: "abc",
+			client:      &mockFirestoreClient{},
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:     "ListMachineChanges error",
+			client:   &mockFirestoreClient{changesErr: fmt.Errorf("firestore unavailable")},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:        "resume token purged",
+			lastEventID: "2",
+			client: &mockFirestoreClient{changesResps: []*service.ListMachineChangesResponse{
+				{Changes: []*service.MachineChange{change(5, service.ChangeUpdated)}, Latest: 5},
+			}},
+			wantCode: http.StatusGone,
+		},
+		{
+			name:        "resume token from another log",
+			lastEventID: "9",
+			client: &mockFirestoreClient{changesResps: []*service.ListMachineChangesResponse{
+				{Latest: 5},
+			}},
+			wantCode: http.StatusGone,
+		},
+		{
+			name: "starts at the end of the log",
+			client: &mockFirestoreClient{changesResps: []*service.ListMachineChangesResponse{
+				{Latest: 7},
+				{Changes: []*service.MachineChange{change(8, service.ChangeCreated)}, Latest: 8},
+			}},
+			wantCode:   http.StatusOK,
+			wantAfter:  []int64{0, 7, 8},
+			wantEvents: "8:created",
+		},
+		{
+			name:        "resumes after Last-Event-ID",
+			lastEventID: "3",
+			client: &mockFirestoreClient{changesResps: []*service.ListMachineChangesResponse{
+				{Changes: []*service.MachineChange{change(4, service.ChangeUpdated), change(5, service.ChangeDeleted)}, Latest: 5},
+				{Latest: 5},
+				{Changes: []*service.MachineChange{change(6, service.ChangeStateChanged)}, Latest: 6},
+			}},
+			wantCode:   http.StatusOK,
+			wantAfter:  []int64{3, 5, 5, 6},
+			wantEvents: "4:updated 5:deleted 6:state_changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &watchMachinesHandler{
+				tracer:          noop.NewTracerProvider().Tracer(""),
+				log:             slog.Default(),
+				firestoreClient: tt.client,
+				pollInterval:    time.Millisecond,
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/machines:watch", nil)
+			if tt.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			// The stream ends when the mock runs out of responses.
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("want status %d, got %d (body: %s)", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != eventStreamContentType {
+				t.Errorf("want Content-Type %s, got %s", eventStreamContentType, got)
+			}
+
+			var after []int64
+			for _, req := range tt.client.changesReqs {
+				after = append(after, req.After)
+			}
+			if fmt.Sprint(after) != fmt.Sprint(tt.wantAfter) {
+				t.Errorf("want reads after %v, got %v", tt.wantAfter, after)
+			}
+
+			var events []string
+			for _, block := range strings.Split(strings.TrimSpace(w.Body.String()), "\n\n") {
+				event := parseEvent(t, block)
+				events = append(events, event["id"]+":"+event["event"])
+				if !strings.Contains(event["data"], `"machine_id":"`+machineID+`"`) {
+					t.Errorf("expected event data for %s, got %s", machineID, event["data"])
+				}
+			}
+			if got := strings.Join(events, " "); got != tt.wantEvents {
+				t.Errorf("want events %s, got %s", tt.wantEvents, got)
+			}
+		})
+	}
+}
+
+func TestConvertMachineChangeToProto(t *testing.T) {
+	machineID := "018c7dbd-c000-7000-8000-fedcba987654"
+	got := convertMachineChangeToProto(&service.MachineChange{
+		Sequence:  12,
+		Type:      service.ChangeStateChanged,
+		MachineID: machineID,
+		Machine:   service.Machine{Revision: 4, LifecycleState: service.LifecycleStateInService},
+		Actor:     "alice",
+	})
+
+	if got.GetType() != endpointpb.MachineEventType_MACHINE_EVENT_TYPE_STATE_CHANGED {
+		t.Errorf("unexpected type %v", got.GetType())
+	}
+	if got.GetSequence() != 12 || got.GetRevision() != 4 || got.GetActor() != "alice" {
+		t.Errorf("unexpected event %v", got)
+	}
+	if got.GetMachine().GetId() != machineID || got.GetMachine().GetLifecycleState() != endpointpb.LifecycleState_LIFECYCLE_STATE_IN_SERVICE {
+		t.Errorf("unexpected machine %v", got.GetMachine())
+	}
+}
+
+func parseEvent(t *testing.T, block string) map[string]string {
+	t.Helper()
+	event := make(map[string]string)
+	for _, line := range strings.Split(block, "\n") {
+		field, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("malformed event line %q", line)
+		}
+		event[field] = value
+	}
+	return event
+}
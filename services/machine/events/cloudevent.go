@@ -0,0 +1,64 @@
+// Package events publishes machine inventory changes as CloudEvents.
+//
+// Every machine write appends to the change log in the same transaction as
+// the machine itself, which makes the log a transactional outbox: a Relay
+// publishes exactly the changes that were committed, and checkpoints its
+// progress so none are lost across restarts.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+const (
+	SpecVersion = "1.0"
+
+	// Source identifies the machine service as the producer of an event.
+	Source = "/api/v1/machines"
+
+	// MachineEventTypePrefix is prefixed to the change type to form the
+	// event type, e.g. com.example.machine.created.
+	MachineEventTypePrefix = "com.example.machine."
+)
+
+// CloudEvent is a CloudEvents 1.0 event with JSON data. Its JSON encoding is
+// the structured content mode of the JSON event format.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// NewMachineEvent wraps data describing change in an event. The change's
+// sequence is the event ID, which consumers can deduplicate on, and the
+// machine ID is its subject.
+func NewMachineEvent(change *service.MachineChange, data json.RawMessage) *CloudEvent {
+	return &CloudEvent{
+		SpecVersion:     SpecVersion,
+		ID:              strconv.FormatInt(change.Sequence, 10),
+		Source:          Source,
+		Type:            MachineEventTypePrefix + string(change.Type),
+		Subject:         change.MachineID,
+		Time:            change.At,
+		DataContentType: "application/json",
+		Data:            data,
+	}
+}
+
+// Publisher delivers events to a broker. Publish returns once the broker has
+// accepted every event, or with an error, in which case any of them may or
+// may not have been accepted.
+type Publisher interface {
+	Publish(ctx context.Context, events ...*CloudEvent) error
+	Close() error
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// LocalPublisher keeps published events in process memory. It stands in for
+// a broker in tests.
+type LocalPublisher struct {
+	mu     sync.Mutex
+	events []*CloudEvent
+}
+
+func NewLocalPublisher() *LocalPublisher {
+	return &LocalPublisher{}
+}
+
+func (p *LocalPublisher) Publish(ctx context.Context, events ...*CloudEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, events...)
+	return nil
+}
+
+// Events returns every event published so far, in publication order.
+func (p *LocalPublisher) Events() []*CloudEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.events)
+}
+
+func (p *LocalPublisher) Close() error {
+	return nil
+}
+
+// FilePublisher appends events to a file, one structured mode JSON event per
+// line. It stands in for a broker in local development, where the file can
+// be followed with tail -f.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish syncs the file before returning, so published events survive a
+// crash.
+func (p *FilePublisher) Publish(ctx context.Context, events ...*CloudEvent) error {
+	var lines []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(lines); err != nil {
+		return fmt.Errorf("failed to write events file: %w", err)
+	}
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync events file: %w", err)
+	}
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
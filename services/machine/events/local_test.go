@@ -0,0 +1,50 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilePublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// Reopening the file appends to it rather than starting over.
+	for _, id := range []string{"1", "2"} {
+		publisher, err := NewFilePublisher(path)
+		if err != nil {
+			t.Fatalf("NewFilePublisher: %v", err)
+		}
+		event := &CloudEvent{SpecVersion: SpecVersion, ID: id, Source: Source, Type: "com.example.machine.created", Data: json.RawMessage(`{}`)}
+		if err := publisher.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+		publisher.Close()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open events file: %v", err)
+	}
+	defer f.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event CloudEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("failed to decode line %q: %v", scanner.Text(), err)
+		}
+		if event.SpecVersion != SpecVersion || event.Source != Source {
+			t.Errorf("unexpected event %+v", event)
+		}
+		ids = append(ids, event.ID)
+	}
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+		t.Errorf("want events [1 2], got %v", ids)
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// PubSubPublisher publishes events to a Google Cloud Pub/Sub topic in the
+// binary content mode of the CloudEvents Pub/Sub protocol binding: the data
+// is the message body and every other attribute becomes a ce- prefixed
+// message attribute.
+//
+// Messages carry the machine ID as their ordering key, so subscriptions with
+// message ordering enabled receive the events of each machine in order.
+type PubSubPublisher struct {
+	topics *pubsub.ProjectsTopicsService
+	topic  string
+}
+
+func NewPubSubPublisher(ctx context.Context, projectID, topicID string, opts ...option.ClientOption) (*PubSubPublisher, error) {
+	svc, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pubsub client: %w", err)
+	}
+	return &PubSubPublisher{
+		topics: svc.Projects.Topics,
+		topic:  fmt.Sprintf("projects/%s/topics/%s", projectID, topicID),
+	}, nil
+}
+
+// Publish sends one request per ordering key, as Pub/Sub rejects requests
+// mixing messages with different keys. Requests are sent in the order each
+// key first appears, and the messages of a key keep their order. When a
+// request fails the earlier ones have been published, and are published
+// again when the caller retries the batch.
+func (p *PubSubPublisher) Publish(ctx context.Context, events ...*CloudEvent) error {
+	var keys []string
+	messagesByKey := make(map[string][]*pubsub.PubsubMessage)
+	for _, event := range events {
+		message := pubsubMessage(event)
+		if _, ok := messagesByKey[message.OrderingKey]; !ok {
+			keys = append(keys, message.OrderingKey)
+		}
+		messagesByKey[message.OrderingKey] = append(messagesByKey[message.OrderingKey], message)
+	}
+
+	for _, key := range keys {
+		req := &pubsub.PublishRequest{Messages: messagesByKey[key]}
+		if _, err := p.topics.Publish(p.topic, req).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", p.topic, err)
+		}
+	}
+	return nil
+}
+
+func (p *PubSubPublisher) Close() error {
+	return nil
+}
+
+func pubsubMessage(event *CloudEvent) *pubsub.PubsubMessage {
+	attributes := map[string]string{
+		"ce-specversion": event.SpecVersion,
+		"ce-id":          event.ID,
+		"ce-source":      event.Source,
+		"ce-type":        event.Type,
+		"ce-time":        event.Time.UTC().Format(time.RFC3339Nano),
+	}
+	if event.Subject != "" {
+		attributes["ce-subject"] = event.Subject
+	}
+	if event.DataContentType != "" {
+		attributes["content-type"] = event.DataContentType
+	}
+
+	return &pubsub.PubsubMessage{
+		Data:        base64.StdEncoding.EncodeToString(event.Data),
+		Attributes:  attributes,
+		OrderingKey: event.Subject,
+	}
+}
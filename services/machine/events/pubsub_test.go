@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+func TestPubSubPublisher_Publish(t *testing.T) {
+	var got pubsub.PublishRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/home-lab/topics/machines:publish" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode publish request: %v", err)
+		}
+		json.NewEncoder(w).Encode(&pubsub.PublishResponse{MessageIds: []string{"1"}})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	publisher, err := NewPubSubPublisher(ctx, "home-lab", "machines", option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewPubSubPublisher: %v", err)
+	}
+
+	event := &CloudEvent{
+		SpecVersion:     SpecVersion,
+		ID:              "42",
+		Source:          Source,
+		Type:            "com.example.machine.updated",
+		Subject:         "018c7dbd-c000-7000-8000-fedcba987654",
+		Time:            time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		DataContentType: "application/json",
+		Data:            json.RawMessage(`{"sequence":"42"}`),
+	}
+	if err := publisher.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if len(got.Messages) != 1 {
+		t.Fatalf("want 1 message, got %d", len(got.Messages))
+	}
+	message := got.Messages[0]
+	data, err := base64.StdEncoding.DecodeString(message.Data)
+	if err != nil || string(data) != `{"sequence":"42"}` {
+		t.Errorf("unexpected data %q (%v)", data, err)
+	}
+	want := map[string]string{
+		"ce-specversion": "1.0",
+		"ce-id":          "42",
+		"ce-source":      Source,
+		"ce-type":        "com.example.machine.updated",
+		"ce-subject":     event.Subject,
+		"ce-time":        "2026-10-01T12:00:00Z",
+		"content-type":   "application/json",
+	}
+	for key, value := range want {
+		if message.Attributes[key] != value {
+			t.Errorf("want attribute %s=%s, got %q", key, value, message.Attributes[key])
+		}
+	}
+	if message.OrderingKey != event.Subject {
+		t.Errorf("want ordering key %s, got %s", event.Subject, message.OrderingKey)
+	}
+}
+
+func TestPubSubPublisher_Publish_OrderingKeys(t *testing.T) {
+	var requests []pubsub.PublishRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pubsub.PublishRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode publish request: %v", err)
+		}
+		// Pub/Sub rejects requests mixing ordering keys.
+		for _, message := range req.Messages {
+			if message.OrderingKey != req.Messages[0].OrderingKey {
+				http.Error(w, "messages in a request must share an ordering key", http.StatusBadRequest)
+				return
+			}
+		}
+		requests = append(requests, req)
+		json.NewEncoder(w).Encode(&pubsub.PublishResponse{MessageIds: make([]string, len(req.Messages))})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	publisher, err := NewPubSubPublisher(ctx, "home-lab", "machines", option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewPubSubPublisher: %v", err)
+	}
+
+	const (
+		machineA = "018c7dbd-c000-7000-8000-00000000000a"
+		machineB = "018c7dbd-c000-7000-8000-00000000000b"
+	)
+	var events []*CloudEvent
+	for i, subject := range []string{machineA, machineB, machineA} {
+		events = append(events, &CloudEvent{
+			SpecVersion: SpecVersion,
+			ID:          strconv.Itoa(i + 1),
+			Source:      Source,
+			Type:        "com.example.machine.updated",
+			Subject:     subject,
+			Time:        time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	if err := publisher.Publish(ctx, events...); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("want a request per machine, got %d", len(requests))
+	}
+	var ids [][]string
+	for _, req := range requests {
+		var reqIDs []string
+		for _, message := range req.Messages {
+			reqIDs = append(reqIDs, message.Attributes["ce-id"])
+		}
+		ids = append(ids, reqIDs)
+	}
+	if want := [][]string{{"1", "3"}, {"2"}}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("want events %v, got %v", want, ids)
+	}
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+const (
+	// RelayConsumer names the change cursor the relay checkpoints to.
+	RelayConsumer = "cloudevents"
+
+	relayPageSize = 100
+)
+
+// ChangeLog is the part of the storage a Relay reads and checkpoints.
+type ChangeLog interface {
+	ListMachineChanges(ctx context.Context, req *service.ListMachineChangesRequest) (*service.ListMachineChangesResponse, error)
+	GetChangeCursor(ctx context.Context, req *service.GetChangeCursorRequest) (*service.GetChangeCursorResponse, error)
+	SetChangeCursor(ctx context.Context, req *service.SetChangeCursorRequest) (*service.SetChangeCursorResponse, error)
+}
+
+// Encoder turns a change into the event published for it.
+type Encoder func(change *service.MachineChange) (*CloudEvent, error)
+
+// Relay publishes the change log. Its cursor is advanced only after the
+// publisher has accepted a batch, so every committed change is published at
+// least once: a crash in between publishes the batch again, and consumers
+// should deduplicate on the event ID.
+type Relay struct {
+	log       *slog.Logger
+	changes   ChangeLog
+	publisher Publisher
+	encode    Encoder
+}
+
+func NewRelay(changes ChangeLog, publisher Publisher, encode Encoder) *Relay {
+	return &Relay{
+		log:       slog.Default(),
+		changes:   changes,
+		publisher: publisher,
+		encode:    encode,
+	}
+}
+
+// Run flushes the change log every pollInterval until ctx is done.
+func (r *Relay) Run(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		published, err := r.Flush(ctx)
+		if err != nil {
+			r.log.ErrorContext(ctx, "failed to publish machine changes", slog.Int("published", published), slog.Any("error", err))
+			continue
+		}
+		if published > 0 {
+			r.log.DebugContext(ctx, "published machine changes", slog.Int("count", published))
+		}
+	}
+}
+
+// Flush publishes every change after the relay's cursor and reports how many
+// it published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	cursor, err := r.changes.GetChangeCursor(ctx, &service.GetChangeCursorRequest{Consumer: RelayConsumer})
+	if err != nil {
+		return 0, err
+	}
+
+	after := cursor.Sequence
+	var published int
+	for {
+		resp, err := r.changes.ListMachineChanges(ctx, &service.ListMachineChangesRequest{After: after, PageSize: relayPageSize})
+		if err != nil {
+			return published, err
+		}
+		if len(resp.Changes) == 0 {
+			return published, nil
+		}
+
+		batch := make([]*CloudEvent, len(resp.Changes))
+		for i, change := range resp.Changes {
+			batch[i], err = r.encode(change)
+			if err != nil {
+				return published, fmt.Errorf("failed to encode change %d: %w", change.Sequence, err)
+			}
+		}
+		if err := r.publisher.Publish(ctx, batch...); err != nil {
+			return published, err
+		}
+
+		after = resp.Changes[len(resp.Changes)-1].Sequence
+		_, err = r.changes.SetChangeCursor(ctx, &service.SetChangeCursorRequest{Consumer: RelayConsumer, Sequence: after})
+		if err != nil {
+			return published, err
+		}
+		published += len(batch)
+	}
+}
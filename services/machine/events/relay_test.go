@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, events ...*CloudEvent) error {
+	return fmt.Errorf("broker unavailable")
+}
+
+func (failingPublisher) Close() error { return nil }
+
+func TestRelay_Flush(t *testing.T) {
+	ctx := context.Background()
+	encode := func(change *service.MachineChange) (*CloudEvent, error) {
+		return NewMachineEvent(change, json.RawMessage(`{}`)), nil
+	}
+
+	store := service.NewMemoryStore()
+	defer store.Close()
+
+	// More machines than fit in one page of the change log.
+	var machines []*service.CreateMachineRequest
+	for i := range relayPageSize + 20 {
+		machines = append(machines, &service.CreateMachineRequest{
+			MachineID: fmt.Sprintf("018c7dbd-c000-7000-8000-%012d", i),
+			Machine:   &service.MachineRequest{},
+		})
+	}
+	if _, err := store.CreateMachines(ctx, &service.CreateMachinesRequest{Machines: machines}); err != nil {
+		t.Fatalf("CreateMachines: %v", err)
+	}
+	cursor := func() int64 {
+		t.Helper()
+		resp, err := store.GetChangeCursor(ctx, &service.GetChangeCursorRequest{Consumer: RelayConsumer})
+		if err != nil {
+			t.Fatalf("GetChangeCursor: %v", err)
+		}
+		return resp.Sequence
+	}
+
+	if _, err := NewRelay(store, failingPublisher{}, encode).Flush(ctx); err == nil {
+		t.Fatal("expected the publisher error to be returned")
+	}
+	if got := cursor(); got != 0 {
+		t.Fatalf("expected a failed publish to leave the cursor at 0, got %d", got)
+	}
+
+	publisher := NewLocalPublisher()
+	relay := NewRelay(store, publisher, encode)
+	published, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if published != len(machines) || cursor() != int64(len(machines)) {
+		t.Fatalf("want %d changes published, got %d with cursor %d", len(machines), published, cursor())
+	}
+	for i, event := range publisher.Events() {
+		if want := fmt.Sprint(i + 1); event.ID != want || event.Subject != machines[i].MachineID || event.Type != "com.example.machine.created" {
+			t.Fatalf("unexpected event %d: %+v", i, event)
+		}
+	}
+
+	if _, err := store.DeleteMachine(ctx, &service.DeleteMachineRequest{MachineID: machines[0].MachineID}); err != nil {
+		t.Fatalf("DeleteMachine: %v", err)
+	}
+	published, err = relay.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	events := publisher.Events()
+	if last := events[len(events)-1]; published != 1 || last.Type != "com.example.machine.deleted" {
+		t.Errorf("expected only the delete to be published, published %d ending with %+v", published, last)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChangeType is the kind of write a MachineChange records.
+type ChangeType string
+
+const (
+	// ChangeCreated is recorded when a machine is registered, and when a
+	// deleted machine is restored.
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+
+	// ChangeStateChanged is recorded when a machine moves to another
+	// lifecycle state.
+	ChangeStateChanged ChangeType = "state_changed"
+)
+
+// MachineChange is an entry in the change log. Every machine write appends
+// one in the same transaction as the write, numbered by a sequence that
+// increases by one per change, so a reader that has seen sequence n has seen
+// every change up to and including n.
+type MachineChange struct {
+	Sequence  int64      `firestore:"sequence"`
+	Type      ChangeType `firestore:"type"`
+	MachineID string     `firestore:"machine_id"`
+
+	// Machine is the machine after the change. For ChangeDeleted it is the
+	// tombstone.
+	Machine Machine `firestore:"machine"`
+
+	Actor string    `firestore:"actor"`
+	At    time.Time `firestore:"at"`
+}
+
+// changeCounter is a position in the change log. It is stored alongside the
+// log to hold the last sequence assigned, and per consumer to hold the last
+// sequence the consumer has processed.
+type changeCounter struct {
+	Sequence int64 `firestore:"sequence"`
+}
+
+// changeDocID zero pads sequence so that document IDs sort in change order.
+func changeDocID(sequence int64) string {
+	return fmt.Sprintf("%019d", sequence)
+}
+
+// ledger collects the bookkeeping a machine write keeps in the same
+// transaction as the machine itself: the inventory summary, the revision it
+// supersedes and the change log. Backends read it before writing anything,
+// as Firestore transactions require, record each machine write into it, and
+// write it back last.
+type ledger struct {
+	at        time.Time
+	summary   *InventorySummary
+	sequence  int64
+	revisions []*MachineRevision
+	changes   []*MachineChange
+}
+
+func newLedger(summary *InventorySummary, counter changeCounter) *ledger {
+	return &ledger{
+		at:       time.Now().UTC(),
+		summary:  summary,
+		sequence: counter.Sequence,
+	}
+}
+
+// record adds a write of the machine from before to after. before is nil for
+// newly registered machines, which have no previous version.
+func (l *ledger) record(changeType ChangeType, machineID string, before, after *Machine, actor string) {
+	l.summary.replace(before, after, l.at)
+
+	if before != nil {
+		revision := newMachineRevision(before, after, actor, l.at)
+		revision.Machine.ID = machineID
+		l.revisions = append(l.revisions, revision)
+	}
+
+	l.sequence++
+	change := &MachineChange{
+		Sequence:  l.sequence,
+		Type:      changeType,
+		MachineID: machineID,
+		Machine:   *after,
+		Actor:     actor,
+		At:        l.at,
+	}
+	change.Machine.ID = machineID
+	l.changes = append(l.changes, change)
+}
+
+func (l *ledger) counter() changeCounter {
+	return changeCounter{Sequence: l.sequence}
+}
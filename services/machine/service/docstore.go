@@ -0,0 +1,1165 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	machinesCollection    = "machines"
+	machineMACsCollection = "machine_macs"
+	idempotencyCollection = "idempotency_keys"
+	inventoryCollection   = "inventory"
+	revisionsCollection   = "machine_revisions"
+	changesCollection     = "machine_changes"
+	countersCollection    = "counters"
+	cursorsCollection     = "change_cursors"
+	webhooksCollection    = "webhooks"
+	deliveriesCollection  = "webhook_deliveries"
+
+	inventorySummaryID = "summary"
+	changeCounterID    = "machine_changes"
+)
+
+// docTx is a transaction over a store of JSON documents grouped into
+// collections. It mirrors the small part of the Firestore data model the
+// machine service relies on, so the self-hosted backends can share one
+// implementation of the storage semantics.
+type docTx interface {
+	// get decodes the document into v, reporting false if it does not exist.
+	get(collection, id string, v any) (bool, error)
+	set(collection, id string, v any) error
+	delete(collection, id string) error
+
+	// scan visits the documents of collection in ID order, starting after
+	// startAfter, until fn returns false.
+	scan(collection, startAfter string, fn func(id string, data []byte) (bool, error)) error
+}
+
+type docDB interface {
+	view(ctx context.Context, fn func(tx docTx) error) error
+	update(ctx context.Context, fn func(tx docTx) error) error
+	close() error
+}
+
+// docStore implements the machine storage operations on top of a docDB. It
+// backs both MemoryStore and SQLiteStore.
+type docStore struct {
+	db docDB
+}
+
+func (s *docStore) CreateMachine(ctx context.Context, req *CreateMachineRequest) (*CreateMachineResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		if req.IdempotencyRecord != nil {
+			record, err := getIdempotencyDoc(tx, req.IdempotencyRecord.Key)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				return &IdempotencyKeyExistsError{Record: record}
+			}
+		}
+
+		if err := claimMACDocs(tx, req.MachineID, macsOf(req.Machine.NICs), nil); err != nil {
+			return err
+		}
+		machine := &Machine{
+			ID:             req.MachineID,
+			MachineRequest: *req.Machine,
+			Revision:       1,
+			LifecycleState: LifecycleStateNew,
+		}
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		if err := recordChangeDoc(tx, ChangeCreated, req.MachineID, nil, machine, req.Actor); err != nil {
+			return err
+		}
+		if req.IdempotencyRecord == nil {
+			return nil
+		}
+		return tx.set(idempotencyCollection, req.IdempotencyRecord.Key, req.IdempotencyRecord)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine document: %w", err)
+	}
+
+	return &CreateMachineResponse{}, nil
+}
+
+func (s *docStore) CreateMachines(ctx context.Context, req *CreateMachinesRequest) (*CreateMachinesResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		conflicts := make(map[int]*MACConflictError)
+		for i, m := range req.Machines {
+			for _, mac := range macsOf(m.Machine.NICs) {
+				var entry macIndexEntry
+				found, err := tx.get(machineMACsCollection, mac.String(), &entry)
+				if err != nil {
+					return fmt.Errorf("failed to read MAC index: %w", err)
+				}
+				if found && entry.MachineID != m.MachineID {
+					conflicts[i] = &MACConflictError{MAC: mac, MachineID: entry.MachineID}
+					break
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return &BatchConflictError{Conflicts: conflicts}
+		}
+
+		ledger, err := readLedgerDoc(tx)
+		if err != nil {
+			return err
+		}
+		for _, m := range req.Machines {
+			if err := claimMACDocs(tx, m.MachineID, macsOf(m.Machine.NICs), nil); err != nil {
+				return err
+			}
+			machine := &Machine{
+				ID:             m.MachineID,
+				MachineRequest: *m.Machine,
+				Revision:       1,
+				LifecycleState: LifecycleStateNew,
+			}
+			if err := tx.set(machinesCollection, m.MachineID, machine); err != nil {
+				return err
+			}
+			ledger.record(ChangeCreated, m.MachineID, nil, machine, m.Actor)
+		}
+		return writeLedgerDoc(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine documents: %w", err)
+	}
+
+	return &CreateMachinesResponse{}, nil
+}
+
+func (s *docStore) UpdateMachine(ctx context.Context, req *UpdateMachineRequest) (*UpdateMachineResponse, error) {
+	var revision int64
+	err := s.db.update(ctx, func(tx docTx) error {
+		existing, err := getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if err := checkNotDeleted(req.MachineID, existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+
+		if err := claimMACDocs(tx, req.MachineID, macsOf(req.Machine.NICs), macsOf(existing.NICs)); err != nil {
+			return err
+		}
+		revision = existing.Revision + 1
+		machine := &Machine{
+			ID:               req.MachineID,
+			MachineRequest:   *req.Machine,
+			Revision:         revision,
+			LifecycleState:   existing.LifecycleState,
+			LifecycleHistory: existing.LifecycleHistory,
+			Labels:           existing.Labels,
+		}
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		return recordChangeDoc(tx, ChangeUpdated, req.MachineID, existing, machine, req.Actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update machine document: %w", err)
+	}
+
+	return &UpdateMachineResponse{Revision: revision}, nil
+}
+
+func (s *docStore) GetMachine(ctx context.Context, req *GetMachineRequest) (*GetMachineResponse, error) {
+	var machine Machine
+	var found bool
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		found, err = tx.get(machinesCollection, req.MachineID, &machine)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine document: %w", err)
+	}
+	if !found || (machine.DeletedAt != nil && !req.IncludeDeleted) {
+		return &GetMachineResponse{Found: false}, nil
+	}
+
+	return &GetMachineResponse{
+		Machine: &machine,
+		Found:   true,
+	}, nil
+}
+
+func (s *docStore) ListMachines(ctx context.Context, req *ListMachinesRequest) (*ListMachinesResponse, error) {
+	if req.MAC != "" {
+		return s.listMachinesByMAC(ctx, req)
+	}
+
+	var machines []*Machine
+	var scanned int
+	var lastID string
+	err := s.db.view(ctx, func(tx docTx) error {
+		return tx.scan(machinesCollection, req.PageToken, func(id string, data []byte) (bool, error) {
+			scanned++
+			lastID = id
+
+			var machine Machine
+			if err := json.Unmarshal(data, &machine); err != nil {
+				return false, fmt.Errorf("failed to decode machine document: %w", err)
+			}
+			if machine.DeletedAt == nil && req.LabelSelector.Matches(machine.Labels) {
+				machines = append(machines, &machine)
+			}
+			return len(machines) <= req.PageSize && scanned < req.maxScanned(), nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	return listPage(req, machines, scanned, lastID), nil
+}
+
+func (s *docStore) listMachinesByMAC(ctx context.Context, req *ListMachinesRequest) (*ListMachinesResponse, error) {
+	findResp, err := s.FindMachineByMAC(ctx, &FindMachineByMACRequest{MAC: req.MAC})
+	if err != nil {
+		return nil, err
+	}
+	if !findResp.Found || findResp.MachineID <= req.PageToken {
+		return &ListMachinesResponse{}, nil
+	}
+
+	getResp, err := s.GetMachine(ctx, &GetMachineRequest{MachineID: findResp.MachineID})
+	if err != nil {
+		return nil, err
+	}
+	if !getResp.Found || !req.LabelSelector.Matches(getResp.Machine.Labels) {
+		return &ListMachinesResponse{}, nil
+	}
+	return &ListMachinesResponse{Machines: []*Machine{getResp.Machine}}, nil
+}
+
+func (s *docStore) FindMachineByMAC(ctx context.Context, req *FindMachineByMACRequest) (*FindMachineByMACResponse, error) {
+	var entry macIndexEntry
+	var found bool
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		found, err = tx.get(machineMACsCollection, req.MAC.String(), &entry)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC index entry: %w", err)
+	}
+	if !found {
+		return &FindMachineByMACResponse{Found: false}, nil
+	}
+
+	return &FindMachineByMACResponse{
+		MachineID: entry.MachineID,
+		Found:     true,
+	}, nil
+}
+
+func (s *docStore) DeleteMachine(ctx context.Context, req *DeleteMachineRequest) (*DeleteMachineResponse, error) {
+	var revision int64
+	err := s.db.update(ctx, func(tx docTx) error {
+		machine, err := getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if err := checkNotDeleted(req.MachineID, machine); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, machine.Revision); err != nil {
+			return err
+		}
+
+		if err := releaseMACDocs(tx, macsOf(machine.NICs), nil); err != nil {
+			return err
+		}
+		ledger, err := readLedgerDoc(tx)
+		if err != nil {
+			return err
+		}
+		before := *machine
+		revision = machine.Revision + 1
+		machine.DeletedAt = &ledger.at
+		machine.DeletedBy = req.DeletedBy
+		machine.Revision = revision
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		ledger.record(ChangeDeleted, req.MachineID, &before, machine, req.DeletedBy)
+		return writeLedgerDoc(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to tombstone machine document: %w", err)
+	}
+
+	return &DeleteMachineResponse{Revision: revision}, nil
+}
+
+func (s *docStore) RestoreMachine(ctx context.Context, req *RestoreMachineRequest) (*RestoreMachineResponse, error) {
+	var revision int64
+	err := s.db.update(ctx, func(tx docTx) error {
+		machine, err := getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if machine.DeletedAt == nil {
+			return &MachineNotDeletedError{MachineID: req.MachineID}
+		}
+
+		if err := claimMACDocs(tx, req.MachineID, macsOf(machine.NICs), nil); err != nil {
+			return err
+		}
+		before := *machine
+		revision = machine.Revision + 1
+		machine.DeletedAt = nil
+		machine.DeletedBy = ""
+		machine.Revision = revision
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		return recordChangeDoc(tx, ChangeCreated, req.MachineID, &before, machine, req.Actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore machine document: %w", err)
+	}
+
+	return &RestoreMachineResponse{Revision: revision}, nil
+}
+
+func (s *docStore) TransitionMachine(ctx context.Context, req *TransitionMachineRequest) (*TransitionMachineResponse, error) {
+	var machine *Machine
+	err := s.db.update(ctx, func(tx docTx) error {
+		var err error
+		machine, err = getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if err := checkNotDeleted(req.MachineID, machine); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, machine.Revision); err != nil {
+			return err
+		}
+
+		before := *machine
+		if err := machine.transition(req, time.Now().UTC()); err != nil {
+			return err
+		}
+		machine.Revision++
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		return recordChangeDoc(tx, ChangeStateChanged, req.MachineID, &before, machine, req.Actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to transition machine document: %w", err)
+	}
+
+	return &TransitionMachineResponse{Machine: machine}, nil
+}
+
+func (s *docStore) SetMachineLabels(ctx context.Context, req *SetMachineLabelsRequest) (*SetMachineLabelsResponse, error) {
+	var revision int64
+	err := s.db.update(ctx, func(tx docTx) error {
+		machine, err := getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if err := checkNotDeleted(req.MachineID, machine); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, machine.Revision); err != nil {
+			return err
+		}
+
+		before := *machine
+		revision = machine.Revision + 1
+		machine.Labels = req.Labels
+		machine.Revision = revision
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		return recordChangeDoc(tx, ChangeUpdated, req.MachineID, &before, machine, req.Actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set machine labels: %w", err)
+	}
+
+	return &SetMachineLabelsResponse{Revision: revision}, nil
+}
+
+func (s *docStore) PurgeDeletedMachines(ctx context.Context, req *PurgeDeletedMachinesRequest) (*PurgeDeletedMachinesResponse, error) {
+	var purged int
+	err := s.db.update(ctx, func(tx docTx) error {
+		var ids []string
+		err := tx.scan(machinesCollection, "", func(id string, data []byte) (bool, error) {
+			var machine Machine
+			if err := json.Unmarshal(data, &machine); err != nil {
+				return false, fmt.Errorf("failed to decode machine document: %w", err)
+			}
+			if machine.DeletedAt != nil && machine.DeletedAt.Before(req.DeletedBefore) {
+				ids = append(ids, id)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := deleteChildDocs(tx, revisionsCollection, id); err != nil {
+				return err
+			}
+			if err := tx.delete(machinesCollection, id); err != nil {
+				return err
+			}
+		}
+		purged = len(ids)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted machines: %w", err)
+	}
+
+	return &PurgeDeletedMachinesResponse{Purged: purged}, nil
+}
+
+func (s *docStore) GetIdempotencyRecord(ctx context.Context, req *GetIdempotencyRecordRequest) (*GetIdempotencyRecordResponse, error) {
+	var record *IdempotencyRecord
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		record, err = getIdempotencyDoc(tx, req.Key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return &GetIdempotencyRecordResponse{Found: false}, nil
+	}
+
+	return &GetIdempotencyRecordResponse{
+		Record: record,
+		Found:  true,
+	}, nil
+}
+
+func (s *docStore) ListMachineRevisions(ctx context.Context, req *ListMachineRevisionsRequest) (*ListMachineRevisionsResponse, error) {
+	startAfter := req.MachineID + "/"
+	if req.PageToken != 0 {
+		startAfter = revisionDocID(req.MachineID, req.PageToken)
+	}
+
+	var revisions []*MachineRevision
+	err := s.db.view(ctx, func(tx docTx) error {
+		return tx.scan(revisionsCollection, startAfter, func(id string, data []byte) (bool, error) {
+			if !strings.HasPrefix(id, req.MachineID+"/") {
+				return false, nil
+			}
+			var revision MachineRevision
+			if err := json.Unmarshal(data, &revision); err != nil {
+				return false, fmt.Errorf("failed to decode machine revision: %w", err)
+			}
+			revisions = append(revisions, &revision)
+			return len(revisions) <= req.PageSize, nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machine revisions: %w", err)
+	}
+
+	resp := &ListMachineRevisionsResponse{Revisions: revisions}
+	if len(revisions) > req.PageSize {
+		resp.Revisions = revisions[:req.PageSize]
+		resp.NextPageToken = resp.Revisions[req.PageSize-1].Machine.Revision
+	}
+	return resp, nil
+}
+
+func (s *docStore) GetMachineRevision(ctx context.Context, req *GetMachineRevisionRequest) (*GetMachineRevisionResponse, error) {
+	var revision MachineRevision
+	var found bool
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		found, err = tx.get(revisionsCollection, revisionDocID(req.MachineID, req.Revision), &revision)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine revision: %w", err)
+	}
+	if !found {
+		return &GetMachineRevisionResponse{Found: false}, nil
+	}
+
+	return &GetMachineRevisionResponse{
+		Revision: &revision,
+		Found:    true,
+	}, nil
+}
+
+func (s *docStore) RollbackMachine(ctx context.Context, req *RollbackMachineRequest) (*RollbackMachineResponse, error) {
+	var machine *Machine
+	err := s.db.update(ctx, func(tx docTx) error {
+		existing, err := getMachineDoc(tx, req.MachineID)
+		if err != nil {
+			return err
+		}
+		if err := checkNotDeleted(req.MachineID, existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+
+		var revision MachineRevision
+		found, err := tx.get(revisionsCollection, revisionDocID(req.MachineID, req.Revision), &revision)
+		if err != nil {
+			return fmt.Errorf("failed to get machine revision: %w", err)
+		}
+		if !found {
+			return &RevisionNotFoundError{MachineID: req.MachineID, Revision: req.Revision}
+		}
+
+		machine = existing.rollback(&revision)
+		machine.Revision = existing.Revision + 1
+		if err := claimMACDocs(tx, req.MachineID, macsOf(machine.NICs), macsOf(existing.NICs)); err != nil {
+			return err
+		}
+		if err := tx.set(machinesCollection, req.MachineID, machine); err != nil {
+			return err
+		}
+		return recordChangeDoc(tx, ChangeUpdated, req.MachineID, existing, machine, req.Actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back machine document: %w", err)
+	}
+
+	return &RollbackMachineResponse{Machine: machine}, nil
+}
+
+func (s *docStore) ListMachineChanges(ctx context.Context, req *ListMachineChangesRequest) (*ListMachineChangesResponse, error) {
+	resp := &ListMachineChangesResponse{}
+	err := s.db.view(ctx, func(tx docTx) error {
+		var counter changeCounter
+		if _, err := tx.get(countersCollection, changeCounterID, &counter); err != nil {
+			return fmt.Errorf("failed to get change counter: %w", err)
+		}
+		resp.Latest = counter.Sequence
+		if req.PageSize == 0 {
+			return nil
+		}
+
+		return tx.scan(changesCollection, changeDocID(req.After), func(id string, data []byte) (bool, error) {
+			var change MachineChange
+			if err := json.Unmarshal(data, &change); err != nil {
+				return false, fmt.Errorf("failed to decode machine change: %w", err)
+			}
+			resp.Changes = append(resp.Changes, &change)
+			return len(resp.Changes) < req.PageSize, nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machine changes: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (s *docStore) PurgeMachineChanges(ctx context.Context, req *PurgeMachineChangesRequest) (*PurgeMachineChangesResponse, error) {
+	var purged int
+	err := s.db.update(ctx, func(tx docTx) error {
+		// Changes are recorded in sequence order, so the purgeable ones are
+		// a prefix of the log.
+		var ids []string
+		err := tx.scan(changesCollection, "", func(id string, data []byte) (bool, error) {
+			var change MachineChange
+			if err := json.Unmarshal(data, &change); err != nil {
+				return false, fmt.Errorf("failed to decode machine change: %w", err)
+			}
+			if !change.At.Before(req.Before) || (req.Through != nil && change.Sequence > *req.Through) {
+				return false, nil
+			}
+			ids = append(ids, id)
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := tx.delete(changesCollection, id); err != nil {
+				return err
+			}
+		}
+		purged = len(ids)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge machine changes: %w", err)
+	}
+
+	return &PurgeMachineChangesResponse{Purged: purged}, nil
+}
+
+func (s *docStore) GetChangeCursor(ctx context.Context, req *GetChangeCursorRequest) (*GetChangeCursorResponse, error) {
+	var cursor changeCounter
+	err := s.db.view(ctx, func(tx docTx) error {
+		_, err := tx.get(cursorsCollection, req.Consumer, &cursor)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change cursor: %w", err)
+	}
+
+	return &GetChangeCursorResponse{Sequence: cursor.Sequence}, nil
+}
+
+func (s *docStore) SetChangeCursor(ctx context.Context, req *SetChangeCursorRequest) (*SetChangeCursorResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		return tx.set(cursorsCollection, req.Consumer, changeCounter{Sequence: req.Sequence})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set change cursor: %w", err)
+	}
+
+	return &SetChangeCursorResponse{}, nil
+}
+
+func (s *docStore) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		found, err := tx.get(webhooksCollection, req.Webhook.ID, &Webhook{})
+		if err != nil {
+			return err
+		}
+		if found {
+			return fmt.Errorf("webhook %s already exists", req.Webhook.ID)
+		}
+		return tx.set(webhooksCollection, req.Webhook.ID, req.Webhook)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &CreateWebhookResponse{}, nil
+}
+
+func (s *docStore) GetWebhook(ctx context.Context, req *GetWebhookRequest) (*GetWebhookResponse, error) {
+	var webhook Webhook
+	var found bool
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		found, err = tx.get(webhooksCollection, req.WebhookID, &webhook)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if !found {
+		return &GetWebhookResponse{Found: false}, nil
+	}
+
+	return &GetWebhookResponse{
+		Webhook: &webhook,
+		Found:   true,
+	}, nil
+}
+
+func (s *docStore) ListWebhooks(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	var webhooks []*Webhook
+	err := s.db.view(ctx, func(tx docTx) error {
+		return tx.scan(webhooksCollection, "", func(id string, data []byte) (bool, error) {
+			var webhook Webhook
+			if err := json.Unmarshal(data, &webhook); err != nil {
+				return false, fmt.Errorf("failed to decode webhook: %w", err)
+			}
+			webhooks = append(webhooks, &webhook)
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return &ListWebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (s *docStore) UpdateWebhook(ctx context.Context, req *UpdateWebhookRequest) (*UpdateWebhookResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		found, err := tx.get(webhooksCollection, req.Webhook.ID, &Webhook{})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &WebhookNotFoundError{WebhookID: req.Webhook.ID}
+		}
+		return tx.set(webhooksCollection, req.Webhook.ID, req.Webhook)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return &UpdateWebhookResponse{}, nil
+}
+
+func (s *docStore) DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		found, err := tx.get(webhooksCollection, req.WebhookID, &Webhook{})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &WebhookNotFoundError{WebhookID: req.WebhookID}
+		}
+		if err := tx.delete(webhooksCollection, req.WebhookID); err != nil {
+			return err
+		}
+		return deleteChildDocs(tx, deliveriesCollection, req.WebhookID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return &DeleteWebhookResponse{}, nil
+}
+
+func (s *docStore) EnqueueWebhookDeliveries(ctx context.Context, req *EnqueueWebhookDeliveriesRequest) (*EnqueueWebhookDeliveriesResponse, error) {
+	err := s.db.update(ctx, func(tx docTx) error {
+		var cursor changeCounter
+		if _, err := tx.get(cursorsCollection, req.Consumer, &cursor); err != nil {
+			return err
+		}
+		if cursor.Sequence != req.From {
+			return &CursorMovedError{Consumer: req.Consumer, Expected: req.From, Actual: cursor.Sequence}
+		}
+
+		for _, delivery := range req.Deliveries {
+			id := deliveryDocID(delivery.WebhookID, delivery.Sequence)
+			found, err := tx.get(deliveriesCollection, id, &WebhookDelivery{})
+			if err != nil {
+				return err
+			}
+			if found {
+				continue
+			}
+			if err := tx.set(deliveriesCollection, id, delivery); err != nil {
+				return err
+			}
+		}
+		return tx.set(cursorsCollection, req.Consumer, changeCounter{Sequence: req.Through})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return &EnqueueWebhookDeliveriesResponse{}, nil
+}
+
+func (s *docStore) ListWebhookDeliveries(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	startAfter := req.WebhookID + "/"
+	if req.PageToken != 0 {
+		startAfter = deliveryDocID(req.WebhookID, req.PageToken)
+	}
+
+	var deliveries []*WebhookDelivery
+	err := s.db.view(ctx, func(tx docTx) error {
+		return tx.scan(deliveriesCollection, startAfter, func(id string, data []byte) (bool, error) {
+			if !strings.HasPrefix(id, req.WebhookID+"/") {
+				return false, nil
+			}
+			var delivery WebhookDelivery
+			if err := json.Unmarshal(data, &delivery); err != nil {
+				return false, fmt.Errorf("failed to decode webhook delivery: %w", err)
+			}
+			deliveries = append(deliveries, &delivery)
+			return len(deliveries) <= req.PageSize, nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	resp := &ListWebhookDeliveriesResponse{Deliveries: deliveries}
+	if len(deliveries) > req.PageSize {
+		resp.Deliveries = deliveries[:req.PageSize]
+		resp.NextPageToken = resp.Deliveries[req.PageSize-1].Sequence
+	}
+	return resp, nil
+}
+
+func (s *docStore) GetWebhookDelivery(ctx context.Context, req *GetWebhookDeliveryRequest) (*GetWebhookDeliveryResponse, error) {
+	var delivery WebhookDelivery
+	var found bool
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		found, err = tx.get(deliveriesCollection, deliveryDocID(req.WebhookID, req.Sequence), &delivery)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if !found {
+		return &GetWebhookDeliveryResponse{Found: false}, nil
+	}
+
+	return &GetWebhookDeliveryResponse{
+		Delivery: &delivery,
+		Found:    true,
+	}, nil
+}
+
+// ListDueWebhookDeliveries scans every delivery, which the purge of settled
+// deliveries keeps to those of the retention period.
+func (s *docStore) ListDueWebhookDeliveries(ctx context.Context, req *ListDueWebhookDeliveriesRequest) (*ListDueWebhookDeliveriesResponse, error) {
+	var deliveries []*WebhookDelivery
+	err := s.db.view(ctx, func(tx docTx) error {
+		return tx.scan(deliveriesCollection, "", func(id string, data []byte) (bool, error) {
+			var delivery WebhookDelivery
+			if err := json.Unmarshal(data, &delivery); err != nil {
+				return false, fmt.Errorf("failed to decode webhook delivery: %w", err)
+			}
+			if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(req.Now) {
+				deliveries = append(deliveries, &delivery)
+			}
+			return true, nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	slices.SortStableFunc(deliveries, func(a, b *WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(*b.NextAttemptAt)
+	})
+	if len(deliveries) > req.Limit {
+		deliveries = deliveries[:req.Limit]
+	}
+	return &ListDueWebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
+
+func (s *docStore) ClaimWebhookDelivery(ctx context.Context, req *ClaimWebhookDeliveryRequest) (*ClaimWebhookDeliveryResponse, error) {
+	id := deliveryDocID(req.WebhookID, req.Sequence)
+
+	var delivery WebhookDelivery
+	err := s.db.update(ctx, func(tx docTx) error {
+		if err := getLeasedDeliveryDoc(tx, req.WebhookID, req.Sequence, req.Due, &delivery); err != nil {
+			return err
+		}
+		leaseUntil := req.LeaseUntil
+		delivery.NextAttemptAt = &leaseUntil
+		return tx.set(deliveriesCollection, id, &delivery)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	return &ClaimWebhookDeliveryResponse{Delivery: &delivery}, nil
+}
+
+func (s *docStore) SetWebhookDelivery(ctx context.Context, req *SetWebhookDeliveryRequest) (*SetWebhookDeliveryResponse, error) {
+	delivery := req.Delivery
+	err := s.db.update(ctx, func(tx docTx) error {
+		var stored WebhookDelivery
+		if err := getLeasedDeliveryDoc(tx, delivery.WebhookID, delivery.Sequence, req.LeasedUntil, &stored); err != nil {
+			return err
+		}
+		return tx.set(deliveriesCollection, deliveryDocID(delivery.WebhookID, delivery.Sequence), delivery)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set webhook delivery: %w", err)
+	}
+
+	return &SetWebhookDeliveryResponse{}, nil
+}
+
+// getLeasedDeliveryDoc reads a delivery, failing with a
+// *DeliveryChangedError unless it exists and is pending at nextAttemptAt.
+func getLeasedDeliveryDoc(tx docTx, webhookID string, sequence int64, nextAttemptAt time.Time, delivery *WebhookDelivery) error {
+	found, err := tx.get(deliveriesCollection, deliveryDocID(webhookID, sequence), delivery)
+	if err != nil {
+		return err
+	}
+	if !found || !delivery.pendingAt(nextAttemptAt) {
+		return &DeliveryChangedError{WebhookID: webhookID, Sequence: sequence}
+	}
+	return nil
+}
+
+func (s *docStore) RedeliverWebhookDelivery(ctx context.Context, req *RedeliverWebhookDeliveryRequest) (*RedeliverWebhookDeliveryResponse, error) {
+	id := deliveryDocID(req.WebhookID, req.Sequence)
+
+	var delivery WebhookDelivery
+	err := s.db.update(ctx, func(tx docTx) error {
+		found, err := tx.get(deliveriesCollection, id, &delivery)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &DeliveryNotFoundError{WebhookID: req.WebhookID, Sequence: req.Sequence}
+		}
+
+		delivery.redeliver(time.Now().UTC(), req.GiveUpAt)
+		return tx.set(deliveriesCollection, id, &delivery)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	return &RedeliverWebhookDeliveryResponse{Delivery: &delivery}, nil
+}
+
+func (s *docStore) PurgeWebhookDeliveries(ctx context.Context, req *PurgeWebhookDeliveriesRequest) (*PurgeWebhookDeliveriesResponse, error) {
+	var purged int
+	err := s.db.update(ctx, func(tx docTx) error {
+		var ids []string
+		err := tx.scan(deliveriesCollection, "", func(id string, data []byte) (bool, error) {
+			var delivery WebhookDelivery
+			if err := json.Unmarshal(data, &delivery); err != nil {
+				return false, fmt.Errorf("failed to decode webhook delivery: %w", err)
+			}
+			if delivery.Status != DeliveryPending && delivery.CreatedAt.Before(req.Before) {
+				ids = append(ids, id)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := tx.delete(deliveriesCollection, id); err != nil {
+				return err
+			}
+		}
+		purged = len(ids)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+
+	return &PurgeWebhookDeliveriesResponse{Purged: purged}, nil
+}
+
+func (s *docStore) GetInventorySummary(ctx context.Context, req *GetInventorySummaryRequest) (*GetInventorySummaryResponse, error) {
+	var summary *InventorySummary
+	err := s.db.view(ctx, func(tx docTx) error {
+		var err error
+		summary, err = getSummaryDoc(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetInventorySummaryResponse{Summary: summary.decodeKeys()}, nil
+}
+
+func (s *docStore) RebuildInventorySummary(ctx context.Context, req *RebuildInventorySummaryRequest) (*RebuildInventorySummaryResponse, error) {
+	var summary *InventorySummary
+	err := s.db.update(ctx, func(tx docTx) error {
+		var err error
+		summary, err = rebuildSummaryDoc(tx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild inventory summary: %w", err)
+	}
+
+	return &RebuildInventorySummaryResponse{Summary: summary.decodeKeys()}, nil
+}
+
+// ensureInventorySummary rebuilds the inventory summary if it has never been
+// written, so databases created before it was maintained start out correct.
+func (s *docStore) ensureInventorySummary(ctx context.Context) error {
+	return s.db.update(ctx, func(tx docTx) error {
+		found, err := tx.get(inventoryCollection, inventorySummaryID, &InventorySummary{})
+		if err != nil || found {
+			return err
+		}
+		_, err = rebuildSummaryDoc(tx)
+		return err
+	})
+}
+
+func (s *docStore) Close() error {
+	return s.db.close()
+}
+
+func getMachineDoc(tx docTx, machineID string) (*Machine, error) {
+	var machine Machine
+	found, err := tx.get(machinesCollection, machineID, &machine)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &MachineNotFoundError{MachineID: machineID}
+	}
+	return &machine, nil
+}
+
+// getIdempotencyDoc treats expired records as missing, matching
+// getIdempotencyRecord.
+func getIdempotencyDoc(tx docTx, key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	found, err := tx.get(idempotencyCollection, key, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	if !found || time.Now().After(record.ExpiresAt) {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// revisionDocID orders the revisions of a machine newest first.
+func revisionDocID(machineID string, revision int64) string {
+	return descendingDocID(machineID, revision)
+}
+
+// deliveryDocID orders the deliveries of a webhook newest first.
+func deliveryDocID(webhookID string, sequence int64) string {
+	return descendingDocID(webhookID, sequence)
+}
+
+// descendingDocID identifies the document numbered n among the children of
+// parentID, such that scans, which only run in ascending ID order, visit the
+// highest numbers first.
+func descendingDocID(parentID string, n int64) string {
+	return fmt.Sprintf("%s/%019d", parentID, math.MaxInt64-n)
+}
+
+// readLedgerDoc is the docTx counterpart of FirestoreClient.readLedger.
+func readLedgerDoc(tx docTx) (*ledger, error) {
+	summary, err := getSummaryDoc(tx)
+	if err != nil {
+		return nil, err
+	}
+	var counter changeCounter
+	if _, err := tx.get(countersCollection, changeCounterID, &counter); err != nil {
+		return nil, fmt.Errorf("failed to get change counter: %w", err)
+	}
+	return newLedger(summary, counter), nil
+}
+
+// writeLedgerDoc is the docTx counterpart of FirestoreClient.writeLedger.
+func writeLedgerDoc(tx docTx, l *ledger) error {
+	for _, revision := range l.revisions {
+		if err := tx.set(revisionsCollection, revisionDocID(revision.Machine.ID, revision.Machine.Revision), revision); err != nil {
+			return err
+		}
+	}
+	for _, change := range l.changes {
+		if err := tx.set(changesCollection, changeDocID(change.Sequence), change); err != nil {
+			return err
+		}
+	}
+	if err := tx.set(inventoryCollection, inventorySummaryID, l.summary); err != nil {
+		return err
+	}
+	return tx.set(countersCollection, changeCounterID, l.counter())
+}
+
+// recordChangeDoc records a single machine write in the ledger.
+func recordChangeDoc(tx docTx, changeType ChangeType, machineID string, before, after *Machine, actor string) error {
+	ledger, err := readLedgerDoc(tx)
+	if err != nil {
+		return err
+	}
+	ledger.record(changeType, machineID, before, after, actor)
+	return writeLedgerDoc(tx, ledger)
+}
+
+// deleteChildDocs deletes the documents of collection identified by
+// descendingDocID under parentID.
+func deleteChildDocs(tx docTx, collection, parentID string) error {
+	var ids []string
+	err := tx.scan(collection, parentID+"/", func(id string, _ []byte) (bool, error) {
+		if !strings.HasPrefix(id, parentID+"/") {
+			return false, nil
+		}
+		ids = append(ids, id)
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.delete(collection, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSummaryDoc returns the stored inventory summary, or an empty one if no
+// machine has been written yet.
+func getSummaryDoc(tx docTx) (*InventorySummary, error) {
+	var summary InventorySummary
+	if _, err := tx.get(inventoryCollection, inventorySummaryID, &summary); err != nil {
+		return nil, fmt.Errorf("failed to get inventory summary: %w", err)
+	}
+	return &summary, nil
+}
+
+func rebuildSummaryDoc(tx docTx) (*InventorySummary, error) {
+	var summary InventorySummary
+	err := tx.scan(machinesCollection, "", func(id string, data []byte) (bool, error) {
+		var machine Machine
+		if err := json.Unmarshal(data, &machine); err != nil {
+			return false, fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		summary.add(&machine, 1)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.UpdatedAt = time.Now().UTC()
+	return &summary, tx.set(inventoryCollection, inventorySummaryID, &summary)
+}
+
+// claimMACDocs is the docTx counterpart of FirestoreClient.claimMACs.
+func claimMACDocs(tx docTx, machineID string, macs, previous []MAC) error {
+	for _, mac := range macs {
+		var entry macIndexEntry
+		found, err := tx.get(machineMACsCollection, mac.String(), &entry)
+		if err != nil {
+			return fmt.Errorf("failed to read MAC index: %w", err)
+		}
+		if found && entry.MachineID != machineID {
+			return &MACConflictError{MAC: mac, MachineID: entry.MachineID}
+		}
+	}
+
+	claimed := make(map[MAC]bool, len(macs))
+	for _, mac := range macs {
+		claimed[mac] = true
+		if err := tx.set(machineMACsCollection, mac.String(), macIndexEntry{MachineID: machineID}); err != nil {
+			return err
+		}
+	}
+	return releaseMACDocs(tx, previous, claimed)
+}
+
+func releaseMACDocs(tx docTx, macs []MAC, keep map[MAC]bool) error {
+	for _, mac := range macs {
+		if keep[mac] {
+			continue
+		}
+		if err := tx.delete(machineMACsCollection, mac.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
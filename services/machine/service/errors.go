@@ -0,0 +1,137 @@
+package service
+
+import "fmt"
+
+// MACConflictError is returned when a write would assign a NIC MAC address
+// that already belongs to another machine.
+type MACConflictError struct {
+	MAC       MAC
+	MachineID string
+}
+
+func (e *MACConflictError) Error() string {
+	return fmt.Sprintf("MAC address %s already belongs to machine %s", e.MAC, e.MachineID)
+}
+
+// MachineNotFoundError is returned when a write targets a machine which does
+// not exist or has been deleted. Writes check this in the same transaction
+// as the write, as a machine can be deleted after a caller has read it.
+type MachineNotFoundError struct {
+	MachineID string
+}
+
+func (e *MachineNotFoundError) Error() string {
+	return fmt.Sprintf("machine %s does not exist", e.MachineID)
+}
+
+// MachineNotDeletedError is returned when a machine is restored which is not
+// deleted, typically because a concurrent restore got there first.
+type MachineNotDeletedError struct {
+	MachineID string
+}
+
+func (e *MachineNotDeletedError) Error() string {
+	return fmt.Sprintf("machine %s is not deleted", e.MachineID)
+}
+
+// IdempotencyKeyExistsError is returned when a machine is created with an
+// idempotency key that has already been recorded and has not yet expired.
+type IdempotencyKeyExistsError struct {
+	Record *IdempotencyRecord
+}
+
+func (e *IdempotencyKeyExistsError) Error() string {
+	return fmt.Sprintf("idempotency key %s has already been used", e.Record.Key)
+}
+
+// RevisionMismatchError is returned when a conditional write expects a
+// machine revision other than the one currently stored.
+type RevisionMismatchError struct {
+	MachineID string
+	Expected  int64
+	Actual    int64
+}
+
+func (e *RevisionMismatchError) Error() string {
+	return fmt.Sprintf("machine %s is at revision %d, expected %d", e.MachineID, e.Actual, e.Expected)
+}
+
+// BatchConflictError is returned when an all-or-nothing batch cannot be
+// created because some of its machines claim MACs owned by other machines.
+// Conflicts is keyed by the index of the machine in the batch.
+type BatchConflictError struct {
+	Conflicts map[int]*MACConflictError
+}
+
+func (e *BatchConflictError) Error() string {
+	return fmt.Sprintf("%d machines in the batch have conflicting MAC addresses", len(e.Conflicts))
+}
+
+// IllegalTransitionError is returned when a machine is asked to move to a
+// lifecycle state that its current state does not allow.
+type IllegalTransitionError struct {
+	MachineID string
+	From      LifecycleState
+	To        LifecycleState
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("machine %s cannot transition from %s to %s", e.MachineID, e.From, e.To)
+}
+
+// RevisionNotFoundError is returned when a machine revision does not exist,
+// either because it was never written or because the machine has since been
+// purged.
+type RevisionNotFoundError struct {
+	MachineID string
+	Revision  int64
+}
+
+func (e *RevisionNotFoundError) Error() string {
+	return fmt.Sprintf("machine %s has no revision %d", e.MachineID, e.Revision)
+}
+
+// WebhookNotFoundError is returned when a webhook does not exist.
+type WebhookNotFoundError struct {
+	WebhookID string
+}
+
+func (e *WebhookNotFoundError) Error() string {
+	return fmt.Sprintf("webhook %s does not exist", e.WebhookID)
+}
+
+// DeliveryNotFoundError is returned when a webhook has no delivery of a
+// change, either because the change did not match the webhook or because
+// the delivery has since been purged.
+type DeliveryNotFoundError struct {
+	WebhookID string
+	Sequence  int64
+}
+
+func (e *DeliveryNotFoundError) Error() string {
+	return fmt.Sprintf("webhook %s has no delivery of change %d", e.WebhookID, e.Sequence)
+}
+
+// CursorMovedError is returned when a change cursor is no longer where a
+// consumer read it, because another instance of the consumer advanced it.
+type CursorMovedError struct {
+	Consumer string
+	Expected int64
+	Actual   int64
+}
+
+func (e *CursorMovedError) Error() string {
+	return fmt.Sprintf("change cursor %s is at %d, not %d", e.Consumer, e.Actual, e.Expected)
+}
+
+// DeliveryChangedError is returned when a webhook delivery is not as a
+// dispatcher expects: another instance claimed it first, or it was
+// redelivered or deleted since it was claimed.
+type DeliveryChangedError struct {
+	WebhookID string
+	Sequence  int64
+}
+
+func (e *DeliveryChangedError) Error() string {
+	return fmt.Sprintf("webhook %s delivery of change %d changed concurrently", e.WebhookID, e.Sequence)
+}
@@ -2,22 +2,221 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"strings"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CreateMachineRequest struct {
 	MachineID string
 	Machine   *MachineRequest
+	Actor     string
+
+	// IdempotencyRecord, if set, is stored atomically with the machine.
+	IdempotencyRecord *IdempotencyRecord
 }
 
 type CreateMachineResponse struct{}
 
+// CreateMachinesRequest creates every machine in one transaction, or none of
+// them.
+type CreateMachinesRequest struct {
+	Machines []*CreateMachineRequest
+}
+
+type CreateMachinesResponse struct{}
+
+type UpdateMachineRequest struct {
+	MachineID string
+	Machine   *MachineRequest
+	Actor     string
+
+	// ExpectedRevision, if set, fails the write with a
+	// *RevisionMismatchError unless the stored machine is at that revision.
+	ExpectedRevision *int64
+}
+
+type UpdateMachineResponse struct {
+	Revision int64
+}
+
+type DeleteMachineRequest struct {
+	MachineID string
+	DeletedBy string
+
+	// ExpectedRevision behaves as in UpdateMachineRequest.
+	ExpectedRevision *int64
+}
+
+type DeleteMachineResponse struct {
+	Revision int64
+}
+
+type RestoreMachineRequest struct {
+	MachineID string
+	Actor     string
+}
+
+type RestoreMachineResponse struct {
+	Revision int64
+}
+
+type TransitionMachineRequest struct {
+	MachineID string
+	To        LifecycleState
+	Actor     string
+	Reason    string
+
+	// ExpectedRevision behaves as in UpdateMachineRequest.
+	ExpectedRevision *int64
+}
+
+type TransitionMachineResponse struct {
+	Machine *Machine
+}
+
+// SetMachineLabelsRequest replaces all labels of a machine.
+type SetMachineLabelsRequest struct {
+	MachineID string
+	Labels    map[string]string
+	Actor     string
+
+	// ExpectedRevision behaves as in UpdateMachineRequest.
+	ExpectedRevision *int64
+}
+
+type SetMachineLabelsResponse struct {
+	Revision int64
+}
+
+// ListMachineRevisionsRequest lists the superseded versions of a machine,
+// newest first.
+type ListMachineRevisionsRequest struct {
+	MachineID string
+	PageSize  int
+
+	// PageToken is the revision the previous page ended with.
+	PageToken int64
+}
+
+type ListMachineRevisionsResponse struct {
+	Revisions     []*MachineRevision
+	NextPageToken int64
+}
+
+type GetMachineRevisionRequest struct {
+	MachineID string
+	Revision  int64
+}
+
+type GetMachineRevisionResponse struct {
+	Revision *MachineRevision
+	Found    bool
+}
+
+// RollbackMachineRequest restores the hardware and labels a machine had at
+// Revision, as a new revision. It fails with a *RevisionNotFoundError if
+// that revision was never stored.
+type RollbackMachineRequest struct {
+	MachineID string
+	Revision  int64
+	Actor     string
+
+	// ExpectedRevision behaves as in UpdateMachineRequest.
+	ExpectedRevision *int64
+}
+
+type RollbackMachineResponse struct {
+	Machine *Machine
+}
+
+type PurgeDeletedMachinesRequest struct {
+	DeletedBefore time.Time
+}
+
+type PurgeDeletedMachinesResponse struct {
+	Purged int
+}
+
+type GetIdempotencyRecordRequest struct {
+	Key string
+}
+
+type GetIdempotencyRecordResponse struct {
+	Record *IdempotencyRecord
+	Found  bool
+}
+
+type GetMachineRequest struct {
+	MachineID string
+
+	// IncludeDeleted returns tombstoned machines instead of reporting them
+	// as not found.
+	IncludeDeleted bool
+}
+
+type GetMachineResponse struct {
+	Machine *Machine
+	Found   bool
+}
+
+// ListMachinesRequest lists a page of live machines. Tombstones and machines
+// not matching LabelSelector are skipped, but count towards MaxScanned, so a
+// page may hold fewer than PageSize machines, or none, and still be followed
+// by another.
+type ListMachinesRequest struct {
+	PageSize      int
+	PageToken     string
+	MAC           MAC
+	LabelSelector Selector
+
+	// MaxScanned bounds how many machines are read, DefaultMaxScanned when
+	// zero.
+	MaxScanned int
+}
+
+// DefaultMaxScanned is how many machines a listing reads at most.
+const DefaultMaxScanned = 1000
+
+type ListMachinesResponse struct {
+	Machines      []*Machine
+	NextPageToken string
+}
+
+// maxScanned is req.MaxScanned, or its default. At least one machine past a
+// full page is read, to tell whether another follows.
+func (req *ListMachinesRequest) maxScanned() int {
+	if req.MaxScanned <= 0 {
+		return max(DefaultMaxScanned, req.PageSize+1)
+	}
+	return max(req.MaxScanned, req.PageSize+1)
+}
+
+// listPage is the response to req after reading machines, the candidates of
+// which that matched, up to lastID. A page cut short by MaxScanned continues
+// after lastID.
+func listPage(req *ListMachinesRequest, machines []*Machine, scanned int, lastID string) *ListMachinesResponse {
+	resp := &ListMachinesResponse{Machines: machines}
+	switch {
+	case len(machines) > req.PageSize:
+		resp.Machines = machines[:req.PageSize]
+		resp.NextPageToken = resp.Machines[req.PageSize-1].ID
+	case scanned >= req.maxScanned():
+		resp.NextPageToken = lastID
+	}
+	return resp
+}
+
 type FindMachineByMACRequest struct {
-	MAC string
+	MAC MAC
 }
 
 type FindMachineByMACResponse struct {
@@ -25,8 +224,225 @@ type FindMachineByMACResponse struct {
 	Found     bool
 }
 
+type MigrateMACsRequest struct {
+	// DryRun reports what would change without writing anything.
+	DryRun bool
+}
+
+type MigrateMACsResponse struct {
+	Scanned   int
+	Rewritten int
+	Failures  []MigrateMACsFailure
+}
+
+type MigrateMACsFailure struct {
+	MachineID string
+	Err       error
+}
+
+type GetInventorySummaryRequest struct{}
+
+type GetInventorySummaryResponse struct {
+	Summary *InventorySummary
+}
+
+// RebuildInventorySummaryRequest recomputes the inventory summary from every
+// machine, for data written before the summary was maintained or after it
+// has drifted.
+type RebuildInventorySummaryRequest struct{}
+
+type RebuildInventorySummaryResponse struct {
+	Summary *InventorySummary
+}
+
+// ListMachineChangesRequest reads the change log from just after the
+// sequence After, oldest first.
+type ListMachineChangesRequest struct {
+	After    int64
+	PageSize int
+}
+
+type ListMachineChangesResponse struct {
+	Changes []*MachineChange
+
+	// Latest is the last sequence assigned when the read began. Every change
+	// up to it is in the log unless it has since been purged.
+	Latest int64
+}
+
+type PurgeMachineChangesRequest struct {
+	Before time.Time
+
+	// Through, if set, keeps every change after it, so that consumers
+	// which have not processed them yet do not lose them.
+	Through *int64
+}
+
+type PurgeMachineChangesResponse struct {
+	Purged int
+}
+
+// GetChangeCursorRequest reads how far Consumer has processed the change
+// log. A consumer that has never stored a cursor is at sequence 0.
+type GetChangeCursorRequest struct {
+	Consumer string
+}
+
+type GetChangeCursorResponse struct {
+	Sequence int64
+}
+
+type SetChangeCursorRequest struct {
+	Consumer string
+	Sequence int64
+}
+
+type SetChangeCursorResponse struct{}
+
+type CreateWebhookRequest struct {
+	Webhook *Webhook
+}
+
+type CreateWebhookResponse struct{}
+
+type GetWebhookRequest struct {
+	WebhookID string
+}
+
+type GetWebhookResponse struct {
+	Webhook *Webhook
+	Found   bool
+}
+
+type ListWebhooksRequest struct{}
+
+type ListWebhooksResponse struct {
+	Webhooks []*Webhook
+}
+
+// UpdateWebhookRequest replaces a webhook. It fails with a
+// *WebhookNotFoundError if the webhook does not exist.
+type UpdateWebhookRequest struct {
+	Webhook *Webhook
+}
+
+type UpdateWebhookResponse struct{}
+
+// DeleteWebhookRequest deletes a webhook along with its deliveries. It fails
+// with a *WebhookNotFoundError if the webhook does not exist.
+type DeleteWebhookRequest struct {
+	WebhookID string
+}
+
+type DeleteWebhookResponse struct{}
+
+// EnqueueWebhookDeliveriesRequest stores new deliveries and advances the
+// change cursor of Consumer from From to Through in one transaction, so that
+// every change is enqueued exactly once. It fails with a *CursorMovedError if
+// the cursor is no longer at From, and never replaces a delivery that
+// already exists.
+type EnqueueWebhookDeliveriesRequest struct {
+	Deliveries []*WebhookDelivery
+	Consumer   string
+	From       int64
+	Through    int64
+}
+
+type EnqueueWebhookDeliveriesResponse struct{}
+
+// ListWebhookDeliveriesRequest lists the deliveries of a webhook, newest
+// first.
+type ListWebhookDeliveriesRequest struct {
+	WebhookID string
+	PageSize  int
+
+	// PageToken is the sequence the previous page ended with.
+	PageToken int64
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries    []*WebhookDelivery
+	NextPageToken int64
+}
+
+type GetWebhookDeliveryRequest struct {
+	WebhookID string
+	Sequence  int64
+}
+
+type GetWebhookDeliveryResponse struct {
+	Delivery *WebhookDelivery
+	Found    bool
+}
+
+// ListDueWebhookDeliveriesRequest lists up to Limit pending deliveries whose
+// next attempt is due at Now, the longest overdue first.
+type ListDueWebhookDeliveriesRequest struct {
+	Now   time.Time
+	Limit int
+}
+
+type ListDueWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery
+}
+
+// ClaimWebhookDeliveryRequest leases a due delivery to one dispatcher by
+// moving its next attempt to LeaseUntil, so that no other instance attempts
+// it meanwhile. It fails with a *DeliveryChangedError unless the delivery is
+// still pending and due at Due.
+type ClaimWebhookDeliveryRequest struct {
+	WebhookID  string
+	Sequence   int64
+	Due        time.Time
+	LeaseUntil time.Time
+}
+
+// ClaimWebhookDeliveryResponse holds the claimed delivery, whose next
+// attempt is the end of the lease.
+type ClaimWebhookDeliveryResponse struct {
+	Delivery *WebhookDelivery
+}
+
+// SetWebhookDeliveryRequest records the outcome of a delivery attempt. It
+// fails with a *DeliveryChangedError unless the delivery is still leased
+// until LeasedUntil, i.e. it was neither redelivered nor deleted since it
+// was claimed.
+type SetWebhookDeliveryRequest struct {
+	Delivery    *WebhookDelivery
+	LeasedUntil time.Time
+}
+
+type SetWebhookDeliveryResponse struct{}
+
+// RedeliverWebhookDeliveryRequest makes a delivery pending again, due
+// immediately, whatever its status. It fails with a *DeliveryNotFoundError
+// if the delivery does not exist.
+type RedeliverWebhookDeliveryRequest struct {
+	WebhookID string
+	Sequence  int64
+	GiveUpAt  time.Time
+}
+
+type RedeliverWebhookDeliveryResponse struct {
+	Delivery *WebhookDelivery
+}
+
+// PurgeWebhookDeliveriesRequest deletes deliveries created before Before
+// that are no longer pending.
+type PurgeWebhookDeliveriesRequest struct {
+	Before time.Time
+}
+
+type PurgeWebhookDeliveriesResponse struct {
+	Purged int
+}
+
 type FirestoreClient struct {
 	client *firestore.Client
+
+	// macIndexBuilt is set once MigrateMACs is known to have indexed every
+	// machine registered before the machine_macs index existed.
+	macIndexBuilt atomic.Bool
 }
 
 func NewFirestoreClient(ctx context.Context, projectID string) (*FirestoreClient, error) {
@@ -40,16 +456,51 @@ func NewFirestoreClient(ctx context.Context, projectID string) (*FirestoreClient
 func (c *FirestoreClient) CreateMachine(ctx context.Context, req *CreateMachineRequest) (*CreateMachineResponse, error) {
 	docRef := c.client.Collection("machines").Doc(req.MachineID)
 
-	data := map[string]interface{}{
-		"id":             req.MachineID,
-		"cpus":           req.Machine.CPUs,
-		"memory_modules": req.Machine.MemoryModules,
-		"accelerators":   req.Machine.Accelerators,
-		"nics":           req.Machine.NICs,
-		"drives":         req.Machine.Drives,
+	unindexed, err := c.unindexedMACs(ctx)
+	if err != nil {
+		return nil, err
 	}
+	err = c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var idempotencyRef *firestore.DocumentRef
+		if req.IdempotencyRecord != nil {
+			idempotencyRef = c.idempotencyRef(req.IdempotencyRecord.Key)
 
-	_, err := docRef.Set(ctx, data)
+			record, err := getIdempotencyRecord(func() (*firestore.DocumentSnapshot, error) {
+				return tx.Get(idempotencyRef)
+			})
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				return &IdempotencyKeyExistsError{Record: record}
+			}
+		}
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.claimMACs(tx, unindexed, req.MachineID, macsOf(req.Machine.NICs), nil); err != nil {
+			return err
+		}
+		machine := &Machine{
+			ID:             req.MachineID,
+			MachineRequest: *req.Machine,
+			Revision:       1,
+			LifecycleState: LifecycleStateNew,
+		}
+		if err := tx.Set(docRef, machineData(machine)); err != nil {
+			return err
+		}
+		ledger.record(ChangeCreated, req.MachineID, nil, machine, req.Actor)
+		if err := c.writeLedger(tx, ledger); err != nil {
+			return err
+		}
+		if idempotencyRef == nil {
+			return nil
+		}
+		return tx.Set(idempotencyRef, req.IdempotencyRecord)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create machine document: %w", err)
 	}
@@ -57,41 +508,1542 @@ func (c *FirestoreClient) CreateMachine(ctx context.Context, req *CreateMachineR
 	return &CreateMachineResponse{}, nil
 }
 
-func (c *FirestoreClient) FindMachineByMAC(ctx context.Context, req *FindMachineByMACRequest) (*FindMachineByMACResponse, error) {
-	normalizedMAC := strings.ToLower(req.MAC)
+func (c *FirestoreClient) CreateMachines(ctx context.Context, req *CreateMachinesRequest) (*CreateMachinesResponse, error) {
+	unindexed, err := c.unindexedMACs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// Firestore transactions must do all reads before any write, so the
+		// index entries of the whole batch are read up front.
+		var refs []*firestore.DocumentRef
+		for _, m := range req.Machines {
+			for _, mac := range macsOf(m.Machine.NICs) {
+				refs = append(refs, c.macRef(mac))
+			}
+		}
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return fmt.Errorf("failed to read MAC index: %w", err)
+		}
 
-	iter := c.client.Collection("machines").
-		Where("nics", "array-contains", map[string]interface{}{"mac": normalizedMAC}).
-		Limit(1).
-		Documents(ctx)
-	defer iter.Stop()
+		conflicts := make(map[int]*MACConflictError)
+		var next int
+		for i, m := range req.Machines {
+			for _, mac := range macsOf(m.Machine.NICs) {
+				doc := docs[next]
+				next++
+				if conflicts[i] != nil {
+					continue
+				}
+				if owner, ok := unindexed[mac]; ok && owner != m.MachineID {
+					conflicts[i] = &MACConflictError{MAC: mac, MachineID: owner}
+					continue
+				}
+				if !doc.Exists() {
+					continue
+				}
 
-	doc, err := iter.Next()
-	if err == iterator.Done {
-		return &FindMachineByMACResponse{Found: false}, nil
+				var entry macIndexEntry
+				if err := doc.DataTo(&entry); err != nil {
+					return fmt.Errorf("failed to decode MAC index entry: %w", err)
+				}
+				if entry.MachineID != m.MachineID {
+					conflicts[i] = &MACConflictError{MAC: mac, MachineID: entry.MachineID}
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return &BatchConflictError{Conflicts: conflicts}
+		}
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range req.Machines {
+			for _, mac := range macsOf(m.Machine.NICs) {
+				if err := tx.Set(c.macRef(mac), macIndexEntry{MachineID: m.MachineID}); err != nil {
+					return err
+				}
+			}
+			machine := &Machine{
+				ID:             m.MachineID,
+				MachineRequest: *m.Machine,
+				Revision:       1,
+				LifecycleState: LifecycleStateNew,
+			}
+			docRef := c.client.Collection("machines").Doc(m.MachineID)
+			if err := tx.Set(docRef, machineData(machine)); err != nil {
+				return err
+			}
+			ledger.record(ChangeCreated, m.MachineID, nil, machine, m.Actor)
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine documents: %w", err)
+	}
+
+	return &CreateMachinesResponse{}, nil
+}
+
+func (c *FirestoreClient) UpdateMachine(ctx context.Context, req *UpdateMachineRequest) (*UpdateMachineResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+
+	var revision int64
+	unindexed, err := c.unindexedMACs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing Machine
+		if err := doc.DataTo(&existing); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		if err := checkNotDeleted(req.MachineID, &existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.claimMACs(tx, unindexed, req.MachineID, macsOf(req.Machine.NICs), macsOf(existing.NICs)); err != nil {
+			return err
+		}
+		revision = existing.Revision + 1
+		machine := &Machine{
+			ID:               req.MachineID,
+			MachineRequest:   *req.Machine,
+			Revision:         revision,
+			LifecycleState:   existing.LifecycleState,
+			LifecycleHistory: existing.LifecycleHistory,
+			Labels:           existing.Labels,
+		}
+		if err := tx.Set(docRef, machineData(machine)); err != nil {
+			return err
+		}
+		ledger.record(ChangeUpdated, req.MachineID, &existing, machine, req.Actor)
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update machine document: %w", err)
 	}
+
+	return &UpdateMachineResponse{Revision: revision}, nil
+}
+
+// macIndexEntry is stored at machine_macs/{mac} for every MAC address owned by
+// a live machine. Keeping the index in the same transaction as the machine
+// document makes MAC uniqueness race free.
+type macIndexEntry struct {
+	MachineID string `firestore:"machine_id"`
+}
+
+func (c *FirestoreClient) revisionsRef(machineID string) *firestore.CollectionRef {
+	return c.client.Collection("machines").Doc(machineID).Collection("revisions")
+}
+
+func (c *FirestoreClient) changesRef() *firestore.CollectionRef {
+	return c.client.Collection("machine_changes")
+}
+
+func (c *FirestoreClient) changeCounterRef() *firestore.DocumentRef {
+	return c.client.Collection("counters").Doc("machine_changes")
+}
+
+// readLedger reads the inventory summary and change counter that every
+// machine write updates. Like every read in a transaction it must come
+// before the first write. The counter hands out gap-free change sequences,
+// so machine writes are serialized on it whether or not the summary is
+// spread over more documents.
+func (c *FirestoreClient) readLedger(tx *firestore.Transaction) (*ledger, error) {
+	summary, err := c.getSummary(tx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query machines by MAC: %w", err)
+		return nil, err
 	}
 
-	var data struct {
-		ID   string `firestore:"id"`
-		NICs []NIC  `firestore:"nics"`
+	var counter changeCounter
+	doc, err := tx.Get(c.changeCounterRef())
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		return nil, fmt.Errorf("failed to get change counter: %w", err)
+	default:
+		if err := doc.DataTo(&counter); err != nil {
+			return nil, fmt.Errorf("failed to decode change counter: %w", err)
+		}
 	}
-	if err := doc.DataTo(&data); err != nil {
-		return nil, fmt.Errorf("failed to decode machine document: %w", err)
+	return newLedger(summary, counter), nil
+}
+
+// writeLedger stores the revisions and changes l recorded, along with the
+// updated summary and counter.
+func (c *FirestoreClient) writeLedger(tx *firestore.Transaction, l *ledger) error {
+	for _, revision := range l.revisions {
+		docRef := c.revisionsRef(revision.Machine.ID).Doc(strconv.FormatInt(revision.Machine.Revision, 10))
+		if err := tx.Set(docRef, revision); err != nil {
+			return err
+		}
+	}
+	for _, change := range l.changes {
+		if err := tx.Set(c.changesRef().Doc(changeDocID(change.Sequence)), change); err != nil {
+			return err
+		}
+	}
+	if err := tx.Set(c.summaryRef(), l.summary); err != nil {
+		return err
+	}
+	return tx.Set(c.changeCounterRef(), l.counter())
+}
+
+func (c *FirestoreClient) summaryRef() *firestore.DocumentRef {
+	return c.client.Collection("inventory").Doc("summary")
+}
+
+// getSummary reads the inventory summary, or an empty one if no machine has
+// been written yet. Like every read in a transaction it must come before the
+// first write.
+func (c *FirestoreClient) getSummary(tx *firestore.Transaction) (*InventorySummary, error) {
+	var summary InventorySummary
+	doc, err := tx.Get(c.summaryRef())
+	if status.Code(err) == codes.NotFound {
+		return &summary, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory summary: %w", err)
+	}
+	if err := doc.DataTo(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory summary: %w", err)
+	}
+	return &summary, nil
+}
+
+func (c *FirestoreClient) macRef(mac MAC) *firestore.DocumentRef {
+	return c.client.Collection("machine_macs").Doc(mac.String())
+}
+
+// claimMACs points the index entries for macs at machineID and releases any
+// entries in previous that are no longer claimed. A MAC already owned by a
+// different machine, in the index or in unindexed, fails with a
+// *MACConflictError.
+func (c *FirestoreClient) claimMACs(tx *firestore.Transaction, unindexed map[MAC]string, machineID string, macs, previous []MAC) error {
+	for _, mac := range macs {
+		if owner, ok := unindexed[mac]; ok && owner != machineID {
+			return &MACConflictError{MAC: mac, MachineID: owner}
+		}
+	}
+	return c.indexMACs(tx, machineID, macs, previous)
+}
+
+// indexMACs is claimMACs for MigrateMACs, which is building the index and
+// so only checks it.
+func (c *FirestoreClient) indexMACs(tx *firestore.Transaction, machineID string, macs, previous []MAC) error {
+	refs := make([]*firestore.DocumentRef, len(macs))
+	for i, mac := range macs {
+		refs[i] = c.macRef(mac)
+	}
+
+	docs, err := tx.GetAll(refs)
+	if err != nil {
+		return fmt.Errorf("failed to read MAC index: %w", err)
+	}
+	for i, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
 
-	for _, nic := range data.NICs {
-		if strings.EqualFold(nic.MAC, req.MAC) {
-			return &FindMachineByMACResponse{
-				MachineID: data.ID,
-				Found:     true,
-			}, nil
+		var entry macIndexEntry
+		if err := doc.DataTo(&entry); err != nil {
+			return fmt.Errorf("failed to decode MAC index entry: %w", err)
 		}
+		if entry.MachineID != machineID {
+			return &MACConflictError{MAC: macs[i], MachineID: entry.MachineID}
+		}
+	}
+
+	claimed := make(map[MAC]bool, len(macs))
+	for i, mac := range macs {
+		claimed[mac] = true
+		if err := tx.Set(refs[i], macIndexEntry{MachineID: machineID}); err != nil {
+			return err
+		}
+	}
+	return c.releaseMACs(tx, previous, claimed)
+}
+
+// unindexedMACs returns the owner of every MAC of a live machine until
+// MigrateMACs has indexed every machine, and nil after. Writes claiming MACs
+// call it before their transaction, so the scan of every machine it takes
+// is not repeated when the transaction retries. Being outside the
+// transaction, the scan can be stale: a MAC released since is still
+// reported as owned, failing the write with a conflict that a retry clears.
+// A MAC claimed since is never missed, as the claim is made through the
+// index the transaction reads.
+func (c *FirestoreClient) unindexedMACs(ctx context.Context) (map[MAC]string, error) {
+	built, err := c.isMACIndexBuilt(ctx)
+	if err != nil || built {
+		return nil, err
+	}
+	return c.findUnindexedMACs(ctx)
+}
+
+func (c *FirestoreClient) macIndexMigrationRef() *firestore.DocumentRef {
+	return c.client.Collection("migrations").Doc("machine_macs")
+}
+
+// isMACIndexBuilt reports whether MigrateMACs has recorded that every machine
+// is in the machine_macs index. Once it has, that is remembered.
+func (c *FirestoreClient) isMACIndexBuilt(ctx context.Context) (bool, error) {
+	if c.macIndexBuilt.Load() {
+		return true, nil
 	}
+	_, err := c.macIndexMigrationRef().Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get MAC index migration: %w", err)
+	}
+	c.macIndexBuilt.Store(true)
+	return true, nil
+}
 
-	return &FindMachineByMACResponse{Found: false}, nil
+// findUnindexedMACs scans every live machine, returning the machine with
+// each MAC found. MACs stored before they were canonicalized are returned in
+// canonical form.
+func (c *FirestoreClient) findUnindexedMACs(ctx context.Context) (map[MAC]string, error) {
+	iter := c.client.Collection("machines").Documents(ctx)
+	defer iter.Stop()
+
+	owners := make(map[MAC]string)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return owners, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan machines for MACs: %w", err)
+		}
+
+		var data struct {
+			NICs      []NIC      `firestore:"nics"`
+			DeletedAt *time.Time `firestore:"deleted_at"`
+		}
+		if err := doc.DataTo(&data); err != nil {
+			return nil, fmt.Errorf("failed to decode machine document %s: %w", doc.Ref.ID, err)
+		}
+		if data.DeletedAt != nil {
+			continue
+		}
+		for _, nic := range data.NICs {
+			mac, err := ParseMAC(nic.MAC.String())
+			if err == nil {
+				owners[mac] = doc.Ref.ID
+			}
+		}
+	}
+}
+
+func (c *FirestoreClient) releaseMACs(tx *firestore.Transaction, macs []MAC, keep map[MAC]bool) error {
+	for _, mac := range macs {
+		if keep[mac] {
+			continue
+		}
+		if err := tx.Delete(c.macRef(mac)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func macsOf(nics []NIC) []MAC {
+	macs := make([]MAC, len(nics))
+	for i, nic := range nics {
+		macs[i] = nic.MAC
+	}
+	return macs
+}
+
+// checkNotDeleted rejects writes to a tombstoned machine. Writes other than
+// RestoreMachine must not bring a machine back or release its MACs twice.
+func checkNotDeleted(machineID string, machine *Machine) error {
+	if machine.DeletedAt != nil {
+		return &MachineNotFoundError{MachineID: machineID}
+	}
+	return nil
+}
+
+// checkRevision enforces an optional ExpectedRevision against the stored one.
+func checkRevision(machineID string, expected *int64, actual int64) error {
+	if expected == nil || *expected == actual {
+		return nil
+	}
+	return &RevisionMismatchError{MachineID: machineID, Expected: *expected, Actual: actual}
+}
+
+// machineData is the document written for a live machine. Tombstone fields
+// are left out, as they are only ever written by DeleteMachine.
+func machineData(machine *Machine) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                machine.ID,
+		"revision":          machine.Revision,
+		"lifecycle_state":   machine.LifecycleState,
+		"lifecycle_history": machine.LifecycleHistory,
+		"labels":            machine.Labels,
+		"cpus":              machine.CPUs,
+		"memory_modules":    machine.MemoryModules,
+		"accelerators":      machine.Accelerators,
+		"nics":              machine.NICs,
+		"drives":            machine.Drives,
+	}
+}
+
+// idempotencyRef locates the record for key. Keys are chosen by clients and
+// may contain characters not allowed in document IDs, so they are hashed.
+// The collection is expected to have a Firestore TTL policy on expires_at.
+func (c *FirestoreClient) idempotencyRef(key string) *firestore.DocumentRef {
+	sum := sha256.Sum256([]byte(key))
+	return c.client.Collection("idempotency_keys").Doc(hex.EncodeToString(sum[:]))
+}
+
+func (c *FirestoreClient) GetIdempotencyRecord(ctx context.Context, req *GetIdempotencyRecordRequest) (*GetIdempotencyRecordResponse, error) {
+	record, err := getIdempotencyRecord(func() (*firestore.DocumentSnapshot, error) {
+		return c.idempotencyRef(req.Key).Get(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return &GetIdempotencyRecordResponse{Found: false}, nil
+	}
+
+	return &GetIdempotencyRecordResponse{
+		Record: record,
+		Found:  true,
+	}, nil
+}
+
+// getIdempotencyRecord returns nil for missing records as well as expired
+// ones, since TTL deletion can lag behind expires_at.
+func getIdempotencyRecord(get func() (*firestore.DocumentSnapshot, error)) (*IdempotencyRecord, error) {
+	doc, err := get()
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record IdempotencyRecord
+	if err := doc.DataTo(&record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (c *FirestoreClient) GetMachine(ctx context.Context, req *GetMachineRequest) (*GetMachineResponse, error) {
+	doc, err := c.client.Collection("machines").Doc(req.MachineID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetMachineResponse{Found: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine document: %w", err)
+	}
+
+	var machine Machine
+	if err := doc.DataTo(&machine); err != nil {
+		return nil, fmt.Errorf("failed to decode machine document: %w", err)
+	}
+	if machine.DeletedAt != nil && !req.IncludeDeleted {
+		return &GetMachineResponse{Found: false}, nil
+	}
+
+	return &GetMachineResponse{
+		Machine: &machine,
+		Found:   true,
+	}, nil
+}
+
+func (c *FirestoreClient) ListMachines(ctx context.Context, req *ListMachinesRequest) (*ListMachinesResponse, error) {
+	if req.MAC != "" {
+		return c.listMachinesByMAC(ctx, req)
+	}
+
+	// Machine IDs are UUIDv7s, so ordering by document ID yields registration
+	// order and the last ID of a page doubles as the cursor for the next one.
+	query := c.client.Collection("machines").OrderBy(firestore.DocumentID, firestore.Asc)
+	if req.PageToken != "" {
+		query = query.StartAfter(req.PageToken)
+	}
+
+	// Tombstoned machines and machines not matching the label selector are
+	// skipped client side, so the query cannot be limited to a page and is
+	// instead read until a page (plus one machine to detect a following
+	// page) has been collected, or MaxScanned machines have been read.
+	// Pushing selectors down to Firestore would need a composite index per
+	// label key.
+	iter := query.Limit(req.maxScanned()).Documents(ctx)
+	defer iter.Stop()
+
+	var machines []*Machine
+	var scanned int
+	var lastID string
+	for len(machines) <= req.PageSize {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machines: %w", err)
+		}
+		scanned++
+		lastID = doc.Ref.ID
+
+		var machine Machine
+		if err := doc.DataTo(&machine); err != nil {
+			return nil, fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		if machine.DeletedAt != nil || !req.LabelSelector.Matches(machine.Labels) {
+			continue
+		}
+		machines = append(machines, &machine)
+	}
+
+	return listPage(req, machines, scanned, lastID), nil
+}
+
+func (c *FirestoreClient) listMachinesByMAC(ctx context.Context, req *ListMachinesRequest) (*ListMachinesResponse, error) {
+	findResp, err := c.FindMachineByMAC(ctx, &FindMachineByMACRequest{MAC: req.MAC})
+	if err != nil {
+		return nil, err
+	}
+	if !findResp.Found || findResp.MachineID <= req.PageToken {
+		return &ListMachinesResponse{}, nil
+	}
+
+	getResp, err := c.GetMachine(ctx, &GetMachineRequest{MachineID: findResp.MachineID})
+	if err != nil {
+		return nil, err
+	}
+	if !getResp.Found || !req.LabelSelector.Matches(getResp.Machine.Labels) {
+		return &ListMachinesResponse{}, nil
+	}
+	return &ListMachinesResponse{Machines: []*Machine{getResp.Machine}}, nil
+}
+
+func (c *FirestoreClient) FindMachineByMAC(ctx context.Context, req *FindMachineByMACRequest) (*FindMachineByMACResponse, error) {
+	doc, err := c.macRef(req.MAC).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return c.findUnindexedMAC(ctx, req.MAC)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC index entry: %w", err)
+	}
+
+	var entry macIndexEntry
+	if err := doc.DataTo(&entry); err != nil {
+		return nil, fmt.Errorf("failed to decode MAC index entry: %w", err)
+	}
+
+	return &FindMachineByMACResponse{
+		MachineID: entry.MachineID,
+		Found:     true,
+	}, nil
+}
+
+// findUnindexedMAC finds a machine not in the machine_macs index yet by
+// scanning the machines, until MigrateMACs has indexed every machine.
+func (c *FirestoreClient) findUnindexedMAC(ctx context.Context, mac MAC) (*FindMachineByMACResponse, error) {
+	built, err := c.isMACIndexBuilt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if built {
+		return &FindMachineByMACResponse{Found: false}, nil
+	}
+
+	owners, err := c.findUnindexedMACs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	machineID, found := owners[mac]
+	return &FindMachineByMACResponse{MachineID: machineID, Found: found}, nil
+}
+
+func (c *FirestoreClient) DeleteMachine(ctx context.Context, req *DeleteMachineRequest) (*DeleteMachineResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+
+	var revision int64
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing Machine
+		if err := doc.DataTo(&existing); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+
+		if err := checkNotDeleted(req.MachineID, &existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		// Tombstoned machines release their MACs so the hardware can be
+		// registered again.
+		if err := c.releaseMACs(tx, macsOf(existing.NICs), nil); err != nil {
+			return err
+		}
+		revision = existing.Revision + 1
+		deleted := existing
+		deleted.DeletedAt = &ledger.at
+		deleted.DeletedBy = req.DeletedBy
+		deleted.Revision = revision
+		ledger.record(ChangeDeleted, req.MachineID, &existing, &deleted, req.DeletedBy)
+		if err := tx.Update(docRef, []firestore.Update{
+			{Path: "deleted_at", Value: ledger.at},
+			{Path: "deleted_by", Value: req.DeletedBy},
+			{Path: "revision", Value: revision},
+		}); err != nil {
+			return err
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to tombstone machine document: %w", err)
+	}
+
+	return &DeleteMachineResponse{Revision: revision}, nil
+}
+
+func (c *FirestoreClient) RestoreMachine(ctx context.Context, req *RestoreMachineRequest) (*RestoreMachineResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+
+	var revision int64
+	unindexed, err := c.unindexedMACs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing Machine
+		if err := doc.DataTo(&existing); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		if existing.DeletedAt == nil {
+			return &MachineNotDeletedError{MachineID: req.MachineID}
+		}
+
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.claimMACs(tx, unindexed, req.MachineID, macsOf(existing.NICs), nil); err != nil {
+			return err
+		}
+		revision = existing.Revision + 1
+		restored := existing
+		restored.DeletedAt = nil
+		restored.DeletedBy = ""
+		restored.Revision = revision
+		ledger.record(ChangeCreated, req.MachineID, &existing, &restored, req.Actor)
+		if err := tx.Update(docRef, []firestore.Update{
+			{Path: "deleted_at", Value: firestore.Delete},
+			{Path: "deleted_by", Value: firestore.Delete},
+			{Path: "revision", Value: revision},
+		}); err != nil {
+			return err
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore machine document: %w", err)
+	}
+
+	return &RestoreMachineResponse{Revision: revision}, nil
+}
+
+func (c *FirestoreClient) TransitionMachine(ctx context.Context, req *TransitionMachineRequest) (*TransitionMachineResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+
+	var machine Machine
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		machine = Machine{}
+		if err := doc.DataTo(&machine); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		if err := checkNotDeleted(req.MachineID, &machine); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, machine.Revision); err != nil {
+			return err
+		}
+
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		before := machine
+		if err := machine.transition(req, time.Now().UTC()); err != nil {
+			return err
+		}
+		machine.Revision++
+		ledger.record(ChangeStateChanged, req.MachineID, &before, &machine, req.Actor)
+		if err := tx.Update(docRef, []firestore.Update{
+			{Path: "lifecycle_state", Value: machine.LifecycleState},
+			{Path: "lifecycle_history", Value: machine.LifecycleHistory},
+			{Path: "revision", Value: machine.Revision},
+		}); err != nil {
+			return err
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to transition machine document: %w", err)
+	}
+
+	return &TransitionMachineResponse{Machine: &machine}, nil
+}
+
+func (c *FirestoreClient) SetMachineLabels(ctx context.Context, req *SetMachineLabelsRequest) (*SetMachineLabelsResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+
+	var revision int64
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing Machine
+		if err := doc.DataTo(&existing); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		if err := checkNotDeleted(req.MachineID, &existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		revision = existing.Revision + 1
+		labeled := existing
+		labeled.Labels = req.Labels
+		labeled.Revision = revision
+		ledger.record(ChangeUpdated, req.MachineID, &existing, &labeled, req.Actor)
+		if err := tx.Update(docRef, []firestore.Update{
+			{Path: "labels", Value: req.Labels},
+			{Path: "revision", Value: revision},
+		}); err != nil {
+			return err
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set machine labels: %w", err)
+	}
+
+	return &SetMachineLabelsResponse{Revision: revision}, nil
+}
+
+func (c *FirestoreClient) PurgeDeletedMachines(ctx context.Context, req *PurgeDeletedMachinesRequest) (*PurgeDeletedMachinesResponse, error) {
+	iter := c.client.Collection("machines").
+		Where("deleted_at", "<", req.DeletedBefore).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var purged int
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query deleted machines: %w", err)
+		}
+
+		// Revisions go first, so that a purge interrupted part way leaves the
+		// machine behind to be retried rather than orphaned revisions.
+		if err := c.deleteCollection(ctx, c.revisionsRef(doc.Ref.ID)); err != nil {
+			return nil, err
+		}
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return nil, fmt.Errorf("failed to purge machine document: %w", err)
+		}
+		purged++
+	}
+
+	return &PurgeDeletedMachinesResponse{Purged: purged}, nil
+}
+
+// deleteCollection deletes every document in collection, such as the
+// revisions of a purged machine.
+func (c *FirestoreClient) deleteCollection(ctx context.Context, collection *firestore.CollectionRef) error {
+	refs := collection.DocumentRefs(ctx)
+	for {
+		ref, err := refs.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", collection.Path, err)
+		}
+		if _, err := ref.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", ref.Path, err)
+		}
+	}
+}
+
+func (c *FirestoreClient) ListMachineRevisions(ctx context.Context, req *ListMachineRevisionsRequest) (*ListMachineRevisionsResponse, error) {
+	query := c.revisionsRef(req.MachineID).OrderBy("machine.revision", firestore.Desc)
+	if req.PageToken != 0 {
+		query = query.StartAfter(req.PageToken)
+	}
+
+	// One revision past the page is read to detect a following page.
+	docs, err := query.Limit(req.PageSize + 1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machine revisions: %w", err)
+	}
+
+	revisions := make([]*MachineRevision, 0, len(docs))
+	for _, doc := range docs {
+		var revision MachineRevision
+		if err := doc.DataTo(&revision); err != nil {
+			return nil, fmt.Errorf("failed to decode machine revision: %w", err)
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	resp := &ListMachineRevisionsResponse{Revisions: revisions}
+	if len(revisions) > req.PageSize {
+		resp.Revisions = revisions[:req.PageSize]
+		resp.NextPageToken = resp.Revisions[req.PageSize-1].Machine.Revision
+	}
+	return resp, nil
+}
+
+func (c *FirestoreClient) GetMachineRevision(ctx context.Context, req *GetMachineRevisionRequest) (*GetMachineRevisionResponse, error) {
+	doc, err := c.revisionsRef(req.MachineID).Doc(strconv.FormatInt(req.Revision, 10)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetMachineRevisionResponse{Found: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine revision: %w", err)
+	}
+
+	var revision MachineRevision
+	if err := doc.DataTo(&revision); err != nil {
+		return nil, fmt.Errorf("failed to decode machine revision: %w", err)
+	}
+
+	return &GetMachineRevisionResponse{
+		Revision: &revision,
+		Found:    true,
+	}, nil
+}
+
+func (c *FirestoreClient) RollbackMachine(ctx context.Context, req *RollbackMachineRequest) (*RollbackMachineResponse, error) {
+	docRef := c.client.Collection("machines").Doc(req.MachineID)
+	revisionRef := c.revisionsRef(req.MachineID).Doc(strconv.FormatInt(req.Revision, 10))
+
+	var machine *Machine
+	unindexed, err := c.unindexedMACs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &MachineNotFoundError{MachineID: req.MachineID}
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing Machine
+		if err := doc.DataTo(&existing); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+		// Writing the rolled back document would clear the tombstone and
+		// bypass RestoreMachine.
+		if err := checkNotDeleted(req.MachineID, &existing); err != nil {
+			return err
+		}
+		if err := checkRevision(req.MachineID, req.ExpectedRevision, existing.Revision); err != nil {
+			return err
+		}
+
+		revisionDoc, err := tx.Get(revisionRef)
+		if status.Code(err) == codes.NotFound {
+			return &RevisionNotFoundError{MachineID: req.MachineID, Revision: req.Revision}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get machine revision: %w", err)
+		}
+		var revision MachineRevision
+		if err := revisionDoc.DataTo(&revision); err != nil {
+			return fmt.Errorf("failed to decode machine revision: %w", err)
+		}
+
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		machine = existing.rollback(&revision)
+		machine.Revision = existing.Revision + 1
+		if err := c.claimMACs(tx, unindexed, req.MachineID, macsOf(machine.NICs), macsOf(existing.NICs)); err != nil {
+			return err
+		}
+		if err := tx.Set(docRef, machineData(machine)); err != nil {
+			return err
+		}
+		ledger.record(ChangeUpdated, req.MachineID, &existing, machine, req.Actor)
+		return c.writeLedger(tx, ledger)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back machine document: %w", err)
+	}
+
+	return &RollbackMachineResponse{Machine: machine}, nil
+}
+
+// ListMachineChanges reads the counter before the log, so a change missing
+// from a log that Latest says should hold it can only have been purged.
+func (c *FirestoreClient) ListMachineChanges(ctx context.Context, req *ListMachineChangesRequest) (*ListMachineChangesResponse, error) {
+	var counter changeCounter
+	doc, err := c.changeCounterRef().Get(ctx)
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		return nil, fmt.Errorf("failed to get change counter: %w", err)
+	default:
+		if err := doc.DataTo(&counter); err != nil {
+			return nil, fmt.Errorf("failed to decode change counter: %w", err)
+		}
+	}
+
+	resp := &ListMachineChangesResponse{Latest: counter.Sequence}
+	if req.PageSize == 0 || req.After >= counter.Sequence {
+		return resp, nil
+	}
+
+	docs, err := c.changesRef().
+		OrderBy(firestore.DocumentID, firestore.Asc).
+		StartAfter(changeDocID(req.After)).
+		Limit(req.PageSize).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machine changes: %w", err)
+	}
+	for _, doc := range docs {
+		var change MachineChange
+		if err := doc.DataTo(&change); err != nil {
+			return nil, fmt.Errorf("failed to decode machine change: %w", err)
+		}
+		resp.Changes = append(resp.Changes, &change)
+	}
+	return resp, nil
+}
+
+// PurgeMachineChanges deletes changes recorded before req.Before. Watchers
+// that have fallen further behind than that have to start over.
+func (c *FirestoreClient) PurgeMachineChanges(ctx context.Context, req *PurgeMachineChangesRequest) (*PurgeMachineChangesResponse, error) {
+	iter := c.changesRef().
+		Where("at", "<", req.Before).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var purged int
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query machine changes: %w", err)
+		}
+		if req.Through != nil {
+			var change MachineChange
+			if err := doc.DataTo(&change); err != nil {
+				return nil, fmt.Errorf("failed to decode machine change: %w", err)
+			}
+			if change.Sequence > *req.Through {
+				continue
+			}
+		}
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return nil, fmt.Errorf("failed to purge machine change: %w", err)
+		}
+		purged++
+	}
+
+	return &PurgeMachineChangesResponse{Purged: purged}, nil
+}
+
+func (c *FirestoreClient) changeCursorRef(consumer string) *firestore.DocumentRef {
+	return c.client.Collection("change_cursors").Doc(consumer)
+}
+
+func (c *FirestoreClient) GetChangeCursor(ctx context.Context, req *GetChangeCursorRequest) (*GetChangeCursorResponse, error) {
+	var cursor changeCounter
+	doc, err := c.changeCursorRef(req.Consumer).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetChangeCursorResponse{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change cursor: %w", err)
+	}
+	if err := doc.DataTo(&cursor); err != nil {
+		return nil, fmt.Errorf("failed to decode change cursor: %w", err)
+	}
+
+	return &GetChangeCursorResponse{Sequence: cursor.Sequence}, nil
+}
+
+func (c *FirestoreClient) SetChangeCursor(ctx context.Context, req *SetChangeCursorRequest) (*SetChangeCursorResponse, error) {
+	if _, err := c.changeCursorRef(req.Consumer).Set(ctx, changeCounter{Sequence: req.Sequence}); err != nil {
+		return nil, fmt.Errorf("failed to set change cursor: %w", err)
+	}
+
+	return &SetChangeCursorResponse{}, nil
+}
+
+func (c *FirestoreClient) webhookRef(webhookID string) *firestore.DocumentRef {
+	return c.client.Collection("webhooks").Doc(webhookID)
+}
+
+// deliveriesRef is the delivery log of a webhook. Deliveries are kept under
+// their webhook so that the log of each can be listed without an index,
+// while the collection group serves the dispatcher.
+func (c *FirestoreClient) deliveriesRef(webhookID string) *firestore.CollectionRef {
+	return c.webhookRef(webhookID).Collection("deliveries")
+}
+
+func (c *FirestoreClient) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	if _, err := c.webhookRef(req.Webhook.ID).Create(ctx, req.Webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &CreateWebhookResponse{}, nil
+}
+
+func (c *FirestoreClient) GetWebhook(ctx context.Context, req *GetWebhookRequest) (*GetWebhookResponse, error) {
+	doc, err := c.webhookRef(req.WebhookID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetWebhookResponse{Found: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	var webhook Webhook
+	if err := doc.DataTo(&webhook); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook: %w", err)
+	}
+
+	return &GetWebhookResponse{
+		Webhook: &webhook,
+		Found:   true,
+	}, nil
+}
+
+func (c *FirestoreClient) ListWebhooks(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	docs, err := c.client.Collection("webhooks").
+		OrderBy(firestore.DocumentID, firestore.Asc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	webhooks := make([]*Webhook, 0, len(docs))
+	for _, doc := range docs {
+		var webhook Webhook
+		if err := doc.DataTo(&webhook); err != nil {
+			return nil, fmt.Errorf("failed to decode webhook: %w", err)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return &ListWebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (c *FirestoreClient) UpdateWebhook(ctx context.Context, req *UpdateWebhookRequest) (*UpdateWebhookResponse, error) {
+	docRef := c.webhookRef(req.Webhook.ID)
+
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &WebhookNotFoundError{WebhookID: req.Webhook.ID}
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Set(docRef, req.Webhook)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return &UpdateWebhookResponse{}, nil
+}
+
+func (c *FirestoreClient) DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	docRef := c.webhookRef(req.WebhookID)
+
+	_, err := docRef.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, &WebhookNotFoundError{WebhookID: req.WebhookID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	// The webhook goes first, so that the dispatcher stops enqueueing
+	// deliveries for it before they are deleted.
+	if _, err := docRef.Delete(ctx); err != nil {
+		return nil, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if err := c.deleteCollection(ctx, c.deliveriesRef(req.WebhookID)); err != nil {
+		return nil, err
+	}
+
+	return &DeleteWebhookResponse{}, nil
+}
+
+func (c *FirestoreClient) EnqueueWebhookDeliveries(ctx context.Context, req *EnqueueWebhookDeliveriesRequest) (*EnqueueWebhookDeliveriesResponse, error) {
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var cursor changeCounter
+		doc, err := tx.Get(c.changeCursorRef(req.Consumer))
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return fmt.Errorf("failed to get change cursor: %w", err)
+		default:
+			if err := doc.DataTo(&cursor); err != nil {
+				return fmt.Errorf("failed to decode change cursor: %w", err)
+			}
+		}
+		if cursor.Sequence != req.From {
+			return &CursorMovedError{Consumer: req.Consumer, Expected: req.From, Actual: cursor.Sequence}
+		}
+
+		refs := make([]*firestore.DocumentRef, len(req.Deliveries))
+		for i, delivery := range req.Deliveries {
+			refs[i] = c.deliveriesRef(delivery.WebhookID).Doc(changeDocID(delivery.Sequence))
+		}
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook deliveries: %w", err)
+		}
+		for i, doc := range docs {
+			if doc.Exists() {
+				continue
+			}
+			if err := tx.Create(refs[i], req.Deliveries[i]); err != nil {
+				return err
+			}
+		}
+		return tx.Set(c.changeCursorRef(req.Consumer), changeCounter{Sequence: req.Through})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return &EnqueueWebhookDeliveriesResponse{}, nil
+}
+
+func (c *FirestoreClient) ListWebhookDeliveries(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	query := c.deliveriesRef(req.WebhookID).OrderBy(firestore.DocumentID, firestore.Desc)
+	if req.PageToken != 0 {
+		query = query.StartAfter(changeDocID(req.PageToken))
+	}
+
+	// One delivery past the page is read to detect a following page.
+	docs, err := query.Limit(req.PageSize + 1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries, err := decodeDeliveries(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListWebhookDeliveriesResponse{Deliveries: deliveries}
+	if len(deliveries) > req.PageSize {
+		resp.Deliveries = deliveries[:req.PageSize]
+		resp.NextPageToken = resp.Deliveries[req.PageSize-1].Sequence
+	}
+	return resp, nil
+}
+
+func (c *FirestoreClient) GetWebhookDelivery(ctx context.Context, req *GetWebhookDeliveryRequest) (*GetWebhookDeliveryResponse, error) {
+	doc, err := c.deliveriesRef(req.WebhookID).Doc(changeDocID(req.Sequence)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetWebhookDeliveryResponse{Found: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	var delivery WebhookDelivery
+	if err := doc.DataTo(&delivery); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook delivery: %w", err)
+	}
+
+	return &GetWebhookDeliveryResponse{
+		Delivery: &delivery,
+		Found:    true,
+	}, nil
+}
+
+// ListDueWebhookDeliveries queries the deliveries collection group, which
+// needs a collection group scoped index on next_attempt_at.
+func (c *FirestoreClient) ListDueWebhookDeliveries(ctx context.Context, req *ListDueWebhookDeliveriesRequest) (*ListDueWebhookDeliveriesResponse, error) {
+	docs, err := c.client.CollectionGroup("deliveries").
+		Where("next_attempt_at", "<=", req.Now).
+		OrderBy("next_attempt_at", firestore.Asc).
+		Limit(req.Limit).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	deliveries, err := decodeDeliveries(docs)
+	if err != nil {
+		return nil, err
+	}
+	return &ListDueWebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
+
+func (c *FirestoreClient) ClaimWebhookDelivery(ctx context.Context, req *ClaimWebhookDeliveryRequest) (*ClaimWebhookDeliveryResponse, error) {
+	docRef := c.deliveriesRef(req.WebhookID).Doc(changeDocID(req.Sequence))
+	// Firestore keeps timestamps to the microsecond, so the lease is
+	// truncated for the stored one to equal it when the outcome is set.
+	leaseUntil := req.LeaseUntil.Truncate(time.Microsecond)
+
+	var delivery WebhookDelivery
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := getLeasedDelivery(tx, docRef, req.WebhookID, req.Sequence, req.Due, &delivery); err != nil {
+			return err
+		}
+		delivery.NextAttemptAt = &leaseUntil
+		return tx.Update(docRef, []firestore.Update{{Path: "next_attempt_at", Value: leaseUntil}})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	return &ClaimWebhookDeliveryResponse{Delivery: &delivery}, nil
+}
+
+func (c *FirestoreClient) SetWebhookDelivery(ctx context.Context, req *SetWebhookDeliveryRequest) (*SetWebhookDeliveryResponse, error) {
+	delivery := req.Delivery
+	docRef := c.deliveriesRef(delivery.WebhookID).Doc(changeDocID(delivery.Sequence))
+
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var stored WebhookDelivery
+		if err := getLeasedDelivery(tx, docRef, delivery.WebhookID, delivery.Sequence, req.LeasedUntil, &stored); err != nil {
+			return err
+		}
+		return tx.Set(docRef, delivery)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set webhook delivery: %w", err)
+	}
+
+	return &SetWebhookDeliveryResponse{}, nil
+}
+
+// getLeasedDelivery reads the delivery at docRef into delivery, failing with
+// a *DeliveryChangedError unless it exists and is pending at nextAttemptAt.
+func getLeasedDelivery(tx *firestore.Transaction, docRef *firestore.DocumentRef, webhookID string, sequence int64, nextAttemptAt time.Time, delivery *WebhookDelivery) error {
+	doc, err := tx.Get(docRef)
+	if status.Code(err) == codes.NotFound {
+		return &DeliveryChangedError{WebhookID: webhookID, Sequence: sequence}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if err := doc.DataTo(delivery); err != nil {
+		return fmt.Errorf("failed to decode webhook delivery: %w", err)
+	}
+	if !delivery.pendingAt(nextAttemptAt) {
+		return &DeliveryChangedError{WebhookID: webhookID, Sequence: sequence}
+	}
+	return nil
+}
+
+func (c *FirestoreClient) RedeliverWebhookDelivery(ctx context.Context, req *RedeliverWebhookDeliveryRequest) (*RedeliverWebhookDeliveryResponse, error) {
+	docRef := c.deliveriesRef(req.WebhookID).Doc(changeDocID(req.Sequence))
+
+	var delivery WebhookDelivery
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if status.Code(err) == codes.NotFound {
+			return &DeliveryNotFoundError{WebhookID: req.WebhookID, Sequence: req.Sequence}
+		}
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&delivery); err != nil {
+			return fmt.Errorf("failed to decode webhook delivery: %w", err)
+		}
+
+		delivery.redeliver(time.Now().UTC(), req.GiveUpAt)
+		return tx.Set(docRef, &delivery)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	return &RedeliverWebhookDeliveryResponse{Delivery: &delivery}, nil
+}
+
+// PurgeWebhookDeliveries queries the deliveries collection group, which needs
+// a collection group scoped index on created_at.
+func (c *FirestoreClient) PurgeWebhookDeliveries(ctx context.Context, req *PurgeWebhookDeliveriesRequest) (*PurgeWebhookDeliveriesResponse, error) {
+	iter := c.client.CollectionGroup("deliveries").
+		Where("created_at", "<", req.Before).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var purged int
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+		}
+
+		var delivery WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to decode webhook delivery: %w", err)
+		}
+		if delivery.Status == DeliveryPending {
+			continue
+		}
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			return nil, fmt.Errorf("failed to purge webhook delivery: %w", err)
+		}
+		purged++
+	}
+
+	return &PurgeWebhookDeliveriesResponse{Purged: purged}, nil
+}
+
+func decodeDeliveries(docs []*firestore.DocumentSnapshot) ([]*WebhookDelivery, error) {
+	deliveries := make([]*WebhookDelivery, 0, len(docs))
+	for _, doc := range docs {
+		var delivery WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			return nil, fmt.Errorf("failed to decode webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+func (c *FirestoreClient) GetInventorySummary(ctx context.Context, req *GetInventorySummaryRequest) (*GetInventorySummaryResponse, error) {
+	var summary InventorySummary
+	doc, err := c.summaryRef().Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &GetInventorySummaryResponse{Summary: summary.decodeKeys()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory summary: %w", err)
+	}
+	if err := doc.DataTo(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory summary: %w", err)
+	}
+
+	return &GetInventorySummaryResponse{Summary: summary.decodeKeys()}, nil
+}
+
+// RebuildInventorySummary reads every machine in the same transaction as the
+// summary write, so concurrent machine writes either land before the rebuild
+// or retry after it.
+func (c *FirestoreClient) RebuildInventorySummary(ctx context.Context, req *RebuildInventorySummaryRequest) (*RebuildInventorySummaryResponse, error) {
+	var summary InventorySummary
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		summary = InventorySummary{}
+		docs, err := tx.Documents(c.client.Collection("machines")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to query machines: %w", err)
+		}
+		for _, doc := range docs {
+			var machine Machine
+			if err := doc.DataTo(&machine); err != nil {
+				return fmt.Errorf("failed to decode machine document %s: %w", doc.Ref.ID, err)
+			}
+			summary.add(&machine, 1)
+		}
+		summary.UpdatedAt = time.Now().UTC()
+		return tx.Set(c.summaryRef(), &summary)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild inventory summary: %w", err)
+	}
+
+	return &RebuildInventorySummaryResponse{Summary: summary.decodeKeys()}, nil
+}
+
+// MigrateMACs rewrites every machine document so its NIC MACs are in
+// canonical form and rebuilds the machine_macs index for live machines.
+// Machines whose MACs cannot be parsed or collide with another machine are
+// left untouched and reported as failures. Once a run has no failures, MACs
+// missing from the index are no longer looked up in the machines.
+func (c *FirestoreClient) MigrateMACs(ctx context.Context, req *MigrateMACsRequest) (*MigrateMACsResponse, error) {
+	iter := c.client.Collection("machines").Documents(ctx)
+	defer iter.Stop()
+
+	resp := &MigrateMACsResponse{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list machines: %w", err)
+		}
+		resp.Scanned++
+
+		rewritten, err := c.migrateMachineMACs(ctx, doc.Ref, req.DryRun)
+		if err != nil {
+			resp.Failures = append(resp.Failures, MigrateMACsFailure{MachineID: doc.Ref.ID, Err: err})
+			continue
+		}
+		if rewritten {
+			resp.Rewritten++
+		}
+	}
+
+	if req.DryRun || len(resp.Failures) > 0 {
+		return resp, nil
+	}
+	_, err := c.macIndexMigrationRef().Set(ctx, map[string]interface{}{"completed_at": time.Now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record MAC index migration: %w", err)
+	}
+	c.macIndexBuilt.Store(true)
+	return resp, nil
+}
+
+func (c *FirestoreClient) migrateMachineMACs(ctx context.Context, docRef *firestore.DocumentRef, dryRun bool) (bool, error) {
+	var rewritten bool
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		rewritten = false
+
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+
+		var machine Machine
+		if err := doc.DataTo(&machine); err != nil {
+			return fmt.Errorf("failed to decode machine document: %w", err)
+		}
+
+		nics := make([]NIC, len(machine.NICs))
+		for i, nic := range machine.NICs {
+			mac, err := ParseMAC(nic.MAC.String())
+			if err != nil {
+				return fmt.Errorf("nics[%d]: %w", i, err)
+			}
+			if mac != nic.MAC {
+				rewritten = true
+			}
+			nic.MAC = mac
+			nics[i] = nic
+		}
+		if dryRun {
+			return nil
+		}
+		ledger, err := c.readLedger(tx)
+		if err != nil {
+			return err
+		}
+
+		if machine.DeletedAt == nil {
+			if err := c.indexMACs(tx, machine.ID, macsOf(nics), nil); err != nil {
+				return err
+			}
+		}
+		if !rewritten {
+			return nil
+		}
+		migrated := machine
+		migrated.NICs = nics
+		migrated.Revision++
+		ledger.record(ChangeUpdated, docRef.ID, &machine, &migrated, "migrate-macs")
+		if err := tx.Update(docRef, []firestore.Update{
+			{Path: "nics", Value: nics},
+			{Path: "revision", Value: migrated.Revision},
+		}); err != nil {
+			return err
+		}
+		return c.writeLedger(tx, ledger)
+	})
+	return rewritten, err
 }
 
 func (c *FirestoreClient) Close() error {
@@ -2,96 +2,134 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
 	"testing"
 )
 
-type mockFirestoreClient struct {
-	machines      map[string]*MachineRequest
-	createErr     error
-	findByMACErr  error
-	existingMacID string
-}
+// emulatorProjectID is the project the emulator suite writes to. The
+// emulator accepts any project ID and keeps each one's data separate.
+const emulatorProjectID = "machine-service-test"
 
-func (m *mockFirestoreClient) CreateMachine(ctx context.Context, req *CreateMachineRequest) (*CreateMachineResponse, error) {
-	if m.createErr != nil {
-		return nil, m.createErr
-	}
-	if m.machines == nil {
-		m.machines = make(map[string]*MachineRequest)
+// TestFirestoreClient runs the shared storage suite against the Firestore
+// emulator. Start one with
+//
+//	gcloud emulators firestore start --host-port=localhost:8086
+//
+// and set FIRESTORE_EMULATOR_HOST=localhost:8086 to run it.
+func TestFirestoreClient(t *testing.T) {
+	host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if host == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
 	}
-	m.machines[req.MachineID] = req.Machine
-	return &CreateMachineResponse{}, nil
-}
 
-func (m *mockFirestoreClient) FindMachineByMAC(ctx context.Context, req *FindMachineByMACRequest) (*FindMachineByMACResponse, error) {
-	if m.findByMACErr != nil {
-		return nil, m.findByMACErr
-	}
-	if m.existingMacID != "" {
-		return &FindMachineByMACResponse{
-			MachineID: m.existingMacID,
-			Found:     true,
-		}, nil
-	}
-	return &FindMachineByMACResponse{Found: false}, nil
-}
-
-func (m *mockFirestoreClient) Close() error {
-	return nil
+	testStore(t, func(t *testing.T) store {
+		return newEmulatorClient(t, host)
+	})
 }
 
-func TestMockClient(t *testing.T) {
+// TestFirestoreClient_UnindexedMACs asserts that machines registered before
+// the machine_macs index existed keep their MACs until migrate-macs has
+// indexed them.
+func TestFirestoreClient_UnindexedMACs(t *testing.T) {
+	host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if host == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
 	ctx := context.Background()
+	c := newEmulatorClient(t, host)
+	defer c.Close()
 
-	t.Run("CreateMachine success", func(t *testing.T) {
-		mock := &mockFirestoreClient{}
-		req := &CreateMachineRequest{
-			MachineID: "test-id",
-			Machine: &MachineRequest{
-				NICs: []NIC{{MAC: "aa:bb:cc:dd:ee:ff"}},
-			},
-		}
+	const (
+		legacyID = "018c7dbd-c000-7000-8000-00000000000a"
+		newID    = "018c7dbd-c000-7000-8000-00000000000b"
+		batchID  = "018c7dbd-c000-7000-8000-00000000000c"
+		mac      = MAC("02:00:00:00:00:01")
+	)
+	_, err := c.client.Collection("machines").Doc(legacyID).Set(ctx, map[string]interface{}{
+		"id":       legacyID,
+		"revision": 1,
+		"nics":     []map[string]interface{}{{"mac": "02-00-00-00-00-01", "name": "eth0"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to write legacy machine: %v", err)
+	}
 
-		_, err := mock.CreateMachine(ctx, req)
+	find := func() *FindMachineByMACResponse {
+		t.Helper()
+		resp, err := c.FindMachineByMAC(ctx, &FindMachineByMACRequest{MAC: mac})
 		if err != nil {
-			t.Errorf("expected no error, got %v", err)
-		}
-
-		if len(mock.machines) != 1 {
-			t.Errorf("expected 1 machine, got %d", len(mock.machines))
+			t.Fatalf("FindMachineByMAC: %v", err)
 		}
+		return resp
+	}
+	if resp := find(); !resp.Found || resp.MachineID != legacyID {
+		t.Errorf("want the MAC found on %s before migrating, got %+v", legacyID, resp)
+	}
+	_, err = c.CreateMachine(ctx, &CreateMachineRequest{
+		MachineID: newID,
+		Machine:   &MachineRequest{NICs: []NIC{{MAC: mac}}},
 	})
+	var conflict *MACConflictError
+	if !errors.As(err, &conflict) || conflict.MachineID != legacyID {
+		t.Fatalf("want a conflict with %s, got %v", legacyID, err)
+	}
+	_, err = c.CreateMachines(ctx, &CreateMachinesRequest{Machines: []*CreateMachineRequest{
+		{MachineID: batchID, Machine: &MachineRequest{NICs: []NIC{{MAC: "02:00:00:00:00:02"}}}},
+		{MachineID: newID, Machine: &MachineRequest{NICs: []NIC{{MAC: mac}}}},
+	}})
+	var batchConflict *BatchConflictError
+	if !errors.As(err, &batchConflict) || len(batchConflict.Conflicts) != 1 || batchConflict.Conflicts[1] == nil || batchConflict.Conflicts[1].MachineID != legacyID {
+		t.Fatalf("want the second machine of the batch to conflict with %s, got %v", legacyID, err)
+	}
+
+	resp, err := c.MigrateMACs(ctx, &MigrateMACsRequest{})
+	if err != nil || len(resp.Failures) > 0 {
+		t.Fatalf("MigrateMACs: %+v, %v", resp, err)
+	}
+	if built, err := c.isMACIndexBuilt(ctx); err != nil || !built {
+		t.Errorf("expected the migration to be recorded, got %t, %v", built, err)
+	}
+	if resp := find(); !resp.Found || resp.MachineID != legacyID {
+		t.Errorf("want the MAC found on %s after migrating, got %+v", legacyID, resp)
+	}
+}
 
-	t.Run("FindMachineByMAC not found", func(t *testing.T) {
-		mock := &mockFirestoreClient{}
+// newEmulatorClient returns a client of an emptied emulator.
+func newEmulatorClient(t *testing.T, host string) *FirestoreClient {
+	t.Helper()
 
-		resp, err := mock.FindMachineByMAC(ctx, &FindMachineByMACRequest{
-			MAC: "aa:bb:cc:dd:ee:ff",
-		})
-		if err != nil {
-			t.Errorf("expected no error, got %v", err)
-		}
-		if resp.Found {
-			t.Errorf("expected not found, got found with ID %s", resp.MachineID)
-		}
-	})
+	ctx := context.Background()
+	if err := resetEmulator(ctx, host, emulatorProjectID); err != nil {
+		t.Fatalf("failed to reset firestore emulator: %v", err)
+	}
 
-	t.Run("FindMachineByMAC found", func(t *testing.T) {
-		mock := &mockFirestoreClient{
-			existingMacID: "existing-id",
-		}
+	client, err := NewFirestoreClient(ctx, emulatorProjectID)
+	if err != nil {
+		t.Fatalf("failed to create firestore client: %v", err)
+	}
+	return client
+}
 
-		resp, err := mock.FindMachineByMAC(ctx, &FindMachineByMACRequest{
-			MAC: "aa:bb:cc:dd:ee:ff",
-		})
-		if err != nil {
-			t.Errorf("expected no error, got %v", err)
-		}
-		if !resp.Found {
-			t.Error("expected found, got not found")
-		}
-		if resp.MachineID != "existing-id" {
-			t.Errorf("expected ID 'existing-id', got '%s'", resp.MachineID)
-		}
-	})
+// resetEmulator deletes every document in the project so each test starts
+// from an empty database.
+func resetEmulator(ctx context.Context, host, projectID string) error {
+	url := fmt.Sprintf("http://%s/emulator/v1/projects/%s/databases/(default)/documents", host, projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"maps"
+	"time"
+)
+
+// InventorySummary holds fleet wide totals over live machines. It is stored as
+// a single document and adjusted in the same transaction as every machine
+// write, so reading it never scans the machines collection.
+type InventorySummary struct {
+	Machines int64 `firestore:"machines"`
+	Cores    int64 `firestore:"cores"`
+	Memory   int64 `firestore:"memory"`
+
+	// StorageByMediaType is the drive capacity in bytes per DriveMediaType.
+	StorageByMediaType map[string]int64 `firestore:"storage_by_media_type"`
+
+	// AcceleratorsByManufacturer counts accelerators, not machines.
+	AcceleratorsByManufacturer map[string]int64 `firestore:"accelerators_by_manufacturer"`
+
+	MachinesByLifecycleState map[string]int64 `firestore:"machines_by_lifecycle_state"`
+
+	// MachinesByLabel counts machines by label key, then label value.
+	MachinesByLabel map[string]map[string]int64 `firestore:"machines_by_label"`
+
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+// emptySummaryKey stands in for empty map keys, such as drives of unknown
+// media type, since Firestore field names cannot be empty. It is not a valid
+// label value, so it cannot collide with one.
+const emptySummaryKey = "_"
+
+func summaryKey(s string) string {
+	if s == "" {
+		return emptySummaryKey
+	}
+	return s
+}
+
+// replace swaps the contribution of before for that of after. Either may be
+// nil, and tombstoned machines contribute nothing.
+func (s *InventorySummary) replace(before, after *Machine, at time.Time) {
+	s.add(before, -1)
+	s.add(after, 1)
+	s.UpdatedAt = at
+}
+
+func (s *InventorySummary) add(m *Machine, sign int64) {
+	if m == nil || m.DeletedAt != nil {
+		return
+	}
+
+	s.Machines += sign
+	s.Cores += sign * m.TotalCores()
+	s.Memory += sign * m.TotalMemory()
+	for _, drive := range m.Drives {
+		s.StorageByMediaType = addCount(s.StorageByMediaType, summaryKey(string(drive.MediaType)), sign*drive.Capacity)
+	}
+	for _, accelerator := range m.Accelerators {
+		s.AcceleratorsByManufacturer = addCount(s.AcceleratorsByManufacturer, summaryKey(accelerator.Manufacturer), sign)
+	}
+	s.MachinesByLifecycleState = addCount(s.MachinesByLifecycleState, string(m.CurrentLifecycleState()), sign)
+
+	for key, value := range m.Labels {
+		if s.MachinesByLabel == nil {
+			s.MachinesByLabel = make(map[string]map[string]int64)
+		}
+		values := addCount(s.MachinesByLabel[key], summaryKey(value), sign)
+		if len(values) == 0 {
+			delete(s.MachinesByLabel, key)
+			continue
+		}
+		s.MachinesByLabel[key] = values
+	}
+}
+
+// addCount adds n to m[key], dropping keys that reach zero so that removed
+// manufacturers or label values do not linger in the summary.
+func addCount(m map[string]int64, key string, n int64) map[string]int64 {
+	if m == nil {
+		m = make(map[string]int64)
+	}
+	m[key] += n
+	if m[key] == 0 {
+		delete(m, key)
+	}
+	return m
+}
+
+// decodeKeys returns a copy of the summary with empty map keys restored.
+func (s *InventorySummary) decodeKeys() *InventorySummary {
+	decoded := *s
+	decoded.StorageByMediaType = decodeCounts(s.StorageByMediaType)
+	decoded.AcceleratorsByManufacturer = decodeCounts(s.AcceleratorsByManufacturer)
+	decoded.MachinesByLifecycleState = maps.Clone(s.MachinesByLifecycleState)
+	decoded.MachinesByLabel = make(map[string]map[string]int64, len(s.MachinesByLabel))
+	for key, values := range s.MachinesByLabel {
+		decoded.MachinesByLabel[key] = decodeCounts(values)
+	}
+	return &decoded
+}
+
+func decodeCounts(m map[string]int64) map[string]int64 {
+	decoded := make(map[string]int64, len(m))
+	for key, n := range m {
+		if key == emptySummaryKey {
+			key = ""
+		}
+		decoded[key] = n
+	}
+	return decoded
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInventorySummary_Replace(t *testing.T) {
+	machine := &Machine{
+		MachineRequest: MachineRequest{
+			CPUs:         []CPU{{Cores: 8}},
+			Drives:       []Drive{{Capacity: 1 << 40}},
+			Accelerators: []Accelerator{{Manufacturer: "NVIDIA"}},
+		},
+		Labels: map[string]string{"rack": "a", "spare": ""},
+	}
+	now := time.Now()
+	deleted := *machine
+	deleted.DeletedAt = &now
+
+	tests := []struct {
+		name   string
+		before *Machine
+		after  *Machine
+		want   InventorySummary
+	}{
+		{
+			name:  "add",
+			after: machine,
+			want: InventorySummary{
+				Machines:                   1,
+				Cores:                      8,
+				StorageByMediaType:         map[string]int64{emptySummaryKey: 1 << 40},
+				AcceleratorsByManufacturer: map[string]int64{"NVIDIA": 1},
+				MachinesByLifecycleState:   map[string]int64{"new": 1},
+				MachinesByLabel: map[string]map[string]int64{
+					"rack":  {"a": 1},
+					"spare": {emptySummaryKey: 1},
+				},
+			},
+		},
+		{
+			name:   "remove drops emptied keys",
+			before: machine,
+			after:  nil,
+			want: InventorySummary{
+				StorageByMediaType:         map[string]int64{},
+				AcceleratorsByManufacturer: map[string]int64{},
+				MachinesByLifecycleState:   map[string]int64{},
+				MachinesByLabel:            map[string]map[string]int64{},
+			},
+		},
+		{
+			name:   "tombstones contribute nothing",
+			before: nil,
+			after:  &deleted,
+			want:   InventorySummary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var summary InventorySummary
+			if tt.before != nil {
+				summary.replace(nil, tt.before, time.Time{})
+			}
+			summary.replace(tt.before, tt.after, time.Time{})
+			if !reflect.DeepEqual(summary, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MaxLabels is the most labels a single machine may carry.
+const MaxLabels = 64
+
+const (
+	maxLabelNameLength   = 63
+	maxLabelPrefixLength = 253
+)
+
+var (
+	labelNameRE   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.-]*[A-Za-z0-9])?$`)
+	labelPrefixRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+)
+
+// ValidateLabelKey checks key against the Kubernetes label key syntax: an
+// optional DNS subdomain prefix and a slash, followed by a name of at most 63
+// alphanumerics, '-', '_' or '.', starting and ending with an alphanumeric.
+func ValidateLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if prefix == "" || len(prefix) > maxLabelPrefixLength || !labelPrefixRE.MatchString(prefix) {
+			return fmt.Errorf("invalid label key %q, the prefix must be a lowercase DNS subdomain", key)
+		}
+		name = rest
+	}
+	if name == "" || len(name) > maxLabelNameLength || !labelNameRE.MatchString(name) {
+		return fmt.Errorf("invalid label key %q, the name must be at most %d alphanumerics, '-', '_' or '.' and start and end with an alphanumeric", key, maxLabelNameLength)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks value against the Kubernetes label value syntax,
+// which is that of a key name, except that the empty value is allowed.
+func ValidateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > maxLabelNameLength || !labelNameRE.MatchString(value) {
+		return fmt.Errorf("invalid label value %q, must be at most %d alphanumerics, '-', '_' or '.' and start and end with an alphanumeric", value, maxLabelNameLength)
+	}
+	return nil
+}
+
+// SelectorOperator is how a Requirement compares a label.
+type SelectorOperator string
+
+const (
+	SelectorOpEquals       SelectorOperator = "="
+	SelectorOpNotEquals    SelectorOperator = "!="
+	SelectorOpIn           SelectorOperator = "in"
+	SelectorOpNotIn        SelectorOperator = "notin"
+	SelectorOpExists       SelectorOperator = "exists"
+	SelectorOpDoesNotExist SelectorOperator = "!"
+)
+
+// Requirement is a single term of a Selector.
+type Requirement struct {
+	Key      string
+	Operator SelectorOperator
+	Values   []string
+}
+
+// Matches reports whether labels satisfy the requirement. As in Kubernetes,
+// != and notin also match machines that do not have the label at all.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorOpEquals, SelectorOpIn:
+		return ok && slices.Contains(r.Values, value)
+	case SelectorOpNotEquals, SelectorOpNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case SelectorOpExists:
+		return ok
+	case SelectorOpDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case SelectorOpEquals, SelectorOpNotEquals:
+		return r.Key + string(r.Operator) + r.Values[0]
+	case SelectorOpIn, SelectorOpNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case SelectorOpDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key
+	}
+}
+
+// Selector is a conjunction of requirements. The empty Selector matches
+// every machine.
+type Selector []Requirement
+
+// Matches reports whether labels satisfy every requirement.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	terms := make([]string, len(s))
+	for i, r := range s {
+		terms[i] = r.String()
+	}
+	return strings.Join(terms, ",")
+}
+
+var setRequirementRE = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// ParseSelector parses a Kubernetes style label selector: comma separated
+// terms of the form key=value, key==value, key!=value, key in (v1,v2),
+// key notin (v1,v2), key and !key.
+func ParseSelector(s string) (Selector, error) {
+	terms, err := splitSelector(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var selector Selector
+	for _, term := range terms {
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateLabelKey(r.Key); err != nil {
+			return nil, err
+		}
+		for _, value := range r.Values {
+			if err := ValidateLabelValue(value); err != nil {
+				return nil, err
+			}
+		}
+		selector = append(selector, r)
+	}
+	return selector, nil
+}
+
+// splitSelector splits s on the commas that separate terms, leaving the
+// commas inside the value sets of in and notin alone.
+func splitSelector(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var terms []string
+	var depth, start int
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unbalanced parentheses in label selector")
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses in label selector")
+	}
+	terms = append(terms, strings.TrimSpace(s[start:]))
+
+	for _, term := range terms {
+		if term == "" {
+			return nil, errors.New("empty term in label selector")
+		}
+	}
+	return terms, nil
+}
+
+func parseRequirement(term string) (Requirement, error) {
+	if m := setRequirementRE.FindStringSubmatch(term); m != nil {
+		r := Requirement{Key: m[1], Operator: SelectorOperator(m[2])}
+		for _, value := range strings.Split(m[3], ",") {
+			r.Values = append(r.Values, strings.TrimSpace(value))
+		}
+		return r, nil
+	}
+	if key, ok := strings.CutPrefix(term, "!"); ok {
+		return Requirement{Key: strings.TrimSpace(key), Operator: SelectorOpDoesNotExist}, nil
+	}
+	if key, value, ok := strings.Cut(term, "!="); ok {
+		return Requirement{Key: strings.TrimSpace(key), Operator: SelectorOpNotEquals, Values: []string{strings.TrimSpace(value)}}, nil
+	}
+	if key, value, ok := strings.Cut(term, "=="); ok {
+		return Requirement{Key: strings.TrimSpace(key), Operator: SelectorOpEquals, Values: []string{strings.TrimSpace(value)}}, nil
+	}
+	if key, value, ok := strings.Cut(term, "="); ok {
+		return Requirement{Key: strings.TrimSpace(key), Operator: SelectorOpEquals, Values: []string{strings.TrimSpace(value)}}, nil
+	}
+	if strings.ContainsAny(term, " ()") {
+		return Requirement{}, fmt.Errorf("invalid label selector term %q", term)
+	}
+	return Requirement{Key: term, Operator: SelectorOpExists}, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateLabelKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "simple", key: "rack"},
+		{name: "with prefix", key: "infra.example.com/role"},
+		{name: "inner punctuation", key: "boot_profile.v2-beta"},
+		{name: "empty", key: "", wantErr: true},
+		{name: "empty name", key: "example.com/", wantErr: true},
+		{name: "empty prefix", key: "/role", wantErr: true},
+		{name: "uppercase prefix", key: "Example.com/role", wantErr: true},
+		{name: "leading dash", key: "-rack", wantErr: true},
+		{name: "trailing dot", key: "rack.", wantErr: true},
+		{name: "space", key: "server rack", wantErr: true},
+		{name: "two slashes", key: "a/b/c", wantErr: true},
+		{name: "name too long", key: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabelKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabelKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLabelValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{name: "simple", value: "storage"},
+		{name: "slash", value: "a/b", wantErr: true},
+		{name: "too long", value: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabelValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLabelValue(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{name: "empty", selector: "", want: ""},
+		{name: "equals", selector: "rack=a", want: "rack=a"},
+		{name: "double equals", selector: "rack==a", want: "rack=a"},
+		{name: "not equals", selector: "env != prod", want: "env!=prod"},
+		{name: "in", selector: "role in (storage, compute)", want: "role in (storage,compute)"},
+		{name: "notin", selector: "role notin (gpu)", want: "role notin (gpu)"},
+		{name: "exists", selector: "rack", want: "rack"},
+		{name: "does not exist", selector: "!decommission", want: "!decommission"},
+		{name: "conjunction", selector: "env=homelab,role in (a,b),!spare", want: "env=homelab,role in (a,b),!spare"},
+		{name: "empty term", selector: "rack=a,,env=b", wantErr: true},
+		{name: "unbalanced", selector: "role in (a,b", wantErr: true},
+		{name: "invalid key", selector: "-rack=a", wantErr: true},
+		{name: "invalid value", selector: "rack=a b", wantErr: true},
+		{name: "unknown operator", selector: "rack like (a)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseSelector(%q) = %q, want %q", tt.selector, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"rack": "a", "role": "storage", "env": "homelab"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"rack=a", true},
+		{"rack=b", false},
+		{"rack!=b", true},
+		{"gpu!=true", true},
+		{"role in (storage,compute)", true},
+		{"role notin (storage)", false},
+		{"gpu notin (true)", true},
+		{"env", true},
+		{"gpu", false},
+		{"!gpu", true},
+		{"!rack", false},
+		{"rack=a,role=storage,!gpu", true},
+		{"rack=a,role=compute", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q): %v", tt.selector, err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"slices"
+	"time"
+)
+
+// LifecycleState is where a machine is in its service life.
+type LifecycleState string
+
+const (
+	LifecycleStateNew            LifecycleState = "new"
+	LifecycleStateProvisioning   LifecycleState = "provisioning"
+	LifecycleStateInService      LifecycleState = "in_service"
+	LifecycleStateMaintenance    LifecycleState = "maintenance"
+	LifecycleStateDecommissioned LifecycleState = "decommissioned"
+)
+
+// lifecycleTransitions lists the states each state may move to.
+// Decommissioned is terminal.
+var lifecycleTransitions = map[LifecycleState][]LifecycleState{
+	LifecycleStateNew: {
+		LifecycleStateProvisioning,
+		LifecycleStateDecommissioned,
+	},
+	LifecycleStateProvisioning: {
+		LifecycleStateInService,
+		LifecycleStateMaintenance,
+		LifecycleStateDecommissioned,
+	},
+	LifecycleStateInService: {
+		LifecycleStateMaintenance,
+		LifecycleStateDecommissioned,
+	},
+	LifecycleStateMaintenance: {
+		LifecycleStateProvisioning,
+		LifecycleStateInService,
+		LifecycleStateDecommissioned,
+	},
+	LifecycleStateDecommissioned: nil,
+}
+
+// Valid reports whether s is a known lifecycle state.
+func (s LifecycleState) Valid() bool {
+	_, ok := lifecycleTransitions[s]
+	return ok
+}
+
+// AllowedTransitions returns the states a machine in state s may move to.
+func (s LifecycleState) AllowedTransitions() []LifecycleState {
+	return slices.Clone(lifecycleTransitions[s])
+}
+
+// CanTransitionTo reports whether a machine in state s may move to state to.
+func (s LifecycleState) CanTransitionTo(to LifecycleState) bool {
+	return slices.Contains(lifecycleTransitions[s], to)
+}
+
+// LifecycleTransition records a single change of a machine's lifecycle state.
+type LifecycleTransition struct {
+	From   LifecycleState `firestore:"from"`
+	To     LifecycleState `firestore:"to"`
+	At     time.Time      `firestore:"at"`
+	Actor  string         `firestore:"actor"`
+	Reason string         `firestore:"reason"`
+}
+
+// CurrentLifecycleState returns the machine's lifecycle state. Machines
+// registered before lifecycles were tracked have no stored state and are
+// considered new.
+func (m *Machine) CurrentLifecycleState() LifecycleState {
+	if m.LifecycleState == "" {
+		return LifecycleStateNew
+	}
+	return m.LifecycleState
+}
+
+// transition moves the machine to req.To and records it in the history,
+// failing with an *IllegalTransitionError if the table does not allow it.
+func (m *Machine) transition(req *TransitionMachineRequest, at time.Time) error {
+	from := m.CurrentLifecycleState()
+	if !from.CanTransitionTo(req.To) {
+		return &IllegalTransitionError{MachineID: m.ID, From: from, To: req.To}
+	}
+
+	m.LifecycleState = req.To
+	m.LifecycleHistory = append(m.LifecycleHistory, LifecycleTransition{
+		From:   from,
+		To:     req.To,
+		At:     at,
+		Actor:  req.Actor,
+		Reason: req.Reason,
+	})
+	return nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import "testing"
+
+func TestLifecycleState_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from LifecycleState
+		to   LifecycleState
+		want bool
+	}{
+		{LifecycleStateNew, LifecycleStateProvisioning, true},
+		{LifecycleStateNew, LifecycleStateInService, false},
+		{LifecycleStateProvisioning, LifecycleStateInService, true},
+		{LifecycleStateInService, LifecycleStateMaintenance, true},
+		{LifecycleStateInService, LifecycleStateProvisioning, false},
+		{LifecycleStateMaintenance, LifecycleStateInService, true},
+		{LifecycleStateMaintenance, LifecycleStateProvisioning, true},
+		{LifecycleStateInService, LifecycleStateDecommissioned, true},
+		{LifecycleStateDecommissioned, LifecycleStateNew, false},
+		{LifecycleStateDecommissioned, LifecycleStateInService, false},
+		{LifecycleStateNew, LifecycleStateNew, false},
+		{LifecycleStateNew, "retired", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMachine_CurrentLifecycleState(t *testing.T) {
+	// Machines registered before lifecycles were tracked have no state.
+	if got := (&Machine{}).CurrentLifecycleState(); got != LifecycleStateNew {
+		t.Errorf("want %s, got %s", LifecycleStateNew, got)
+	}
+	if got := (&Machine{LifecycleState: LifecycleStateInService}).CurrentLifecycleState(); got != LifecycleStateInService {
+		t.Errorf("want %s, got %s", LifecycleStateInService, got)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// MAC is a unicast EUI-48 NIC address in canonical form: lowercase hex octets
+// separated by colons, e.g. aa:bb:cc:dd:ee:ff. Values should be obtained from
+// ParseMAC so that every stored MAC compares equal to its other spellings.
+type MAC string
+
+var (
+	ErrMulticastMAC = errors.New("multicast MAC addresses cannot be assigned to a NIC")
+	ErrBroadcastMAC = errors.New("broadcast MAC address cannot be assigned to a NIC")
+)
+
+// ParseMAC accepts colon (aa:bb:cc:dd:ee:ff), dash (aa-bb-cc-dd-ee-ff),
+// Cisco dot (aabb.ccdd.eeff) and bare hex (aabbccddeeff) notation in any
+// case and returns the canonical form.
+func ParseMAC(s string) (MAC, error) {
+	if s == "" {
+		return "", errors.New("MAC address cannot be empty")
+	}
+
+	var hw net.HardwareAddr
+	if len(s) == 12 {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid MAC address %q", s)
+		}
+		hw = b
+	} else {
+		parsed, err := net.ParseMAC(s)
+		if err != nil || len(parsed) != 6 {
+			return "", fmt.Errorf("invalid MAC address %q, expected a 48-bit address such as aa:bb:cc:dd:ee:ff", s)
+		}
+		hw = parsed
+	}
+
+	if isBroadcast(hw) {
+		return "", ErrBroadcastMAC
+	}
+	// The least significant bit of the first octet marks group addresses.
+	if hw[0]&1 == 1 {
+		return "", ErrMulticastMAC
+	}
+	return MAC(hw.String()), nil
+}
+
+func (m MAC) String() string {
+	return string(m)
+}
+
+func isBroadcast(hw net.HardwareAddr) bool {
+	for _, b := range hw {
+		if b != 0xff {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMAC(t *testing.T) {
+	tests := []struct {
+		name    string
+		mac     string
+		want    MAC
+		wantErr error
+	}{
+		{
+			name: "colon lowercase",
+			mac:  "52:54:00:12:34:56",
+			want: "52:54:00:12:34:56",
+		},
+		{
+			name: "colon uppercase",
+			mac:  "AA:BB:CC:DD:EE:FE",
+			want: "aa:bb:cc:dd:ee:fe",
+		},
+		{
+			name: "dash",
+			mac:  "aa-bb-cc-dd-ee-fe",
+			want: "aa:bb:cc:dd:ee:fe",
+		},
+		{
+			name: "cisco dot",
+			mac:  "AABB.CCDD.EEFE",
+			want: "aa:bb:cc:dd:ee:fe",
+		},
+		{
+			name: "bare hex",
+			mac:  "aabbccddeefe",
+			want: "aa:bb:cc:dd:ee:fe",
+		},
+		{
+			name:    "broadcast",
+			mac:     "ff:ff:ff:ff:ff:ff",
+			wantErr: ErrBroadcastMAC,
+		},
+		{
+			name:    "multicast",
+			mac:     "01:00:5e:00:00:01",
+			wantErr: ErrMulticastMAC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMAC(tt.mac)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseMAC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMAC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []string{
+		"",
+		"aa:bb:cc:dd:ee",
+		"aa:bb:cc:dd:ee:ff:00:11",
+		"zz:yy:xx:ww:vv:uu",
+		"aabbccddeezz",
+		"aa:bb-cc:dd:ee:ff",
+	}
+	for _, mac := range invalid {
+		t.Run("invalid "+mac, func(t *testing.T) {
+			if _, err := ParseMAC(mac); err == nil {
+				t.Errorf("ParseMAC(%q) expected error", mac)
+			}
+		})
+	}
+}
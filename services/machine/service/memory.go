@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// MemoryStore keeps machines in process memory. It is safe for concurrent use
+// and is intended for local development and tests; everything is lost when
+// the process exits.
+type MemoryStore struct {
+	docStore
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		docStore: docStore{
+			db: &memoryDB{docs: make(map[string]map[string][]byte)},
+		},
+	}
+}
+
+// memoryDB serializes writers behind a single lock. Readers share the lock,
+// so each transaction observes a consistent snapshot.
+type memoryDB struct {
+	mu   sync.RWMutex
+	docs map[string]map[string][]byte
+}
+
+func (db *memoryDB) view(_ context.Context, fn func(tx docTx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return fn(&memoryTx{db: db, readOnly: true})
+}
+
+func (db *memoryDB) update(_ context.Context, fn func(tx docTx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &memoryTx{db: db, undo: make(map[docKey]undoEntry)}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+func (db *memoryDB) close() error { return nil }
+
+type docKey struct {
+	collection string
+	id         string
+}
+
+type undoEntry struct {
+	data    []byte
+	existed bool
+}
+
+// memoryTx writes straight through to the underlying maps and keeps the
+// first prior value of every touched document so a failed transaction can be
+// undone.
+type memoryTx struct {
+	db       *memoryDB
+	readOnly bool
+	undo     map[docKey]undoEntry
+}
+
+var errReadOnlyTx = errors.New("write in read-only transaction")
+
+func (tx *memoryTx) get(collection, id string, v any) (bool, error) {
+	data, ok := tx.db.docs[collection][id]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (tx *memoryTx) set(collection, id string, v any) error {
+	if tx.readOnly {
+		return errReadOnlyTx
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tx.remember(collection, id)
+	docs, ok := tx.db.docs[collection]
+	if !ok {
+		docs = make(map[string][]byte)
+		tx.db.docs[collection] = docs
+	}
+	docs[id] = data
+	return nil
+}
+
+func (tx *memoryTx) delete(collection, id string) error {
+	if tx.readOnly {
+		return errReadOnlyTx
+	}
+
+	tx.remember(collection, id)
+	delete(tx.db.docs[collection], id)
+	return nil
+}
+
+func (tx *memoryTx) scan(collection, startAfter string, fn func(id string, data []byte) (bool, error)) error {
+	docs := tx.db.docs[collection]
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		if id > startAfter {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		more, err := fn(id, docs[id])
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *memoryTx) remember(collection, id string) {
+	key := docKey{collection: collection, id: id}
+	if _, ok := tx.undo[key]; ok {
+		return
+	}
+	data, existed := tx.db.docs[collection][id]
+	tx.undo[key] = undoEntry{data: data, existed: existed}
+}
+
+func (tx *memoryTx) rollback() {
+	for key, entry := range tx.undo {
+		if !entry.existed {
+			delete(tx.db.docs[key.collection], key.id)
+			continue
+		}
+		tx.db.docs[key.collection][key.id] = entry.data
+	}
+}
@@ -1,5 +1,28 @@
 package service
 
+import "time"
+
+type Machine struct {
+	ID string `firestore:"id"`
+	MachineRequest
+
+	// Revision is incremented on every write and backs the machine's ETag.
+	// Machines written before revisions were tracked read as revision 0.
+	Revision int64 `firestore:"revision"`
+
+	// LifecycleState and LifecycleHistory are only changed by
+	// TransitionMachine.
+	LifecycleState   LifecycleState        `firestore:"lifecycle_state"`
+	LifecycleHistory []LifecycleTransition `firestore:"lifecycle_history"`
+
+	// Labels are only changed by SetMachineLabels.
+	Labels map[string]string `firestore:"labels"`
+
+	// DeletedAt and DeletedBy are only set on tombstoned machines.
+	DeletedAt *time.Time `firestore:"deleted_at,omitempty"`
+	DeletedBy string     `firestore:"deleted_by,omitempty"`
+}
+
 type MachineRequest struct {
 	CPUs          []CPU          `firestore:"cpus"`
 	MemoryModules []MemoryModule `firestore:"memory_modules"`
@@ -10,22 +33,106 @@ type MachineRequest struct {
 
 type CPU struct {
 	Manufacturer   string `firestore:"manufacturer"`
+	Model          string `firestore:"model"`
+	Socket         string `firestore:"socket"`
 	ClockFrequency int64  `firestore:"clock_frequency"`
 	Cores          int64  `firestore:"cores"`
+	Threads        int64  `firestore:"threads"`
 }
 
+// MemoryType is the DRAM generation of a memory module. The zero value means
+// it is unknown.
+type MemoryType string
+
+const (
+	MemoryTypeDDR3 MemoryType = "DDR3"
+	MemoryTypeDDR4 MemoryType = "DDR4"
+	MemoryTypeDDR5 MemoryType = "DDR5"
+)
+
 type MemoryModule struct {
-	Size int64 `firestore:"size"`
+	Size         int64      `firestore:"size"`
+	Type         MemoryType `firestore:"type"`
+	Speed        int64      `firestore:"speed"`
+	Slot         string     `firestore:"slot"`
+	Manufacturer string     `firestore:"manufacturer"`
+	Model        string     `firestore:"model"`
+	SerialNumber string     `firestore:"serial_number"`
 }
 
 type Accelerator struct {
-	Manufacturer string `firestore:"manufacturer"`
+	Manufacturer string     `firestore:"manufacturer"`
+	Model        string     `firestore:"model"`
+	SerialNumber string     `firestore:"serial_number"`
+	PCIAddress   PCIAddress `firestore:"pci_address"`
 }
 
 type NIC struct {
-	MAC string `firestore:"mac"`
+	MAC        MAC        `firestore:"mac"`
+	Name       string     `firestore:"name"`
+	Speed      int64      `firestore:"speed"`
+	Driver     string     `firestore:"driver"`
+	PCIAddress PCIAddress `firestore:"pci_address"`
+	Model      string     `firestore:"model"`
 }
 
+// DriveMediaType and DriveInterface describe how a drive stores data and how
+// it is attached. The zero values mean they are unknown.
+type (
+	DriveMediaType string
+	DriveInterface string
+)
+
+const (
+	DriveMediaTypeHDD DriveMediaType = "HDD"
+	DriveMediaTypeSSD DriveMediaType = "SSD"
+
+	DriveInterfaceSATA DriveInterface = "SATA"
+	DriveInterfaceSAS  DriveInterface = "SAS"
+	DriveInterfaceNVMe DriveInterface = "NVME"
+)
+
 type Drive struct {
-	Capacity int64 `firestore:"capacity"`
+	Capacity     int64          `firestore:"capacity"`
+	MediaType    DriveMediaType `firestore:"media_type"`
+	Interface    DriveInterface `firestore:"interface"`
+	Model        string         `firestore:"model"`
+	SerialNumber string         `firestore:"serial_number"`
+	Slot         string         `firestore:"slot"`
+}
+
+// IdempotencyRecord remembers the outcome of a machine registration made with
+// an Idempotency-Key so retries can be answered with the original response.
+type IdempotencyRecord struct {
+	Key         string    `firestore:"key"`
+	RequestHash string    `firestore:"request_hash"`
+	Response    []byte    `firestore:"response"`
+	ExpiresAt   time.Time `firestore:"expires_at"`
+}
+
+// TotalCores sums the cores of every CPU.
+func (m *MachineRequest) TotalCores() int64 {
+	var total int64
+	for _, cpu := range m.CPUs {
+		total += cpu.Cores
+	}
+	return total
+}
+
+// TotalMemory sums the size of every memory module, in bytes.
+func (m *MachineRequest) TotalMemory() int64 {
+	var total int64
+	for _, module := range m.MemoryModules {
+		total += module.Size
+	}
+	return total
+}
+
+// TotalDriveCapacity sums the capacity of every drive, in bytes.
+func (m *MachineRequest) TotalDriveCapacity() int64 {
+	var total int64
+	for _, drive := range m.Drives {
+		total += drive.Capacity
+	}
+	return total
 }
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PCIAddress is a PCI bus address in canonical form: lowercase hex with the
+// domain included, e.g. 0000:3b:00.0. Values should be obtained from
+// ParsePCIAddress.
+type PCIAddress string
+
+var pciAddressPattern = regexp.MustCompile(`^(?:([0-9a-f]{4}):)?([0-9a-f]{2}):([0-1][0-9a-f])\.([0-7])$`)
+
+// ParsePCIAddress accepts a domain:bus:device.function address, as printed by
+// lspci -D, or the shorter bus:device.function form, which is assumed to be
+// in domain 0000.
+func ParsePCIAddress(s string) (PCIAddress, error) {
+	m := pciAddressPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return "", fmt.Errorf("invalid PCI address %q, expected a domain:bus:device.function address such as 0000:3b:00.0", s)
+	}
+
+	domain := m[1]
+	if domain == "" {
+		domain = "0000"
+	}
+	return PCIAddress(fmt.Sprintf("%s:%s:%s.%s", domain, m[2], m[3], m[4])), nil
+}
+
+func (a PCIAddress) String() string {
+	return string(a)
+}
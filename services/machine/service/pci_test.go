@@ -0,0 +1,33 @@
+package service
+
+import "testing"
+
+func TestParsePCIAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    PCIAddress
+		wantErr bool
+	}{
+		{name: "full address", in: "0000:3b:00.0", want: "0000:3b:00.0"},
+		{name: "uppercase", in: "0000:3B:00.1", want: "0000:3b:00.1"},
+		{name: "without domain", in: "af:00.0", want: "0000:af:00.0"},
+		{name: "other domain", in: "0001:00:1f.7", want: "0001:00:1f.7"},
+		{name: "empty", in: "", wantErr: true},
+		{name: "device out of range", in: "0000:3b:20.0", wantErr: true},
+		{name: "function out of range", in: "0000:3b:00.8", wantErr: true},
+		{name: "not hex", in: "0000:zz:00.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePCIAddress(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePCIAddress(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePCIAddress(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
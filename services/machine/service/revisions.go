@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MachineRevision is a superseded version of a machine. One is stored by
+// every write that bumps a machine's revision, recording the machine as it
+// was before the write along with who made the write, when, and what it
+// changed.
+type MachineRevision struct {
+	// Machine is the machine as it was at Machine.Revision.
+	Machine Machine `firestore:"machine"`
+
+	ChangedAt time.Time `firestore:"changed_at"`
+	ChangedBy string    `firestore:"changed_by"`
+
+	// Changes lists the fields the superseding write changed, in path order.
+	Changes []FieldChange `firestore:"changes"`
+}
+
+// FieldChange is a single changed field, addressed by its Firestore field
+// path, e.g. cpus[0].cores or labels["rack"]. Before is nil for added fields
+// and After is nil for removed ones.
+type FieldChange struct {
+	Path   string  `firestore:"path"`
+	Before *string `firestore:"before"`
+	After  *string `firestore:"after"`
+}
+
+func newMachineRevision(before, after *Machine, actor string, at time.Time) *MachineRevision {
+	return &MachineRevision{
+		Machine:   *before,
+		ChangedAt: at,
+		ChangedBy: actor,
+		Changes:   diffMachines(before, after),
+	}
+}
+
+// rollback returns m with the hardware and labels of revision. The lifecycle
+// is left alone, since rolling it back could skip the transition table.
+func (m *Machine) rollback(revision *MachineRevision) *Machine {
+	rolled := *m
+	rolled.MachineRequest = revision.Machine.MachineRequest
+	rolled.Labels = revision.Machine.Labels
+	return &rolled
+}
+
+// undiffedFields change on every write, or are recorded in full elsewhere, so
+// diffing them would only add noise.
+var undiffedFields = map[string]bool{
+	"revision":          true,
+	"lifecycle_history": true,
+}
+
+func diffMachines(before, after *Machine) []FieldChange {
+	beforeFields := make(map[string]string)
+	flattenFields("", reflect.ValueOf(before).Elem(), beforeFields)
+	afterFields := make(map[string]string)
+	flattenFields("", reflect.ValueOf(after).Elem(), afterFields)
+
+	var paths []string
+	for path := range beforeFields {
+		paths = append(paths, path)
+	}
+	for path := range afterFields {
+		if _, ok := beforeFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var changes []FieldChange
+	for _, path := range paths {
+		b, inBefore := beforeFields[path]
+		a, inAfter := afterFields[path]
+		if inBefore && inAfter && a == b {
+			continue
+		}
+		change := FieldChange{Path: path}
+		if inBefore {
+			change.Before = &b
+		}
+		if inAfter {
+			change.After = &a
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// flattenFields records every non-zero leaf of v in fields, keyed by its
+// path. Struct fields are named by their firestore tags and embedded structs
+// are flattened into their parent, as Firestore stores them.
+func flattenFields(path string, v reflect.Value, fields map[string]string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			flattenFields(path, v.Elem(), fields)
+		}
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			if !t.IsZero() {
+				fields[path] = t.UTC().Format(time.RFC3339Nano)
+			}
+			return
+		}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if field.Anonymous {
+				flattenFields(path, v.Field(i), fields)
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("firestore"), ",")
+			if !field.IsExported() || name == "" || name == "-" || (path == "" && undiffedFields[name]) {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			flattenFields(name, v.Field(i), fields)
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			flattenFields(fmt.Sprintf("%s[%d]", path, i), v.Index(i), fields)
+		}
+	case reflect.Map:
+		// Map entries are recorded even when zero, so that adding a label
+		// with an empty value still shows up as a change.
+		for _, key := range v.MapKeys() {
+			keyPath := path + "[" + strconv.Quote(key.String()) + "]"
+			if value := v.MapIndex(key); value.Kind() == reflect.String {
+				fields[keyPath] = value.String()
+			} else {
+				flattenFields(keyPath, value, fields)
+			}
+		}
+	default:
+		if !v.IsZero() {
+			fields[path] = fmt.Sprint(v.Interface())
+		}
+	}
+}
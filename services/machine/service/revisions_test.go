@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDiffMachines(t *testing.T) {
+	base := &Machine{
+		ID:             "018c7dbd-c000-7000-8000-00000000000a",
+		MachineRequest: MachineRequest{CPUs: []CPU{{Manufacturer: "Intel", Cores: 8}}},
+		Revision:       1,
+		LifecycleState: LifecycleStateNew,
+	}
+	deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		change func(m *Machine)
+		want   string
+	}{
+		{
+			name:   "no change",
+			change: func(m *Machine) {},
+			want:   "[]",
+		},
+		{
+			name: "revision and history are ignored",
+			change: func(m *Machine) {
+				m.Revision = 2
+				m.LifecycleHistory = []LifecycleTransition{{To: LifecycleStateProvisioning}}
+			},
+			want: "[]",
+		},
+		{
+			name:   "modified field",
+			change: func(m *Machine) { m.CPUs = []CPU{{Manufacturer: "AMD", Cores: 8}} },
+			want:   "[cpus[0].manufacturer: Intel -> AMD]",
+		},
+		{
+			name:   "added and removed slice elements",
+			change: func(m *Machine) { m.CPUs = nil; m.Drives = []Drive{{Capacity: 1 << 30}} },
+			want:   "[cpus[0].cores: 8 -> <nil> cpus[0].manufacturer: Intel -> <nil> drives[0].capacity: <nil> -> 1073741824]",
+		},
+		{
+			name:   "labels with empty values",
+			change: func(m *Machine) { m.Labels = map[string]string{"spare": "", "example.com/gpu": "true"} },
+			want:   `[labels["example.com/gpu"]: <nil> -> true labels["spare"]: <nil> -> ]`,
+		},
+		{
+			name:   "tombstone",
+			change: func(m *Machine) { m.DeletedAt = &deletedAt; m.DeletedBy = "admin" },
+			want:   "[deleted_at: <nil> -> 2026-10-01T12:00:00Z deleted_by: <nil> -> admin]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := *base
+			after.CPUs = append([]CPU(nil), base.CPUs...)
+			tt.change(&after)
+
+			var got []string
+			for _, change := range diffMachines(base, &after) {
+				got = append(got, fmt.Sprintf("%s: %s -> %s", change.Path, deref(change.Before), deref(change.After)))
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("want %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
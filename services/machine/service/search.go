@@ -0,0 +1,60 @@
+package service
+
+import (
+	"slices"
+	"strings"
+)
+
+// HardwareRequirements describes what a workload needs from a machine. Zero
+// values place no constraint.
+type HardwareRequirements struct {
+	MinCores                int64
+	MinMemory               int64
+	MinDriveCapacity        int64
+	MinNICs                 int64
+	AcceleratorManufacturer string
+	LifecycleStates         []LifecycleState
+	LabelSelector           Selector
+}
+
+// Fit reports whether m satisfies the requirements and, if so, how tightly.
+// The score is the mean of required/available over the numeric requirements
+// that are set, so 1 is an exact fit and smaller scores leave more capacity
+// unused. Without numeric requirements every match scores 1.
+func (r *HardwareRequirements) Fit(m *Machine) (float64, bool) {
+	if len(r.LifecycleStates) > 0 && !slices.Contains(r.LifecycleStates, m.CurrentLifecycleState()) {
+		return 0, false
+	}
+	if !r.LabelSelector.Matches(m.Labels) {
+		return 0, false
+	}
+	if r.AcceleratorManufacturer != "" && !slices.ContainsFunc(m.Accelerators, func(a Accelerator) bool {
+		return strings.EqualFold(a.Manufacturer, r.AcceleratorManufacturer)
+	}) {
+		return 0, false
+	}
+
+	dimensions := []struct{ required, available int64 }{
+		{r.MinCores, m.TotalCores()},
+		{r.MinMemory, m.TotalMemory()},
+		{r.MinDriveCapacity, m.TotalDriveCapacity()},
+		{r.MinNICs, int64(len(m.NICs))},
+	}
+
+	var sum float64
+	var n int
+	for _, d := range dimensions {
+		if d.required <= 0 {
+			continue
+		}
+		if d.available < d.required {
+			return 0, false
+		}
+		sum += float64(d.required) / float64(d.available)
+		n++
+	}
+	if n == 0 {
+		return 1, true
+	}
+	return sum / float64(n), true
+}
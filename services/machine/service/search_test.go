@@ -0,0 +1,86 @@
+package service
+
+import "testing"
+
+func TestHardwareRequirements_Fit(t *testing.T) {
+	machine := &Machine{
+		MachineRequest: MachineRequest{
+			CPUs:          []CPU{{Cores: 8}, {Cores: 8}},
+			MemoryModules: []MemoryModule{{Size: 32 << 30}, {Size: 32 << 30}},
+			Accelerators:  []Accelerator{{Manufacturer: "NVIDIA"}},
+			NICs:          []NIC{{MAC: "02:00:00:00:00:01"}, {MAC: "02:00:00:00:00:02"}},
+			Drives:        []Drive{{Capacity: 500 << 30}, {Capacity: 500 << 30}},
+		},
+		LifecycleState: LifecycleStateInService,
+		Labels:         map[string]string{"rack": "a"},
+	}
+
+	tests := []struct {
+		name      string
+		req       HardwareRequirements
+		wantMatch bool
+		wantScore float64
+	}{
+		{
+			name:      "no requirements",
+			req:       HardwareRequirements{},
+			wantMatch: true,
+			wantScore: 1,
+		},
+		{
+			name:      "exact fit",
+			req:       HardwareRequirements{MinCores: 16, MinMemory: 64 << 30},
+			wantMatch: true,
+			wantScore: 1,
+		},
+		{
+			name:      "loose fit",
+			req:       HardwareRequirements{MinCores: 4, MinNICs: 1},
+			wantMatch: true,
+			wantScore: 0.375,
+		},
+		{
+			name:      "too few cores",
+			req:       HardwareRequirements{MinCores: 17},
+			wantMatch: false,
+		},
+		{
+			name:      "too little storage",
+			req:       HardwareRequirements{MinDriveCapacity: 2 << 40},
+			wantMatch: false,
+		},
+		{
+			name:      "accelerator manufacturer ignores case",
+			req:       HardwareRequirements{AcceleratorManufacturer: "nvidia"},
+			wantMatch: true,
+			wantScore: 1,
+		},
+		{
+			name:      "missing accelerator manufacturer",
+			req:       HardwareRequirements{AcceleratorManufacturer: "AMD"},
+			wantMatch: false,
+		},
+		{
+			name:      "lifecycle state",
+			req:       HardwareRequirements{LifecycleStates: []LifecycleState{LifecycleStateNew}},
+			wantMatch: false,
+		},
+		{
+			name:      "label selector",
+			req:       HardwareRequirements{LabelSelector: Selector{{Key: "rack", Operator: SelectorOpEquals, Values: []string{"b"}}}},
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := tt.req.Fit(machine)
+			if ok != tt.wantMatch {
+				t.Fatalf("want match %v, got %v", tt.wantMatch, ok)
+			}
+			if ok && score != tt.wantScore {
+				t.Errorf("want score %v, got %v", tt.wantScore, score)
+			}
+		})
+	}
+}
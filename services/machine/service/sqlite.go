@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps machines in a single SQLite database file, for hosts
+// without access to Firestore.
+type SQLiteStore struct {
+	docStore
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS documents (
+	collection TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	PRIMARY KEY (collection, id)
+) WITHOUT ROWID;
+`
+
+// NewSQLiteStore opens, and creates if needed, the database at path.
+func NewSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer at a time. Funnelling every transaction
+	// through one connection avoids SQLITE_BUSY errors under concurrent
+	// requests and keeps in-memory databases shared.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize sqlite schema: %w", err)
+	}
+
+	s := &SQLiteStore{
+		docStore: docStore{
+			db: &sqliteDB{db: db},
+		},
+	}
+	if err := s.ensureInventorySummary(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize inventory summary: %w", err)
+	}
+	return s, nil
+}
+
+type sqliteDB struct {
+	db *sql.DB
+}
+
+func (db *sqliteDB) view(ctx context.Context, fn func(tx docTx) error) error {
+	tx, err := db.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(&sqliteTx{ctx: ctx, tx: tx})
+}
+
+func (db *sqliteDB) update(ctx context.Context, fn func(tx docTx) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&sqliteTx{ctx: ctx, tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *sqliteDB) close() error {
+	return db.db.Close()
+}
+
+type sqliteTx struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (tx *sqliteTx) get(collection, id string, v any) (bool, error) {
+	var data []byte
+	err := tx.tx.QueryRowContext(tx.ctx,
+		`SELECT data FROM documents WHERE collection = ? AND id = ?`,
+		collection, id,
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (tx *sqliteTx) set(collection, id string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.tx.ExecContext(tx.ctx,
+		`INSERT INTO documents (collection, id, data) VALUES (?, ?, ?)
+		 ON CONFLICT (collection, id) DO UPDATE SET data = excluded.data`,
+		collection, id, data,
+	)
+	return err
+}
+
+func (tx *sqliteTx) delete(collection, id string) error {
+	_, err := tx.tx.ExecContext(tx.ctx,
+		`DELETE FROM documents WHERE collection = ? AND id = ?`,
+		collection, id,
+	)
+	return err
+}
+
+func (tx *sqliteTx) scan(collection, startAfter string, fn func(id string, data []byte) (bool, error)) error {
+	rows, err := tx.tx.QueryContext(tx.ctx,
+		`SELECT id, data FROM documents WHERE collection = ? AND id > ? ORDER BY id`,
+		collection, startAfter,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return err
+		}
+
+		more, err := fn(id, data)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+	}
+	return rows.Err()
+}
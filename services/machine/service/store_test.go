@@ -0,0 +1,1325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// store is the set of operations every storage backend provides.
+type store interface {
+	CreateMachine(ctx context.Context, req *CreateMachineRequest) (*CreateMachineResponse, error)
+	CreateMachines(ctx context.Context, req *CreateMachinesRequest) (*CreateMachinesResponse, error)
+	GetMachine(ctx context.Context, req *GetMachineRequest) (*GetMachineResponse, error)
+	UpdateMachine(ctx context.Context, req *UpdateMachineRequest) (*UpdateMachineResponse, error)
+	DeleteMachine(ctx context.Context, req *DeleteMachineRequest) (*DeleteMachineResponse, error)
+	RestoreMachine(ctx context.Context, req *RestoreMachineRequest) (*RestoreMachineResponse, error)
+	ListMachines(ctx context.Context, req *ListMachinesRequest) (*ListMachinesResponse, error)
+	FindMachineByMAC(ctx context.Context, req *FindMachineByMACRequest) (*FindMachineByMACResponse, error)
+	GetIdempotencyRecord(ctx context.Context, req *GetIdempotencyRecordRequest) (*GetIdempotencyRecordResponse, error)
+	TransitionMachine(ctx context.Context, req *TransitionMachineRequest) (*TransitionMachineResponse, error)
+	SetMachineLabels(ctx context.Context, req *SetMachineLabelsRequest) (*SetMachineLabelsResponse, error)
+	PurgeDeletedMachines(ctx context.Context, req *PurgeDeletedMachinesRequest) (*PurgeDeletedMachinesResponse, error)
+	GetInventorySummary(ctx context.Context, req *GetInventorySummaryRequest) (*GetInventorySummaryResponse, error)
+	RebuildInventorySummary(ctx context.Context, req *RebuildInventorySummaryRequest) (*RebuildInventorySummaryResponse, error)
+	ListMachineRevisions(ctx context.Context, req *ListMachineRevisionsRequest) (*ListMachineRevisionsResponse, error)
+	GetMachineRevision(ctx context.Context, req *GetMachineRevisionRequest) (*GetMachineRevisionResponse, error)
+	RollbackMachine(ctx context.Context, req *RollbackMachineRequest) (*RollbackMachineResponse, error)
+	ListMachineChanges(ctx context.Context, req *ListMachineChangesRequest) (*ListMachineChangesResponse, error)
+	PurgeMachineChanges(ctx context.Context, req *PurgeMachineChangesRequest) (*PurgeMachineChangesResponse, error)
+	GetChangeCursor(ctx context.Context, req *GetChangeCursorRequest) (*GetChangeCursorResponse, error)
+	SetChangeCursor(ctx context.Context, req *SetChangeCursorRequest) (*SetChangeCursorResponse, error)
+	CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error)
+	GetWebhook(ctx context.Context, req *GetWebhookRequest) (*GetWebhookResponse, error)
+	ListWebhooks(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	UpdateWebhook(ctx context.Context, req *UpdateWebhookRequest) (*UpdateWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	EnqueueWebhookDeliveries(ctx context.Context, req *EnqueueWebhookDeliveriesRequest) (*EnqueueWebhookDeliveriesResponse, error)
+	ListWebhookDeliveries(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	GetWebhookDelivery(ctx context.Context, req *GetWebhookDeliveryRequest) (*GetWebhookDeliveryResponse, error)
+	ListDueWebhookDeliveries(ctx context.Context, req *ListDueWebhookDeliveriesRequest) (*ListDueWebhookDeliveriesResponse, error)
+	ClaimWebhookDelivery(ctx context.Context, req *ClaimWebhookDeliveryRequest) (*ClaimWebhookDeliveryResponse, error)
+	SetWebhookDelivery(ctx context.Context, req *SetWebhookDeliveryRequest) (*SetWebhookDeliveryResponse, error)
+	RedeliverWebhookDelivery(ctx context.Context, req *RedeliverWebhookDeliveryRequest) (*RedeliverWebhookDeliveryResponse, error)
+	PurgeWebhookDeliveries(ctx context.Context, req *PurgeWebhookDeliveriesRequest) (*PurgeWebhookDeliveriesResponse, error)
+	Close() error
+}
+
+var (
+	_ store = (*FirestoreClient)(nil)
+	_ store = (*MemoryStore)(nil)
+	_ store = (*SQLiteStore)(nil)
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) store {
+		return NewMemoryStore()
+	})
+}
+
+func TestSQLiteStore(t *testing.T) {
+	testStore(t, func(t *testing.T) store {
+		s, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "machines.db"))
+		if err != nil {
+			t.Fatalf("failed to open sqlite store: %v", err)
+		}
+		return s
+	})
+}
+
+// testStore runs the behaviour shared by all storage backends against the
+// stores returned by newStore. Each subtest gets a fresh store.
+func testStore(t *testing.T, newStore func(t *testing.T) store) {
+	ctx := context.Background()
+
+	const (
+		idA = "018c7dbd-c000-7000-8000-00000000000a"
+		idB = "018c7dbd-c000-7000-8000-00000000000b"
+		idC = "018c7dbd-c000-7000-8000-00000000000c"
+	)
+	machineWith := func(macs ...MAC) *MachineRequest {
+		m := &MachineRequest{CPUs: []CPU{{Manufacturer: "Intel", Cores: 8}}}
+		for _, mac := range macs {
+			m.NICs = append(m.NICs, NIC{MAC: mac})
+		}
+		return m
+	}
+	run := func(name string, fn func(t *testing.T, s store)) {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			t.Cleanup(func() { s.Close() })
+			fn(t, s)
+		})
+	}
+	mustCreate := func(t *testing.T, s store, id string, m *MachineRequest) {
+		t.Helper()
+		if _, err := s.CreateMachine(ctx, &CreateMachineRequest{MachineID: id, Machine: m}); err != nil {
+			t.Fatalf("CreateMachine(%s): %v", id, err)
+		}
+	}
+	mustGet := func(t *testing.T, s store, req *GetMachineRequest) *GetMachineResponse {
+		t.Helper()
+		resp, err := s.GetMachine(ctx, req)
+		if err != nil {
+			t.Fatalf("GetMachine(%s): %v", req.MachineID, err)
+		}
+		return resp
+	}
+	owner := func(t *testing.T, s store, mac MAC) string {
+		t.Helper()
+		resp, err := s.FindMachineByMAC(ctx, &FindMachineByMACRequest{MAC: mac})
+		if err != nil {
+			t.Fatalf("FindMachineByMAC(%s): %v", mac, err)
+		}
+		return resp.MachineID
+	}
+	// settle claims a due delivery and records the outcome set by attempt,
+	// as a dispatcher would.
+	settle := func(t *testing.T, s store, due *WebhookDelivery, attempt func(d *WebhookDelivery)) {
+		t.Helper()
+		claimed, err := s.ClaimWebhookDelivery(ctx, &ClaimWebhookDeliveryRequest{
+			WebhookID:  due.WebhookID,
+			Sequence:   due.Sequence,
+			Due:        *due.NextAttemptAt,
+			LeaseUntil: time.Now().UTC().Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("ClaimWebhookDelivery(%d): %v", due.Sequence, err)
+		}
+		leasedUntil := *claimed.Delivery.NextAttemptAt
+		attempt(claimed.Delivery)
+		_, err = s.SetWebhookDelivery(ctx, &SetWebhookDeliveryRequest{Delivery: claimed.Delivery, LeasedUntil: leasedUntil})
+		if err != nil {
+			t.Fatalf("SetWebhookDelivery(%d): %v", due.Sequence, err)
+		}
+	}
+
+	run("create and get", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+
+		resp := mustGet(t, s, &GetMachineRequest{MachineID: idA})
+		if !resp.Found {
+			t.Fatal("expected machine to be found")
+		}
+		if resp.Machine.ID != idA || resp.Machine.Revision != 1 {
+			t.Errorf("unexpected machine %+v", resp.Machine)
+		}
+		if len(resp.Machine.CPUs) != 1 || resp.Machine.CPUs[0].Cores != 8 {
+			t.Errorf("unexpected CPUs %+v", resp.Machine.CPUs)
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != idA {
+			t.Errorf("want MAC owned by %s, got %q", idA, got)
+		}
+
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idB}); resp.Found {
+			t.Error("expected unknown machine not to be found")
+		}
+	})
+
+	run("create with taken MAC is atomic", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+
+		_, err := s.CreateMachine(ctx, &CreateMachineRequest{
+			MachineID: idB,
+			Machine:   machineWith("02:00:00:00:00:02", "02:00:00:00:00:01"),
+		})
+		var conflict *MACConflictError
+		if !errors.As(err, &conflict) || conflict.MachineID != idA {
+			t.Fatalf("want MACConflictError for %s, got %v", idA, err)
+		}
+		if got := owner(t, s, "02:00:00:00:00:02"); got != "" {
+			t.Errorf("expected failed create to leave no MAC claims, got owner %q", got)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idB}); resp.Found {
+			t.Error("expected failed create to leave no machine")
+		}
+	})
+
+	run("batch create is all or nothing", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+
+		_, err := s.CreateMachines(ctx, &CreateMachinesRequest{
+			Machines: []*CreateMachineRequest{
+				{MachineID: idB, Machine: machineWith("02:00:00:00:00:02")},
+				{MachineID: idC, Machine: machineWith("02:00:00:00:00:03", "02:00:00:00:00:01")},
+			},
+		})
+		var batchErr *BatchConflictError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("want BatchConflictError, got %v", err)
+		}
+		if len(batchErr.Conflicts) != 1 || batchErr.Conflicts[1] == nil || batchErr.Conflicts[1].MachineID != idA {
+			t.Fatalf("want conflict for machine 1 with %s, got %+v", idA, batchErr.Conflicts)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idB}); resp.Found {
+			t.Error("expected failed batch to create no machines")
+		}
+		if got := owner(t, s, "02:00:00:00:00:02"); got != "" {
+			t.Errorf("expected failed batch to claim no MACs, got owner %q", got)
+		}
+
+		_, err = s.CreateMachines(ctx, &CreateMachinesRequest{
+			Machines: []*CreateMachineRequest{
+				{MachineID: idB, Machine: machineWith("02:00:00:00:00:02")},
+				{MachineID: idC, Machine: machineWith("02:00:00:00:00:03")},
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateMachines: %v", err)
+		}
+		for _, id := range []string{idB, idC} {
+			resp := mustGet(t, s, &GetMachineRequest{MachineID: id})
+			if !resp.Found || resp.Machine.Revision != 1 {
+				t.Errorf("expected %s to be created at revision 1, got %+v", id, resp)
+			}
+		}
+		if got := owner(t, s, "02:00:00:00:00:03"); got != idC {
+			t.Errorf("want MAC owned by %s, got %q", idC, got)
+		}
+	})
+
+	run("idempotency records", func(t *testing.T, s store) {
+		record := &IdempotencyRecord{
+			Key:         "retry-1",
+			RequestHash: "hash",
+			Response:    []byte("response"),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		_, err := s.CreateMachine(ctx, &CreateMachineRequest{
+			MachineID:         idA,
+			Machine:           machineWith("02:00:00:00:00:01"),
+			IdempotencyRecord: record,
+		})
+		if err != nil {
+			t.Fatalf("CreateMachine: %v", err)
+		}
+
+		resp, err := s.GetIdempotencyRecord(ctx, &GetIdempotencyRecordRequest{Key: "retry-1"})
+		if err != nil {
+			t.Fatalf("GetIdempotencyRecord: %v", err)
+		}
+		if !resp.Found || resp.Record.RequestHash != "hash" || string(resp.Record.Response) != "response" {
+			t.Errorf("unexpected record %+v", resp)
+		}
+
+		_, err = s.CreateMachine(ctx, &CreateMachineRequest{
+			MachineID:         idB,
+			Machine:           machineWith("02:00:00:00:00:02"),
+			IdempotencyRecord: record,
+		})
+		var exists *IdempotencyKeyExistsError
+		if !errors.As(err, &exists) {
+			t.Fatalf("want IdempotencyKeyExistsError, got %v", err)
+		}
+
+		_, err = s.CreateMachine(ctx, &CreateMachineRequest{
+			MachineID: idC,
+			Machine:   machineWith("02:00:00:00:00:03"),
+			IdempotencyRecord: &IdempotencyRecord{
+				Key:       "expired",
+				ExpiresAt: time.Now().Add(-time.Minute),
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateMachine: %v", err)
+		}
+		resp, err = s.GetIdempotencyRecord(ctx, &GetIdempotencyRecordRequest{Key: "expired"})
+		if err != nil {
+			t.Fatalf("GetIdempotencyRecord: %v", err)
+		}
+		if resp.Found {
+			t.Error("expected expired record not to be found")
+		}
+	})
+
+	run("update moves MAC claims and bumps revision", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		mustCreate(t, s, idB, machineWith("02:00:00:00:00:02"))
+
+		updateResp, err := s.UpdateMachine(ctx, &UpdateMachineRequest{
+			MachineID: idA,
+			Machine:   machineWith("02:00:00:00:00:03"),
+		})
+		if err != nil {
+			t.Fatalf("UpdateMachine: %v", err)
+		}
+		if updateResp.Revision != 2 {
+			t.Errorf("want revision 2, got %d", updateResp.Revision)
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != "" {
+			t.Errorf("expected old MAC to be released, owned by %q", got)
+		}
+		if got := owner(t, s, "02:00:00:00:00:03"); got != idA {
+			t.Errorf("want new MAC owned by %s, got %q", idA, got)
+		}
+
+		_, err = s.UpdateMachine(ctx, &UpdateMachineRequest{
+			MachineID: idA,
+			Machine:   machineWith("02:00:00:00:00:02"),
+		})
+		var conflict *MACConflictError
+		if !errors.As(err, &conflict) || conflict.MachineID != idB {
+			t.Fatalf("want MACConflictError for %s, got %v", idB, err)
+		}
+
+		stale := int64(1)
+		_, err = s.UpdateMachine(ctx, &UpdateMachineRequest{
+			MachineID:        idA,
+			Machine:          machineWith("02:00:00:00:00:03"),
+			ExpectedRevision: &stale,
+		})
+		var mismatch *RevisionMismatchError
+		if !errors.As(err, &mismatch) || mismatch.Actual != 2 {
+			t.Fatalf("want RevisionMismatchError at revision 2, got %v", err)
+		}
+	})
+
+	run("lifecycle transitions", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		if got := mustGet(t, s, &GetMachineRequest{MachineID: idA}).Machine.LifecycleState; got != LifecycleStateNew {
+			t.Fatalf("want new machines in state %s, got %q", LifecycleStateNew, got)
+		}
+
+		resp, err := s.TransitionMachine(ctx, &TransitionMachineRequest{
+			MachineID: idA,
+			To:        LifecycleStateProvisioning,
+			Actor:     "alice",
+			Reason:    "racked",
+		})
+		if err != nil {
+			t.Fatalf("TransitionMachine: %v", err)
+		}
+		if resp.Machine.LifecycleState != LifecycleStateProvisioning || resp.Machine.Revision != 2 {
+			t.Errorf("unexpected machine after transition %+v", resp.Machine)
+		}
+
+		_, err = s.TransitionMachine(ctx, &TransitionMachineRequest{
+			MachineID: idA,
+			To:        LifecycleStateNew,
+		})
+		var illegal *IllegalTransitionError
+		if !errors.As(err, &illegal) || illegal.From != LifecycleStateProvisioning {
+			t.Fatalf("want IllegalTransitionError from %s, got %v", LifecycleStateProvisioning, err)
+		}
+
+		// A hardware update must not reset the lifecycle.
+		if _, err := s.UpdateMachine(ctx, &UpdateMachineRequest{MachineID: idA, Machine: machineWith("02:00:00:00:00:01")}); err != nil {
+			t.Fatalf("UpdateMachine: %v", err)
+		}
+
+		stale := int64(2)
+		_, err = s.TransitionMachine(ctx, &TransitionMachineRequest{
+			MachineID:        idA,
+			To:               LifecycleStateInService,
+			ExpectedRevision: &stale,
+		})
+		var mismatch *RevisionMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("want RevisionMismatchError, got %v", err)
+		}
+
+		machine := mustGet(t, s, &GetMachineRequest{MachineID: idA}).Machine
+		if machine.LifecycleState != LifecycleStateProvisioning {
+			t.Errorf("want state %s, got %s", LifecycleStateProvisioning, machine.LifecycleState)
+		}
+		if len(machine.LifecycleHistory) != 1 {
+			t.Fatalf("want one recorded transition, got %+v", machine.LifecycleHistory)
+		}
+		got := machine.LifecycleHistory[0]
+		if got.From != LifecycleStateNew || got.To != LifecycleStateProvisioning || got.Actor != "alice" || got.Reason != "racked" || got.At.IsZero() {
+			t.Errorf("unexpected transition %+v", got)
+		}
+	})
+
+	run("delete, restore and purge", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+
+		deleteResp, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idA, DeletedBy: "admin"})
+		if err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+		if deleteResp.Revision != 2 {
+			t.Errorf("want revision 2, got %d", deleteResp.Revision)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idA}); resp.Found {
+			t.Error("expected deleted machine to be hidden")
+		}
+		resp := mustGet(t, s, &GetMachineRequest{MachineID: idA, IncludeDeleted: true})
+		if !resp.Found || resp.Machine.DeletedAt == nil || resp.Machine.DeletedBy != "admin" {
+			t.Fatalf("expected tombstone, got %+v", resp)
+		}
+		changes, err := s.ListMachineChanges(ctx, &ListMachineChangesRequest{After: 1, PageSize: 1})
+		if err != nil || len(changes.Changes) != 1 {
+			t.Fatalf("ListMachineChanges: %+v, %v", changes, err)
+		}
+		if at := changes.Changes[0].At; !resp.Machine.DeletedAt.Equal(at) {
+			t.Errorf("want deleted_at %v as in the change log, got %v", at, resp.Machine.DeletedAt)
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != "" {
+			t.Errorf("expected MAC to be released, owned by %q", got)
+		}
+
+		restoreResp, err := s.RestoreMachine(ctx, &RestoreMachineRequest{MachineID: idA})
+		if err != nil {
+			t.Fatalf("RestoreMachine: %v", err)
+		}
+		if restoreResp.Revision != 3 {
+			t.Errorf("want revision 3, got %d", restoreResp.Revision)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idA}); !resp.Found {
+			t.Error("expected restored machine to be found")
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != idA {
+			t.Errorf("want MAC reclaimed by %s, got %q", idA, got)
+		}
+
+		if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idA}); err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+		purgeResp, err := s.PurgeDeletedMachines(ctx, &PurgeDeletedMachinesRequest{DeletedBefore: time.Now().Add(-time.Hour)})
+		if err != nil {
+			t.Fatalf("PurgeDeletedMachines: %v", err)
+		}
+		if purgeResp.Purged != 0 {
+			t.Errorf("expected recent tombstone to be kept, purged %d", purgeResp.Purged)
+		}
+		purgeResp, err = s.PurgeDeletedMachines(ctx, &PurgeDeletedMachinesRequest{DeletedBefore: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("PurgeDeletedMachines: %v", err)
+		}
+		if purgeResp.Purged != 1 {
+			t.Errorf("want 1 purged, got %d", purgeResp.Purged)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idA, IncludeDeleted: true}); resp.Found {
+			t.Error("expected purged machine to be gone")
+		}
+	})
+
+	// The handlers check for tombstones before writing, but a machine can be
+	// deleted in between, so every write checks again in its transaction.
+	deletedWrites := []struct {
+		name  string
+		write func(s store) error
+	}{
+		{"update", func(s store) error {
+			_, err := s.UpdateMachine(ctx, &UpdateMachineRequest{MachineID: idA, Machine: machineWith("02:00:00:00:00:01")})
+			return err
+		}},
+		{"delete", func(s store) error {
+			_, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idA})
+			return err
+		}},
+		{"transition", func(s store) error {
+			_, err := s.TransitionMachine(ctx, &TransitionMachineRequest{MachineID: idA, To: LifecycleStateProvisioning})
+			return err
+		}},
+		{"set labels", func(s store) error {
+			_, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idA, Labels: map[string]string{"rack": "r1"}})
+			return err
+		}},
+		{"rollback", func(s store) error {
+			_, err := s.RollbackMachine(ctx, &RollbackMachineRequest{MachineID: idA, Revision: 1})
+			return err
+		}},
+	}
+	for _, tt := range deletedWrites {
+		run(tt.name+" of a deleted machine", func(t *testing.T, s store) {
+			mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+			if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idA}); err != nil {
+				t.Fatalf("DeleteMachine: %v", err)
+			}
+			// The released MAC now belongs to another machine, which the
+			// write must leave alone.
+			mustCreate(t, s, idB, machineWith("02:00:00:00:00:01"))
+
+			var notFound *MachineNotFoundError
+			if err := tt.write(s); !errors.As(err, &notFound) || notFound.MachineID != idA {
+				t.Fatalf("want MachineNotFoundError for %s, got %v", idA, err)
+			}
+			resp := mustGet(t, s, &GetMachineRequest{MachineID: idA, IncludeDeleted: true})
+			if resp.Machine.DeletedAt == nil || resp.Machine.Revision != 2 {
+				t.Errorf("expected the tombstone to be unchanged, got %+v", resp.Machine)
+			}
+			if got := owner(t, s, "02:00:00:00:00:01"); got != idB {
+				t.Errorf("want MAC still owned by %s, got %q", idB, got)
+			}
+		})
+	}
+
+	run("writes to a missing machine", func(t *testing.T, s store) {
+		for _, tt := range deletedWrites {
+			var notFound *MachineNotFoundError
+			if err := tt.write(s); !errors.As(err, &notFound) {
+				t.Errorf("%s: want MachineNotFoundError, got %v", tt.name, err)
+			}
+		}
+	})
+
+	run("restore of a live machine", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+
+		_, err := s.RestoreMachine(ctx, &RestoreMachineRequest{MachineID: idA})
+		var notDeleted *MachineNotDeletedError
+		if !errors.As(err, &notDeleted) {
+			t.Fatalf("want MachineNotDeletedError, got %v", err)
+		}
+		if resp := mustGet(t, s, &GetMachineRequest{MachineID: idA}); resp.Machine.Revision != 1 {
+			t.Errorf("expected the machine to be unchanged, got revision %d", resp.Machine.Revision)
+		}
+	})
+
+	run("list pages skip tombstones", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		mustCreate(t, s, idB, machineWith("02:00:00:00:00:02"))
+		mustCreate(t, s, idC, machineWith("02:00:00:00:00:03"))
+		if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idB}); err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+
+		first, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 1})
+		if err != nil {
+			t.Fatalf("ListMachines: %v", err)
+		}
+		if len(first.Machines) != 1 || first.Machines[0].ID != idA || first.NextPageToken != idA {
+			t.Fatalf("unexpected first page %+v", first)
+		}
+
+		second, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 1, PageToken: first.NextPageToken})
+		if err != nil {
+			t.Fatalf("ListMachines: %v", err)
+		}
+		if len(second.Machines) != 1 || second.Machines[0].ID != idC || second.NextPageToken != "" {
+			t.Fatalf("unexpected second page %+v", second)
+		}
+
+		byMAC, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 10, MAC: "02:00:00:00:00:03"})
+		if err != nil {
+			t.Fatalf("ListMachines: %v", err)
+		}
+		if len(byMAC.Machines) != 1 || byMAC.Machines[0].ID != idC {
+			t.Fatalf("unexpected MAC filtered page %+v", byMAC)
+		}
+	})
+
+	run("list pages stop after MaxScanned", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		mustCreate(t, s, idB, machineWith("02:00:00:00:00:02"))
+		mustCreate(t, s, idC, machineWith("02:00:00:00:00:03"))
+		for _, id := range []string{idA, idB} {
+			if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: id}); err != nil {
+				t.Fatalf("DeleteMachine: %v", err)
+			}
+		}
+
+		// Two tombstones use up the scan, leaving an empty page which is
+		// followed by another.
+		first, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 1, MaxScanned: 2})
+		if err != nil {
+			t.Fatalf("ListMachines: %v", err)
+		}
+		if len(first.Machines) != 0 || first.NextPageToken != idB {
+			t.Fatalf("unexpected first page %+v", first)
+		}
+
+		second, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 1, PageToken: first.NextPageToken, MaxScanned: 2})
+		if err != nil {
+			t.Fatalf("ListMachines: %v", err)
+		}
+		if len(second.Machines) != 1 || second.Machines[0].ID != idC || second.NextPageToken != "" {
+			t.Fatalf("unexpected second page %+v", second)
+		}
+	})
+	run("labels and selectors", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		mustCreate(t, s, idB, machineWith("02:00:00:00:00:02"))
+		mustCreate(t, s, idC, machineWith("02:00:00:00:00:03"))
+		setLabels := func(id string, labels map[string]string) {
+			t.Helper()
+			if _, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: id, Labels: labels}); err != nil {
+				t.Fatalf("SetMachineLabels(%s): %v", id, err)
+			}
+		}
+		setLabels(idA, map[string]string{"rack": "a", "role": "storage"})
+		setLabels(idB, map[string]string{"rack": "b", "role": "compute", "example.com/gpu": "true"})
+
+		// A hardware update must not drop the labels.
+		if _, err := s.UpdateMachine(ctx, &UpdateMachineRequest{MachineID: idA, Machine: machineWith("02:00:00:00:00:01")}); err != nil {
+			t.Fatalf("UpdateMachine: %v", err)
+		}
+		machine := mustGet(t, s, &GetMachineRequest{MachineID: idA}).Machine
+		if machine.Labels["role"] != "storage" || machine.Revision != 3 {
+			t.Errorf("unexpected machine after update %+v", machine)
+		}
+
+		list := func(selector string, mac MAC) []string {
+			t.Helper()
+			sel, err := ParseSelector(selector)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q): %v", selector, err)
+			}
+			resp, err := s.ListMachines(ctx, &ListMachinesRequest{PageSize: 10, MAC: mac, LabelSelector: sel})
+			if err != nil {
+				t.Fatalf("ListMachines: %v", err)
+			}
+			var ids []string
+			for _, m := range resp.Machines {
+				ids = append(ids, m.ID)
+			}
+			return ids
+		}
+		for selector, want := range map[string][]string{
+			"rack=a":                    {idA},
+			"role in (storage,compute)": {idA, idB},
+			"role!=storage":             {idB, idC},
+			"example.com/gpu":           {idB},
+			"!rack":                     {idC},
+		} {
+			if got := list(selector, ""); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("%s: want %v, got %v", selector, want, got)
+			}
+		}
+		if got := list("rack=b", "02:00:00:00:00:01"); len(got) != 0 {
+			t.Errorf("expected MAC lookup to honour the selector, got %v", got)
+		}
+
+		stale := int64(1)
+		_, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idA, ExpectedRevision: &stale})
+		var mismatch *RevisionMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("want RevisionMismatchError, got %v", err)
+		}
+	})
+
+	run("inventory summary", func(t *testing.T, s store) {
+		summary := func() *InventorySummary {
+			t.Helper()
+			resp, err := s.GetInventorySummary(ctx, &GetInventorySummaryRequest{})
+			if err != nil {
+				t.Fatalf("GetInventorySummary: %v", err)
+			}
+			return resp.Summary
+		}
+		if got := summary(); got.Machines != 0 || len(got.MachinesByLifecycleState) != 0 {
+			t.Fatalf("expected an empty summary, got %+v", got)
+		}
+
+		gpu := machineWith("02:00:00:00:00:01")
+		gpu.MemoryModules = []MemoryModule{{Size: 16 << 30}, {Size: 16 << 30}}
+		gpu.Drives = []Drive{{Capacity: 1 << 40, MediaType: DriveMediaTypeSSD}, {Capacity: 1 << 30}}
+		gpu.Accelerators = []Accelerator{{Manufacturer: "NVIDIA"}, {Manufacturer: "NVIDIA"}}
+		mustCreate(t, s, idA, gpu)
+		_, err := s.CreateMachines(ctx, &CreateMachinesRequest{Machines: []*CreateMachineRequest{
+			{MachineID: idB, Machine: machineWith("02:00:00:00:00:02")},
+			{MachineID: idC, Machine: machineWith("02:00:00:00:00:03")},
+		}})
+		if err != nil {
+			t.Fatalf("CreateMachines: %v", err)
+		}
+		if _, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idA, Labels: map[string]string{"rack": "a", "spare": ""}}); err != nil {
+			t.Fatalf("SetMachineLabels: %v", err)
+		}
+		if _, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idB, Labels: map[string]string{"rack": "b"}}); err != nil {
+			t.Fatalf("SetMachineLabels: %v", err)
+		}
+		if _, err := s.TransitionMachine(ctx, &TransitionMachineRequest{MachineID: idA, To: LifecycleStateProvisioning}); err != nil {
+			t.Fatalf("TransitionMachine: %v", err)
+		}
+		smaller := machineWith("02:00:00:00:00:02")
+		smaller.CPUs[0].Cores = 4
+		if _, err := s.UpdateMachine(ctx, &UpdateMachineRequest{MachineID: idB, Machine: smaller}); err != nil {
+			t.Fatalf("UpdateMachine: %v", err)
+		}
+		if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idC}); err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+
+		want := &InventorySummary{
+			Machines:                   2,
+			Cores:                      12,
+			Memory:                     32 << 30,
+			StorageByMediaType:         map[string]int64{"SSD": 1 << 40, "": 1 << 30},
+			AcceleratorsByManufacturer: map[string]int64{"NVIDIA": 2},
+			MachinesByLifecycleState:   map[string]int64{"new": 1, "provisioning": 1},
+			MachinesByLabel: map[string]map[string]int64{
+				"rack":  {"a": 1, "b": 1},
+				"spare": {"": 1},
+			},
+		}
+		got := summary()
+		if got.UpdatedAt.IsZero() {
+			t.Error("expected updated_at to be set")
+		}
+		got.UpdatedAt = time.Time{}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want summary %+v, got %+v", want, got)
+		}
+
+		if _, err := s.RestoreMachine(ctx, &RestoreMachineRequest{MachineID: idC}); err != nil {
+			t.Fatalf("RestoreMachine: %v", err)
+		}
+		if got := summary(); got.Machines != 3 || got.Cores != 20 || got.MachinesByLifecycleState["new"] != 2 {
+			t.Errorf("unexpected summary after restore %+v", got)
+		}
+
+		rebuilt, err := s.RebuildInventorySummary(ctx, &RebuildInventorySummaryRequest{})
+		if err != nil {
+			t.Fatalf("RebuildInventorySummary: %v", err)
+		}
+		maintained := summary()
+		rebuilt.Summary.UpdatedAt, maintained.UpdatedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(rebuilt.Summary, maintained) {
+			t.Errorf("rebuilt summary %+v differs from maintained %+v", rebuilt.Summary, maintained)
+		}
+	})
+
+	run("revisions and rollback", func(t *testing.T, s store) {
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		bigger := machineWith("02:00:00:00:00:02")
+		bigger.CPUs[0].Cores = 16
+		if _, err := s.UpdateMachine(ctx, &UpdateMachineRequest{MachineID: idA, Machine: bigger, Actor: "alice"}); err != nil {
+			t.Fatalf("UpdateMachine: %v", err)
+		}
+		if _, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idA, Labels: map[string]string{"rack": "a"}, Actor: "bob"}); err != nil {
+			t.Fatalf("SetMachineLabels: %v", err)
+		}
+		if _, err := s.TransitionMachine(ctx, &TransitionMachineRequest{MachineID: idA, To: LifecycleStateProvisioning, Actor: "carol"}); err != nil {
+			t.Fatalf("TransitionMachine: %v", err)
+		}
+
+		list := func(pageToken int64) *ListMachineRevisionsResponse {
+			t.Helper()
+			resp, err := s.ListMachineRevisions(ctx, &ListMachineRevisionsRequest{MachineID: idA, PageSize: 2, PageToken: pageToken})
+			if err != nil {
+				t.Fatalf("ListMachineRevisions: %v", err)
+			}
+			return resp
+		}
+		revisionNumbers := func(resp *ListMachineRevisionsResponse) []int64 {
+			var numbers []int64
+			for _, revision := range resp.Revisions {
+				numbers = append(numbers, revision.Machine.Revision)
+			}
+			return numbers
+		}
+		first := list(0)
+		if got := revisionNumbers(first); fmt.Sprint(got) != "[3 2]" || first.NextPageToken != 2 {
+			t.Fatalf("unexpected first page %v, next %d", got, first.NextPageToken)
+		}
+		second := list(first.NextPageToken)
+		if got := revisionNumbers(second); fmt.Sprint(got) != "[1]" || second.NextPageToken != 0 {
+			t.Fatalf("unexpected second page %v, next %d", got, second.NextPageToken)
+		}
+
+		getResp, err := s.GetMachineRevision(ctx, &GetMachineRevisionRequest{MachineID: idA, Revision: 1})
+		if err != nil {
+			t.Fatalf("GetMachineRevision: %v", err)
+		}
+		revision := getResp.Revision
+		if !getResp.Found || revision.ChangedBy != "alice" || revision.ChangedAt.IsZero() || revision.Machine.CPUs[0].Cores != 8 {
+			t.Fatalf("unexpected revision 1 %+v", getResp)
+		}
+		var changes []string
+		for _, change := range revision.Changes {
+			changes = append(changes, fmt.Sprintf("%s:%s->%s", change.Path, *change.Before, *change.After))
+		}
+		if want := "[cpus[0].cores:8->16 nics[0].mac:02:00:00:00:00:01->02:00:00:00:00:02]"; fmt.Sprint(changes) != want {
+			t.Errorf("want changes %s, got %v", want, changes)
+		}
+		if resp, err := s.GetMachineRevision(ctx, &GetMachineRevisionRequest{MachineID: idA, Revision: 9}); err != nil || resp.Found {
+			t.Errorf("expected revision 9 to be missing, got %+v, %v", resp, err)
+		}
+
+		rollbackResp, err := s.RollbackMachine(ctx, &RollbackMachineRequest{MachineID: idA, Revision: 1, Actor: "dave"})
+		if err != nil {
+			t.Fatalf("RollbackMachine: %v", err)
+		}
+		machine := mustGet(t, s, &GetMachineRequest{MachineID: idA}).Machine
+		if machine.Revision != 5 || machine.CPUs[0].Cores != 8 || len(machine.Labels) != 0 || machine.LifecycleState != LifecycleStateProvisioning {
+			t.Errorf("unexpected machine after rollback %+v", machine)
+		}
+		if rollbackResp.Machine.Revision != machine.Revision {
+			t.Errorf("want rolled back revision %d, got %d", machine.Revision, rollbackResp.Machine.Revision)
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != idA {
+			t.Errorf("want MAC reclaimed by %s, got %q", idA, got)
+		}
+		if got := owner(t, s, "02:00:00:00:00:02"); got != "" {
+			t.Errorf("expected MAC to be released, owned by %q", got)
+		}
+		if latest := list(0).Revisions[0]; latest.Machine.Revision != 4 || latest.ChangedBy != "dave" {
+			t.Errorf("expected the rollback to store revision 4, got %+v", latest)
+		}
+
+		_, err = s.RollbackMachine(ctx, &RollbackMachineRequest{MachineID: idA, Revision: 42})
+		var notFound *RevisionNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("want RevisionNotFoundError, got %v", err)
+		}
+
+		if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idA}); err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+		if _, err := s.PurgeDeletedMachines(ctx, &PurgeDeletedMachinesRequest{DeletedBefore: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("PurgeDeletedMachines: %v", err)
+		}
+		if resp := list(0); len(resp.Revisions) != 0 {
+			t.Errorf("expected purge to remove revisions, got %v", revisionNumbers(resp))
+		}
+	})
+
+	run("change log", func(t *testing.T, s store) {
+		if resp, err := s.ListMachineChanges(ctx, &ListMachineChangesRequest{PageSize: 10}); err != nil || resp.Latest != 0 || len(resp.Changes) != 0 {
+			t.Fatalf("expected an empty change log, got %+v, %v", resp, err)
+		}
+
+		mustCreate(t, s, idA, machineWith("02:00:00:00:00:01"))
+		_, err := s.CreateMachines(ctx, &CreateMachinesRequest{Machines: []*CreateMachineRequest{
+			{MachineID: idB, Machine: machineWith(), Actor: "alice"},
+			{MachineID: idC, Machine: machineWith(), Actor: "alice"},
+		}})
+		if err != nil {
+			t.Fatalf("CreateMachines: %v", err)
+		}
+		if _, err := s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: idA, Labels: map[string]string{"rack": "a"}, Actor: "bob"}); err != nil {
+			t.Fatalf("SetMachineLabels: %v", err)
+		}
+		if _, err := s.TransitionMachine(ctx, &TransitionMachineRequest{MachineID: idA, To: LifecycleStateProvisioning, Actor: "carol"}); err != nil {
+			t.Fatalf("TransitionMachine: %v", err)
+		}
+		if _, err := s.DeleteMachine(ctx, &DeleteMachineRequest{MachineID: idB, DeletedBy: "dave"}); err != nil {
+			t.Fatalf("DeleteMachine: %v", err)
+		}
+		if _, err := s.RestoreMachine(ctx, &RestoreMachineRequest{MachineID: idB, Actor: "dave"}); err != nil {
+			t.Fatalf("RestoreMachine: %v", err)
+		}
+
+		list := func(after int64, pageSize int) *ListMachineChangesResponse {
+			t.Helper()
+			resp, err := s.ListMachineChanges(ctx, &ListMachineChangesRequest{After: after, PageSize: pageSize})
+			if err != nil {
+				t.Fatalf("ListMachineChanges: %v", err)
+			}
+			return resp
+		}
+		describe := func(changes []*MachineChange) []string {
+			var described []string
+			for _, change := range changes {
+				described = append(described, fmt.Sprintf("%d:%s:%s@%d", change.Sequence, change.Type, change.MachineID[len(change.MachineID)-1:], change.Machine.Revision))
+			}
+			return described
+		}
+
+		all := list(0, 10)
+		want := "[1:created:a@1 2:created:b@1 3:created:c@1 4:updated:a@2 5:state_changed:a@3 6:deleted:b@2 7:created:b@3]"
+		if got := describe(all.Changes); fmt.Sprint(got) != want || all.Latest != 7 {
+			t.Fatalf("want changes %s, got %v (latest %d)", want, got, all.Latest)
+		}
+		if labeled := all.Changes[3]; labeled.Actor != "bob" || labeled.Machine.Labels["rack"] != "a" || labeled.At.IsZero() {
+			t.Errorf("unexpected label change %+v", labeled)
+		}
+		if deleted := all.Changes[5]; deleted.Machine.DeletedAt == nil || deleted.Machine.DeletedBy != "dave" {
+			t.Errorf("expected the delete to carry the tombstone, got %+v", deleted.Machine)
+		}
+		if got := describe(list(4, 2).Changes); fmt.Sprint(got) != "[5:state_changed:a@3 6:deleted:b@2]" {
+			t.Errorf("unexpected page after 4: %v", got)
+		}
+		if resp := list(7, 10); len(resp.Changes) != 0 || resp.Latest != 7 {
+			t.Errorf("expected nothing after the latest change, got %+v", resp)
+		}
+		if resp := list(0, 0); len(resp.Changes) != 0 || resp.Latest != 7 {
+			t.Errorf("expected only the latest sequence, got %+v", resp)
+		}
+
+		// Revisions are stored by the same writes that append to the log.
+		revisions, err := s.ListMachineRevisions(ctx, &ListMachineRevisionsRequest{MachineID: idA, PageSize: 10})
+		if err != nil {
+			t.Fatalf("ListMachineRevisions: %v", err)
+		}
+		if len(revisions.Revisions) != 2 {
+			t.Errorf("want 2 revisions of %s, got %d", idA, len(revisions.Revisions))
+		}
+
+		through := int64(3)
+		purged, err := s.PurgeMachineChanges(ctx, &PurgeMachineChangesRequest{Before: time.Now().Add(time.Hour), Through: &through})
+		if err != nil {
+			t.Fatalf("PurgeMachineChanges: %v", err)
+		}
+		if got := describe(list(0, 10).Changes); purged.Purged != 3 || len(got) != 4 || got[0] != "4:updated:a@2" {
+			t.Errorf("expected changes through 3 to be purged, purged %d leaving %v", purged.Purged, got)
+		}
+		purged, err = s.PurgeMachineChanges(ctx, &PurgeMachineChangesRequest{Before: time.Now().Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("PurgeMachineChanges: %v", err)
+		}
+		if purged.Purged != 4 {
+			t.Errorf("want 4 changes purged, got %d", purged.Purged)
+		}
+		if resp := list(0, 10); len(resp.Changes) != 0 || resp.Latest != 7 {
+			t.Errorf("expected purge to keep the sequence, got %+v", resp)
+		}
+
+		mustCreate(t, s, "018c7dbd-c000-7000-8000-00000000000d", machineWith())
+		if got := describe(list(7, 10).Changes); fmt.Sprint(got) != "[8:created:d@1]" {
+			t.Errorf("expected numbering to continue after a purge, got %v", got)
+		}
+	})
+
+	run("change cursors", func(t *testing.T, s store) {
+		cursor := func(consumer string) int64 {
+			t.Helper()
+			resp, err := s.GetChangeCursor(ctx, &GetChangeCursorRequest{Consumer: consumer})
+			if err != nil {
+				t.Fatalf("GetChangeCursor(%s): %v", consumer, err)
+			}
+			return resp.Sequence
+		}
+
+		if got := cursor("cloudevents"); got != 0 {
+			t.Errorf("want a new consumer at 0, got %d", got)
+		}
+		if _, err := s.SetChangeCursor(ctx, &SetChangeCursorRequest{Consumer: "cloudevents", Sequence: 12}); err != nil {
+			t.Fatalf("SetChangeCursor: %v", err)
+		}
+		if got := cursor("cloudevents"); got != 12 {
+			t.Errorf("want cursor 12, got %d", got)
+		}
+		if got := cursor("webhooks"); got != 0 {
+			t.Errorf("expected consumers to have separate cursors, got %d", got)
+		}
+	})
+
+	run("webhooks", func(t *testing.T, s store) {
+		const webhookID = "018c7dbd-c000-7000-8000-0000000000f1"
+		created := time.Now().UTC().Truncate(time.Second)
+		webhook := &Webhook{
+			ID:            webhookID,
+			URL:           "https://hooks.example.com/machines",
+			Secret:        "s3cret",
+			EventTypes:    []ChangeType{ChangeCreated},
+			LabelSelector: "rack=r1",
+			CreatedAt:     created,
+			UpdatedAt:     created,
+		}
+		if _, err := s.CreateWebhook(ctx, &CreateWebhookRequest{Webhook: webhook}); err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+		if _, err := s.CreateWebhook(ctx, &CreateWebhookRequest{Webhook: webhook}); err == nil {
+			t.Error("expected creating an existing webhook to fail")
+		}
+
+		getResp, err := s.GetWebhook(ctx, &GetWebhookRequest{WebhookID: webhookID})
+		if err != nil || !getResp.Found {
+			t.Fatalf("GetWebhook: found=%v err=%v", getResp != nil && getResp.Found, err)
+		}
+		if !reflect.DeepEqual(getResp.Webhook, webhook) {
+			t.Errorf("want %+v, got %+v", webhook, getResp.Webhook)
+		}
+
+		updated := *webhook
+		updated.EventTypes = nil
+		if _, err := s.UpdateWebhook(ctx, &UpdateWebhookRequest{Webhook: &updated}); err != nil {
+			t.Fatalf("UpdateWebhook: %v", err)
+		}
+		listResp, err := s.ListWebhooks(ctx, &ListWebhooksRequest{})
+		if err != nil {
+			t.Fatalf("ListWebhooks: %v", err)
+		}
+		if len(listResp.Webhooks) != 1 || len(listResp.Webhooks[0].EventTypes) != 0 {
+			t.Errorf("want the updated webhook listed, got %+v", listResp.Webhooks)
+		}
+
+		missing := &Webhook{ID: "018c7dbd-c000-7000-8000-0000000000f2"}
+		var notFound *WebhookNotFoundError
+		if _, err := s.UpdateWebhook(ctx, &UpdateWebhookRequest{Webhook: missing}); !errors.As(err, &notFound) {
+			t.Errorf("want *WebhookNotFoundError updating a missing webhook, got %v", err)
+		}
+		if _, err := s.DeleteWebhook(ctx, &DeleteWebhookRequest{WebhookID: missing.ID}); !errors.As(err, &notFound) {
+			t.Errorf("want *WebhookNotFoundError deleting a missing webhook, got %v", err)
+		}
+	})
+
+	run("webhook deliveries", func(t *testing.T, s store) {
+		const webhookID = "018c7dbd-c000-7000-8000-0000000000f1"
+		if _, err := s.CreateWebhook(ctx, &CreateWebhookRequest{Webhook: &Webhook{ID: webhookID}}); err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		var deliveries []*WebhookDelivery
+		for seq := int64(1); seq <= 3; seq++ {
+			// Later changes are due earlier, to tell due order from
+			// sequence order.
+			due := now.Add(-time.Duration(seq) * time.Minute)
+			deliveries = append(deliveries, &WebhookDelivery{
+				WebhookID:     webhookID,
+				Sequence:      seq,
+				EventType:     ChangeCreated,
+				MachineID:     idA,
+				Payload:       []byte(`{}`),
+				Status:        DeliveryPending,
+				CreatedAt:     now.Add(-time.Hour),
+				NextAttemptAt: &due,
+				GiveUpAt:      now.Add(time.Hour),
+			})
+		}
+		_, err := s.EnqueueWebhookDeliveries(ctx, &EnqueueWebhookDeliveriesRequest{
+			Deliveries: deliveries,
+			Consumer:   "webhooks",
+			Through:    5,
+		})
+		if err != nil {
+			t.Fatalf("EnqueueWebhookDeliveries: %v", err)
+		}
+		cursorResp, err := s.GetChangeCursor(ctx, &GetChangeCursorRequest{Consumer: "webhooks"})
+		if err != nil || cursorResp.Sequence != 5 {
+			t.Fatalf("want the cursor advanced to 5 with the enqueue, got %+v (%v)", cursorResp, err)
+		}
+
+		listResp, err := s.ListWebhookDeliveries(ctx, &ListWebhookDeliveriesRequest{WebhookID: webhookID, PageSize: 2})
+		if err != nil {
+			t.Fatalf("ListWebhookDeliveries: %v", err)
+		}
+		if len(listResp.Deliveries) != 2 || listResp.Deliveries[0].Sequence != 3 || listResp.NextPageToken != 2 {
+			t.Fatalf("want deliveries newest first with a next page, got %+v", listResp)
+		}
+		listResp, err = s.ListWebhookDeliveries(ctx, &ListWebhookDeliveriesRequest{WebhookID: webhookID, PageSize: 2, PageToken: 2})
+		if err != nil {
+			t.Fatalf("ListWebhookDeliveries: %v", err)
+		}
+		if len(listResp.Deliveries) != 1 || listResp.Deliveries[0].Sequence != 1 || listResp.NextPageToken != 0 {
+			t.Fatalf("want the last delivery on the second page, got %+v", listResp)
+		}
+
+		dueResp, err := s.ListDueWebhookDeliveries(ctx, &ListDueWebhookDeliveriesRequest{Now: now, Limit: 2})
+		if err != nil {
+			t.Fatalf("ListDueWebhookDeliveries: %v", err)
+		}
+		if len(dueResp.Deliveries) != 2 || dueResp.Deliveries[0].Sequence != 3 || dueResp.Deliveries[1].Sequence != 2 {
+			t.Fatalf("want the longest overdue deliveries first, got %+v", dueResp.Deliveries)
+		}
+
+		// Settling a delivery takes it off the due list.
+		settle(t, s, deliveries[2], func(d *WebhookDelivery) {
+			d.Status = DeliverySucceeded
+			d.Attempts = 1
+			d.NextAttemptAt = nil
+			d.LastStatusCode = 204
+		})
+		dueResp, err = s.ListDueWebhookDeliveries(ctx, &ListDueWebhookDeliveriesRequest{Now: now, Limit: 10})
+		if err != nil {
+			t.Fatalf("ListDueWebhookDeliveries: %v", err)
+		}
+		if len(dueResp.Deliveries) != 2 || dueResp.Deliveries[0].Sequence != 2 {
+			t.Fatalf("expected the settled delivery to no longer be due, got %+v", dueResp.Deliveries)
+		}
+
+		getResp, err := s.GetWebhookDelivery(ctx, &GetWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 3})
+		if err != nil || !getResp.Found {
+			t.Fatalf("GetWebhookDelivery: found=%v err=%v", getResp != nil && getResp.Found, err)
+		}
+		if getResp.Delivery.Status != DeliverySucceeded || getResp.Delivery.LastStatusCode != 204 {
+			t.Errorf("want the recorded outcome, got %+v", getResp.Delivery)
+		}
+
+		// Only settled deliveries are purged.
+		purgeResp, err := s.PurgeWebhookDeliveries(ctx, &PurgeWebhookDeliveriesRequest{Before: now})
+		if err != nil || purgeResp.Purged != 1 {
+			t.Fatalf("want the settled delivery purged, got %+v (%v)", purgeResp, err)
+		}
+		var deliveryNotFound *DeliveryNotFoundError
+		_, err = s.RedeliverWebhookDelivery(ctx, &RedeliverWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 3})
+		if !errors.As(err, &deliveryNotFound) {
+			t.Errorf("want *DeliveryNotFoundError redelivering a purged delivery, got %v", err)
+		}
+
+		settle(t, s, deliveries[0], func(d *WebhookDelivery) {
+			d.Status = DeliveryFailed
+			d.NextAttemptAt = nil
+		})
+		giveUpAt := now.Add(2 * time.Hour)
+		redeliverResp, err := s.RedeliverWebhookDelivery(ctx, &RedeliverWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 1, GiveUpAt: giveUpAt})
+		if err != nil {
+			t.Fatalf("RedeliverWebhookDelivery: %v", err)
+		}
+		if d := redeliverResp.Delivery; d.Status != DeliveryPending || d.NextAttemptAt == nil || !d.GiveUpAt.Equal(giveUpAt) {
+			t.Errorf("want the delivery pending again, got %+v", d)
+		}
+
+		// Deleting the webhook deletes its deliveries.
+		if _, err := s.DeleteWebhook(ctx, &DeleteWebhookRequest{WebhookID: webhookID}); err != nil {
+			t.Fatalf("DeleteWebhook: %v", err)
+		}
+		dueResp, err = s.ListDueWebhookDeliveries(ctx, &ListDueWebhookDeliveriesRequest{Now: time.Now(), Limit: 10})
+		if err != nil {
+			t.Fatalf("ListDueWebhookDeliveries: %v", err)
+		}
+		if len(dueResp.Deliveries) != 0 {
+			t.Errorf("expected the deliveries of the deleted webhook to be deleted, got %+v", dueResp.Deliveries)
+		}
+	})
+
+	run("enqueue only from the cursor", func(t *testing.T, s store) {
+		const webhookID = "018c7dbd-c000-7000-8000-0000000000f1"
+		now := time.Now().UTC().Truncate(time.Second)
+		delivery := func(status DeliveryStatus) *WebhookDelivery {
+			return &WebhookDelivery{WebhookID: webhookID, Sequence: 1, Status: status, CreatedAt: now, NextAttemptAt: &now, GiveUpAt: now.Add(time.Hour)}
+		}
+		enqueue := func(from, through int64) error {
+			_, err := s.EnqueueWebhookDeliveries(ctx, &EnqueueWebhookDeliveriesRequest{
+				Deliveries: []*WebhookDelivery{delivery(DeliveryPending)},
+				Consumer:   "webhooks",
+				From:       from,
+				Through:    through,
+			})
+			return err
+		}
+
+		if err := enqueue(0, 1); err != nil {
+			t.Fatalf("EnqueueWebhookDeliveries: %v", err)
+		}
+		settle(t, s, delivery(DeliveryPending), func(d *WebhookDelivery) {
+			d.Status = DeliverySucceeded
+			d.NextAttemptAt = nil
+		})
+
+		// An instance that read the cursor before the first enqueue.
+		var moved *CursorMovedError
+		if err := enqueue(0, 1); !errors.As(err, &moved) || moved.Actual != 1 {
+			t.Errorf("want *CursorMovedError at 1, got %v", err)
+		}
+		// Deliveries already stored are kept, even when the cursor matches.
+		if err := enqueue(1, 2); err != nil {
+			t.Fatalf("EnqueueWebhookDeliveries: %v", err)
+		}
+		resp, err := s.GetWebhookDelivery(ctx, &GetWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 1})
+		if err != nil || !resp.Found || resp.Delivery.Status != DeliverySucceeded {
+			t.Errorf("want the delivery left succeeded, got %+v (%v)", resp, err)
+		}
+		cursor, err := s.GetChangeCursor(ctx, &GetChangeCursorRequest{Consumer: "webhooks"})
+		if err != nil || cursor.Sequence != 2 {
+			t.Errorf("want the cursor at 2, got %+v (%v)", cursor, err)
+		}
+	})
+
+	run("delivery leases", func(t *testing.T, s store) {
+		const webhookID = "018c7dbd-c000-7000-8000-0000000000f1"
+		if _, err := s.CreateWebhook(ctx, &CreateWebhookRequest{Webhook: &Webhook{ID: webhookID}}); err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+		now := time.Now().UTC().Truncate(time.Second)
+		_, err := s.EnqueueWebhookDeliveries(ctx, &EnqueueWebhookDeliveriesRequest{
+			Deliveries: []*WebhookDelivery{{WebhookID: webhookID, Sequence: 1, Status: DeliveryPending, CreatedAt: now, NextAttemptAt: &now, GiveUpAt: now.Add(time.Hour)}},
+			Consumer:   "webhooks",
+			Through:    1,
+		})
+		if err != nil {
+			t.Fatalf("EnqueueWebhookDeliveries: %v", err)
+		}
+
+		claim := func(due time.Time) (*WebhookDelivery, error) {
+			resp, err := s.ClaimWebhookDelivery(ctx, &ClaimWebhookDeliveryRequest{
+				WebhookID:  webhookID,
+				Sequence:   1,
+				Due:        due,
+				LeaseUntil: time.Now().UTC().Add(time.Minute),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return resp.Delivery, nil
+		}
+		claimed, err := claim(now)
+		if err != nil {
+			t.Fatalf("ClaimWebhookDelivery: %v", err)
+		}
+		var changed *DeliveryChangedError
+		if _, err := claim(now); !errors.As(err, &changed) {
+			t.Errorf("want *DeliveryChangedError claiming a claimed delivery, got %v", err)
+		}
+
+		// A redelivery while the delivery is attempted wins over the
+		// attempt's outcome.
+		leasedUntil := *claimed.NextAttemptAt
+		redelivered, err := s.RedeliverWebhookDelivery(ctx, &RedeliverWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 1, GiveUpAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("RedeliverWebhookDelivery: %v", err)
+		}
+		claimed.Status = DeliverySucceeded
+		claimed.NextAttemptAt = nil
+		_, err = s.SetWebhookDelivery(ctx, &SetWebhookDeliveryRequest{Delivery: claimed, LeasedUntil: leasedUntil})
+		if !errors.As(err, &changed) {
+			t.Errorf("want *DeliveryChangedError setting a redelivered delivery, got %v", err)
+		}
+
+		// Nor is the outcome of a delivery of a deleted webhook recorded.
+		claimed, err = claim(*redelivered.Delivery.NextAttemptAt)
+		if err != nil {
+			t.Fatalf("ClaimWebhookDelivery: %v", err)
+		}
+		if _, err := s.DeleteWebhook(ctx, &DeleteWebhookRequest{WebhookID: webhookID}); err != nil {
+			t.Fatalf("DeleteWebhook: %v", err)
+		}
+		leasedUntil = *claimed.NextAttemptAt
+		claimed.Status = DeliveryFailed
+		claimed.NextAttemptAt = nil
+		_, err = s.SetWebhookDelivery(ctx, &SetWebhookDeliveryRequest{Delivery: claimed, LeasedUntil: leasedUntil})
+		if !errors.As(err, &changed) {
+			t.Errorf("want *DeliveryChangedError setting a deleted delivery, got %v", err)
+		}
+		if resp, err := s.GetWebhookDelivery(ctx, &GetWebhookDeliveryRequest{WebhookID: webhookID, Sequence: 1}); err != nil || resp.Found {
+			t.Errorf("expected no delivery of the deleted webhook, got %+v (%v)", resp, err)
+		}
+	})
+
+	run("concurrent creates with the same MAC", func(t *testing.T, s store) {
+		const n = 4
+		ids := make([]string, n)
+		errs := make([]error, n)
+
+		var wg sync.WaitGroup
+		for i := range n {
+			ids[i] = fmt.Sprintf("018c7dbd-c000-7000-8000-%012d", i)
+			wg.Go(func() {
+				_, errs[i] = s.CreateMachine(ctx, &CreateMachineRequest{
+					MachineID: ids[i],
+					Machine:   machineWith("02:00:00:00:00:01"),
+				})
+			})
+		}
+		wg.Wait()
+
+		// Under contention Firestore may give up on a transaction instead
+		// of reporting the conflict, so only the outcome is asserted: one
+		// winner, which owns the MAC, and no trace of the losers.
+		winner := ""
+		for i, err := range errs {
+			if err != nil {
+				if resp := mustGet(t, s, &GetMachineRequest{MachineID: ids[i]}); resp.Found {
+					t.Errorf("expected failed create of %s to leave no machine", ids[i])
+				}
+				continue
+			}
+			if winner != "" {
+				t.Fatalf("both %s and %s were created with the same MAC", winner, ids[i])
+			}
+			winner = ids[i]
+		}
+		if winner == "" {
+			t.Fatalf("expected one create to succeed, got %v", errs)
+		}
+		if got := owner(t, s, "02:00:00:00:00:01"); got != winner {
+			t.Errorf("want MAC owned by %s, got %q", winner, got)
+		}
+	})
+
+	run("concurrent writes keep the ledger consistent", func(t *testing.T, s store) {
+		const n = 8
+		ids := make([]string, n)
+		created := make([]bool, n)
+		labeled := make([]bool, n)
+
+		var wg sync.WaitGroup
+		for i := range n {
+			ids[i] = fmt.Sprintf("018c7dbd-c000-7000-8000-%012d", i)
+			wg.Go(func() {
+				_, err := s.CreateMachine(ctx, &CreateMachineRequest{
+					MachineID: ids[i],
+					Machine:   machineWith(MAC(fmt.Sprintf("02:00:00:00:00:%02x", i))),
+				})
+				if created[i] = err == nil; !created[i] {
+					return
+				}
+				_, err = s.SetMachineLabels(ctx, &SetMachineLabelsRequest{MachineID: ids[i], Labels: map[string]string{"rack": "a"}})
+				labeled[i] = err == nil
+			})
+		}
+		wg.Wait()
+
+		// As above, Firestore may give up on contended transactions, so
+		// the summary and change log are checked against the writes that
+		// succeeded: every one of them is counted once.
+		var machines, racked int64
+		for i := range n {
+			if created[i] {
+				machines++
+			}
+			if labeled[i] {
+				racked++
+			}
+		}
+		if machines == 0 {
+			t.Fatal("expected some creates to succeed")
+		}
+
+		resp, err := s.GetInventorySummary(ctx, &GetInventorySummaryRequest{})
+		if err != nil {
+			t.Fatalf("GetInventorySummary: %v", err)
+		}
+		if resp.Summary.Machines != machines || resp.Summary.MachinesByLabel["rack"]["a"] != racked {
+			t.Errorf("want %d machines, %d in rack a, got %+v", machines, racked, resp.Summary)
+		}
+
+		changes, err := s.ListMachineChanges(ctx, &ListMachineChangesRequest{PageSize: 2 * n})
+		if err != nil {
+			t.Fatalf("ListMachineChanges: %v", err)
+		}
+		if writes := machines + racked; changes.Latest != writes || int64(len(changes.Changes)) != writes {
+			t.Fatalf("want %d changes, got %d up to %d", writes, len(changes.Changes), changes.Latest)
+		}
+		seen := make(map[string]ChangeType)
+		for i, change := range changes.Changes {
+			if change.Sequence != int64(i+1) {
+				t.Errorf("want change %d at sequence %d, got %d", i, i+1, change.Sequence)
+			}
+			if _, ok := seen[change.MachineID]; ok != (change.Type == ChangeUpdated) {
+				t.Errorf("unexpected %s change of %s at sequence %d", change.Type, change.MachineID, change.Sequence)
+			}
+			seen[change.MachineID] = change.Type
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook is an HTTP endpoint that machine changes are delivered to.
+type Webhook struct {
+	ID  string `firestore:"id"`
+	URL string `firestore:"url"`
+
+	// Secret keys the HMAC-SHA256 signature of every delivery.
+	Secret string `firestore:"secret"`
+
+	// EventTypes restricts the webhook to changes of these types. Empty
+	// means every type.
+	EventTypes []ChangeType `firestore:"event_types"`
+
+	// LabelSelector restricts the webhook to machines whose labels, after
+	// the change, match it. Empty means every machine.
+	LabelSelector string `firestore:"label_selector"`
+
+	CreatedAt time.Time `firestore:"created_at"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+// Matches reports whether change should be delivered to the webhook.
+func (w *Webhook) Matches(change *MachineChange) bool {
+	if len(w.EventTypes) > 0 && !slices.Contains(w.EventTypes, change.Type) {
+		return false
+	}
+	if w.LabelSelector == "" {
+		return true
+	}
+
+	// Selectors are validated when the webhook is written, so one that no
+	// longer parses matches nothing rather than everything.
+	selector, err := ParseSelector(w.LabelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(change.Machine.Labels)
+}
+
+// DeliveryStatus is where a WebhookDelivery is in its lifecycle.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are attempted once NextAttemptAt passes.
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+
+	// DeliveryFailed deliveries were given up on at GiveUpAt, and are only
+	// attempted again if they are redelivered.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is the delivery of one change to one webhook, identified
+// by the webhook ID and the sequence of the change.
+type WebhookDelivery struct {
+	WebhookID string     `firestore:"webhook_id"`
+	Sequence  int64      `firestore:"sequence"`
+	EventType ChangeType `firestore:"event_type"`
+	MachineID string     `firestore:"machine_id"`
+
+	// Payload is the request body, encoded when the delivery is enqueued so
+	// that every attempt sends the same bytes.
+	Payload []byte `firestore:"payload"`
+
+	Status    DeliveryStatus `firestore:"status"`
+	Attempts  int            `firestore:"attempts"`
+	CreatedAt time.Time      `firestore:"created_at"`
+	GiveUpAt  time.Time      `firestore:"give_up_at"`
+
+	// NextAttemptAt is only set while the delivery is pending, so that the
+	// due deliveries can be found with a single field index.
+	NextAttemptAt *time.Time `firestore:"next_attempt_at,omitempty"`
+
+	// LastAttemptAt, LastStatusCode and LastError describe the most recent
+	// attempt. LastStatusCode is 0 if no response was received.
+	LastAttemptAt  *time.Time `firestore:"last_attempt_at"`
+	LastStatusCode int        `firestore:"last_status_code"`
+	LastError      string     `firestore:"last_error"`
+}
+
+// pendingAt reports whether the delivery is pending with its next attempt at
+// t, as it was when it was listed as due or claimed.
+func (d *WebhookDelivery) pendingAt(t time.Time) bool {
+	return d.Status == DeliveryPending && d.NextAttemptAt != nil && d.NextAttemptAt.Equal(t)
+}
+
+// redeliver makes the delivery pending again, due at now.
+func (d *WebhookDelivery) redeliver(now, giveUpAt time.Time) {
+	d.Status = DeliveryPending
+	d.NextAttemptAt = &now
+	d.GiveUpAt = giveUpAt
+}
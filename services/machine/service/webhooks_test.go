@@ -0,0 +1,32 @@
+package service
+
+import "testing"
+
+func TestWebhook_Matches(t *testing.T) {
+	change := &MachineChange{
+		Type:    ChangeUpdated,
+		Machine: Machine{Labels: map[string]string{"rack": "r1", "zone": "a"}},
+	}
+
+	tests := []struct {
+		name    string
+		webhook *Webhook
+		want    bool
+	}{
+		{"no filters", &Webhook{}, true},
+		{"matching type", &Webhook{EventTypes: []ChangeType{ChangeCreated, ChangeUpdated}}, true},
+		{"other type", &Webhook{EventTypes: []ChangeType{ChangeDeleted}}, false},
+		{"matching selector", &Webhook{LabelSelector: "rack=r1,zone in (a,b)"}, true},
+		{"other selector", &Webhook{LabelSelector: "rack=r2"}, false},
+		{"matching type, other selector", &Webhook{EventTypes: []ChangeType{ChangeUpdated}, LabelSelector: "!zone"}, false},
+		{"invalid selector", &Webhook{LabelSelector: "rack in r1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.webhook.Matches(change); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
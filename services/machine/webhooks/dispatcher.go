@@ -0,0 +1,337 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/events"
+	"github.com/Zaba505/infra/services/machine/service"
+	"github.com/sourcegraph/conc/pool"
+)
+
+const (
+	// Consumer names the change cursor deliveries are enqueued up to.
+	Consumer = "webhooks"
+
+	pageSize = 100
+
+	// maxEnqueueBatch bounds the deliveries written per transaction, well
+	// inside the 500 writes Firestore allows.
+	maxEnqueueBatch = 400
+
+	// maxErrorLength bounds the response body excerpt kept as LastError.
+	maxErrorLength = 512
+
+	// leaseMargin is how much longer than Config.Timeout a claimed delivery
+	// is leased, to cover claiming it and recording its outcome.
+	leaseMargin = time.Minute
+)
+
+// Storage is the part of the storage a Dispatcher uses.
+type Storage interface {
+	ListMachineChanges(ctx context.Context, req *service.ListMachineChangesRequest) (*service.ListMachineChangesResponse, error)
+	GetChangeCursor(ctx context.Context, req *service.GetChangeCursorRequest) (*service.GetChangeCursorResponse, error)
+	ListWebhooks(ctx context.Context, req *service.ListWebhooksRequest) (*service.ListWebhooksResponse, error)
+	EnqueueWebhookDeliveries(ctx context.Context, req *service.EnqueueWebhookDeliveriesRequest) (*service.EnqueueWebhookDeliveriesResponse, error)
+	ListDueWebhookDeliveries(ctx context.Context, req *service.ListDueWebhookDeliveriesRequest) (*service.ListDueWebhookDeliveriesResponse, error)
+	ClaimWebhookDelivery(ctx context.Context, req *service.ClaimWebhookDeliveryRequest) (*service.ClaimWebhookDeliveryResponse, error)
+	SetWebhookDelivery(ctx context.Context, req *service.SetWebhookDeliveryRequest) (*service.SetWebhookDeliveryResponse, error)
+}
+
+// Config controls how deliveries are retried. A failed attempt is retried
+// after MinBackoff, doubling with every further attempt up to MaxBackoff,
+// until the next attempt would fall more than Horizon after the delivery
+// was enqueued. An instance attempting a delivery leases it for Timeout plus
+// a minute; if the instance stops, the delivery is attempted again after.
+type Config struct {
+	Horizon     time.Duration
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+	Concurrency int
+}
+
+// Dispatcher turns the change log into deliveries for every matching
+// webhook and attempts the deliveries that are due.
+type Dispatcher struct {
+	log     *slog.Logger
+	storage Storage
+	encode  events.Encoder
+	client  *http.Client
+	cfg     Config
+}
+
+func NewDispatcher(storage Storage, encode events.Encoder, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		log:     slog.Default(),
+		storage: storage,
+		encode:  encode,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		cfg:     cfg,
+	}
+}
+
+// Run enqueues and delivers every pollInterval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		enqueued, err := d.Enqueue(ctx)
+		if err != nil {
+			d.log.ErrorContext(ctx, "failed to enqueue webhook deliveries", slog.Int("enqueued", enqueued), slog.Any("error", err))
+		} else if enqueued > 0 {
+			d.log.DebugContext(ctx, "enqueued webhook deliveries", slog.Int("count", enqueued))
+		}
+
+		attempted, err := d.Deliver(ctx)
+		if err != nil {
+			d.log.ErrorContext(ctx, "failed to deliver webhooks", slog.Int("attempted", attempted), slog.Any("error", err))
+		} else if attempted > 0 {
+			d.log.DebugContext(ctx, "attempted webhook deliveries", slog.Int("count", attempted))
+		}
+	}
+}
+
+// Enqueue stores a delivery for every webhook matching each change after the
+// dispatcher's cursor, and reports how many it stored. The cursor advances
+// in the same transaction as the deliveries, only from where this call read
+// it, so each change is enqueued once even when several instances run a
+// dispatcher. When another instance has moved the cursor, Enqueue leaves
+// the changes to it.
+func (d *Dispatcher) Enqueue(ctx context.Context) (int, error) {
+	cursor, err := d.storage.GetChangeCursor(ctx, &service.GetChangeCursorRequest{Consumer: Consumer})
+	if err != nil {
+		return 0, err
+	}
+	webhooksResp, err := d.storage.ListWebhooks(ctx, &service.ListWebhooksRequest{})
+	if err != nil {
+		return 0, err
+	}
+
+	// after is where the next page of changes starts, and from is where
+	// the cursor is expected to be by the next batch.
+	after, from := cursor.Sequence, cursor.Sequence
+	var enqueued int
+	for {
+		resp, err := d.storage.ListMachineChanges(ctx, &service.ListMachineChangesRequest{After: after, PageSize: pageSize})
+		if err != nil {
+			return enqueued, err
+		}
+		if len(resp.Changes) == 0 {
+			return enqueued, nil
+		}
+
+		var batch []*service.WebhookDelivery
+		for _, change := range resp.Changes {
+			deliveries, err := d.deliveriesFor(change, webhooksResp.Webhooks)
+			if err != nil {
+				return enqueued, err
+			}
+			batch = append(batch, deliveries...)
+
+			// Batches end on a change boundary, so that the cursor never
+			// points into the middle of a change's deliveries.
+			if len(batch) >= maxEnqueueBatch || change == resp.Changes[len(resp.Changes)-1] {
+				_, err := d.storage.EnqueueWebhookDeliveries(ctx, &service.EnqueueWebhookDeliveriesRequest{
+					Deliveries: batch,
+					Consumer:   Consumer,
+					From:       from,
+					Through:    change.Sequence,
+				})
+				var moved *service.CursorMovedError
+				if errors.As(err, &moved) {
+					return enqueued, nil
+				}
+				if err != nil {
+					return enqueued, err
+				}
+				enqueued += len(batch)
+				from = change.Sequence
+				batch = nil
+			}
+		}
+		after = resp.Changes[len(resp.Changes)-1].Sequence
+	}
+}
+
+func (d *Dispatcher) deliveriesFor(change *service.MachineChange, webhooks []*service.Webhook) ([]*service.WebhookDelivery, error) {
+	var deliveries []*service.WebhookDelivery
+	var payload []byte
+	for _, webhook := range webhooks {
+		// A webhook only receives changes made after it was registered,
+		// even if the dispatcher is behind when it is.
+		if change.At.Before(webhook.CreatedAt) || !webhook.Matches(change) {
+			continue
+		}
+		if payload == nil {
+			event, err := d.encode(change)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode change %d: %w", change.Sequence, err)
+			}
+			payload, err = json.Marshal(event)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode change %d: %w", change.Sequence, err)
+			}
+		}
+
+		now := time.Now().UTC()
+		deliveries = append(deliveries, &service.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Sequence:      change.Sequence,
+			EventType:     change.Type,
+			MachineID:     change.MachineID,
+			Payload:       payload,
+			Status:        service.DeliveryPending,
+			CreatedAt:     now,
+			NextAttemptAt: &now,
+			GiveUpAt:      now.Add(d.cfg.Horizon),
+		})
+	}
+	return deliveries, nil
+}
+
+// Deliver attempts the deliveries that are due and records their outcomes,
+// reporting how many it attempted. Each delivery is claimed before it is
+// attempted, so that instances do not attempt the same one, and its outcome
+// is dropped if it was redelivered or deleted meanwhile.
+func (d *Dispatcher) Deliver(ctx context.Context) (int, error) {
+	due, err := d.storage.ListDueWebhookDeliveries(ctx, &service.ListDueWebhookDeliveriesRequest{
+		Now:   time.Now().UTC(),
+		Limit: pageSize,
+	})
+	if err != nil || len(due.Deliveries) == 0 {
+		return 0, err
+	}
+	webhooksResp, err := d.storage.ListWebhooks(ctx, &service.ListWebhooksRequest{})
+	if err != nil {
+		return 0, err
+	}
+	webhooks := make(map[string]*service.Webhook, len(webhooksResp.Webhooks))
+	for _, webhook := range webhooksResp.Webhooks {
+		webhooks[webhook.ID] = webhook
+	}
+
+	var attempted atomic.Int64
+	p := pool.New().WithErrors().WithContext(ctx).WithMaxGoroutines(max(d.cfg.Concurrency, 1))
+	for _, delivery := range due.Deliveries {
+		p.Go(func(ctx context.Context) error {
+			claimed, err := d.storage.ClaimWebhookDelivery(ctx, &service.ClaimWebhookDeliveryRequest{
+				WebhookID:  delivery.WebhookID,
+				Sequence:   delivery.Sequence,
+				Due:        *delivery.NextAttemptAt,
+				LeaseUntil: time.Now().UTC().Add(d.cfg.Timeout + leaseMargin),
+			})
+			var changed *service.DeliveryChangedError
+			if errors.As(err, &changed) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			delivery := claimed.Delivery
+			leasedUntil := *delivery.NextAttemptAt
+			attempted.Add(1)
+			d.attempt(ctx, webhooks[delivery.WebhookID], delivery)
+			_, err = d.storage.SetWebhookDelivery(ctx, &service.SetWebhookDeliveryRequest{
+				Delivery:    delivery,
+				LeasedUntil: leasedUntil,
+			})
+			if errors.As(err, &changed) {
+				d.log.InfoContext(ctx, "dropped the outcome of a webhook delivery changed while it was attempted",
+					slog.String("webhook_id", delivery.WebhookID),
+					slog.Int64("sequence", delivery.Sequence),
+				)
+				return nil
+			}
+			return err
+		})
+	}
+	err = p.Wait()
+	return int(attempted.Load()), err
+}
+
+// attempt makes one delivery attempt and records its outcome on delivery.
+func (d *Dispatcher) attempt(ctx context.Context, webhook *service.Webhook, delivery *service.WebhookDelivery) {
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	// The webhook was deleted after the delivery was listed as due.
+	if webhook == nil {
+		delivery.Status = service.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook no longer exists"
+		return
+	}
+
+	statusCode, err := d.post(ctx, webhook, delivery, now)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.Status = service.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		return
+	}
+	delivery.LastError = err.Error()
+
+	next := now.Add(d.backoff(delivery.Attempts))
+	if next.After(delivery.GiveUpAt) {
+		delivery.Status = service.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	delivery.NextAttemptAt = &next
+}
+
+// post sends the delivery, reporting the status code of the response if one
+// was received, and an error unless it was a 2xx.
+func (d *Dispatcher) post(ctx context.Context, webhook *service.Webhook, delivery *service.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set(WebhookIDHeader, webhook.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+	return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+// backoff is the delay before the attempt after the given number of
+// attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.MinBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
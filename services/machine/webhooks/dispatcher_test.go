@@ -0,0 +1,253 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Zaba505/infra/services/machine/events"
+	"github.com/Zaba505/infra/services/machine/service"
+)
+
+const (
+	machineID = "018c7dbd-c000-7000-8000-fedcba987654"
+	webhookID = "018c7dbd-c000-7000-8000-0000000000f1"
+	secret    = "s3cret"
+)
+
+func encode(change *service.MachineChange) (*events.CloudEvent, error) {
+	return events.NewMachineEvent(change, json.RawMessage(`{}`)), nil
+}
+
+// receiver records the deliveries it verifies, and responds with the status
+// codes in statuses in turn, then 204.
+type receiver struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	statuses  []int
+	delivered []*events.CloudEvent
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := Verify(secret, r.Header, body, time.Minute, time.Now()); err != nil {
+		rc.t.Errorf("Verify: %v", err)
+	}
+	if r.Header.Get("Content-Type") != ContentType || r.Header.Get(WebhookIDHeader) != webhookID {
+		rc.t.Errorf("unexpected headers %v", r.Header)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.statuses) > 0 {
+		status := rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+		http.Error(w, "unavailable", status)
+		return
+	}
+	var event events.CloudEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		rc.t.Errorf("failed to decode delivery: %v", err)
+	}
+	rc.delivered = append(rc.delivered, &event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setup(t *testing.T, webhook *service.Webhook, statuses ...int) (*service.MemoryStore, *receiver) {
+	t.Helper()
+	ctx := context.Background()
+
+	rc := &receiver{t: t, statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	store := service.NewMemoryStore()
+	t.Cleanup(func() { store.Close() })
+
+	webhook.ID = webhookID
+	webhook.URL = srv.URL
+	webhook.Secret = secret
+	webhook.CreatedAt = time.Now().UTC().Add(-time.Minute)
+	if _, err := store.CreateWebhook(ctx, &service.CreateWebhookRequest{Webhook: webhook}); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return store, rc
+}
+
+func getDelivery(t *testing.T, store *service.MemoryStore, sequence int64) *service.WebhookDelivery {
+	t.Helper()
+	resp, err := store.GetWebhookDelivery(context.Background(), &service.GetWebhookDeliveryRequest{WebhookID: webhookID, Sequence: sequence})
+	if err != nil || !resp.Found {
+		t.Fatalf("GetWebhookDelivery(%d): found=%v err=%v", sequence, resp != nil && resp.Found, err)
+	}
+	return resp.Delivery
+}
+
+func TestDispatcher_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setup(t, &service.Webhook{
+		EventTypes:    []service.ChangeType{service.ChangeUpdated},
+		LabelSelector: "rack=r1",
+	})
+
+	if _, err := store.CreateMachine(ctx, &service.CreateMachineRequest{MachineID: machineID, Machine: &service.MachineRequest{}}); err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+	for _, rack := range []string{"r2", "r1"} {
+		_, err := store.SetMachineLabels(ctx, &service.SetMachineLabelsRequest{MachineID: machineID, Labels: map[string]string{"rack": rack}})
+		if err != nil {
+			t.Fatalf("SetMachineLabels: %v", err)
+		}
+	}
+
+	dispatcher := NewDispatcher(store, encode, Config{Horizon: time.Hour})
+	enqueued, err := dispatcher.Enqueue(ctx)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	// Only the update into rack r1 matches both filters.
+	if enqueued != 1 {
+		t.Fatalf("want 1 delivery enqueued, got %d", enqueued)
+	}
+	delivery := getDelivery(t, store, 3)
+	if delivery.Status != service.DeliveryPending || delivery.EventType != service.ChangeUpdated || delivery.MachineID != machineID {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	cursor, err := store.GetChangeCursor(ctx, &service.GetChangeCursorRequest{Consumer: Consumer})
+	if err != nil || cursor.Sequence != 3 {
+		t.Fatalf("want the cursor at 3, got %+v (%v)", cursor, err)
+	}
+	if enqueued, err := dispatcher.Enqueue(ctx); err != nil || enqueued != 0 {
+		t.Errorf("expected nothing more to enqueue, got %d (%v)", enqueued, err)
+	}
+}
+
+// staleCursor reports the change cursor as it was before any enqueue, as an
+// instance which read it before another instance enqueued would.
+type staleCursor struct {
+	*service.MemoryStore
+}
+
+func (staleCursor) GetChangeCursor(context.Context, *service.GetChangeCursorRequest) (*service.GetChangeCursorResponse, error) {
+	return &service.GetChangeCursorResponse{}, nil
+}
+
+func TestDispatcher_Enqueue_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store, rc := setup(t, &service.Webhook{})
+	if _, err := store.CreateMachine(ctx, &service.CreateMachineRequest{MachineID: machineID, Machine: &service.MachineRequest{}}); err != nil {
+		t.Fatalf("CreateMachine: %v", err)
+	}
+
+	dispatcher := NewDispatcher(store, encode, Config{Horizon: time.Hour})
+	if _, err := dispatcher.Enqueue(ctx); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := dispatcher.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	stale := NewDispatcher(staleCursor{store}, encode, Config{Horizon: time.Hour})
+	if enqueued, err := stale.Enqueue(ctx); err != nil || enqueued != 0 {
+		t.Fatalf("expected the stale instance to enqueue nothing, got %d (%v)", enqueued, err)
+	}
+	if _, err := dispatcher.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if delivery := getDelivery(t, store, 1); delivery.Status != service.DeliverySucceeded || len(rc.delivered) != 1 {
+		t.Errorf("want the event delivered once, got %+v and %d deliveries", delivery, len(rc.delivered))
+	}
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		cfg          Config
+		wantStatus   service.DeliveryStatus
+		wantAttempts int
+	}{
+		{
+			name:         "first attempt succeeds",
+			cfg:          Config{Horizon: time.Hour, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			wantStatus:   service.DeliverySucceeded,
+			wantAttempts: 1,
+		},
+		{
+			name:         "succeeds after retries",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusInternalServerError},
+			cfg:          Config{Horizon: time.Hour, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			wantStatus:   service.DeliverySucceeded,
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after the horizon",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			cfg:          Config{Horizon: time.Minute, MinBackoff: time.Hour, MaxBackoff: time.Hour},
+			wantStatus:   service.DeliveryFailed,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, rc := setup(t, &service.Webhook{}, tt.statuses...)
+			if _, err := store.CreateMachine(ctx, &service.CreateMachineRequest{MachineID: machineID, Machine: &service.MachineRequest{}}); err != nil {
+				t.Fatalf("CreateMachine: %v", err)
+			}
+
+			dispatcher := NewDispatcher(store, encode, tt.cfg)
+			if _, err := dispatcher.Enqueue(ctx); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			for range 5 {
+				if _, err := dispatcher.Deliver(ctx); err != nil {
+					t.Fatalf("Deliver: %v", err)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			delivery := getDelivery(t, store, 1)
+			if delivery.Status != tt.wantStatus || delivery.Attempts != tt.wantAttempts {
+				t.Fatalf("want %s after %d attempts, got %+v", tt.wantStatus, tt.wantAttempts, delivery)
+			}
+			if delivery.NextAttemptAt != nil {
+				t.Errorf("expected a settled delivery to have no next attempt, got %v", delivery.NextAttemptAt)
+			}
+			if tt.wantStatus == service.DeliveryFailed && (delivery.LastStatusCode != http.StatusServiceUnavailable || delivery.LastError == "") {
+				t.Errorf("expected the last failure to be recorded, got %+v", delivery)
+			}
+			if tt.wantStatus == service.DeliverySucceeded && (len(rc.delivered) != 1 || rc.delivered[0].Subject != machineID) {
+				t.Errorf("want the event delivered once, got %+v", rc.delivered)
+			}
+		})
+	}
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	d := NewDispatcher(nil, encode, Config{MinBackoff: 10 * time.Second, MaxBackoff: time.Minute})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d): want %s, got %s", tt.attempts, tt.want, got)
+		}
+	}
+}
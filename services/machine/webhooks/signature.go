@@ -0,0 +1,72 @@
+// Package webhooks delivers machine changes to the HTTP endpoints registered
+// as webhooks.
+//
+// Every delivery is a POST of the change as a structured mode CloudEvent,
+// signed with the webhook's secret so that receivers can check it came from
+// the machine service. Deliveries that fail are retried with exponential
+// backoff until the configured horizon passes.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the timestamp and body,
+	// formatted as "sha256=<hex>".
+	SignatureHeader = "X-Webhook-Signature"
+
+	// TimestampHeader carries the Unix time the attempt was signed at.
+	// Receivers should reject timestamps too far from their own clock, so
+	// that captured deliveries cannot be replayed later.
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	// WebhookIDHeader names the webhook a delivery was made for.
+	WebhookIDHeader = "X-Webhook-Id"
+
+	// ContentType is the media type of structured mode CloudEvents.
+	ContentType = "application/cloudevents+json"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature of body sent at timestamp. The HMAC covers
+// the timestamp as well as the body, joined by a dot, so that neither can
+// be altered without the secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a delivery with body against
+// secret, for use by receivers. Deliveries signed further than tolerance
+// from now are rejected.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header", TimestampHeader)
+	}
+	timestamp := time.Unix(unix, 0)
+	if d := now.Sub(timestamp); d > tolerance || d < -tolerance {
+		return fmt.Errorf("delivery was signed at %s, outside the tolerance of %s", timestamp.UTC().Format(time.RFC3339), tolerance)
+	}
+
+	signature := header.Get(SignatureHeader)
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("invalid %s header", SignatureHeader)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	signedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"1"}`)
+	signed := func(secret string, at time.Time) http.Header {
+		header := http.Header{}
+		header.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+		header.Set(SignatureHeader, Sign(secret, at, body))
+		return header
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", signed(secret, signedAt), body, signedAt.Add(time.Second), false},
+		{"wrong secret", signed("other", signedAt), body, signedAt, true},
+		{"altered body", signed(secret, signedAt), []byte(`{"id":"2"}`), signedAt, true},
+		{"expired", signed(secret, signedAt), body, signedAt.Add(time.Hour), true},
+		{"missing headers", http.Header{}, body, signedAt, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("want error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerify_AlteredTimestamp(t *testing.T) {
+	signedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{}`)
+
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(secret, signedAt, body))
+	header.Set(TimestampHeader, strconv.FormatInt(signedAt.Add(time.Minute).Unix(), 10))
+	if err := Verify(secret, header, body, 5*time.Minute, signedAt); err == nil {
+		t.Error("expected a signature over another timestamp to be rejected")
+	}
+}